@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 	"syscall"
 
 	"github.com/spf13/cobra"
@@ -73,26 +72,27 @@ Interactive login:
 
 		// Validate credentials by making an API call
 		formatter := newFormatter()
-		if formatter.Format != "json" && !formatter.Quiet {
+		if !formatter.Structured() && !formatter.Quiet {
 			fmt.Print("Validating credentials... ")
 		}
 
 		client := api.NewClient(auth)
 		user, err := client.GetMe()
 		if err != nil {
-			if formatter.Format != "json" && !formatter.Quiet {
+			if !formatter.Structured() && !formatter.Quiet {
 				fmt.Println("failed")
 			}
 			return fmt.Errorf("authentication failed: %v", err)
 		}
 
-		if formatter.Format != "json" && !formatter.Quiet {
+		if !formatter.Structured() && !formatter.Quiet {
 			fmt.Println("ok")
 		}
 
 		// Store user info in credentials
 		creds.UserID = user.ID
 		creds.UserName = user.Name
+		creds.UserTimezone = user.Timezone
 
 		// Save credentials
 		if err := config.SaveCredentials(creds); err != nil {
@@ -144,7 +144,7 @@ var statusAuthCmd = &cobra.Command{
 		formatter := newFormatter()
 
 		if creds == nil {
-			if formatter.Format == "json" {
+			if formatter.Structured() {
 				return formatter.FormatTimerStatus(map[string]interface{}{
 					"authenticated": false,
 				})
@@ -165,7 +165,7 @@ var statusAuthCmd = &cobra.Command{
 			}
 		}
 
-		if formatter.Format == "json" {
+		if formatter.Structured() {
 			status := map[string]interface{}{
 				"authenticated": true,
 				"method":        creds.AuthType,
@@ -199,6 +199,10 @@ var statusAuthCmd = &cobra.Command{
 // When caching is enabled, the returned client transparently caches reads.
 // Defined as a var to allow test injection.
 var getAPIClient = func() (api.PaymoAPI, error) {
+	if viper.GetBool("offline") {
+		return offlineClient()
+	}
+
 	// Check environment variable first
 	if envKey := config.GetAPIKeyFromEnv(); envKey != "" {
 		auth := &api.APIKeyAuth{APIKey: envKey}
@@ -237,24 +241,28 @@ var getAPIClient = func() (api.PaymoAPI, error) {
 }
 
 
-// wrapWithCache wraps a client with the JSON file cache layer if enabled.
+// wrapWithCache wraps a client with the cache layer if enabled.
 func wrapWithCache(client api.PaymoAPI) api.PaymoAPI {
 	if viper.GetBool("no_cache") {
 		return client
 	}
-	cacheDir, err := config.GetConfigDir()
+	cachePath, err := cacheFilePath()
 	if err != nil {
 		return client
 	}
-	cachePath := filepath.Join(cacheDir, "cache.json")
-	store, err := cache.Open(cachePath)
+	store, err := openCache(cachePath)
 	if err != nil {
 		if viper.GetBool("verbose") {
 			fmt.Fprintf(os.Stderr, "Warning: cache unavailable: %v\n", err)
 		}
 		return client
 	}
-	return cache.NewCachedClient(client, store)
+	cached := cache.NewCachedClient(client, store)
+	if grace, err := cacheGrace(); err == nil {
+		cached.SetGrace(grace)
+	}
+	sessionCache = cached
+	return cached
 }
 
 func init() {
@@ -1,14 +1,21 @@
 package cmd
 
 import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/ComputClaw/paymo-cli/internal/cache"
 	"github.com/ComputClaw/paymo-cli/internal/config"
+	"github.com/ComputClaw/paymo-cli/internal/output"
 )
 
 var cacheCmd = &cobra.Command{
@@ -21,19 +28,20 @@ var cacheClearCmd = &cobra.Command{
 	Use:   "clear",
 	Short: "Clear all cached data",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cacheDir, err := config.GetConfigDir()
+		dbPath, err := cacheFilePath()
 		if err != nil {
 			return fmt.Errorf("getting config dir: %w", err)
 		}
-		dbPath := filepath.Join(cacheDir, "cache.json")
 
-		// Check if cache file exists
-		if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+		// Check if cache file exists (or a JSON cache still to be migrated)
+		_, err = os.Stat(dbPath)
+		_, legacyErr := os.Stat(filepath.Join(filepath.Dir(dbPath), cache.LegacyFileName))
+		if os.IsNotExist(err) && os.IsNotExist(legacyErr) {
 			formatter := newFormatter()
 			return formatter.FormatSuccess("No cache to clear.", 0)
 		}
 
-		store, err := cache.Open(dbPath)
+		store, err := openCache(dbPath)
 		if err != nil {
 			return fmt.Errorf("opening cache: %w", err)
 		}
@@ -52,18 +60,17 @@ var cacheStatusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show cache statistics",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cacheDir, err := config.GetConfigDir()
+		dbPath, err := cacheFilePath()
 		if err != nil {
 			return fmt.Errorf("getting config dir: %w", err)
 		}
-		dbPath := filepath.Join(cacheDir, "cache.json")
 
 		formatter := newFormatter()
 
 		// Check if cache file exists
 		info, statErr := os.Stat(dbPath)
 		if os.IsNotExist(statErr) {
-			if formatter.Format == "json" {
+			if formatter.Structured() {
 				return formatter.FormatTimerStatus(map[string]interface{}{
 					"enabled":  true,
 					"entries":  0,
@@ -75,7 +82,7 @@ var cacheStatusCmd = &cobra.Command{
 			return nil
 		}
 
-		store, err := cache.Open(dbPath)
+		store, err := openCache(dbPath)
 		if err != nil {
 			return fmt.Errorf("opening cache: %w", err)
 		}
@@ -96,14 +103,26 @@ var cacheStatusCmd = &cobra.Command{
 			sizeKB = info.Size() / 1024
 		}
 
-		if formatter.Format == "json" {
-			return formatter.FormatTimerStatus(map[string]interface{}{
-				"enabled":    true,
-				"entries":    total,
-				"size_kb":    sizeKB,
-				"db_path":    dbPath,
-				"by_type":    stats,
-			})
+		hits, err := store.HitStats()
+		if err != nil {
+			return fmt.Errorf("reading cache hit counters: %w", err)
+		}
+		reads := cache.TotalHitStats(hits)
+
+		if formatter.Structured() {
+			status := map[string]interface{}{
+				"enabled":       true,
+				"entries":       total,
+				"size_kb":       sizeKB,
+				"db_path":       dbPath,
+				"by_type":       stats,
+				"reads":         reads,
+				"reads_by_type": hits,
+			}
+			if rate, ok := reads.HitRate(); ok {
+				status["hit_rate"] = rate
+			}
+			return formatter.FormatTimerStatus(status)
 		}
 
 		fmt.Fprintf(formatter.Writer, "Cache Status\n")
@@ -116,13 +135,586 @@ var cacheStatusCmd = &cobra.Command{
 				fmt.Fprintf(formatter.Writer, "    %-20s %d\n", rt, count)
 			}
 		}
+		fmt.Fprintf(formatter.Writer, "  Hit rate: %s\n", describeHits(reads))
+		if len(hits) > 0 {
+			fmt.Fprintf(formatter.Writer, "  Reads by type:\n")
+			types := make([]string, 0, len(hits))
+			for rt := range hits {
+				types = append(types, rt)
+			}
+			sort.Strings(types)
+			for _, rt := range types {
+				fmt.Fprintf(formatter.Writer, "    %-20s %s\n", rt, describeHits(hits[rt]))
+			}
+		}
 
 		return nil
 	},
 }
 
+// describeHits summarizes read counters:
+// "84.0% (420 hits, 12 grace, 68 misses, 3 stale)".
+func describeHits(h cache.HitStats) string {
+	rate, ok := h.HitRate()
+	if !ok {
+		return "no reads yet"
+	}
+	return fmt.Sprintf("%.1f%% (%d hits, %d grace, %d misses, %d stale)", rate*100, h.Hits, h.Grace, h.Misses, h.Stale)
+}
+
+var cacheLsCmd = &cobra.Command{
+	Use:   "ls [type]",
+	Short: "List cached resource types, or the entries of one",
+	Long: `List the cached resource types with their entry counts and TTLs, or, given
+a type, its cached entries with when each was fetched and when it expires.`,
+	Example: `  paymo cache ls
+  paymo cache ls tasks`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 1 {
+			if err := checkCacheType(args[0]); err != nil {
+				return err
+			}
+		}
+		store, err := openExistingCache()
+		if err != nil {
+			return err
+		}
+		formatter := newFormatter()
+		if store == nil {
+			if formatter.Structured() {
+				return formatter.FormatTimerStatus([]interface{}{})
+			}
+			fmt.Fprintln(formatter.Writer, "Cache is empty (no database file).")
+			return nil
+		}
+		defer store.Close()
+
+		if len(args) == 1 {
+			return listCacheEntries(formatter, store, args[0])
+		}
+		return listCacheTypes(formatter, store)
+	},
+}
+
+// listCacheTypes prints the cached types with their entry counts and TTLs.
+func listCacheTypes(formatter *output.Formatter, store *cache.Store) error {
+	stats, err := store.Stats()
+	if err != nil {
+		return fmt.Errorf("reading cache stats: %w", err)
+	}
+	types := make([]string, 0, len(stats))
+	for rt := range stats {
+		types = append(types, rt)
+	}
+	sort.Strings(types)
+
+	rows := []map[string]interface{}{}
+	for _, rt := range types {
+		entries, err := store.Entries(rt)
+		if err != nil {
+			return fmt.Errorf("reading cached %s: %w", rt, err)
+		}
+		expired := 0
+		for _, e := range entries {
+			if e.Expired {
+				expired++
+			}
+		}
+		rows = append(rows, map[string]interface{}{
+			"type":        rt,
+			"entries":     len(entries),
+			"expired":     expired,
+			"ttl_seconds": int64(store.TTL(rt).Seconds()),
+		})
+	}
+
+	if formatter.Structured() {
+		return formatter.FormatTimerStatus(rows)
+	}
+	if len(rows) == 0 {
+		fmt.Fprintln(formatter.Writer, "Nothing is cached.")
+		return nil
+	}
+	fmt.Fprintf(formatter.Writer, "%-16s %7s %7s  %s\n", "TYPE", "ENTRIES", "EXPIRED", "TTL")
+	for _, row := range rows {
+		rt := row["type"].(string)
+		fmt.Fprintf(formatter.Writer, "%-16s %7d %7d  %s\n", rt, row["entries"], row["expired"], formatTTL(store.TTL(rt)))
+	}
+	return nil
+}
+
+// listCacheEntries prints the cached entries of a type.
+func listCacheEntries(formatter *output.Formatter, store *cache.Store, resourceType string) error {
+	entries, err := store.Entries(resourceType)
+	if err != nil {
+		return fmt.Errorf("reading cached %s: %w", resourceType, err)
+	}
+
+	if formatter.Structured() {
+		rows := make([]map[string]interface{}, len(entries))
+		for i, e := range entries {
+			rows[i] = cacheEntryInfo(resourceType, e)
+		}
+		return formatter.FormatTimerStatus(rows)
+	}
+	if len(entries) == 0 {
+		fmt.Fprintf(formatter.Writer, "No cached %s entries.\n", resourceType)
+		return nil
+	}
+	now := time.Now()
+	fmt.Fprintf(formatter.Writer, "%-30s %-20s %6s  %s\n", "KEY", "CACHED", "AGE", "EXPIRES")
+	for _, e := range entries {
+		fmt.Fprintf(formatter.Writer, "%-30s %-20s %6s  %s\n", e.Key, formatter.Locale.DateTime(e.CachedAt),
+			formatAge(now.Sub(e.CachedAt)), describeExpiry(e, now))
+	}
+	return nil
+}
+
+// cacheEntryInfo is the structured form of a cached entry.
+func cacheEntryInfo(resourceType string, e cache.EntryInfo) map[string]interface{} {
+	return map[string]interface{}{
+		"type":        resourceType,
+		"key":         e.Key,
+		"cached_at":   e.CachedAt,
+		"expires_at":  e.CachedAt.Add(e.TTL),
+		"ttl_seconds": int64(e.TTL.Seconds()),
+		"expired":     e.Expired,
+		"size":        e.Size,
+	}
+}
+
+// describeExpiry tells when an entry expires ("in 25m") or that it has.
+func describeExpiry(e cache.EntryInfo, now time.Time) string {
+	if e.Expired {
+		return "expired"
+	}
+	return "in " + formatAge(e.CachedAt.Add(e.TTL).Sub(now))
+}
+
+// formatTTL renders a TTL without zero units: 1h, 1h30m, 10m.
+func formatTTL(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+var cacheShowCmd = &cobra.Command{
+	Use:   "show <type> <key>",
+	Short: "Show a cached entry",
+	Long: `Show the data of one cached entry, expired or not, with when it was fetched.
+The keys of a type are listed by 'paymo cache ls <type>'.`,
+	Example: `  paymo cache show project 42
+  paymo cache show projects all`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		resourceType, key := args[0], args[1]
+		if err := checkCacheType(resourceType); err != nil {
+			return err
+		}
+		store, err := openExistingCache()
+		if err != nil {
+			return err
+		}
+		if store == nil {
+			return fmt.Errorf("no cached %s entry %q: the cache is empty", resourceType, key)
+		}
+		defer store.Close()
+
+		info, raw, err := store.Entry(resourceType, key)
+		if errors.Is(err, cache.ErrCacheMiss) {
+			return fmt.Errorf("no cached %s entry %q (see 'paymo cache ls %s')", resourceType, key, resourceType)
+		}
+		if err != nil {
+			return fmt.Errorf("reading cache: %w", err)
+		}
+		var data interface{}
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return fmt.Errorf("decoding cached %s %q: %w", resourceType, key, err)
+		}
+
+		formatter := newFormatter()
+		if formatter.Structured() {
+			entry := cacheEntryInfo(resourceType, info)
+			entry["data"] = data
+			return formatter.FormatTimerStatus(entry)
+		}
+		pretty, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		fmt.Fprintf(formatter.Writer, "%s %s\n", resourceType, key)
+		fmt.Fprintf(formatter.Writer, "  Cached:  %s (%s old)\n", formatter.Locale.DateTime(info.CachedAt), formatAge(now.Sub(info.CachedAt)))
+		fmt.Fprintf(formatter.Writer, "  Expires: %s (%s)\n", formatter.Locale.DateTime(info.CachedAt.Add(info.TTL)), describeExpiry(info, now))
+		fmt.Fprintln(formatter.Writer, string(pretty))
+		return nil
+	},
+}
+
+var cacheInvalidateCmd = &cobra.Command{
+	Use:   "invalidate <type>...",
+	Short: "Drop the cached entries of resource types",
+	Long: `Drop the cached entries of the given resource types, so that the next read
+of them goes to the API. Types are invalidated as given: "tasks" is the task
+lists and "task" single tasks.
+
+Synced records are kept, and answer reads until their TTL runs out; pass
+--records to drop those of the types too, making their next sync a full one.`,
+	Example: `  paymo cache invalidate tasks task
+  paymo cache invalidate projects --records`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		records, _ := cmd.Flags().GetBool("records")
+		for _, rt := range args {
+			if err := checkCacheType(rt); err != nil {
+				return err
+			}
+		}
+		store, err := openExistingCache()
+		if err != nil {
+			return err
+		}
+		formatter := newFormatter()
+		if store == nil {
+			return formatter.FormatSuccess("No cache to invalidate.", 0)
+		}
+		defer store.Close()
+
+		if err := store.InvalidateType(args...); err != nil {
+			return fmt.Errorf("invalidating cache: %w", err)
+		}
+		msg := fmt.Sprintf("Invalidated %s.", strings.Join(args, ", "))
+		if records {
+			var dropped []string
+			for _, rt := range args {
+				if _, synced := store.SyncState(rt); !synced {
+					continue
+				}
+				if err := store.DropRecords(rt); err != nil {
+					return fmt.Errorf("dropping synced %s: %w", rt, err)
+				}
+				dropped = append(dropped, rt)
+			}
+			if len(dropped) > 0 {
+				msg += fmt.Sprintf(" Dropped synced %s.", strings.Join(dropped, ", "))
+			}
+		}
+		return formatter.FormatSuccess(msg, 0)
+	},
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove expired cache entries",
+	Long: `Remove expired entries from the cache. Expired entries are otherwise kept to
+answer reads while Paymo is unreachable (see --offline).`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openExistingCache()
+		if err != nil {
+			return err
+		}
+		formatter := newFormatter()
+		if store == nil {
+			return formatter.FormatSuccess("No cache to prune.", 0)
+		}
+		defer store.Close()
+
+		removed, err := store.Prune()
+		if err != nil {
+			return fmt.Errorf("pruning cache: %w", err)
+		}
+		return formatter.FormatSuccess(fmt.Sprintf("Pruned %d expired entries.", removed), 0)
+	},
+}
+
+// checkCacheType rejects a resource type the cache doesn't hold.
+func checkCacheType(resourceType string) error {
+	if _, ok := cache.DefaultTTL[resourceType]; !ok {
+		return fmt.Errorf("unknown cache type %q (known: %s)", resourceType, strings.Join(cache.ResourceTypes(), ", "))
+	}
+	return nil
+}
+
+var cacheRekeyCmd = &cobra.Command{
+	Use:   "rekey",
+	Short: "Re-encrypt the cache with a new key",
+	Long: `Generate a new cache key, re-encrypt the cached data with it and replace
+the key stored next to the credentials. Use it if the key may have leaked.
+The caches of every API base URL (such as a mock server's) are re-encrypted.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		paths, err := cacheFiles()
+		if err != nil {
+			return fmt.Errorf("getting config dir: %w", err)
+		}
+		var stores []*cache.Store
+		defer func() {
+			for _, store := range stores {
+				store.Close()
+			}
+		}()
+		for _, path := range paths {
+			store, err := openCache(path)
+			if err != nil {
+				return fmt.Errorf("opening cache %s: %w", path, err)
+			}
+			stores = append(stores, store)
+		}
+		old, err := cacheKey()
+		if err != nil {
+			return err
+		}
+		key, err := cache.NewKey()
+		if err != nil {
+			return err
+		}
+		// The new key is on disk, with the databases it is for, before they
+		// are sealed with it; see recoverCacheKey for a rekey cut off in
+		// between
+		if err := config.SavePendingCacheKey(key, paths); err != nil {
+			return err
+		}
+		for i, store := range stores {
+			if err := store.Rekey(key); err != nil {
+				// Put the databases sealed so far back under the old key
+				for _, sealed := range stores[:i] {
+					sealed.Rekey(old)
+				}
+				config.DiscardPendingCacheKey()
+				return fmt.Errorf("re-encrypting cache %s: %w", paths[i], err)
+			}
+		}
+		if err := config.CommitPendingCacheKey(); err != nil {
+			return err
+		}
+
+		formatter := newFormatter()
+		return formatter.FormatSuccess("Cache re-encrypted with a new key.", 0)
+	},
+}
+
+// cacheFilePath returns the cache database of the configured API: cache.db
+// in the config dir for Paymo, and a file of its own under caches/ for any
+// other base URL (such as a \`paymo dev mock-server\`), so the data of
+// different servers never mixes.
+func cacheFilePath() (string, error) {
+	cacheDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	baseURL := strings.TrimRight(config.GetAPIBaseURL(), "/")
+	if baseURL == config.DefaultAPIBaseURL {
+		return filepath.Join(cacheDir, cache.FileName), nil
+	}
+	sum := sha256.Sum256([]byte(baseURL))
+	return filepath.Join(cacheDir, "caches", fmt.Sprintf("%x.db", sum[:8])), nil
+}
+
+// cacheFiles returns every cache database in the config dir: cache.db, if
+// it or the cache.json it is migrated from exists, and those of other base
+// URLs under caches/.
+func cacheFiles() ([]string, error) {
+	cacheDir, err := config.GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	dbPath := filepath.Join(cacheDir, cache.FileName)
+	_, err = os.Stat(dbPath)
+	_, legacyErr := os.Stat(filepath.Join(cacheDir, cache.LegacyFileName))
+	if !os.IsNotExist(err) || !os.IsNotExist(legacyErr) {
+		paths = append(paths, dbPath)
+	}
+	others, err := filepath.Glob(filepath.Join(cacheDir, "caches", "*.db"))
+	if err != nil {
+		return nil, err
+	}
+	return append(paths, others...), nil
+}
+
+// openCache opens the cache database, encrypted with the cache key, with the
+// TTL overrides of config.yaml.
+func openCache(path string) (*cache.Store, error) {
+	ttls, err := cacheTTLs()
+	if err != nil {
+		return nil, err
+	}
+	if err := recoverCacheKey(); err != nil {
+		return nil, err
+	}
+	key, err := cacheKey()
+	if err != nil {
+		return nil, err
+	}
+	store, err := cache.OpenEncrypted(path, key)
+	if err != nil {
+		return nil, err
+	}
+	store.SetTTLs(ttls)
+	return store, nil
+}
+
+// openExistingCache opens the cache database for the cache commands. It
+// returns a nil store if nothing has been cached yet.
+func openExistingCache() (*cache.Store, error) {
+	dbPath, err := cacheFilePath()
+	if err != nil {
+		return nil, fmt.Errorf("getting config dir: %w", err)
+	}
+	_, err = os.Stat(dbPath)
+	_, legacyErr := os.Stat(filepath.Join(filepath.Dir(dbPath), cache.LegacyFileName))
+	if os.IsNotExist(err) && os.IsNotExist(legacyErr) {
+		return nil, nil
+	}
+	store, err := openCache(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("opening cache: %w", err)
+	}
+	return store, nil
+}
+
+// cacheKey returns the key the cache is encrypted with, generating and
+// storing one the first time. If another paymo process stores one first,
+// its key is used.
+func cacheKey() ([]byte, error) {
+	key, err := config.LoadCacheKey()
+	if err != nil || key != nil {
+		return key, err
+	}
+	if key, err = cache.NewKey(); err != nil {
+		return nil, err
+	}
+	return config.CreateCacheKey(key)
+}
+
+// recoverCacheKey finishes a `cache rekey` that was cut off between
+// sealing the cache databases with the new key and storing that key. It
+// goes by the databases the rekey was for, whichever one is being opened:
+// if the pending key opens none of them it is dropped, and otherwise the
+// rest are sealed with it too and it replaces the stored key.
+func recoverCacheKey() error {
+	pending, paths, err := config.LoadPendingCacheKey()
+	if err != nil || pending == nil {
+		return err
+	}
+	var rest []string
+	sealed := false
+	for _, path := range paths {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			continue
+		}
+		backend, err := cache.OpenBolt(path)
+		if err != nil {
+			return err
+		}
+		opens, err := cache.KeyOpens(backend, pending)
+		backend.Close()
+		if err != nil {
+			return err
+		}
+		if opens {
+			sealed = true
+		} else {
+			rest = append(rest, path)
+		}
+	}
+	if !sealed {
+		return config.DiscardPendingCacheKey()
+	}
+
+	current, err := config.LoadCacheKey()
+	if err != nil {
+		return err
+	}
+	for _, path := range rest {
+		store, err := cache.OpenEncrypted(path, current)
+		if err != nil {
+			return err
+		}
+		err = store.Rekey(pending)
+		store.Close()
+		if err != nil {
+			return fmt.Errorf("re-encrypting cache %s: %w", path, err)
+		}
+	}
+	return config.CommitPendingCacheKey()
+}
+
+// cacheTTLs returns the TTL overrides set under cache.ttl in config.yaml.
+func cacheTTLs() (map[string]time.Duration, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	ttls, err := cache.ParseTTLs(cfg.Cache.TTL)
+	if err != nil {
+		return nil, fmt.Errorf("%w (check cache.ttl in config.yaml)", err)
+	}
+	return ttls, nil
+}
+
+// cacheGrace returns the stale-while-revalidate window, cache.grace in
+// config.yaml.
+func cacheGrace() (time.Duration, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return 0, err
+	}
+	if cfg.Cache.Grace == "" {
+		return cache.DefaultGrace, nil
+	}
+	grace, err := time.ParseDuration(cfg.Cache.Grace)
+	if err != nil || grace < 0 {
+		return 0, fmt.Errorf("invalid cache.grace %q in config.yaml: expected a duration such as 30m", cfg.Cache.Grace)
+	}
+	return grace, nil
+}
+
+// refreshWait is how long a command waits, after its output is shown, for
+// background refreshes of the cache to finish. It is kept short so scripts
+// and prompts don't block on the network: a refresh cut off by the exit
+// commits nothing, and the next read of the stale data starts another.
+const refreshWait = 300 * time.Millisecond
+
+// waitForRefresh lets the cache refreshes the command started in the
+// background finish, if they do so quickly, so the next command finds
+// fresh data.
+func waitForRefresh() {
+	if sessionCache != nil {
+		sessionCache.Wait(refreshWait)
+	}
+}
+
+// saveCacheStats adds the reads of the command to the cache's hit/miss
+// counters.
+func saveCacheStats() {
+	if sessionCache != nil {
+		sessionCache.SaveStats()
+	}
+}
+
+// closeCache releases the cache file once the command is done, letting the
+// next paymo process open it.
+func closeCache() {
+	if sessionCache != nil {
+		sessionCache.Release()
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(cacheCmd)
 	cacheCmd.AddCommand(cacheClearCmd)
 	cacheCmd.AddCommand(cacheStatusCmd)
+	cacheCmd.AddCommand(cacheLsCmd)
+	cacheCmd.AddCommand(cacheShowCmd)
+	cacheCmd.AddCommand(cacheInvalidateCmd)
+	cacheCmd.AddCommand(cachePruneCmd)
+	cacheCmd.AddCommand(cacheRekeyCmd)
+
+	cacheInvalidateCmd.Flags().Bool("records", false, "Also drop the synced records of the types")
 }
@@ -4,6 +4,8 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+
+	"github.com/ComputClaw/paymo-cli/internal/output"
 )
 
 var clientsCmd = &cobra.Command{
@@ -32,7 +34,11 @@ Examples:
 			return fmt.Errorf("fetching clients: %w", err)
 		}
 
-		formatter := newFormatter()
+		formatter, err := newResourceFormatter(cmd)
+		if err != nil {
+			return err
+		}
+		withEnvelope(formatter, client, nil)
 		return formatter.FormatClients(clients)
 	},
 }
@@ -40,4 +46,8 @@ Examples:
 func init() {
 	rootCmd.AddCommand(clientsCmd)
 	clientsCmd.AddCommand(listClientsCmd)
+
+	// Output flags for list command
+	addOutputFlags(listClientsCmd, output.ClientResource)
+	addListFlags(listClientsCmd)
 }
@@ -2,30 +2,48 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 
 	"github.com/ComputClaw/paymo-cli/internal/api"
+	"github.com/ComputClaw/paymo-cli/internal/cache"
+	"github.com/ComputClaw/paymo-cli/internal/config"
+	"github.com/ComputClaw/paymo-cli/internal/ical"
+	"github.com/ComputClaw/paymo-cli/internal/locale"
+	"github.com/ComputClaw/paymo-cli/internal/mockserver"
+	"github.com/ComputClaw/paymo-cli/internal/output"
 )
 
 // mockPaymoAPI implements api.PaymoAPI for cmd/ testing.
 type mockPaymoAPI struct {
-	projects     []api.Project
-	tasks        []api.Task
-	entries      []api.TimeEntry
-	tasklists    []api.TaskList
-	user         *api.User
-	activeEntry  *api.TimeEntry
-	createErr    error
-	archiveErr   error
-	completeErr  error
-	deleteErr    error
+	projects    []api.Project
+	tasks       []api.Task
+	entries     []api.TimeEntry
+	tasklists   []api.TaskList
+	user        *api.User
+	activeEntry *api.TimeEntry
+	createErr   error
+	archiveErr  error
+	completeErr error
+	deleteErr   error
+	todayErr    error
+	today       time.Time // day of the last GetTodayEntries
+	created     []*api.CreateTimeEntryRequest
 }
 
 func newMockAPI() *mockPaymoAPI {
@@ -48,9 +66,9 @@ func newMockAPI() *mockPaymoAPI {
 	}
 }
 
-func (m *mockPaymoAPI) GetMe() (*api.User, error)                { return m.user, nil }
-func (m *mockPaymoAPI) ValidateAuth() error                      { return nil }
-func (m *mockPaymoAPI) GetClients() ([]api.PaymoClient, error)   { return nil, nil }
+func (m *mockPaymoAPI) GetMe() (*api.User, error)              { return m.user, nil }
+func (m *mockPaymoAPI) ValidateAuth() error                    { return nil }
+func (m *mockPaymoAPI) GetClients() ([]api.PaymoClient, error) { return nil, nil }
 
 func (m *mockPaymoAPI) GetProjects(opts *api.ProjectListOptions) ([]api.Project, error) {
 	return m.projects, nil
@@ -141,6 +159,7 @@ func (m *mockPaymoAPI) CreateEntry(req *api.CreateTimeEntryRequest) (*api.TimeEn
 	if m.createErr != nil {
 		return nil, m.createErr
 	}
+	m.created = append(m.created, req)
 	return &api.TimeEntry{ID: 99, TaskID: req.TaskID, Description: req.Description, StartTime: time.Now()}, nil
 }
 
@@ -152,7 +171,11 @@ func (m *mockPaymoAPI) DeleteEntry(id int) error {
 	return m.deleteErr
 }
 
-func (m *mockPaymoAPI) GetTodayEntries(userID int) ([]api.TimeEntry, error) {
+func (m *mockPaymoAPI) GetTodayEntries(userID int, today time.Time) ([]api.TimeEntry, error) {
+	m.today = today
+	if m.todayErr != nil {
+		return nil, m.todayErr
+	}
 	return m.entries, nil
 }
 
@@ -181,6 +204,10 @@ func runCommand(mock api.PaymoAPI, args ...string) error {
 		return mock, nil
 	}
 
+	origInteractive := isInteractive
+	defer func() { isInteractive = origInteractive }()
+	isInteractive = func() bool { return false }
+
 	// Reset persistent flag state to avoid bleeding between tests.
 	// Cobra doesn't reset flag values between Execute() calls.
 	resetCommandFlags(showTaskCmd, "project")
@@ -189,6 +216,9 @@ func runCommand(mock api.PaymoAPI, args ...string) error {
 	resetCommandFlags(listTasksCmd, "project")
 	resetCommandFlags(logCmd, "date", "project")
 	resetCommandFlags(editEntryCmd, "description", "duration", "task")
+	resetCommandFlags(statusCmd, "watch", "target")
+	resetCommandFlags(promptCmd, "template", "idle")
+	resetCommandFlags(startCmd, "project", "task", "description")
 
 	rootCmd.SetArgs(args)
 	viper.Set("format", "json")
@@ -202,10 +232,15 @@ func runCommand(mock api.PaymoAPI, args ...string) error {
 func resetCommandFlags(cmd *cobra.Command, flagNames ...string) {
 	for _, name := range flagNames {
 		f := cmd.Flags().Lookup(name)
-		if f != nil {
+		if f == nil {
+			continue
+		}
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			sv.Replace(nil)
+		} else {
 			f.Value.Set(f.DefValue)
-			f.Changed = false
 		}
+		f.Changed = false
 	}
 }
 
@@ -439,6 +474,158 @@ func TestTimeStatus(t *testing.T) {
 	}
 }
 
+func TestTimeStatus_WatchInvalidTarget(t *testing.T) {
+	err := runCommand(newMockAPI(), "time", "status", "--watch", "--target", "soon")
+	if err == nil {
+		t.Fatal("expected error for invalid --target")
+	}
+	if !strings.Contains(err.Error(), "invalid target") {
+		t.Errorf("expected invalid target error, got: %v", err)
+	}
+}
+
+func TestTodayLoggedSeconds_SkipsRunningEntry(t *testing.T) {
+	entries := []api.TimeEntry{
+		{ID: 1, Duration: 3600},
+		{ID: 2, Duration: 1800},
+		{ID: 3, Duration: 0},
+	}
+	if got := todayLoggedSeconds(entries, 3); got != 5400 {
+		t.Errorf("expected 5400, got %d", got)
+	}
+	if got := todayLoggedSeconds(entries, 0); got != 5400 {
+		t.Errorf("expected 5400 with no running entry, got %d", got)
+	}
+}
+
+func TestBuildWatchTick(t *testing.T) {
+	now := time.Date(2026, 2, 7, 12, 0, 0, 0, time.UTC)
+	state := &config.TimerState{
+		Active:      true,
+		EntryID:     3,
+		ProjectName: "Project Alpha",
+		TaskName:    "Design",
+		StartTime:   now.Add(-30 * time.Minute),
+	}
+
+	tick := buildWatchTick(state, 3*3600, 8*time.Hour, now)
+	if tick.ElapsedSeconds != 1800 {
+		t.Errorf("expected 1800 elapsed seconds, got %d", tick.ElapsedSeconds)
+	}
+	if tick.TodaySeconds != 3*3600+1800 {
+		t.Errorf("expected today total to include elapsed, got %d", tick.TodaySeconds)
+	}
+	if tick.Progress < 0.43 || tick.Progress > 0.44 {
+		t.Errorf("expected progress ~0.4375, got %f", tick.Progress)
+	}
+	if tick.Elapsed != "30m 0s" {
+		t.Errorf("expected elapsed at the given time, got %q", tick.Elapsed)
+	}
+
+	idle := buildWatchTick(&config.TimerState{}, 3600, 8*time.Hour, now)
+	if idle.Active || idle.ElapsedSeconds != 0 || idle.TodaySeconds != 3600 {
+		t.Errorf("unexpected idle tick: %+v", idle)
+	}
+}
+
+func TestStatusWatcher_ReleasesCache(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	viper.Set("no_cache", false)
+	defer viper.Set("no_cache", true)
+	defer func() { sessionCache = nil }()
+	client := wrapWithCache(newMockAPI())
+	f := output.NewFormatter("json")
+	f.Writer = &bytes.Buffer{}
+	w := &statusWatcher{client: client, target: 8 * time.Hour, formatter: f}
+
+	if err := w.tick(time.Date(2026, 2, 7, 12, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another paymo process can open the cache while the watch waits
+	dir, err := config.GetConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		store, err := openCache(filepath.Join(dir, cache.FileName))
+		if err == nil {
+			err = store.Close()
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the watch to release the cache file between refreshes")
+	}
+}
+
+func TestStatusWatcher_KeepsTotalOnFetchError(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	mock := newMockAPI()
+	f := output.NewFormatter("json")
+	var buf bytes.Buffer
+	f.Writer = &buf
+	w := &statusWatcher{client: mock, target: 8 * time.Hour, formatter: f}
+
+	now := time.Date(2026, 2, 7, 12, 0, 0, 0, time.UTC)
+	if err := w.tick(now); err != nil || w.loggedSeconds != 3600 {
+		t.Fatalf("first tick: logged %d, err %v", w.loggedSeconds, err)
+	}
+
+	// A failed refresh keeps the previous total instead of ending the watch
+	mock.todayErr = errors.New("service unavailable")
+	mock.entries = append(mock.entries, api.TimeEntry{ID: 101, Duration: 1800})
+	now = now.Add(watchRefreshInterval)
+	if err := w.tick(now); err != nil || w.loggedSeconds != 3600 {
+		t.Fatalf("failed refresh: logged %d, err %v", w.loggedSeconds, err)
+	}
+
+	// ...and is retried at the next refresh
+	mock.todayErr = nil
+	if err := w.tick(now.Add(time.Second)); err != nil || w.loggedSeconds != 3600 {
+		t.Fatalf("before the next refresh: logged %d, err %v", w.loggedSeconds, err)
+	}
+	if err := w.tick(now.Add(watchRefreshInterval)); err != nil || w.loggedSeconds != 5400 {
+		t.Errorf("next refresh: logged %d, err %v", w.loggedSeconds, err)
+	}
+}
+
+func TestStatusWatcher_RefetchesAtLocaleMidnight(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	mock := newMockAPI()
+	f := output.NewFormatter("json")
+	f.Writer = io.Discard
+	loc, err := locale.New("Asia/Tokyo", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Locale = loc
+	w := &statusWatcher{client: mock, target: 8 * time.Hour, formatter: f}
+
+	// 14:59 UTC is 23:59 in Tokyo; a minute later is a new day there
+	now := time.Date(2026, 2, 7, 14, 59, 0, 0, time.UTC)
+	if err := w.tick(now); err != nil {
+		t.Fatal(err)
+	}
+	mock.entries = nil
+	if err := w.tick(now.Add(30 * time.Second)); err != nil || w.loggedSeconds != 3600 {
+		t.Fatalf("same day: logged %d, err %v", w.loggedSeconds, err)
+	}
+	if err := w.tick(now.Add(61 * time.Second)); err != nil || w.loggedSeconds != 0 {
+		t.Errorf("after midnight in Tokyo: logged %d, err %v", w.loggedSeconds, err)
+	}
+	// ...and the entries fetched are those of Tokyo's day, not the system's
+	if want := time.Date(2026, 2, 8, 0, 0, 0, 0, loc.Location); !mock.today.Equal(want) {
+		t.Errorf("expected the entries of the day starting %v, got %v", want, mock.today)
+	}
+}
+
 func TestTimeShow(t *testing.T) {
 	err := runCommand(newMockAPI(), "time", "show", "100")
 	if err != nil {
@@ -590,90 +777,1904 @@ func TestResolveTask_NotFound_ByID(t *testing.T) {
 	}
 }
 
-// --- newFormatter test ---
+// --- Fuzzy resolution tests (resolve.go) ---
 
-func TestNewFormatter(t *testing.T) {
-	viper.Set("format", "json")
-	viper.Set("quiet", true)
+func TestMatchScore(t *testing.T) {
+	ref := cache.NameRef{ID: 1, Name: "Website Redesign", Code: "WEB"}
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"website redesign", scoreExactName},
+		{"web", scoreExactCode},
+		{"Websi", scoreNamePrefix},
+		{"redes", scoreWordPrefix},
+		{"site re", scoreSubstring},
+		{"wr", scoreInitials},
+		{"redesgin", scoreTypo - 2},
+		{"payroll", 0},
+	}
+	for _, tt := range tests {
+		if got := matchScore(tt.query, ref); got != tt.want {
+			t.Errorf("matchScore(%q) = %d, want %d", tt.query, got, tt.want)
+		}
+	}
+}
 
-	f := newFormatter()
-	if f.Format != "json" {
-		t.Errorf("expected format 'json', got '%s'", f.Format)
+func TestResolveProject_ByCode(t *testing.T) {
+	mock := newMockAPI()
+	mock.projects[1].Code = "BETA"
+	p, err := resolveProject(mock, "beta")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
-	if !f.Quiet {
-		t.Error("expected quiet to be true")
+	if p.ID != 2 {
+		t.Errorf("expected project 2, got %d", p.ID)
 	}
+}
 
-	// Reset
-	viper.Set("format", "table")
+func TestResolveProject_Typo(t *testing.T) {
+	// A close spelling is suggested, never picked
+	mock := newMockAPI()
+	_, err := resolveProject(mock, "Alpah")
+	if err == nil || !strings.Contains(err.Error(), `Did you mean: "Project Alpha" (#1)`) {
+		t.Errorf("expected a suggestion for Project Alpha, got: %v", err)
+	}
+}
+
+func TestResolveProject_PartialIndex(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	mock := newMockAPI()
+	mock.projects = []api.Project{{ID: 1, Name: "Alpha"}, {ID: 3, Name: "Alpha 2"}}
+	client := wrapWithCache(mock)
+
+	// Only Alpha is indexed; the index doesn't know Alpha 2 exists
+	if _, err := client.GetProject(1); err != nil {
+		t.Fatal(err)
+	}
+	p, err := resolveProject(client, "Alpha 2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.ID != 3 {
+		t.Errorf("expected Alpha 2 (#3), got %s (#%d)", p.Name, p.ID)
+	}
+}
+
+func TestResolveProject_Ambiguous(t *testing.T) {
+	mock := newMockAPI()
+	orig := isInteractive
+	defer func() { isInteractive = orig }()
+	isInteractive = func() bool { return false }
+
+	_, err := resolveProject(mock, "Project")
+	if err == nil {
+		t.Fatal("expected ambiguity error")
+	}
+	if !strings.Contains(err.Error(), "ambiguous") || !strings.Contains(err.Error(), "Project Beta") {
+		t.Errorf("expected ambiguity error listing matches, got: %v", err)
+	}
+}
+
+func TestResolveProject_AmbiguousPicker(t *testing.T) {
+	mock := newMockAPI()
+	origInteractive, origIn, origOut := isInteractive, pickerIn, pickerOut
+	defer func() { isInteractive, pickerIn, pickerOut = origInteractive, origIn, origOut }()
+	var out bytes.Buffer
+	isInteractive = func() bool { return true }
+	pickerIn = strings.NewReader("7\n2\n")
+	pickerOut = &out
+
+	p, err := resolveProject(mock, "Project")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.ID != 2 {
+		t.Errorf("expected picked project 2, got %d", p.ID)
+	}
+	if !strings.Contains(out.String(), "Invalid choice") {
+		t.Errorf("expected invalid choice to be reported, got: %s", out.String())
+	}
+}
+
+func TestResolveTask_NotFoundSuggestions(t *testing.T) {
+	mock := newMockAPI()
+	_, err := resolveTask(mock, "Dezine", "1")
+	if err == nil {
+		t.Fatal("expected error for unknown task")
+	}
+	if !strings.Contains(err.Error(), `Did you mean: "Design" (#10)`) {
+		t.Errorf("expected suggestion for Design, got: %v", err)
+	}
+}
+
+func TestTimeStart_Picker(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+
+	origClient, origInteractive, origIn, origOut := getAPIClient, isInteractive, pickerIn, pickerOut
+	defer func() {
+		getAPIClient, isInteractive, pickerIn, pickerOut = origClient, origInteractive, origIn, origOut
+	}()
+	mock := newMockAPI()
+	getAPIClient = func() (api.PaymoAPI, error) { return mock, nil }
+	isInteractive = func() bool { return true }
+	pickerIn = strings.NewReader("1\n2\n") // Project Alpha, then Development
+	pickerOut = io.Discard
+
+	resetCommandFlags(startCmd, "project", "task", "description")
+	rootCmd.SetArgs([]string{"time", "start"})
+	viper.Set("format", "json")
 	viper.Set("quiet", false)
+	viper.Set("no_cache", true)
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	state, _ := config.LoadTimerState()
+	if state.ProjectID != 1 || state.TaskID != 11 {
+		t.Errorf("expected project 1 / task 11 from picker, got %+v", state)
+	}
 }
 
-// --- GetOutputFormat test ---
+// --- Prompt tests ---
 
-func TestGetOutputFormat(t *testing.T) {
-	viper.Set("format", "csv")
-	got := GetOutputFormat()
-	if got != "csv" {
-		t.Errorf("expected 'csv', got '%s'", got)
+func TestRenderPrompt(t *testing.T) {
+	now := time.Date(2026, 2, 7, 12, 0, 0, 0, time.UTC)
+	state := &config.TimerState{
+		Active:      true,
+		EntryID:     42,
+		ProjectName: "Project Alpha",
+		TaskName:    "Design",
+		Description: "Mockups",
+		StartTime:   now.Add(-65 * time.Minute),
 	}
 
-	viper.Set("format", "")
-	got = GetOutputFormat()
-	if got != "table" {
-		t.Errorf("expected 'table' as default, got '%s'", got)
+	tests := []struct {
+		tmpl     string
+		expected string
+	}{
+		{defaultPromptTemplate, "Project Alpha/Design 1h05m"},
+		{"{task}: {description}", "Design: Mockups"},
+		{"#{entry_id} {clock}", "#42 1:05:00"},
+		{"{start}", "10:55"},
+	}
+	for _, tt := range tests {
+		if got := renderPrompt(tt.tmpl, state, now); got != tt.expected {
+			t.Errorf("renderPrompt(%q) = %q, want %q", tt.tmpl, got, tt.expected)
+		}
+	}
+}
+
+func TestPromptInit(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish", "starship"} {
+		if err := runCommand(newMockAPI(), "prompt", "init", shell); err != nil {
+			t.Errorf("prompt init %s: unexpected error: %v", shell, err)
+		}
 	}
+}
 
-	// Reset
-	viper.Set("format", "table")
+func TestPromptInit_UnknownShell(t *testing.T) {
+	err := runCommand(newMockAPI(), "prompt", "init", "tcsh")
+	if err == nil {
+		t.Fatal("expected error for unsupported shell")
+	}
 }
 
-// --- Schema command test ---
+// --- Git hook tests ---
 
-func TestSchemaCommand(t *testing.T) {
-	rootCmd.SetArgs([]string{"schema"})
-	err := rootCmd.Execute()
+func TestAppendCommitSubject(t *testing.T) {
+	tests := []struct {
+		desc     string
+		subject  string
+		expected string
+	}{
+		{"", "Fix login", "- Fix login"},
+		{"feature/login", "Fix login", "feature/login\n- Fix login"},
+		{"feature/login\n- Fix login", "Fix login", "feature/login\n- Fix login"},
+	}
+	for _, tt := range tests {
+		if got := appendCommitSubject(tt.desc, tt.subject); got != tt.expected {
+			t.Errorf("appendCommitSubject(%q, %q) = %q, want %q", tt.desc, tt.subject, got, tt.expected)
+		}
+	}
+}
+
+func TestInstallHook(t *testing.T) {
+	dir := t.TempDir()
+	if err := installHook(dir, "post-commit", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "post-commit"))
 	if err != nil {
+		t.Fatalf("hook not written: %v", err)
+	}
+	if !strings.Contains(string(data), "paymo git hook post-commit") {
+		t.Errorf("unexpected hook script: %s", data)
+	}
+
+	// Reinstalling over our own hook is fine
+	if err := installHook(dir, "post-commit", false); err != nil {
+		t.Fatalf("unexpected error reinstalling: %v", err)
+	}
+
+	removed, err := uninstallHook(dir, "post-commit")
+	if err != nil || !removed {
+		t.Fatalf("expected hook to be removed, got %v, %v", removed, err)
+	}
+}
+
+func TestInstallHook_ForeignHook(t *testing.T) {
+	dir := t.TempDir()
+	foreign := filepath.Join(dir, "post-checkout")
+	os.WriteFile(foreign, []byte("#!/bin/sh\necho custom\n"), 0755)
+
+	if err := installHook(dir, "post-checkout", false); err == nil {
+		t.Fatal("expected error when overwriting a foreign hook")
+	}
+	if removed, _ := uninstallHook(dir, "post-checkout"); removed {
+		t.Error("foreign hook must not be removed")
+	}
+	if err := installHook(dir, "post-checkout", true); err != nil {
+		t.Fatalf("unexpected error with force: %v", err)
+	}
+}
+
+func TestHookSwitchTimerAndAppendCommit(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	viper.Set("format", "json")
+	mock := newMockAPI()
+	ctx := &config.ProjectContext{Project: "Alpha", Task: "Design"}
+
+	if err := hookSwitchTimer(mock, ctx, "feature/login"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	state, _ := config.LoadTimerState()
+	if !state.Active || state.TaskID != 10 || state.Description != "feature/login" {
+		t.Fatalf("unexpected timer state after checkout: %+v", state)
+	}
+
+	if err := hookAppendCommit(mock, ctx, "Add login form"); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
+	state, _ = config.LoadTimerState()
+	if state.Description != "feature/login\n- Add login form" {
+		t.Errorf("unexpected description after commit: %q", state.Description)
+	}
+
+	// A project mismatch leaves the running entry alone
+	other := &config.ProjectContext{Project: "Beta", Task: "Design"}
+	if err := hookAppendCommit(mock, other, "Unrelated"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	state, _ = config.LoadTimerState()
+	if strings.Contains(state.Description, "Unrelated") {
+		t.Error("commit subject appended to a timer on another project")
+	}
 }
 
-// --- Error cases ---
+// --- Context tests ---
 
-func TestGetAPIClient_NoAuth(t *testing.T) {
+func TestExpandDescriptionTemplate_Date(t *testing.T) {
+	// 23:30 UTC is the next morning in Tokyo
+	tokyo := time.FixedZone("JST", 9*3600)
+	now := time.Date(2026, 2, 7, 23, 30, 0, 0, time.UTC).In(tokyo)
+	if got := expandDescriptionTemplate("{date} standup", "/repo/"+config.ContextFile, now); got != "2026-02-08 standup" {
+		t.Errorf("expected the date in the user's timezone, got %q", got)
+	}
+}
+
+func TestContextFile_NotReadAsConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := t.TempDir()
+	t.Chdir(dir)
+	// A context file in the current directory and one in the home directory,
+	// both places the CLI config is looked up
+	for _, d := range []string{dir, home} {
+		os.WriteFile(filepath.Join(d, config.ContextFile), []byte("project: Alpha\nformat: csv\n"), 0644)
+	}
+
+	initConfig()
+	if used := viper.ConfigFileUsed(); filepath.Base(used) == config.ContextFile {
+		t.Errorf("expected the context file not to be read as config, got %s", used)
+	}
+	if viper.GetString("project") == "Alpha" {
+		t.Error("expected the context's project not to reach the config")
+	}
+}
+
+func TestTimeStart_FromContext(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, config.ContextFile),
+		[]byte("project: Alpha\ntask: Development\ndescription: \"{dir} work\"\n"), 0644)
+	t.Chdir(dir)
+
+	if err := runCommand(newMockAPI(), "time", "start"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	state, _ := config.LoadTimerState()
+	if state.ProjectID != 1 || state.TaskID != 11 {
+		t.Errorf("expected project 1 / task 11 from context, got %+v", state)
+	}
+	if state.Description != filepath.Base(dir)+" work" {
+		t.Errorf("unexpected description from template: %q", state.Description)
+	}
+}
+
+func TestTimeStart_BrokenContext(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, config.ContextFile), []byte("project: [unclosed"), 0644)
+	t.Chdir(dir)
+
+	// Explicit project and task don't need the context file
+	if err := runCommand(newMockAPI(), "time", "start", "-p", "1", "-t", "10"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state, _ := config.LoadTimerState(); state.TaskID != 10 {
+		t.Errorf("expected the timer on task 10, got %+v", state)
+	}
+	config.ClearTimerState()
+
+	// ...but a project that has to come from it does
+	if err := runCommand(newMockAPI(), "time", "start"); err == nil || !strings.Contains(err.Error(), config.ContextFile) {
+		t.Errorf("expected the context file error, got %v", err)
+	}
+}
+
+func TestContextShow(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, config.ContextFile), []byte("project: Alpha\n"), 0644)
+	t.Chdir(dir)
+
+	if err := runCommand(newMockAPI(), "context", "show"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestUnknownFormat(t *testing.T) {
+	mock := newMockAPI()
 	origClient := getAPIClient
 	defer func() { getAPIClient = origClient }()
+	getAPIClient = func() (api.PaymoAPI, error) { return mock, nil }
 
-	getAPIClient = func() (api.PaymoAPI, error) {
-		return nil, fmt.Errorf("not authenticated")
+	rootCmd.SetArgs([]string{"projects", "list"})
+	viper.Set("format", "xml")
+	defer viper.Set("format", "json")
+	err := rootCmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "unknown output format") {
+		t.Errorf("expected unknown format error, got: %v", err)
 	}
+}
 
-	buf := new(bytes.Buffer)
-	rootCmd.SetOut(buf)
-	rootCmd.SetErr(buf)
-	rootCmd.SetArgs([]string{"projects", "list"})
-	viper.Set("format", "json")
-	viper.Set("no_cache", true)
+func TestNewResourceFormatter_BuiltOnce(t *testing.T) {
+	startSession()
+	defer endSession()
+	validated, err := newResourceFormatter(listProjectsCmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessionFormatter, sessionFormatterCmd = validated, listProjectsCmd
 
-	err := rootCmd.Execute()
-	if err == nil {
-		t.Fatal("expected error when not authenticated")
+	// The command gets the formatter built while validating its flags
+	if f, _ := newResourceFormatter(listTasksCmd); f == validated {
+		t.Error("expected another command to get its own formatter")
+	}
+	if f, _ := newResourceFormatter(listProjectsCmd); f != validated {
+		t.Error("expected the validated formatter to be handed over")
+	}
+	if f, _ := newResourceFormatter(listProjectsCmd); f == validated {
+		t.Error("expected the validated formatter to be handed over only once")
 	}
 }
 
-func TestProjectsArchive_MissingArgs(t *testing.T) {
-	err := runCommand(newMockAPI(), "projects", "archive")
-	if err == nil {
-		t.Fatal("expected error when no project specified")
+func TestOutputFlags_InvalidColumn(t *testing.T) {
+	defer resetCommandFlags(listProjectsCmd, "columns")
+	err := runCommand(newMockAPI(), "projects", "list", "--columns", "id,nope")
+	if err == nil || !strings.Contains(err.Error(), "unknown column") {
+		t.Errorf("expected unknown column error, got: %v", err)
 	}
 }
 
-func TestTasksComplete_MissingArgs(t *testing.T) {
-	err := runCommand(newMockAPI(), "tasks", "complete")
-	if err == nil {
-		t.Fatal("expected error when no task specified")
+func TestOutputFlags_Template(t *testing.T) {
+	defer resetCommandFlags(listTasksCmd, "template")
+	if err := runCommand(newMockAPI(), "tasks", "list", "--template", "{{.ID}} {{.Name}}"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
 }
 
-// Verify the mock implements the full interface
-var _ api.PaymoAPI = (*mockPaymoAPI)(nil)
+func TestWithEnvelope(t *testing.T) {
+	f := output.NewFormatter("json")
+	withEnvelope(f, newMockAPI(), map[string]interface{}{"project_id": 5})
+	if f.Envelope != nil {
+		t.Fatal("envelope should be opt-in")
+	}
+
+	viper.Set("envelope", true)
+	defer viper.Set("envelope", false)
+	withEnvelope(f, newMockAPI(), map[string]interface{}{"project_id": 5, "client_id": 0, "date": ""})
+	if f.Envelope == nil {
+		t.Fatal("expected envelope with --envelope")
+	}
+	if len(f.Envelope.Filters) != 1 || f.Envelope.Filters["project_id"] != 5 {
+		t.Errorf("expected zero-valued filters to be dropped, got %v", f.Envelope.Filters)
+	}
+	if f.Envelope.Cached {
+		t.Error("uncached client should not report cached results")
+	}
+}
+
+func TestTimeLog_Envelope(t *testing.T) {
+	viper.Set("envelope", true)
+	defer viper.Set("envelope", false)
+	if err := runCommand(newMockAPI(), "time", "log", "--date", "yesterday"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestListFlags_SortAndGroupBy(t *testing.T) {
+	defer resetCommandFlags(logCmd, "sort", "group-by")
+	if err := runCommand(newMockAPI(), "time", "log", "--sort", "-duration", "--group-by", "day"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resetCommandFlags(logCmd, "sort", "group-by")
+	err := runCommand(newMockAPI(), "time", "log", "--sort", "duration,-nope")
+	if err == nil || !strings.Contains(err.Error(), "unknown sort field") {
+		t.Errorf("expected unknown sort field error, got: %v", err)
+	}
+
+	resetCommandFlags(logCmd, "sort", "group-by")
+	err = runCommand(newMockAPI(), "time", "log", "--group-by", "weekday")
+	if err == nil || !strings.Contains(err.Error(), "unknown group-by field") {
+		t.Errorf("expected unknown group-by field error, got: %v", err)
+	}
+}
+
+func TestListFlags_Filter(t *testing.T) {
+	defer resetCommandFlags(listProjectsCmd, "filter")
+	if err := runCommand(newMockAPI(), "projects", "list", "--filter", "active and name ~ 'web'"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resetCommandFlags(listProjectsCmd, "filter")
+	err := runCommand(newMockAPI(), "projects", "list", "--filter", "active and budget_hours > soon")
+	if err == nil || !strings.Contains(err.Error(), "invalid filter") || !strings.Contains(err.Error(), "column") {
+		t.Errorf("expected invalid filter error, got: %v", err)
+	}
+}
+
+func TestSchema_OutputColumns(t *testing.T) {
+	schema := buildSchema(rootCmd)
+	var log *SchemaCommand
+	for _, c := range schema["commands"].([]SchemaCommand) {
+		if c.Name != "time" {
+			continue
+		}
+		for i := range c.Subcommands {
+			if c.Subcommands[i].Name == "log" {
+				log = &c.Subcommands[i]
+			}
+		}
+	}
+	if log == nil || log.Output == nil {
+		t.Fatal("expected output schema for time log")
+	}
+	if log.Output.Resource != "entries" || len(log.Output.Columns) == 0 {
+		t.Errorf("unexpected output schema: %+v", log.Output)
+	}
+}
+
+// --- newFormatter test ---
+
+func TestNewFormatter(t *testing.T) {
+	viper.Set("format", "json")
+	viper.Set("quiet", true)
+
+	f := newFormatter()
+	if f.Format != "json" {
+		t.Errorf("expected format 'json', got '%s'", f.Format)
+	}
+	if !f.Quiet {
+		t.Error("expected quiet to be true")
+	}
+
+	// Reset
+	viper.Set("format", "table")
+	viper.Set("quiet", false)
+}
+
+func TestTableLayout(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	orig := stdoutTerminal
+	defer func() { stdoutTerminal = orig }()
+
+	stdoutTerminal = func() (bool, int) { return false, 0 }
+	if style, width := tableLayout(); style != output.StylePlain || width != 0 {
+		t.Errorf("expected plain unlimited layout when piped, got %q/%d", style, width)
+	}
+
+	cfg := &config.Config{Output: config.OutputConfig{TableStyle: "ascii"}}
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatalf("saving config: %v", err)
+	}
+	stdoutTerminal = func() (bool, int) { return true, 100 }
+	if style, width := tableLayout(); style != "ascii" || width != 100 {
+		t.Errorf("expected configured ascii style at terminal width, got %q/%d", style, width)
+	}
+}
+
+func TestLoadLocale(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	l, err := loadLocale()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if l.Location != time.Local || l.DateFormat != locale.DefaultDateFormat {
+		t.Errorf("expected system defaults, got %+v", l)
+	}
+
+	// The Paymo user's timezone applies when config.yaml has none
+	if err := config.SaveCredentials(&config.Credentials{AuthType: "api_key", APIKey: "k", UserTimezone: "Asia/Tokyo"}); err != nil {
+		t.Fatalf("saving credentials: %v", err)
+	}
+	if l, _ := loadLocale(); l.Location.String() != "Asia/Tokyo" {
+		t.Errorf("expected user timezone, got %s", l.Location)
+	}
+
+	cfg := &config.Config{
+		Defaults: config.DefaultsConfig{Timezone: "Europe/Copenhagen"},
+		Output:   config.OutputConfig{DateFormat: "02.01.2006", TimeFormat: "15.04"},
+	}
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatalf("saving config: %v", err)
+	}
+	l, _ = loadLocale()
+	if l.Location.String() != "Europe/Copenhagen" || l.DateFormat != "02.01.2006" || l.TimeFormat != "15.04" {
+		t.Errorf("expected configured locale, got %+v", l)
+	}
+
+	cfg.Defaults.Timezone = "Nowhere/Special"
+	config.SaveConfig(cfg)
+	if _, err := loadLocale(); err == nil || !strings.Contains(err.Error(), "defaults.timezone") {
+		t.Errorf("expected invalid timezone error, got %v", err)
+	}
+}
+
+func TestLoadConfig_OncePerCommand(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cfg := &config.Config{Defaults: config.DefaultsConfig{Timezone: "Europe/Copenhagen"}}
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatalf("saving config: %v", err)
+	}
+
+	startSession()
+	defer endSession()
+	if l, _ := loadLocale(); l.Location.String() != "Europe/Copenhagen" {
+		t.Fatalf("expected configured timezone, got %s", l.Location)
+	}
+	// Within a command the files are not read again
+	cfg.Defaults.Timezone = "Asia/Tokyo"
+	config.SaveConfig(cfg)
+	if l, _ := loadLocale(); l.Location.String() != "Europe/Copenhagen" {
+		t.Errorf("expected the locale loaded for the command, got %s", l.Location)
+	}
+	if c, _ := loadConfig(); c.Defaults.Timezone != "Europe/Copenhagen" {
+		t.Errorf("expected the config loaded for the command, got %q", c.Defaults.Timezone)
+	}
+
+	// The next command reads them afresh
+	endSession()
+	if l, _ := loadLocale(); l.Location.String() != "Asia/Tokyo" {
+		t.Errorf("expected the new timezone after the command, got %s", l.Location)
+	}
+}
+
+// --- GetOutputFormat test ---
+
+func TestGetOutputFormat(t *testing.T) {
+	viper.Set("format", "csv")
+	got := GetOutputFormat()
+	if got != "csv" {
+		t.Errorf("expected 'csv', got '%s'", got)
+	}
+
+	viper.Set("format", "")
+	got = GetOutputFormat()
+	if got != "table" {
+		t.Errorf("expected 'table' as default, got '%s'", got)
+	}
+
+	// Reset
+	viper.Set("format", "table")
+}
+
+// --- Schema command test ---
+
+func TestSchemaCommand(t *testing.T) {
+	rootCmd.SetArgs([]string{"schema"})
+	err := rootCmd.Execute()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// --- Error cases ---
+
+func TestGetAPIClient_NoAuth(t *testing.T) {
+	origClient := getAPIClient
+	defer func() { getAPIClient = origClient }()
+
+	getAPIClient = func() (api.PaymoAPI, error) {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+	rootCmd.SetErr(buf)
+	rootCmd.SetArgs([]string{"projects", "list"})
+	viper.Set("format", "json")
+	viper.Set("no_cache", true)
+
+	err := rootCmd.Execute()
+	if err == nil {
+		t.Fatal("expected error when not authenticated")
+	}
+}
+
+func TestProjectsArchive_MissingArgs(t *testing.T) {
+	err := runCommand(newMockAPI(), "projects", "archive")
+	if err == nil {
+		t.Fatal("expected error when no project specified")
+	}
+}
+
+func TestTasksComplete_MissingArgs(t *testing.T) {
+	err := runCommand(newMockAPI(), "tasks", "complete")
+	if err == nil {
+		t.Fatal("expected error when no task specified")
+	}
+}
+
+// Verify the mock implements the full interface
+var _ api.PaymoAPI = (*mockPaymoAPI)(nil)
+
+// --- Calendar export/import tests ---
+
+func TestEntryEvents(t *testing.T) {
+	start := time.Date(2026, 2, 9, 9, 0, 0, 0, time.UTC)
+	entries := []api.TimeEntry{
+		{ID: 1, TaskID: 10, StartTime: start, Duration: 5400, Description: "Checkout flow", Task: &api.Task{Name: "Design", ProjectID: 1}},
+		{ID: 2, TaskID: 11, StartTime: start, EndTime: start.Add(time.Hour), Project: &api.Project{Name: "Website"}, Task: &api.Task{Name: "Dev"}},
+		{ID: 3, TaskID: 12, StartTime: start}, // running timer
+	}
+	events := entryEvents(entries, map[int]string{1: "Project Alpha"})
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	if events[0].Summary != "Project Alpha / Design" || events[0].Description != "Checkout flow" || events[0].Duration() != 90*time.Minute {
+		t.Errorf("unexpected event: %+v", events[0])
+	}
+	if events[1].Summary != "Website / Dev" || events[1].UID != "paymo-entry-2@paymo-cli" {
+		t.Errorf("unexpected event: %+v", events[1])
+	}
+}
+
+func TestTimeExport_ICS(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	defer resetCommandFlags(exportCmd, "ics", "from", "to", "output")
+
+	mock := newMockAPI()
+	mock.entries[0].StartTime = time.Date(2026, 2, 9, 9, 0, 0, 0, time.UTC)
+	out := filepath.Join(t.TempDir(), "week.ics")
+	if err := runCommand(mock, "time", "export", "--ics", "--from", "2026-02-09", "--to", "2026-02-13", "-o", out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"BEGIN:VCALENDAR", "UID:paymo-entry-100@paymo-cli", "DTSTART:20260209T090000Z", "DTEND:20260209T100000Z", "DESCRIPTION:Working on design"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected %q in:\n%s", want, data)
+		}
+	}
+
+	resetCommandFlags(exportCmd, "ics", "from", "to", "output")
+	err = runCommand(mock, "time", "export", "--from", "2026-02-09")
+	if err == nil || !strings.Contains(err.Error(), "--ics") {
+		t.Errorf("expected missing format error, got: %v", err)
+	}
+}
+
+const importCalendar = `BEGIN:VCALENDAR
+VERSION:2.0
+BEGIN:VEVENT
+UID:standup@example.com
+DTSTART:20260209T083000Z
+DTEND:20260209T084500Z
+SUMMARY:Daily standup
+END:VEVENT
+BEGIN:VEVENT
+UID:design@example.com
+DTSTART:20260209T090000Z
+DTEND:20260209T100000Z
+SUMMARY:Design review
+END:VEVENT
+BEGIN:VEVENT
+UID:lunch@example.com
+DTSTART:20260209T113000Z
+DTEND:20260209T120000Z
+SUMMARY:Lunch
+END:VEVENT
+BEGIN:VEVENT
+UID:offsite@example.com
+DTSTART;VALUE=DATE:20260210
+SUMMARY:Offsite
+END:VEVENT
+BEGIN:VEVENT
+UID:dentist@example.com
+DTSTART:20260211T150000Z
+DTEND:20260211T160000Z
+SUMMARY:Dentist
+END:VEVENT
+END:VCALENDAR
+`
+
+const importRules = `rules:
+  - match: standup
+    project: Project Alpha
+    task: Development
+  - match: review
+    project: 1
+    task: Design
+    description: "Meeting: {summary}"
+  - match: lunch
+    skip: true
+`
+
+func writeImportFiles(t *testing.T) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	ics := filepath.Join(dir, "calendar.ics")
+	rules := filepath.Join(dir, "rules.yaml")
+	if err := os.WriteFile(ics, []byte(importCalendar), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(rules, []byte(importRules), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return ics, rules
+}
+
+// importMock returns a mock where the design review is already logged.
+func importMock() *mockPaymoAPI {
+	mock := newMockAPI()
+	mock.entries[0].StartTime = time.Date(2026, 2, 9, 9, 0, 20, 0, time.UTC)
+	return mock
+}
+
+func TestTimeImport_ICS(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	defer resetCommandFlags(importCmd, "map", "dry-run")
+	ics, rules := writeImportFiles(t)
+
+	mock := importMock()
+	if err := runCommand(mock, "time", "import", ics, "--map", rules); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mock.created) != 1 {
+		t.Fatalf("expected 1 entry created, got %d", len(mock.created))
+	}
+	got := mock.created[0]
+	if got.TaskID != 11 || got.StartTime != "2026-02-09T08:30:00Z" || got.EndTime != "2026-02-09T08:45:00Z" || got.Description != "Daily standup" {
+		t.Errorf("unexpected entry: %+v", got)
+	}
+}
+
+func TestTimeImport_DryRun(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	defer resetCommandFlags(importCmd, "map", "dry-run")
+	ics, rules := writeImportFiles(t)
+
+	mock := importMock()
+	if err := runCommand(mock, "time", "import", ics, "--map", rules, "--dry-run"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mock.created) != 0 {
+		t.Errorf("dry run created %d entries", len(mock.created))
+	}
+}
+
+func TestPlanCalendarImport(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ics, rulesPath := writeImportFiles(t)
+	f, _ := os.Open(ics)
+	defer f.Close()
+	events, err := ical.Parse(f, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules, err := ical.LoadRules(rulesPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules.Default = &ical.Rule{Project: "Project Alpha", Task: "Design"}
+
+	mock := importMock()
+	rows, err := planCalendarImport(mock, events, rules, time.Time{}, time.Date(2026, 2, 11, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := markDuplicates(mock, rows, locale.Default()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []struct{ action, reason string }{
+		{importCreate, ""},
+		{importDuplicate, "matches entry 100"},
+		{importSkip, `skipped by rule "lunch"`},
+		{importSkip, "all-day event"},
+		{importSkip, "outside --from/--to"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("expected %d rows, got %d", len(want), len(rows))
+	}
+	for i, w := range want {
+		if rows[i].Action != w.action || rows[i].Reason != w.reason {
+			t.Errorf("row %d = %s (%s), want %s (%s)", i, rows[i].Action, rows[i].Reason, w.action, w.reason)
+		}
+	}
+	if rows[1].Description != "Meeting: Design review" || rows[1].Project != "Project Alpha" {
+		t.Errorf("unexpected mapping: %+v", rows[1])
+	}
+}
+
+func TestTimeImport_Errors(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	defer resetCommandFlags(importCmd, "map", "project", "task")
+	ics, _ := writeImportFiles(t)
+
+	err := runCommand(newMockAPI(), "time", "import", ics)
+	if err == nil || !strings.Contains(err.Error(), "no mapping given") {
+		t.Errorf("expected missing mapping error, got: %v", err)
+	}
+
+	err = runCommand(newMockAPI(), "time", "import", ics, "--project", "Project Alpha")
+	if err == nil || !strings.Contains(err.Error(), "must be given together") {
+		t.Errorf("expected --task error, got: %v", err)
+	}
+
+	resetCommandFlags(importCmd, "map", "project", "task")
+	err = runCommand(newMockAPI(), "time", "import", ics, "--project", "Nope", "--task", "Design")
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected project resolution error, got: %v", err)
+	}
+}
+
+const importCSV = `Project,Task,Description,Start date,Start time,Duration
+Project Alpha,Development,API work,2026-02-09,09:00:00,01:30:00
+Project Alpha,Design,Mockups,2026-02-09,2:00 PM,0:45
+Project Alpha,Development,Night shift,2026-02-10,23:30,1h
+`
+
+// useUTC configures the UTC timezone for the test's HOME.
+func useUTC(t *testing.T) {
+	t.Helper()
+	if err := config.SaveConfig(&config.Config{Defaults: config.DefaultsConfig{Timezone: "UTC"}}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeImportFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestTimeImport_CSV(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	useUTC(t)
+	defer resetCommandFlags(importCmd, "dry-run")
+	path := writeImportFile(t, "toggl.csv", importCSV)
+
+	mock := newMockAPI()
+	if err := runCommand(mock, "time", "import", path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mock.created) != 3 {
+		t.Fatalf("expected 3 entries created, got %d", len(mock.created))
+	}
+	want := []struct {
+		task       int
+		start, end string
+	}{
+		{11, "2026-02-09T09:00:00Z", "2026-02-09T10:30:00Z"},
+		{10, "2026-02-09T14:00:00Z", "2026-02-09T14:45:00Z"},
+		{11, "2026-02-10T23:30:00Z", "2026-02-11T00:30:00Z"},
+	}
+	for i, w := range want {
+		got := mock.created[i]
+		if got.TaskID != w.task || got.StartTime != w.start || got.EndTime != w.end {
+			t.Errorf("entry %d = %+v, want task %d %s-%s", i, got, w.task, w.start, w.end)
+		}
+	}
+	if _, err := os.Stat(path + ".progress.json"); !os.IsNotExist(err) {
+		t.Errorf("expected progress file to be removed after a complete import, got: %v", err)
+	}
+}
+
+func TestTimeImport_DateOnlyRows(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	defer resetCommandFlags(importCmd, "task", "column")
+	path := writeImportFile(t, "harvest.csv", "Date,Project,Hours,Work done\n2026-02-09,Project Alpha,\"1,5\",Reviews\n")
+
+	mock := newMockAPI()
+	if err := runCommand(mock, "time", "import", path, "--task", "Design", "--column", "description=work_done"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mock.created) != 1 {
+		t.Fatalf("expected 1 entry created, got %d", len(mock.created))
+	}
+	got := mock.created[0]
+	if got.Date != "2026-02-09" || got.Duration != 5400 || got.StartTime != "" || got.TaskID != 10 || got.Description != "Reviews" {
+		t.Errorf("unexpected entry: %+v", got)
+	}
+}
+
+func TestImportColumnMapping(t *testing.T) {
+	mapping, err := importColumnMapping([]string{"Start Date", "start_time", "End Time", "Notes"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mapping["date"] != "Start Date" || mapping["start"] != "start_time" || mapping["end"] != "End Time" || mapping["description"] != "Notes" {
+		t.Errorf("unexpected mapping: %v", mapping)
+	}
+
+	tests := []struct {
+		columns, overrides []string
+		want               string
+	}{
+		{[]string{"Date", "Hours"}, []string{"billable=Hours"}, "unknown import field"},
+		{[]string{"Date", "Hours"}, []string{"description=Memo"}, `column "Memo" not found`},
+		{[]string{"Date", "Hours"}, []string{"description"}, "use field=Column"},
+		{[]string{"Date", "Start"}, nil, "no duration column"},
+	}
+	for _, tt := range tests {
+		_, err := importColumnMapping(tt.columns, tt.overrides)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("importColumnMapping(%v, %v) error = %v, want %q", tt.columns, tt.overrides, err, tt.want)
+		}
+	}
+}
+
+func TestParseImportDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"1:30":    90 * time.Minute,
+		"0:45:30": 45*time.Minute + 30*time.Second,
+		"1.5":     90 * time.Minute,
+		"0,25":    15 * time.Minute,
+		"2h15m":   135 * time.Minute,
+	}
+	for in, want := range tests {
+		got, err := parseImportDuration(in)
+		if err != nil || got != want {
+			t.Errorf("parseImportDuration(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := parseImportDuration("soon"); err == nil {
+		t.Error("expected error for invalid duration")
+	}
+}
+
+func TestTimeImport_InvalidRows(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	useUTC(t)
+	defer resetCommandFlags(importCmd, "skip-invalid", "dry-run")
+	path := writeImportFile(t, "entries.json", `{"entries": [
+		{"project": "Project Alpha", "task": "Design", "start": "2026-02-09 09:00", "duration": 1.25},
+		{"project": "Project Alpha", "task": "Design", "start": "yesterday-ish", "duration": 1},
+		{"task": "Design", "start": "2026-02-09 11:00", "duration": 1}
+	]}`)
+
+	mock := newMockAPI()
+	err := runCommand(mock, "time", "import", path)
+	if err == nil || !strings.Contains(err.Error(), "2 rows are invalid") {
+		t.Fatalf("expected invalid rows error, got: %v", err)
+	}
+	if len(mock.created) != 0 {
+		t.Fatalf("created %d entries despite invalid rows", len(mock.created))
+	}
+
+	if err := runCommand(mock, "time", "import", path, "--skip-invalid"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mock.created) != 1 || mock.created[0].EndTime != "2026-02-09T10:15:00Z" {
+		t.Errorf("expected only the valid row to be created, got %+v", mock.created)
+	}
+}
+
+// failingCreateAPI fails CreateEntry after the first failAfter calls.
+type failingCreateAPI struct {
+	*mockPaymoAPI
+	failAfter int
+}
+
+func (m *failingCreateAPI) CreateEntry(req *api.CreateTimeEntryRequest) (*api.TimeEntry, error) {
+	if len(m.created) >= m.failAfter {
+		return nil, errors.New("connection reset")
+	}
+	return m.mockPaymoAPI.CreateEntry(req)
+}
+
+func TestTimeImport_Resume(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	useUTC(t)
+	defer resetCommandFlags(importCmd, "batch-size", "restart")
+	path := writeImportFile(t, "toggl.csv", importCSV)
+
+	mock := &failingCreateAPI{mockPaymoAPI: newMockAPI(), failAfter: 1}
+	err := runCommand(mock, "time", "import", path, "--batch-size", "2")
+	if err == nil || !strings.Contains(err.Error(), "import stopped at line 3") {
+		t.Fatalf("expected import to stop at line 3, got: %v", err)
+	}
+	if _, err := os.Stat(path + ".progress.json"); err != nil {
+		t.Fatalf("expected progress file to be kept: %v", err)
+	}
+
+	mock.failAfter = 10
+	if err := runCommand(mock, "time", "import", path); err != nil {
+		t.Fatalf("unexpected error resuming: %v", err)
+	}
+	if len(mock.created) != 3 {
+		t.Fatalf("expected 3 entries in total, got %d", len(mock.created))
+	}
+	if mock.created[1].Description != "Mockups" {
+		t.Errorf("resume should start at the failed row, got %+v", mock.created[1])
+	}
+	if _, err := os.Stat(path + ".progress.json"); !os.IsNotExist(err) {
+		t.Errorf("expected progress file to be removed, got: %v", err)
+	}
+}
+
+func TestTimeImport_ChangedFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	defer resetCommandFlags(importCmd, "restart", "dry-run")
+	path := writeImportFile(t, "toggl.csv", importCSV)
+	progress := `{"source": "toggl.csv", "checksum": "0000", "done": {"line 2": 99}}`
+	if err := os.WriteFile(path+".progress.json", []byte(progress), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	err := runCommand(newMockAPI(), "time", "import", path, "--dry-run")
+	if err == nil || !strings.Contains(err.Error(), "has changed") {
+		t.Fatalf("expected changed file error, got: %v", err)
+	}
+	if err := runCommand(newMockAPI(), "time", "import", path, "--dry-run", "--restart"); err != nil {
+		t.Errorf("unexpected error with --restart: %v", err)
+	}
+}
+
+// rateLimitedMock reports a nearly exhausted rate limit.
+type rateLimitedMock struct {
+	*mockPaymoAPI
+	status api.RateLimitStatus
+}
+
+func (m *rateLimitedMock) RateLimit() api.RateLimitStatus { return m.status }
+
+func TestWaitForRateLimit(t *testing.T) {
+	var slept time.Duration
+	orig := importSleep
+	defer func() { importSleep = orig }()
+	importSleep = func(d time.Duration) { slept = d }
+
+	mock := &rateLimitedMock{mockPaymoAPI: newMockAPI()}
+	mock.status = api.RateLimitStatus{Limit: 100, Remaining: 50, Reset: time.Now().Add(30 * time.Second)}
+	waitForRateLimit(mock, 25)
+	if slept != 0 {
+		t.Errorf("expected no wait with requests to spare, slept %s", slept)
+	}
+
+	mock.status.Remaining = 3
+	waitForRateLimit(mock, 25)
+	if slept < 25*time.Second || slept > 30*time.Second {
+		t.Errorf("expected to wait for the reset, slept %s", slept)
+	}
+
+	slept = 0
+	waitForRateLimit(newMockAPI(), 25)
+	if slept != 0 {
+		t.Errorf("clients without a rate limit should not wait, slept %s", slept)
+	}
+}
+
+// bulkMock records concurrent updates and deletes.
+type bulkMock struct {
+	*mockPaymoAPI
+	mu      sync.Mutex
+	updated map[int]*api.UpdateTimeEntryRequest
+	deleted []int
+	fail    map[int]bool
+}
+
+func newBulkMock() *bulkMock {
+	mock := &bulkMock{mockPaymoAPI: newMockAPI(), updated: map[int]*api.UpdateTimeEntryRequest{}, fail: map[int]bool{}}
+	mock.entries = []api.TimeEntry{
+		{ID: 100, TaskID: 10, UserID: 1, Duration: 900, Description: "Daily standup"},
+		{ID: 101, TaskID: 10, UserID: 1, Duration: 3600, Description: "Mockups"},
+		{ID: 102, TaskID: 11, UserID: 1, Duration: 900, Description: "Standup notes"},
+	}
+	return mock
+}
+
+func (m *bulkMock) UpdateEntry(id int, req *api.UpdateTimeEntryRequest) (*api.TimeEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.fail[id] {
+		return nil, errors.New("server error")
+	}
+	m.updated[id] = req
+	return &api.TimeEntry{ID: id}, nil
+}
+
+func (m *bulkMock) DeleteEntry(id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.fail[id] {
+		return errors.New("server error")
+	}
+	m.deleted = append(m.deleted, id)
+	return nil
+}
+
+func TestTimeBulkEdit(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	defer resetCommandFlags(bulkEditCmd, "set", "project", "yes", "filter")
+
+	mock := newBulkMock()
+	err := runCommand(mock, "time", "bulk-edit", "--project", "Project Alpha", "--set", "task=Development", "--set", "description=Sprint work", "--yes")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mock.updated) != 3 {
+		t.Fatalf("expected 3 entries updated, got %d", len(mock.updated))
+	}
+	for id, req := range mock.updated {
+		if req.TaskID == nil || *req.TaskID != 11 || req.Description == nil || *req.Description != "Sprint work" || req.Duration != nil {
+			t.Errorf("entry %d: unexpected update %+v", id, req)
+		}
+	}
+}
+
+func TestTimeBulkEdit_Errors(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	defer resetCommandFlags(bulkEditCmd, "set", "yes")
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{nil, "nothing to change"},
+		{[]string{"--set", "billable=true"}, `cannot set "billable"`},
+		{[]string{"--set", "duration"}, "use field=value"},
+		{[]string{"--set", "project=Project Beta"}, "needs --set task"},
+		{[]string{"--set", "duration=soon"}, "invalid duration"},
+	}
+	for _, tt := range tests {
+		resetCommandFlags(bulkEditCmd, "set", "yes")
+		err := runCommand(newBulkMock(), append([]string{"time", "bulk-edit", "--yes"}, tt.args...)...)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("bulk-edit %v: expected %q error, got: %v", tt.args, tt.want, err)
+		}
+	}
+}
+
+func TestTimeBulkDelete(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	defer resetCommandFlags(bulkDeleteCmd, "filter", "yes", "date")
+
+	mock := newBulkMock()
+	// Without --yes nothing is deleted when there is no terminal to ask in
+	err := runCommand(mock, "time", "bulk-delete", "--date", "today", "--filter", `description ~ "standup"`)
+	if err == nil || !strings.Contains(err.Error(), "2 entries would be deleted") {
+		t.Fatalf("expected confirmation error, got: %v", err)
+	}
+	if len(mock.deleted) != 0 {
+		t.Fatalf("deleted %d entries without confirmation", len(mock.deleted))
+	}
+
+	mock.fail[102] = true
+	err = runCommand(mock, "time", "bulk-delete", "--date", "today", "--filter", `description ~ "standup"`, "--yes")
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 entries failed to delete") {
+		t.Fatalf("expected partial failure, got: %v", err)
+	}
+	if len(mock.deleted) != 1 || mock.deleted[0] != 100 {
+		t.Errorf("expected entry 100 deleted, got %v", mock.deleted)
+	}
+}
+
+func TestTimeBulkDelete_RequiresDate(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	defer resetCommandFlags(bulkDeleteCmd, "yes", "date")
+
+	mock := newBulkMock()
+	err := runCommand(mock, "time", "bulk-delete", "--yes")
+	if err == nil || !strings.Contains(err.Error(), "--date is required") {
+		t.Fatalf("expected --date to be required, got: %v", err)
+	}
+	if len(mock.deleted) != 0 {
+		t.Errorf("deleted %v without --date", mock.deleted)
+	}
+}
+
+func TestTimeBulkDelete_ClearsRunningTimer(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	defer resetCommandFlags(bulkDeleteCmd, "filter", "yes", "date")
+	config.SaveTimerState(&config.TimerState{Active: true, EntryID: 101, StartTime: time.Now()})
+
+	mock := newBulkMock()
+	if err := runCommand(mock, "time", "bulk-delete", "--date", "today", "--filter", `description ~ "standup"`, "--yes"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state, _ := config.LoadTimerState(); !state.Active {
+		t.Fatal("expected the timer kept when its entry isn't deleted")
+	}
+
+	if err := runCommand(mock, "time", "bulk-delete", "--date", "today", "--filter", `description ~ "mockups"`, "--yes"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state, _ := config.LoadTimerState(); state.Active {
+		t.Errorf("expected the timer cleared with its entry, got %+v", state)
+	}
+}
+
+func TestBulkApply_RespectsRateLimit(t *testing.T) {
+	var waits int
+	orig := importSleep
+	defer func() { importSleep = orig }()
+	importSleep = func(time.Duration) { waits++ }
+
+	mock := &rateLimitedMock{mockPaymoAPI: newMockAPI()}
+	mock.status = api.RateLimitStatus{Limit: 40, Remaining: 1, Reset: time.Now().Add(time.Minute)}
+	var mu sync.Mutex
+	seen := map[int]bool{}
+	results := bulkApply(mock, []int{1, 2, 3, 4, 5}, 2, "deleted", func(id int) error {
+		mu.Lock()
+		defer mu.Unlock()
+		seen[id] = true
+		return nil
+	})
+	if len(seen) != 5 || results[4].ID != 5 || results[4].Status != "deleted" {
+		t.Errorf("unexpected results: %+v", results)
+	}
+	// Rounds of 2, 2 and 1 requests; the last fits in the remaining one
+	if waits != 2 {
+		t.Errorf("expected 2 rate limit waits, got %d", waits)
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	origIn, origOut := pickerIn, pickerOut
+	defer func() { pickerIn, pickerOut = origIn, origOut }()
+	pickerOut = io.Discard
+
+	for answer, want := range map[string]bool{"y\n": true, "YES\n": true, "n\n": false, "\n": false, "": false} {
+		pickerIn = strings.NewReader(answer)
+		if got := confirm("Delete?"); got != want {
+			t.Errorf("confirm with %q = %v, want %v", answer, got, want)
+		}
+	}
+}
+
+func TestSync_MergesIntoCache(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	defer resetCommandFlags(syncCmd, "full")
+
+	store := cache.NewStore(cache.NewMemoryBackend())
+	client := cache.NewCachedClient(newMockAPI(), store)
+	if err := runCommand(client, "sync", "projects"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	state, ok := store.SyncState("projects")
+	if !ok || state.FullAt.IsZero() {
+		t.Fatalf("expected a full first sync, got %+v", state)
+	}
+
+	if err := runCommand(client, "sync", "projects", "tasks", "--full"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var tasks []api.Task
+	if err := store.Records("tasks", &tasks); err != nil || len(tasks) != 2 {
+		t.Errorf("expected 2 synced tasks, got %d (%v)", len(tasks), err)
+	}
+}
+
+func TestSync_EntriesRange(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	useUTC(t)
+	defer resetCommandFlags(syncCmd, "from", "to")
+
+	store := cache.NewStore(cache.NewMemoryBackend())
+	client := cache.NewCachedClient(newMockAPI(), store)
+	if err := runCommand(client, "sync", "entries", "tasklists", "--from", "2026-02-01", "--to", "2026-02-28"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	state, ok := store.SyncState("entries")
+	if !ok || len(state.Coverage) != 1 {
+		t.Fatalf("expected one synced range, got %+v", state)
+	}
+	c := state.Coverage[0]
+	if !c.From.Equal(time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)) || !c.To.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected range %s - %s", c.From, c.To)
+	}
+	if _, ok := store.SyncState("tasklists"); !ok {
+		t.Error("expected task lists to be synced")
+	}
+
+	err := runCommand(client, "sync", "entries", "--from", "2026-03-01", "--to", "2026-02-01")
+	if err == nil || !strings.Contains(err.Error(), "is after --to") {
+		t.Errorf("expected range error, got: %v", err)
+	}
+}
+
+func TestSync_WithoutRecordsRefetches(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := runCommand(newMockAPI(), "sync", "clients", "projects", "tasklists", "entries"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := runCommand(newMockAPI(), "sync", "invoices"); err == nil {
+		t.Error("expected error for unknown target")
+	}
+}
+
+func TestDescribeSync(t *testing.T) {
+	f := output.NewFormatter("table")
+	f.Locale = locale.Default()
+	since := time.Date(2026, 2, 9, 9, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		result cache.SyncResult
+		want   string
+	}{
+		{cache.SyncResult{Full: true, Fetched: 3, MergeStats: cache.MergeStats{Total: 3}}, "3 items"},
+		{cache.SyncResult{Full: true, Fetched: 3, MergeStats: cache.MergeStats{Added: 1, Removed: 2, Total: 3}}, "3 items: 1 added, 0 changed, 2 removed"},
+		{cache.SyncResult{Since: since, Fetched: 1, MergeStats: cache.MergeStats{Changed: 1, Total: 5}}, "0 added, 1 changed since " + f.Locale.DateTime(since) + "; 5 items"},
+		{cache.SyncResult{Full: true, Range: &cache.Coverage{From: since, To: since.AddDate(0, 1, 0)}, MergeStats: cache.MergeStats{Added: 2, Total: 2}},
+			f.Locale.Date(since) + " to " + f.Locale.Date(since.AddDate(0, 1, -1)) + ", 2 items: 2 added, 0 changed"},
+	}
+	for _, tt := range tests {
+		if got := describeSync(tt.result, f); got != tt.want {
+			t.Errorf("describeSync(%+v) = %q, want %q", tt.result, got, tt.want)
+		}
+	}
+}
+
+func TestOffline_ReadsCacheWithoutCredentials(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	defer viper.Set("offline", false)
+	viper.Set("format", "json")
+	viper.Set("no_cache", false)
+	viper.Set("offline", true)
+
+	if _, err := getAPIClient(); err == nil || !strings.Contains(err.Error(), "nothing is cached yet") {
+		t.Fatalf("expected an empty-cache error, got: %v", err)
+	}
+
+	dir, err := config.EnsureConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := cache.Open(filepath.Join(dir, cache.FileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.MergeRecords("projects", []cache.Record{
+		{ID: 1, Value: api.Project{ID: 1, Name: "Project Alpha", Active: true}},
+	}, true, time.Now().Add(-3*time.Hour))
+	store.Close()
+
+	rootCmd.SetArgs([]string{"projects", "list"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("expected the cached projects offline, got: %v", err)
+	}
+	if _, stale := sessionCache.OldestStale(); !stale {
+		t.Error("expected the read to be reported stale")
+	}
+
+	rootCmd.SetArgs([]string{"projects", "create", "Gamma"})
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "cannot create a project") {
+		t.Errorf("expected the write to be refused, got: %v", err)
+	}
+
+	viper.Set("no_cache", true)
+	if _, err := getAPIClient(); err == nil || !strings.Contains(err.Error(), "--no-cache") {
+		t.Errorf("expected --offline and --no-cache to conflict, got: %v", err)
+	}
+}
+
+func TestStaleBanner(t *testing.T) {
+	loc := locale.Default()
+	cachedAt := time.Date(2026, 2, 9, 9, 30, 0, 0, time.Local)
+
+	got := staleBanner(cachedAt, "Offline", loc, cachedAt.Add(3*time.Hour+20*time.Minute))
+	want := "⚠ Offline: showing cached data from " + loc.DateTime(cachedAt) + " (3h old)"
+	if got != want {
+		t.Errorf("staleBanner = %q, want %q", got, want)
+	}
+	if got := staleBanner(cachedAt, "Paymo unreachable", loc, cachedAt.Add(time.Minute)); !strings.HasPrefix(got, "⚠ Paymo unreachable:") {
+		t.Errorf("unexpected banner %q", got)
+	}
+
+	for d, want := range map[time.Duration]string{
+		40 * time.Second: "40s",
+		12 * time.Minute: "12m",
+		50 * time.Hour:   "2d",
+	} {
+		if got := formatAge(d); got != want {
+			t.Errorf("formatAge(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+// --- Cache command tests ---
+
+// openTestCache opens the cache database in the test's HOME, encrypted
+// like the commands open it.
+func openTestCache(t *testing.T) *cache.Store {
+	t.Helper()
+	dir, err := config.EnsureConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := openCache(filepath.Join(dir, cache.FileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestCacheCommands(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	defer resetCommandFlags(cacheInvalidateCmd, "records")
+
+	if err := runCommand(newMockAPI(), "cache", "ls"); err != nil {
+		t.Fatalf("cache ls on an empty cache: %v", err)
+	}
+
+	store := openTestCache(t)
+	store.Set("project", "1", api.Project{ID: 1, Name: "Project Alpha"})
+	store.Set("projects", "all", []api.Project{{ID: 1, Name: "Project Alpha"}})
+	store.MergeRecords("projects", []cache.Record{{ID: 1, Value: api.Project{ID: 1}}}, true, time.Now())
+	store.Close()
+
+	for _, args := range [][]string{
+		{"cache", "ls"},
+		{"cache", "ls", "project"},
+		{"cache", "show", "project", "1"},
+		{"cache", "status"},
+		{"cache", "prune"},
+	} {
+		if err := runCommand(newMockAPI(), args...); err != nil {
+			t.Errorf("%v: %v", args, err)
+		}
+	}
+
+	if err := runCommand(newMockAPI(), "cache", "ls", "widgets"); err == nil || !strings.Contains(err.Error(), "unknown cache type") {
+		t.Errorf("expected an unknown type error, got: %v", err)
+	}
+	if err := runCommand(newMockAPI(), "cache", "show", "project", "9"); err == nil || !strings.Contains(err.Error(), "no cached project entry") {
+		t.Errorf("expected a missing entry error, got: %v", err)
+	}
+
+	if err := runCommand(newMockAPI(), "cache", "invalidate", "projects", "project", "--records"); err != nil {
+		t.Fatalf("cache invalidate: %v", err)
+	}
+	store = openTestCache(t)
+	defer store.Close()
+	if stats, _ := store.Stats(); len(stats) != 0 {
+		t.Errorf("expected the projects invalidated, got %v", stats)
+	}
+	if _, ok := store.SyncState("projects"); ok {
+		t.Error("expected --records to drop the synced projects")
+	}
+}
+
+func TestCacheRekey(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	store := openTestCache(t)
+	store.Set("project", "1", api.Project{ID: 1, Name: "Project Alpha"})
+	store.Close()
+	dir, _ := config.GetConfigDir()
+	mockPath := filepath.Join(dir, "caches", "0123456789abcdef.db")
+	mockStore, err := openCache(mockPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mockStore.Set("project", "2", api.Project{ID: 2, Name: "Project Beta"})
+	mockStore.Close()
+	oldKey, err := config.LoadCacheKey()
+	if err != nil || oldKey == nil {
+		t.Fatalf("expected a cache key to be created, got %v, %v", oldKey, err)
+	}
+
+	if err := runCommand(newMockAPI(), "cache", "rekey"); err != nil {
+		t.Fatalf("cache rekey: %v", err)
+	}
+	newKey, _ := config.LoadCacheKey()
+	if string(newKey) == string(oldKey) {
+		t.Error("expected cache rekey to replace the key")
+	}
+
+	store = openTestCache(t)
+	defer store.Close()
+	var project api.Project
+	if err := store.Get("project", "1", &project); err != nil || project.Name != "Project Alpha" {
+		t.Errorf("expected the cached project to survive rekey, got %+v, %v", project, err)
+	}
+	// The cache of another base URL is re-encrypted as well
+	mockStore, err = openCache(mockPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mockStore.Close()
+	if err := mockStore.Get("project", "2", &project); err != nil || project.Name != "Project Beta" {
+		t.Errorf("expected the mock server's cached project to survive rekey, got %+v, %v", project, err)
+	}
+}
+
+func TestCacheRekey_Interrupted(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	store := openTestCache(t)
+	store.Set("project", "1", api.Project{ID: 1, Name: "Project Alpha"})
+	dir, _ := config.GetConfigDir()
+	dbPath := filepath.Join(dir, cache.FileName)
+	mockPath := filepath.Join(dir, "caches", "0123456789abcdef.db")
+	mockStore, err := openCache(mockPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mockStore.Set("project", "2", api.Project{ID: 2, Name: "Project Beta"})
+	mockStore.Close()
+
+	// Cut off after re-encrypting cache.db, before the mock server's cache
+	key, _ := cache.NewKey()
+	config.SavePendingCacheKey(key, []string{dbPath, mockPath})
+	if err := store.Rekey(key); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	// Opening the cache that wasn't re-encrypted yet finishes the rekey
+	mockStore, err = openCache(mockPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var project api.Project
+	if err := mockStore.Get("project", "2", &project); err != nil || project.Name != "Project Beta" {
+		t.Errorf("expected the mock server's cached project under the new key, got %+v, %v", project, err)
+	}
+	mockStore.Close()
+	if stored, _ := config.LoadCacheKey(); string(stored) != string(key) {
+		t.Error("expected the pending key to be stored")
+	}
+	store = openTestCache(t)
+	if err := store.Get("project", "1", &project); err != nil || project.Name != "Project Alpha" {
+		t.Errorf("expected the cached project under the new key, got %+v, %v", project, err)
+	}
+	store.Close()
+
+	// Cut off before re-encrypting
+	other, _ := cache.NewKey()
+	config.SavePendingCacheKey(other, []string{dbPath, mockPath})
+	mockStore, err = openCache(mockPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mockStore.Close()
+	store = openTestCache(t)
+	defer store.Close()
+	if err := store.Get("project", "1", &project); err != nil {
+		t.Errorf("expected the cached project under the old key, got %v", err)
+	}
+	if stored, _ := config.LoadCacheKey(); string(stored) != string(key) {
+		t.Error("expected the stored key to be kept")
+	}
+	if pending, _, _ := config.LoadPendingCacheKey(); pending != nil {
+		t.Error("expected the unused pending key to be dropped")
+	}
+}
+
+func TestCacheTTLOverrides(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cfg := &config.Config{Cache: config.CacheConfig{TTL: map[string]string{"tasks": "10m"}}}
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	dir, _ := config.GetConfigDir()
+	store, err := openCache(filepath.Join(dir, cache.FileName))
+	if err != nil {
+		t.Fatalf("openCache: %v", err)
+	}
+	if got := store.TTL("task"); got != 10*time.Minute {
+		t.Errorf("task TTL = %v, want the 10m override", got)
+	}
+	store.Close()
+
+	cfg.Cache.TTL["tasks"] = "ten minutes"
+	if err := config.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := runCommand(newMockAPI(), "projects", "list"); err == nil || !strings.Contains(err.Error(), "cache.ttl") {
+		t.Errorf("expected an invalid TTL to be rejected, got: %v", err)
+	}
+}
+
+func TestCacheStats_CountsCommandReads(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	origClient := getAPIClient
+	defer func() { getAPIClient = origClient }()
+	mock := newMockAPI()
+	getAPIClient = func() (api.PaymoAPI, error) { return wrapWithCache(mock), nil }
+
+	viper.Set("format", "json")
+	viper.Set("no_cache", false)
+	defer viper.Set("no_cache", true)
+	for i := 0; i < 2; i++ {
+		rootCmd.SetArgs([]string{"projects", "list"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("projects list: %v", err)
+		}
+	}
+
+	store := openTestCache(t)
+	defer store.Close()
+	counts, _ := store.HitStats()
+	if counts["projects"] != (cache.HitStats{Hits: 1, Misses: 1}) {
+		t.Errorf("projects counters = %+v, want 1 hit and 1 miss", counts["projects"])
+	}
+}
+
+func TestFormatTTL(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		time.Hour:                     "1h",
+		90 * time.Minute:              "1h30m",
+		10 * time.Minute:              "10m",
+		45 * time.Second:              "45s",
+		24*time.Hour + 30*time.Second: "24h0m30s",
+	} {
+		if got := formatTTL(d); got != want {
+			t.Errorf("formatTTL(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestCacheFilePath_PerBaseURL(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir, _ := config.GetConfigDir()
+	defer viper.Set("api.base_url", "")
+
+	if path, err := cacheFilePath(); err != nil || path != filepath.Join(dir, cache.FileName) {
+		t.Errorf("expected %s for Paymo, got %s, %v", cache.FileName, path, err)
+	}
+
+	viper.Set("api.base_url", "http://127.0.0.1:8765/api")
+	mockPath, err := cacheFilePath()
+	if err != nil || filepath.Dir(mockPath) != filepath.Join(dir, "caches") {
+		t.Fatalf("expected a cache of its own for another base URL, got %s, %v", mockPath, err)
+	}
+	viper.Set("api.base_url", "http://127.0.0.1:9000/api")
+	if other, _ := cacheFilePath(); other == mockPath {
+		t.Error("expected each base URL to have its own cache")
+	}
+
+	// Reads through another server don't touch Paymo's cache
+	viper.Set("api.base_url", "http://127.0.0.1:8765/api")
+	viper.Set("no_cache", false)
+	defer viper.Set("no_cache", true)
+	defer func() { sessionCache = nil }()
+	client := wrapWithCache(newMockAPI())
+	if _, err := client.GetProjects(nil); err != nil {
+		t.Fatal(err)
+	}
+	sessionCache.Release()
+	if _, err := os.Stat(filepath.Join(dir, cache.FileName)); !os.IsNotExist(err) {
+		t.Errorf("expected no %s for the mock server, got %v", cache.FileName, err)
+	}
+	if _, err := os.Stat(mockPath); err != nil {
+		t.Errorf("expected the mock server's cache at %s, got %v", mockPath, err)
+	}
+}
+
+func TestCacheGrace(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if grace, err := cacheGrace(); err != nil || grace != cache.DefaultGrace {
+		t.Errorf("cacheGrace() = %v, %v; want the default", grace, err)
+	}
+
+	cfg := &config.Config{Cache: config.CacheConfig{Grace: "0"}}
+	config.SaveConfig(cfg)
+	if grace, err := cacheGrace(); err != nil || grace != 0 {
+		t.Errorf("cacheGrace() = %v, %v; want 0", grace, err)
+	}
+
+	cfg.Cache.Grace = "a while"
+	config.SaveConfig(cfg)
+	if err := runCommand(newMockAPI(), "projects", "list"); err == nil || !strings.Contains(err.Error(), "cache.grace") {
+		t.Errorf("expected an invalid grace to be rejected, got: %v", err)
+	}
+}
+
+// --- End-to-end tests against the mock Paymo server ---
+
+// newMockServerAPI serves a mock Paymo server with a user, a project and
+// two tasks, and returns it with a real API client talking to it over
+// HTTP.
+func newMockServerAPI(t *testing.T) (*mockserver.Server, api.PaymoAPI) {
+	t.Helper()
+	srv := mockserver.New()
+	srv.Add("users", api.User{Name: "Dana", Email: "dana@example.com", Active: true})
+	project := srv.Add("projects", api.Project{Name: "Website Redesign", Active: true, Billable: true})
+	srv.Add("tasks", api.Task{Name: "Design", ProjectID: project})
+	srv.Add("tasks", api.Task{Name: "Development", ProjectID: project})
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+	return srv, api.NewClientWithBaseURL(ts.URL+mockserver.BasePath, &api.APIKeyAuth{APIKey: "test-key"})
+}
+
+func TestEndToEnd_TimerFlow(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	srv, client := newMockServerAPI(t)
+
+	if err := runCommand(client, "time", "start", "Website Redesign", "Design", "Sketching"); err != nil {
+		t.Fatalf("time start: %v", err)
+	}
+	var entries []api.TimeEntry
+	srv.List("entries", &entries)
+	if len(entries) != 1 || entries[0].TaskID != 1 || entries[0].Description != "Sketching" || !entries[0].EndTime.IsZero() {
+		t.Fatalf("expected a running entry on Design, got %+v", entries)
+	}
+
+	if err := runCommand(client, "time", "stop"); err != nil {
+		t.Fatalf("time stop: %v", err)
+	}
+	srv.List("entries", &entries)
+	if len(entries) != 1 || entries[0].EndTime.IsZero() {
+		t.Errorf("expected the entry to be stopped, got %+v", entries)
+	}
+	if state, _ := config.LoadTimerState(); state != nil && state.Active {
+		t.Errorf("expected no local timer, got %+v", state)
+	}
+
+	if err := runCommand(client, "time", "log"); err != nil {
+		t.Errorf("time log: %v", err)
+	}
+	if err := runCommand(client, "time", "delete", fmt.Sprint(entries[0].ID)); err != nil {
+		t.Fatalf("time delete: %v", err)
+	}
+	if srv.List("entries", &entries); len(entries) != 0 {
+		t.Errorf("expected the entry to be deleted, got %+v", entries)
+	}
+}
+
+func TestEndToEnd_ProjectsAndTasks(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	srv, client := newMockServerAPI(t)
+
+	if err := runCommand(client, "tasks", "create", "Testing", "--project", "Website Redesign"); err != nil {
+		t.Fatalf("tasks create: %v", err)
+	}
+	var task api.Task
+	if !srv.Get("tasks", 3, &task) || task.Name != "Testing" || task.ProjectID != 1 || task.TaskListID == 0 {
+		t.Errorf("expected the task in the project's default list, got %+v", task)
+	}
+	if err := runCommand(client, "tasks", "complete", "3"); err != nil {
+		t.Fatalf("tasks complete: %v", err)
+	}
+	if srv.Get("tasks", 3, &task); !task.Complete {
+		t.Error("expected the task to be completed")
+	}
+
+	for _, args := range [][]string{
+		{"projects", "list"},
+		{"projects", "show", "Website Redesign"},
+		{"tasks", "list", "--project", "Website Redesign"},
+	} {
+		if err := runCommand(client, args...); err != nil {
+			t.Errorf("%v: %v", args, err)
+		}
+	}
+
+	var sawWhere bool
+	for _, req := range srv.Requests() {
+		if req.Path == "tasks" && strings.Contains(req.Query.Get("where"), "project_id=1") {
+			sawWhere = true
+		}
+	}
+	if !sawWhere {
+		t.Error("expected tasks list to filter by project on the server")
+	}
+}
+
+func TestEndToEnd_APIErrors(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	srv, client := newMockServerAPI(t)
+
+	srv.Fail(mockserver.Failure{Path: "projects", Status: http.StatusForbidden, Message: "no access to projects"})
+	err := runCommand(client, "projects", "list")
+	var apiErr *api.APIError
+	if !errors.As(err, &apiErr) || apiErr.ExitCode() != 3 || !strings.Contains(err.Error(), "no access to projects") {
+		t.Errorf("expected the injected 403, got %v", err)
+	}
+}
+
+func TestEndToEnd_CachedReads(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	srv, client := newMockServerAPI(t)
+	origClient := getAPIClient
+	defer func() { getAPIClient = origClient }()
+	getAPIClient = func() (api.PaymoAPI, error) { return wrapWithCache(client), nil }
+
+	viper.Set("format", "json")
+	viper.Set("no_cache", false)
+	defer viper.Set("no_cache", true)
+	for i := 0; i < 2; i++ {
+		rootCmd.SetArgs([]string{"projects", "list"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("projects list: %v", err)
+		}
+	}
+
+	calls := 0
+	for _, req := range srv.Requests() {
+		if req.Path == "projects" {
+			calls++
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected the second list to come from the cache, got %d API calls", calls)
+	}
+}
+
+func TestServeMockServer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := mockserver.New()
+	srv.Seed()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- serveMockServer(ctx, ln, srv) }()
+
+	client := api.NewClientWithBaseURL("http://"+ln.Addr().String()+mockserver.BasePath, &api.APIKeyAuth{APIKey: "mock"})
+	if user, err := client.GetMe(); err != nil || user.Name != "Dana Demo" {
+		t.Errorf("GetMe = %+v, %v", user, err)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("serveMockServer: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the server to stop when the context is done")
+	}
+}
+
+func TestMockServerCmd_InvalidRateLimit(t *testing.T) {
+	defer resetCommandFlags(mockServerCmd, "rate-limit")
+	if err := runCommand(newMockAPI(), "dev", "mock-server", "--rate-limit", "-1"); err == nil || !strings.Contains(err.Error(), "--rate-limit") {
+		t.Errorf("expected a rate limit error, got %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ComputClaw/paymo-cli/internal/config"
+)
+
+// contextCmd groups the directory context commands
+var contextCmd = &cobra.Command{
+	Use:   "context",
+	Short: "Per-directory project context commands",
+	Long: `Inspect the project context supplied by .paymo-project.yaml files.
+
+paymo looks for .paymo-project.yaml in the current directory and every
+parent directory. The nearest file wins for each setting; the project
+falls back to defaults.project_id in config.yaml. A task only applies with
+a project from the same file or a parent's, never with the config.yaml
+default.
+
+  project: "Website Redesign"     # default project (name or ID)
+  task: "Development"             # default task (name or ID)
+  billable: true                  # default for projects/tasks create
+  description: "{branch}"         # template for time start descriptions
+
+Description placeholders: {branch} (current git branch), {dir} (name of
+the directory holding the context file), {date} (YYYY-MM-DD).`,
+}
+
+// contextShowCmd shows the effective context and where each value came from
+var contextShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the effective project context and its sources",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, err := loadContext()
+		if err != nil {
+			return err
+		}
+
+		formatter := newFormatter()
+		if formatter.Structured() {
+			return formatter.FormatTimerStatus(ctx)
+		}
+		if formatter.Quiet {
+			return nil
+		}
+
+		fmt.Fprintf(formatter.Writer, "Context for %s\n", ctx.Dir)
+		for _, row := range []struct {
+			label string
+			value config.ContextValue
+		}{
+			{"Project", ctx.Project},
+			{"Task", ctx.Task},
+			{"Billable", ctx.Billable},
+			{"Description", ctx.Description},
+		} {
+			if !row.value.IsSet() {
+				fmt.Fprintf(formatter.Writer, "  %-12s (not set)\n", row.label+":")
+				continue
+			}
+			fmt.Fprintf(formatter.Writer, "  %-12s %s\n", row.label+":", row.value.Value)
+			fmt.Fprintf(formatter.Writer, "  %-12s   from %s\n", "", row.value.Source)
+		}
+		if len(ctx.Files) == 0 {
+			fmt.Fprintf(formatter.Writer, "\nNo %s found in this directory or its parents.\n", config.ContextFile)
+		}
+		return nil
+	},
+}
+
+// loadContext resolves the project context for the working directory.
+func loadContext() (*config.Context, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("getting working dir: %w", err)
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return config.ResolveContext(wd, cfg)
+}
+
+// contextBillable returns the context's billable default for a --billable
+// flag the user did not set explicitly.
+func contextBillable(cmd *cobra.Command, flagValue bool) bool {
+	if cmd.Flags().Changed("billable") {
+		return flagValue
+	}
+	ctx, err := loadContext()
+	if err != nil || !ctx.Billable.IsSet() {
+		return flagValue
+	}
+	if b, err := strconv.ParseBool(ctx.Billable.Value); err == nil {
+		return b
+	}
+	return flagValue
+}
+
+// expandDescriptionTemplate fills the description template placeholders.
+// source is the context file that supplied the template; {date} is the
+// date of now, in the user's timezone.
+func expandDescriptionTemplate(tmpl, source string, now time.Time) string {
+	if !strings.Contains(tmpl, "{") {
+		return tmpl
+	}
+	branch := ""
+	if strings.Contains(tmpl, "{branch}") {
+		if b, err := gitOutput("rev-parse", "--abbrev-ref", "HEAD"); err == nil && b != "HEAD" {
+			branch = b
+		}
+	}
+	r := strings.NewReplacer(
+		"{branch}", branch,
+		"{dir}", filepath.Base(filepath.Dir(source)),
+		"{date}", now.Format("2006-01-02"),
+	)
+	return strings.TrimSpace(r.Replace(tmpl))
+}
+
+func init() {
+	rootCmd.AddCommand(contextCmd)
+	contextCmd.AddCommand(contextShowCmd)
+}
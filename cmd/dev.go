@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ComputClaw/paymo-cli/internal/mockserver"
+	"github.com/ComputClaw/paymo-cli/internal/output"
+)
+
+// devCmd groups tools for developing against paymo and Paymo
+var devCmd = &cobra.Command{
+	Use:   "dev",
+	Short: "Development tools",
+	Long:  `Tools for developing and testing paymo and scripts built on it.`,
+}
+
+// mockServerCmd serves an in-memory fake of the Paymo API
+var mockServerCmd = &cobra.Command{
+	Use:   "mock-server",
+	Short: "Run a local fake Paymo API",
+	Long: `Serve an in-memory fake of the Paymo API on localhost, for trying paymo
+and scripts without touching a real account. It answers clients, projects,
+task lists, tasks, time entries and users, with where filters, includes
+and rate-limit headers. It starts with a small sample account unless
+--empty is given; everything is lost when it stops.
+
+Point paymo at it with:
+  PAYMO_BASE_URL=http://127.0.0.1:8765/api PAYMO_API_KEY=mock paymo projects list
+
+paymo keeps a separate cache for every base URL other than Paymo's, so
+mock data never mixes with your account's cached data.
+
+Failures can be injected while it runs, by POSTing JSON to /_mock/failures
+(DELETE clears them):
+  curl -d '{"path":"projects","status":503,"times":2}' http://127.0.0.1:8765/_mock/failures
+
+Examples:
+  paymo dev mock-server
+  paymo dev mock-server --port 9000 --empty
+  paymo dev mock-server --api-key secret --rate-limit 10`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		port, _ := cmd.Flags().GetInt("port")
+		apiKey, _ := cmd.Flags().GetString("api-key")
+		empty, _ := cmd.Flags().GetBool("empty")
+		rateLimit, _ := cmd.Flags().GetInt("rate-limit")
+		ratePeriod, _ := cmd.Flags().GetDuration("rate-period")
+		if rateLimit < 0 || (rateLimit > 0 && ratePeriod <= 0) {
+			return fmt.Errorf("--rate-limit and --rate-period must be positive")
+		}
+
+		srv := mockserver.New()
+		if !empty {
+			srv.Seed()
+		}
+		srv.SetAPIKey(apiKey)
+		srv.SetRateLimit(rateLimit, ratePeriod)
+
+		ln, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+		if err != nil {
+			return fmt.Errorf("starting mock server: %w", err)
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		formatter := newFormatter()
+		baseURL := "http://" + ln.Addr().String() + mockserver.BasePath
+		if err := formatter.FormatMockServer(output.MockServerInfo{BaseURL: baseURL, APIKey: apiKey}); err != nil {
+			ln.Close()
+			return err
+		}
+		return serveMockServer(ctx, ln, srv)
+	},
+}
+
+// serveMockServer serves the mock server on ln until ctx is done.
+func serveMockServer(ctx context.Context, ln net.Listener, srv *mockserver.Server) error {
+	server := &http.Server{Handler: srv, ReadHeaderTimeout: 10 * time.Second}
+	done := make(chan error, 1)
+	go func() { done <- server.Serve(ln) }()
+
+	select {
+	case err := <-done:
+		return fmt.Errorf("mock server: %w", err)
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("stopping mock server: %w", err)
+	}
+	if err := <-done; !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("mock server: %w", err)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(devCmd)
+	devCmd.AddCommand(mockServerCmd)
+
+	mockServerCmd.Flags().Int("port", 8765, "Port to listen on (on 127.0.0.1)")
+	mockServerCmd.Flags().String("api-key", "", "Only accept this API key (default: any)")
+	mockServerCmd.Flags().Bool("empty", false, "Start without the sample account")
+	mockServerCmd.Flags().Int("rate-limit", 0, "Requests allowed per --rate-period (0: no limit)")
+	mockServerCmd.Flags().Duration("rate-period", time.Minute, "Rate limit period")
+}
@@ -80,7 +80,7 @@ MAIN COMMANDS
 GLOBAL FLAGS
 ------------
   --config string   Custom config file path
-  --format string   Output format: table, json, csv (default "table")
+  --format string   Output format: csv, json, markdown, ndjson, table, tsv, yaml (default "table")
   --verbose         Enable verbose output
   --help            Show help for any command
 
@@ -149,17 +149,24 @@ Track time on projects and tasks with start/stop commands.
 
 COMMANDS
 --------
-  paymo time start    Start a new timer
-  paymo time stop     Stop the running timer
-  paymo time status   Show current timer status
-  paymo time log      List time entries
+  paymo time start        Start a new timer
+  paymo time stop         Stop the running timer
+  paymo time status       Show current timer status
+  paymo time log          List time entries
+  paymo time bulk-edit    Change many entries at once (--set field=value)
+  paymo time bulk-delete  Delete many entries at once
+  paymo time export       Export entries as calendar events (.ics)
+  paymo time import       Create entries from calendar events or CSV/JSON files
 
 START TIMER
 -----------
   paymo time start <project> <task> [description]
   paymo time start -p <project> -t <task> -d <description>
 
-  Arguments can be names (fuzzy matched) or IDs.
+  Arguments can be names (fuzzy matched) or IDs. Names match by prefix or
+  project code; close spellings are shown as "did you mean" hints but never
+  picked on their own. In a terminal, an omitted or ambiguous project/task
+  opens a numbered picker.
 
   Examples:
     paymo time start "Website Redesign" "Development" "Working on homepage"
@@ -175,8 +182,11 @@ STOP TIMER
 CHECK STATUS
 ------------
   paymo time status
+  paymo time status --watch [--target 8h]
 
   Shows: project, task, description, start time, elapsed time.
+  --watch redraws every second with today's total and progress towards
+  the daily target; with --format json it emits one JSON line per tick.
 
 VIEW TIME LOG
 -------------
@@ -185,7 +195,7 @@ VIEW TIME LOG
   Flags:
     --date string      Filter by date (today, yesterday, this-week, YYYY-MM-DD)
     --project string   Filter by project name or ID
-    --format string    Output format (table, json, csv)
+    --format string    Output format (table, json, csv, tsv, yaml, ndjson, markdown)
 
   Examples:
     paymo time log                        # Today's entries
@@ -193,6 +203,38 @@ VIEW TIME LOG
     paymo time log --date 2026-02-01      # Specific date
     paymo time log --project "Website"    # Filter by project
     paymo time log --format json          # JSON output
+
+BULK CHANGES
+------------
+  paymo time bulk-edit --date last-week --project Website --set task=Design
+  paymo time bulk-delete --date yesterday --filter 'duration < 1m'
+
+  Entries are selected with --date, --project and --filter, shown, and
+  changed after you confirm (--yes skips the question). Each entry is
+  reported as updated/deleted or failed.
+
+CALENDARS
+---------
+  paymo time export --ics --from 2026-02-01 --to 2026-02-28 > february.ics
+  paymo time import meetings.ics --map rules.yaml --dry-run
+
+  export writes one VEVENT per entry ("Project / Task" as the summary,
+  the description as the body) to overlay logged time on a calendar.
+
+  import turns calendar events into entries. The rules file maps event
+  summaries to a project and task; see 'paymo time import --help' for the
+  format. All-day, cancelled and recurring events are skipped, and so are
+  events already logged on the same task at the same time.
+
+  import also reads CSV and JSON files, such as Toggl and Harvest exports.
+  Columns are recognized by name (Date, Start time, Duration, Hours,
+  Project, Task, Description, Notes); map others with --column
+  "field=Header". Rows are checked first: invalid rows stop the import
+  unless --skip-invalid. Entries are created in rate-limited batches and
+  progress is saved, so an interrupted import resumes when run again.
+
+    paymo time import toggl.csv --dry-run
+    paymo time import harvest.csv --task Development --skip-invalid
 `)
 	return nil
 }
@@ -220,7 +262,7 @@ LIST PROJECTS
     --active        Show only active projects (default true)
     --all           Include inactive projects
     --client ID     Filter by client ID
-    --format        Output format (table, json, csv)
+    --format        Output format (table, json, csv, tsv, yaml, ndjson, markdown)
 
   Examples:
     paymo projects list
@@ -293,7 +335,7 @@ LIST TASKS
   Flags:
     --project string   Filter by project name or ID
     --all              Include completed tasks
-    --format           Output format (table, json, csv)
+    --format           Output format (table, json, csv, tsv, yaml, ndjson, markdown)
 
   Examples:
     paymo tasks list
@@ -340,18 +382,120 @@ All list commands support multiple output formats via --format flag.
 
 FORMATS
 -------
-  table    Human-readable table with borders (default)
-  json     JSON array for parsing/automation
-  csv      CSV for spreadsheet import
+  table     Human-readable table with borders (default)
+  json      JSON array for parsing/automation
+  ndjson    Newline-delimited JSON, one object per line (streaming, jq -c)
+  yaml      YAML with the same keys as JSON
+  csv       CSV for spreadsheet import
+  tsv       Tab-separated values (cut/awk friendly)
+  markdown  GitHub-flavored Markdown table (issues, PRs, wikis)
+
+Unknown formats are rejected with an error listing the available ones.
 
 EXAMPLES
 --------
   paymo projects list --format table
   paymo projects list --format json
   paymo projects list --format csv > projects.csv
+  paymo time log --date this-week --format markdown
 
   paymo time log --format json | jq '.[] | .duration'
 
+TABLE LAYOUT
+------------
+Tables are sized to the terminal: narrow columns keep their width, long
+ones are shortened with "..." (descriptions wrap onto extra lines). Wide
+East Asian characters are measured correctly. The border style comes from
+output.table_style in config.yaml:
+
+  box      Unicode box drawing (default)
+  ascii    +---+ borders
+  plain    No borders, two-space gaps
+  compact  No borders, single-space gaps, underlined header
+
+When stdout is not a terminal (piped or redirected), tables are printed in
+the plain style at full width.
+
+COLUMNS AND TEMPLATES
+---------------------
+List and show commands accept --columns and --template:
+
+  paymo time log --columns id,project,task,billable,start,end
+  paymo projects list --format csv --columns id,name,client_id
+  paymo time log --template '{{.ID}}\t{{.Task.Name}}\t{{duration .Duration}}'
+
+--columns applies to table, markdown, csv and tsv output; unknown names are
+rejected. Run 'paymo schema' to see the columns of each command.
+--template replaces --format and is rendered once per item (Go text/template
+syntax). Functions: duration, hours, date, time, datetime, json, upper, lower.
+Related records that weren't loaded, such as .Task on an entry without a
+task, are empty rather than an error.
+
+SORTING AND GROUPING
+--------------------
+List commands accept --sort and --group-by, using the same column names:
+
+  paymo time log --date this-week --sort duration,-date
+  paymo time log --date last-week --group-by day
+  paymo time log --date this-week --group-by project --sort -duration
+  paymo tasks list --group-by project
+
+--sort takes comma-separated columns; prefix one with "-" to sort it
+descending. Numbers sort numerically, times chronologically.
+
+--group-by sorts rows into groups (project, task or day for time entries;
+any column works). Tables and markdown show a subtotal row after each group
+with the item count and total duration. json, yaml and ndjson return one
+object per group: {"group": ..., "count": ..., "total_duration": ...,
+"items": [...]}. csv and tsv stay flat, ordered by group.
+
+FILTERING
+---------
+List commands accept --filter, an expression evaluated locally over the
+same column names as --columns and --sort:
+
+  paymo time log --date this-week --filter 'duration > 2h and billable'
+  paymo time log --date last-week --filter 'description ~ "review"'
+  paymo tasks list --filter 'not complete and priority >= 75'
+  paymo projects list --filter '(client = 5 or client = 6) and active'
+
+Comparisons are =, !=, <, <=, >, >=, ~ (contains, case-insensitive) and
+!~. Combine them with and, or, not and parentheses. A yes/no column on its
+own (billable, active, complete) is true when set.
+
+Values are typed by column: durations as 2h, 90m, 1h30m or seconds; dates
+as YYYY-MM-DD (or output.date_format), today, yesterday or tomorrow,
+compared by day in your timezone; exact timestamps as RFC 3339. Quote text
+containing spaces. Mistakes are reported with the column they occur at:
+
+  invalid filter: "duration" expects a duration like 2h, 90m or 1h30m, got "soon" at column 12
+    duration > soon
+               ^
+
+Filtering happens before --sort, --group-by and totals, and the expression
+is recorded under "filter" in the --envelope filters.
+
+ENVELOPE
+--------
+With --envelope, list commands in json, yaml and ndjson wrap the results
+with metadata about how they were produced:
+
+  paymo time log --date this-week --format json --envelope
+
+  {
+    "data": [ ... ],
+    "count": 12,
+    "total_duration": 86400,
+    "filters": {"date": "this-week", "from": "...", "to": "...", "user_id": 42},
+    "cached": true,
+    "stale": false,
+    "cached_at": "2026-02-07T09:00:00+01:00"
+  }
+
+total_duration (seconds) is only present for time entries. "cached" is true
+when the results came from the local cache; "stale" is true when the API
+was unreachable and expired cache data was served instead.
+
 JSON OUTPUT
 -----------
 JSON format is ideal for:
@@ -393,6 +537,8 @@ FILES
 -----
   config.yaml     Preferences and settings (0644 permissions)
   credentials     API key and auth info (0600 permissions)
+  cache.key       Key the cache is encrypted with (0600 permissions)
+  cache.db        Cached and synced Paymo data, encrypted (0600 permissions)
 
 CONFIG FILE (~/.config/paymo-cli/config.yaml)
 ----------------------------------------------
@@ -402,11 +548,25 @@ api:
 
 defaults:
   format: "table"
-  timezone: "UTC"
+  timezone: "UTC"        # IANA name; default: your Paymo timezone, else the system's
 
 output:
-  date_format: "2006-01-02"
-  time_format: "15:04"
+  date_format: "2006-01-02"  # Go layout, e.g. "02/01/2006"
+  time_format: "15:04"       # Go layout, e.g. "3:04PM"
+  table_style: "box"     # box, ascii, plain or compact
+
+cache:
+  ttl:                   # cache lifetime per type; see 'paymo cache ls'
+    tasks: "10m"         # a list type's TTL also applies to its single items
+    me: "0"              # 0 turns caching of a type off
+  grace: "1h"            # expired data younger than this (and than half its
+                         # TTL) is shown at once, with a notice, and
+                         # refreshed in the background; "0" waits instead
+
+Dates and times in tables, detail views, markdown, CSV/TSV and --template
+are shown in the timezone above. Date flags (--date) are interpreted in the
+same timezone and accept YYYY-MM-DD as well as date_format. CSV and TSV use
+ISO dates and RFC 3339 timestamps; json and yaml keep the API's timestamps.
 
 CREDENTIALS FILE (~/.config/paymo-cli/credentials)
 ---------------------------------------------------
@@ -414,14 +574,17 @@ CREDENTIALS FILE (~/.config/paymo-cli/credentials)
   "auth_type": "api_key",
   "api_key": "your-api-key",
   "user_id": 123,
-  "user_name": "Your Name"
+  "user_name": "Your Name",
+  "user_timezone": "Europe/Copenhagen"
 }
 
 ENVIRONMENT VARIABLES
 ---------------------
   PAYMO_API_KEY       API key (overrides credentials file)
-  PAYMO_FORMAT        Default output format (table/json/csv)
+  PAYMO_FORMAT        Default output format (table/json/csv/...)
   PAYMO_VERBOSE       Enable verbose output (true/false)
+  PAYMO_OFFLINE       Never touch the network, like --offline (1/true)
+  PAYMO_BASE_URL      API base URL (overrides api.base_url)
 
 PRECEDENCE
 ----------
@@ -435,6 +598,8 @@ SECURITY
 - Credentials file: 0600 permissions (owner read/write only)
 - Config file: 0644 permissions (world readable, owner writable)
 - Warning shown if credentials have insecure permissions
+- Cache: encrypted with cache.key (AES-256-GCM); 'paymo cache rekey'
+  replaces the key and re-encrypts the caches
 - Use PAYMO_API_KEY env var in CI/CD pipelines
 
 CUSTOM CONFIG FILE
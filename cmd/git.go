@@ -0,0 +1,352 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ComputClaw/paymo-cli/internal/api"
+	"github.com/ComputClaw/paymo-cli/internal/config"
+)
+
+// hookMarker identifies hook scripts written by paymo.
+const hookMarker = "# Installed by paymo-cli"
+
+// managedHooks are the git hooks installed by `paymo git install-hooks`.
+var managedHooks = []string{"post-checkout", "post-commit"}
+
+// gitCmd groups the git integration commands
+var gitCmd = &cobra.Command{
+	Use:   "git",
+	Short: "Git integration commands",
+	Long: `Commands for tracking time automatically from git activity.
+
+A .paymo-project.yaml file in the repository root maps the repository to a
+project and task. Branch globs can map branches to different tasks:
+
+  project: "Website Redesign"
+  task: "Development"
+  branches:
+    "fix/*": "Bug Fixing"
+    "docs/*": "Documentation"`,
+}
+
+// installHooksCmd writes the paymo git hooks into the current repository
+var installHooksCmd = &cobra.Command{
+	Use:   "install-hooks",
+	Short: "Install git hooks for automatic time tracking",
+	Long: `Install post-checkout and post-commit hooks in the current repository.
+
+post-checkout  Switches the running timer to the project/task mapped in
+               .paymo-project.yaml when a branch is checked out, using the
+               branch name as the description.
+post-commit    Appends the commit subject to the running entry's description.
+
+Existing hooks not written by paymo are left alone unless --force is given.
+
+Examples:
+  paymo git install-hooks
+  paymo git install-hooks --project "Website" --task "Development"`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		root, err := gitOutput("rev-parse", "--show-toplevel")
+		if err != nil {
+			return fmt.Errorf("not a git repository: %w", err)
+		}
+		hooksDir, err := gitHooksDir()
+		if err != nil {
+			return err
+		}
+
+		projectFlag, _ := cmd.Flags().GetString("project")
+		taskFlag, _ := cmd.Flags().GetString("task")
+		force, _ := cmd.Flags().GetBool("force")
+
+		var ctx *config.ProjectContext
+		if projectFlag != "" || taskFlag != "" {
+			ctx, err = config.UpdateProjectContext(root, projectFlag, taskFlag)
+		} else {
+			ctx, err = config.LoadProjectContext(root)
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, name := range managedHooks {
+			if err := installHook(hooksDir, name, force); err != nil {
+				return err
+			}
+		}
+
+		formatter := newFormatter()
+		if ctx == nil || ctx.Project == "" {
+			fmt.Fprintf(os.Stderr, "Warning: no project configured in %s — hooks do nothing until one is added\n",
+				filepath.Join(root, config.ContextFile))
+		}
+		return formatter.FormatSuccess(fmt.Sprintf("Installed git hooks: %s", strings.Join(managedHooks, ", ")), 0)
+	},
+}
+
+// uninstallHooksCmd removes the paymo git hooks from the current repository
+var uninstallHooksCmd = &cobra.Command{
+	Use:   "uninstall-hooks",
+	Short: "Remove git hooks installed by paymo",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		hooksDir, err := gitHooksDir()
+		if err != nil {
+			return err
+		}
+
+		var removed []string
+		for _, name := range managedHooks {
+			ok, err := uninstallHook(hooksDir, name)
+			if err != nil {
+				return err
+			}
+			if ok {
+				removed = append(removed, name)
+			}
+		}
+
+		formatter := newFormatter()
+		if len(removed) == 0 {
+			return formatter.FormatSuccess("No paymo git hooks installed.", 0)
+		}
+		return formatter.FormatSuccess(fmt.Sprintf("Removed git hooks: %s", strings.Join(removed, ", ")), 0)
+	},
+}
+
+// gitHookCmd is invoked by the installed hook scripts
+var gitHookCmd = &cobra.Command{
+	Use:    "hook <name> [args...]",
+	Short:  "Run a git hook (called by the installed hook scripts)",
+	Hidden: true,
+	Args:   cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		root, err := gitOutput("rev-parse", "--show-toplevel")
+		if err != nil {
+			return fmt.Errorf("not a git repository: %w", err)
+		}
+		ctx, err := config.LoadProjectContext(root)
+		if err != nil {
+			return err
+		}
+		if ctx == nil || ctx.Project == "" {
+			return nil // repository not mapped to a project
+		}
+
+		switch args[0] {
+		case "post-checkout":
+			// Args: <previous HEAD> <new HEAD> <branch checkout flag>
+			if len(args) < 4 || args[3] != "1" {
+				return nil // file checkout, not a branch switch
+			}
+			branch, err := gitOutput("rev-parse", "--abbrev-ref", "HEAD")
+			if err != nil || branch == "HEAD" {
+				return nil // detached HEAD
+			}
+			client, err := getAPIClient()
+			if err != nil {
+				return err
+			}
+			return hookSwitchTimer(client, ctx, branch)
+		case "post-commit":
+			subject, err := gitOutput("log", "-1", "--format=%s")
+			if err != nil {
+				return err
+			}
+			client, err := getAPIClient()
+			if err != nil {
+				return err
+			}
+			return hookAppendCommit(client, ctx, subject)
+		default:
+			return fmt.Errorf("unknown hook: %s", args[0])
+		}
+	},
+}
+
+// hookSwitchTimer starts a timer on the task mapped to branch, stopping any
+// other running timer first. Nothing happens if the mapped task is already running.
+func hookSwitchTimer(client api.PaymoAPI, ctx *config.ProjectContext, branch string) error {
+	taskArg := ctx.TaskForBranch(branch)
+	if taskArg == "" {
+		return nil // no task mapped for this branch
+	}
+
+	project, err := resolveProject(client, ctx.Project)
+	if err != nil {
+		return err
+	}
+	task, err := resolveTask(client, taskArg, fmt.Sprintf("%d", project.ID))
+	if err != nil {
+		return err
+	}
+
+	state, err := config.LoadTimerState()
+	if err != nil {
+		return fmt.Errorf("loading timer state: %w", err)
+	}
+	if state.Active && state.TaskID == task.ID && state.Description == branch {
+		return nil
+	}
+	if state.Active {
+		if _, err := client.StopEntry(state.EntryID); err != nil {
+			return fmt.Errorf("stopping timer: %w", err)
+		}
+		if err := config.ClearTimerState(); err != nil {
+			return fmt.Errorf("clearing timer state: %w", err)
+		}
+	}
+
+	if _, err := startTimer(client, project.ID, project.Name, task.ID, task.Name, branch); err != nil {
+		return err
+	}
+
+	formatter := newFormatter()
+	if !formatter.Structured() && !formatter.Quiet {
+		fmt.Fprintf(formatter.Writer, "paymo: timer switched to %s / %s (%s)\n", project.Name, task.Name, branch)
+	}
+	return nil
+}
+
+// hookAppendCommit appends a commit subject to the running entry's description
+// when the timer belongs to the repository's project.
+func hookAppendCommit(client api.PaymoAPI, ctx *config.ProjectContext, subject string) error {
+	if subject == "" {
+		return nil
+	}
+	state, err := config.LoadTimerState()
+	if err != nil {
+		return fmt.Errorf("loading timer state: %w", err)
+	}
+	if !state.Active || state.EntryID == 0 {
+		return nil
+	}
+
+	project, err := resolveProject(client, ctx.Project)
+	if err != nil {
+		return err
+	}
+	if project.ID != state.ProjectID {
+		return nil // timer is running on another project
+	}
+
+	description := state.Description
+	if entry, err := client.GetEntry(state.EntryID); err == nil {
+		description = entry.Description
+	}
+	updated := appendCommitSubject(description, subject)
+	if updated == description {
+		return nil
+	}
+
+	if _, err := client.UpdateEntry(state.EntryID, &api.UpdateTimeEntryRequest{Description: &updated}); err != nil {
+		return fmt.Errorf("updating entry: %w", err)
+	}
+	state.Description = updated
+	if err := config.SaveTimerState(state); err != nil {
+		return fmt.Errorf("saving timer state: %w", err)
+	}
+	return nil
+}
+
+// appendCommitSubject adds subject as a new "- " line, skipping duplicates
+// (e.g. from `git commit --amend`).
+func appendCommitSubject(description, subject string) string {
+	line := "- " + subject
+	for _, l := range strings.Split(description, "\n") {
+		if l == line {
+			return description
+		}
+	}
+	if description == "" {
+		return line
+	}
+	return description + "\n" + line
+}
+
+// hookScript returns the shell script installed for a hook.
+func hookScript(name string) string {
+	return fmt.Sprintf(`#!/bin/sh
+%s
+command -v paymo >/dev/null 2>&1 || exit 0
+paymo git hook %s "$@" || true
+`, hookMarker, name)
+}
+
+// installHook writes a hook script, refusing to overwrite foreign hooks unless forced.
+func installHook(hooksDir, name string, force bool) error {
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		return fmt.Errorf("creating hooks dir: %w", err)
+	}
+	p := filepath.Join(hooksDir, name)
+	if existing, err := os.ReadFile(p); err == nil && !force && !strings.Contains(string(existing), hookMarker) {
+		return fmt.Errorf("hook %s already exists and was not installed by paymo (use --force to overwrite)", p)
+	}
+	if err := os.WriteFile(p, []byte(hookScript(name)), 0755); err != nil {
+		return fmt.Errorf("writing hook %s: %w", name, err)
+	}
+	return nil
+}
+
+// uninstallHook removes a hook if it was installed by paymo.
+func uninstallHook(hooksDir, name string) (bool, error) {
+	p := filepath.Join(hooksDir, name)
+	existing, err := os.ReadFile(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("reading hook %s: %w", name, err)
+	}
+	if !strings.Contains(string(existing), hookMarker) {
+		return false, nil
+	}
+	if err := os.Remove(p); err != nil {
+		return false, fmt.Errorf("removing hook %s: %w", name, err)
+	}
+	return true, nil
+}
+
+// gitHooksDir returns the absolute hooks directory of the current repository,
+// honoring core.hooksPath.
+func gitHooksDir() (string, error) {
+	dir, err := gitOutput("rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", fmt.Errorf("not a git repository: %w", err)
+	}
+	return filepath.Abs(dir)
+}
+
+// gitOutput runs git with args and returns its trimmed stdout.
+// Defined as a var to allow test injection.
+var gitOutput = func(args ...string) (string, error) {
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func init() {
+	rootCmd.AddCommand(gitCmd)
+	gitCmd.AddCommand(installHooksCmd)
+	gitCmd.AddCommand(uninstallHooksCmd)
+	gitCmd.AddCommand(gitHookCmd)
+
+	// Flags for install-hooks command
+	installHooksCmd.Flags().StringP("project", "p", "", "project name or ID to write to .paymo-project.yaml")
+	installHooksCmd.Flags().StringP("task", "t", "", "default task name or ID to write to .paymo-project.yaml")
+	installHooksCmd.Flags().Bool("force", false, "overwrite existing hooks not installed by paymo")
+}
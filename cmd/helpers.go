@@ -2,11 +2,18 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"strconv"
+	"strings"
 
+	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"golang.org/x/term"
 
 	"github.com/ComputClaw/paymo-cli/internal/api"
+	"github.com/ComputClaw/paymo-cli/internal/cache"
+	"github.com/ComputClaw/paymo-cli/internal/config"
+	"github.com/ComputClaw/paymo-cli/internal/locale"
 	"github.com/ComputClaw/paymo-cli/internal/output"
 )
 
@@ -14,38 +21,272 @@ import (
 func newFormatter() *output.Formatter {
 	f := output.NewFormatter(viper.GetString("format"))
 	f.Quiet = viper.GetBool("quiet")
+	f.Style, f.Width = tableLayout()
+	if l, err := loadLocale(); err == nil {
+		f.Locale = l
+	}
 	return f
 }
 
-// resolveProjectID resolves a project argument (ID or name) to a numeric ID
+// sessionConfig and sessionLocale are config.yaml and the locale loaded for
+// the running command, so each is read once per command. Outside a command
+// (inSession is false) nothing is kept. sessionFormatter is the formatter
+// built for sessionFormatterCmd while validating its flags, handed to the
+// command's first newResourceFormatter call.
+var (
+	inSession           bool
+	sessionConfig       *config.Config
+	sessionLocale       *locale.Locale
+	sessionFormatter    *output.Formatter
+	sessionFormatterCmd *cobra.Command
+)
+
+// startSession begins keeping the config for a command.
+func startSession() {
+	endSession()
+	inSession = true
+}
+
+// endSession drops the config and locale loaded for a command.
+func endSession() {
+	inSession = false
+	sessionConfig = nil
+	sessionLocale = nil
+	sessionFormatter, sessionFormatterCmd = nil, nil
+}
+
+// loadConfig returns config.yaml, read once per command.
+func loadConfig() (*config.Config, error) {
+	if sessionConfig != nil {
+		return sessionConfig, nil
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	if inSession {
+		sessionConfig = cfg
+	}
+	return cfg, nil
+}
+
+// loadLocale returns the timezone and date/time formats for output and date
+// flags, read once per command.
+func loadLocale() (*locale.Locale, error) {
+	if sessionLocale != nil {
+		return sessionLocale, nil
+	}
+	l, err := readLocale()
+	if err == nil && inSession {
+		sessionLocale = l
+	}
+	return l, err
+}
+
+// readLocale builds the locale: defaults.timezone from config.yaml, else
+// the Paymo user's timezone saved at login, else the system timezone.
+func readLocale() (*locale.Locale, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Defaults.Timezone == "" {
+		if creds, _ := config.LoadCredentials(); creds != nil && creds.UserTimezone != "" {
+			if l, err := locale.New(creds.UserTimezone, cfg.Output.DateFormat, cfg.Output.TimeFormat); err == nil {
+				return l, nil
+			}
+		}
+	}
+	l, err := locale.New(cfg.Defaults.Timezone, cfg.Output.DateFormat, cfg.Output.TimeFormat)
+	if err != nil {
+		return nil, fmt.Errorf("%w (check defaults.timezone in config.yaml)", err)
+	}
+	return l, nil
+}
+
+// stdoutTerminal reports whether stdout is a terminal, and its width.
+// Defined as a var to allow test injection.
+var stdoutTerminal = func() (bool, int) {
+	fd := int(os.Stdout.Fd())
+	if !term.IsTerminal(fd) {
+		return false, 0
+	}
+	width, _, err := term.GetSize(fd)
+	if err != nil {
+		return true, 0
+	}
+	return true, width
+}
+
+// tableLayout returns the table style and width: the configured style sized
+// to the terminal, or plain text when stdout is not a terminal.
+func tableLayout() (string, int) {
+	tty, width := stdoutTerminal()
+	if !tty {
+		return output.StylePlain, 0
+	}
+	style := ""
+	if cfg, err := loadConfig(); err == nil {
+		style = cfg.Output.TableStyle
+	}
+	return style, width
+}
+
+// resourceAnnotation records which output resource a command renders,
+// for --columns validation and the schema.
+const resourceAnnotation = "paymo.output.resource"
+
+// addOutputFlags adds --columns and --template to a list or show command.
+func addOutputFlags(cmd *cobra.Command, res *output.Resource) {
+	if cmd.Annotations == nil {
+		cmd.Annotations = map[string]string{}
+	}
+	cmd.Annotations[resourceAnnotation] = res.Name
+	cmd.Flags().String("columns", "", "comma-separated columns to show: "+strings.Join(res.FieldNames(), ", "))
+	cmd.Flags().String("template", "", `Go template rendered per item, e.g. '{{.ID}}\t{{.Name}}'`)
+}
+
+// addListFlags adds --filter, --sort and --group-by to a list command. Call
+// it after addOutputFlags.
+func addListFlags(cmd *cobra.Command) {
+	cmd.Flags().String("filter", "", `filter rows locally, e.g. 'duration > 2h and billable and description ~ "review"'`)
+	cmd.Flags().StringP("sort", "s", "", "sort by comma-separated columns, '-' for descending, e.g. duration,-date")
+	cmd.Flags().String("group-by", "", "group rows by a column (e.g. project, task, day) with subtotals")
+}
+
+// newResourceFormatter creates a formatter honoring --columns, --template,
+// --filter, --sort and --group-by.
+func newResourceFormatter(cmd *cobra.Command) (*output.Formatter, error) {
+	if sessionFormatter != nil && sessionFormatterCmd == cmd {
+		f := sessionFormatter
+		sessionFormatter, sessionFormatterCmd = nil, nil
+		return f, nil
+	}
+	f := newFormatter()
+	res := output.LookupResource(cmd.Annotations[resourceAnnotation])
+	if res == nil {
+		return f, nil
+	}
+
+	if columns, _ := cmd.Flags().GetString("columns"); columns != "" {
+		names, err := res.ParseColumns(columns)
+		if err != nil {
+			return nil, err
+		}
+		f.Columns = names
+	}
+	if text, _ := cmd.Flags().GetString("template"); text != "" {
+		tmpl, err := output.ParseTemplate(text)
+		if err != nil {
+			return nil, err
+		}
+		f.Template = tmpl
+	}
+	if expr, _ := cmd.Flags().GetString("filter"); expr != "" {
+		filter, err := res.ParseFilter(expr, f.Locale)
+		if err != nil {
+			return nil, err
+		}
+		f.Filter = filter
+	}
+	if spec, _ := cmd.Flags().GetString("sort"); spec != "" {
+		keys, err := res.ParseSort(spec)
+		if err != nil {
+			return nil, err
+		}
+		f.Sort = keys
+	}
+	if name, _ := cmd.Flags().GetString("group-by"); name != "" {
+		field, err := res.ParseGroupBy(name)
+		if err != nil {
+			return nil, err
+		}
+		f.GroupBy = field
+	}
+	return f, nil
+}
+
+// readInfoer is implemented by clients that report where their last read
+// came from (the cached client).
+type readInfoer interface {
+	LastRead() cache.ReadInfo
+}
+
+// withEnvelope wraps structured list output in an envelope when --envelope
+// is set. filters records the query that produced the list; zero values are
+// left out. Call it after the list has been fetched.
+func withEnvelope(f *output.Formatter, client api.PaymoAPI, filters map[string]interface{}) {
+	if !viper.GetBool("envelope") {
+		return
+	}
+	env := &output.Envelope{Filters: map[string]interface{}{}}
+	for k, v := range filters {
+		if v == 0 || v == "" {
+			continue
+		}
+		env.Filters[k] = v
+	}
+	if r, ok := client.(readInfoer); ok {
+		info := r.LastRead()
+		env.Cached, env.Stale = info.Cached, info.Stale
+		if info.Cached && !info.CachedAt.IsZero() {
+			env.CachedAt = &info.CachedAt
+		}
+	}
+	f.Envelope = env
+}
+
+// resolveProjectID resolves a project argument (ID, name or code) to a numeric ID
 func resolveProjectID(client api.PaymoAPI, arg string) (int, error) {
 	if id, err := strconv.Atoi(arg); err == nil {
 		return id, nil
 	}
-	project, err := client.GetProjectByName(arg)
+	ref, err := matchProject(client, arg)
 	if err != nil {
-		return 0, fmt.Errorf("project not found: %w", err)
+		return 0, err
 	}
-	return project.ID, nil
+	return ref.ID, nil
 }
 
-// resolveProject resolves a project argument (ID or name) to a full Project
+// resolveProject resolves a project argument (ID, name or code) to a full Project
 func resolveProject(client api.PaymoAPI, arg string) (*api.Project, error) {
-	if id, err := strconv.Atoi(arg); err == nil {
-		project, err := client.GetProject(id)
+	id, err := strconv.Atoi(arg)
+	if err != nil {
+		ref, err := matchProject(client, arg)
 		if err != nil {
-			return nil, fmt.Errorf("project not found: %w", err)
+			return nil, err
 		}
-		return project, nil
+		id = ref.ID
 	}
-	project, err := client.GetProjectByName(arg)
+	project, err := client.GetProject(id)
 	if err != nil {
 		return nil, fmt.Errorf("project not found: %w", err)
 	}
 	return project, nil
 }
 
-// resolveTask resolves a task argument (ID or name) to a full Task.
+// matchProject fuzzy-matches a project name or code against the cached name
+// index, falling back to the API's name search. An exact name is looked up
+// by name first unless the index is complete, to avoid listing every project.
+func matchProject(client api.PaymoAPI, arg string) (*cache.NameRef, error) {
+	if _, complete := indexedCandidates(client, "project", 0); !complete {
+		if project, err := client.GetProjectByName(arg); err == nil && strings.EqualFold(project.Name, strings.TrimSpace(arg)) {
+			return &cache.NameRef{ID: project.ID, Name: project.Name, Code: project.Code}, nil
+		}
+	}
+	candidates, _ := projectCandidates(client)
+	ref, err := matchName("project", arg, candidates)
+	if err != nil || ref != nil {
+		return ref, err
+	}
+	project, err := client.GetProjectByName(arg)
+	if err != nil {
+		return nil, notFoundError("project", arg, err, candidates)
+	}
+	return &cache.NameRef{ID: project.ID, Name: project.Name, Code: project.Code}, nil
+}
+
+// resolveTask resolves a task argument (ID, name or code) to a full Task.
 // Name-based lookup requires a project context.
 func resolveTask(client api.PaymoAPI, arg string, projectFlag string) (*api.Task, error) {
 	if id, err := strconv.Atoi(arg); err == nil {
@@ -63,9 +304,28 @@ func resolveTask(client api.PaymoAPI, arg string, projectFlag string) (*api.Task
 	if err != nil {
 		return nil, err
 	}
+
+	if _, complete := indexedCandidates(client, "task", projectID); !complete {
+		if task, err := client.GetTaskByName(projectID, arg); err == nil && strings.EqualFold(task.Name, strings.TrimSpace(arg)) {
+			return task, nil
+		}
+	}
+	candidates, _ := taskCandidates(client, projectID)
+	ref, err := matchName("task", arg, candidates)
+	if err != nil {
+		return nil, err
+	}
+	if ref != nil {
+		task, err := client.GetTask(ref.ID)
+		if err != nil {
+			return nil, fmt.Errorf("task not found: %w", err)
+		}
+		return task, nil
+	}
+
 	task, err := client.GetTaskByName(projectID, arg)
 	if err != nil {
-		return nil, fmt.Errorf("task not found: %w", err)
+		return nil, notFoundError("task", arg, err, candidates)
 	}
 	return task, nil
 }
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/ComputClaw/paymo-cli/internal/api"
+	"github.com/ComputClaw/paymo-cli/internal/cache"
+	"github.com/ComputClaw/paymo-cli/internal/locale"
+)
+
+// sessionCache is the cached client handed out to the running command, if
+// any, so the staleness banner can report what it served.
+var sessionCache *cache.CachedClient
+
+// offlineClient returns a client for --offline that serves reads from the
+// cache, expired or not, and never touches the network. It needs no
+// credentials.
+func offlineClient() (api.PaymoAPI, error) {
+	if viper.GetBool("no_cache") {
+		return nil, fmt.Errorf("--offline reads from the cache and can't be combined with --no-cache")
+	}
+	path, err := cacheFilePath()
+	if err != nil {
+		return nil, fmt.Errorf("getting config dir: %w", err)
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: nothing is cached yet — run 'paymo sync' while online", cache.ErrOffline)
+	}
+	store, err := openCache(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening cache: %w", err)
+	}
+	client := cache.NewOfflineClient(store)
+	sessionCache = client
+	return client, nil
+}
+
+// printStaleBanner warns on stderr when the command showed expired cache
+// data, so it isn't mistaken for current data.
+func printStaleBanner() {
+	if sessionCache == nil || viper.GetBool("quiet") {
+		return
+	}
+	reason := "Paymo unreachable"
+	if viper.GetBool("offline") {
+		reason = "Offline"
+	}
+	oldest, ok := sessionCache.OldestStale()
+	if !ok {
+		// Data in the grace window, shown while it is refreshed
+		reason = "Refreshing"
+		if oldest, ok = sessionCache.OldestRefreshing(); !ok {
+			return
+		}
+	}
+	loc, err := loadLocale()
+	if err != nil {
+		loc = locale.Default()
+	}
+	fmt.Fprintln(os.Stderr, staleBanner(oldest, reason, loc, time.Now()))
+}
+
+// staleBanner describes cached data fetched at cachedAt that was shown for
+// the given reason: paymo is offline, Paymo couldn't be reached, or the
+// data is being refreshed.
+func staleBanner(cachedAt time.Time, reason string, loc *locale.Locale, now time.Time) string {
+	return fmt.Sprintf("⚠ %s: showing cached data from %s (%s old)", reason, loc.DateTime(cachedAt), formatAge(now.Sub(cachedAt)))
+}
+
+// formatAge renders a duration in its largest whole unit: 45s, 12m, 3h, 2d.
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
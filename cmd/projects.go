@@ -7,6 +7,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/ComputClaw/paymo-cli/internal/api"
+	"github.com/ComputClaw/paymo-cli/internal/output"
 )
 
 // projectsCmd represents the projects command
@@ -52,7 +53,14 @@ Examples:
 			return fmt.Errorf("fetching projects: %w", err)
 		}
 
-		formatter := newFormatter()
+		formatter, err := newResourceFormatter(cmd)
+		if err != nil {
+			return err
+		}
+		withEnvelope(formatter, client, map[string]interface{}{
+			"active_only": opts.ActiveOnly,
+			"client_id":   opts.ClientID,
+		})
 		return formatter.FormatProjects(projects)
 	},
 }
@@ -77,6 +85,7 @@ Examples:
 		description, _ := cmd.Flags().GetString("description")
 		billable, _ := cmd.Flags().GetBool("billable")
 		clientID, _ := cmd.Flags().GetInt("client")
+		billable = contextBillable(cmd, billable)
 
 		req := &api.CreateProjectRequest{
 			Name:        name,
@@ -119,7 +128,10 @@ Examples:
 			return err
 		}
 
-		formatter := newFormatter()
+		formatter, err := newResourceFormatter(cmd)
+		if err != nil {
+			return err
+		}
 		return formatter.FormatProject(project)
 	},
 }
@@ -155,7 +167,14 @@ Examples:
 			return fmt.Errorf("fetching tasks: %w", err)
 		}
 
-		formatter := newFormatter()
+		formatter, err := newResourceFormatter(cmd)
+		if err != nil {
+			return err
+		}
+		withEnvelope(formatter, client, map[string]interface{}{
+			"project_id":        projectID,
+			"include_completed": includeCompleted,
+		})
 		return formatter.FormatTasks(tasks)
 	},
 }
@@ -193,6 +212,13 @@ func init() {
 	projectsCmd.AddCommand(tasksProjectCmd)
 	projectsCmd.AddCommand(archiveProjectCmd)
 
+	// Output flags for list and show commands
+	addOutputFlags(listProjectsCmd, output.ProjectResource)
+	addOutputFlags(showProjectCmd, output.ProjectResource)
+	addOutputFlags(tasksProjectCmd, output.TaskResource)
+	addListFlags(listProjectsCmd)
+	addListFlags(tasksProjectCmd)
+
 	// Flags for list command
 	listProjectsCmd.Flags().BoolP("active", "a", true, "show only active projects")
 	listProjectsCmd.Flags().Bool("all", false, "show all projects including inactive")
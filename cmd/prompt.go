@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/ComputClaw/paymo-cli/internal/config"
+)
+
+// defaultPromptTemplate is used when neither --template nor the
+// prompt.template config key is set.
+const defaultPromptTemplate = "{project}/{task} {elapsed}"
+
+// promptCmd prints the running timer for shell prompts.
+var promptCmd = &cobra.Command{
+	Use:   "prompt",
+	Short: "Print the running timer for shell prompts",
+	Long: `Print a short status line for the running timer, for use in shell prompts.
+
+Only the local timer state (timer.json) is read — no API client is created
+and no network calls are made, so it is safe to run on every prompt.
+Nothing is printed when no timer is running (unless --idle is set).
+
+Template placeholders:
+  {project}      Project name
+  {task}         Task name
+  {description}  Entry description
+  {elapsed}      Compact elapsed time (e.g. 1h05m)
+  {clock}        Elapsed time as H:MM:SS
+  {start}        Start time (HH:MM)
+  {entry_id}     Time entry ID
+
+The template can also be set with the prompt.template config key.
+
+Examples:
+  paymo prompt
+  paymo prompt --template '{task} ({elapsed})'
+  paymo prompt init zsh >> ~/.zshrc`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tmpl, _ := cmd.Flags().GetString("template")
+		if tmpl == "" {
+			tmpl = viper.GetString("prompt.template")
+		}
+		if tmpl == "" {
+			tmpl = defaultPromptTemplate
+		}
+		idle, _ := cmd.Flags().GetString("idle")
+
+		state, err := config.LoadTimerState()
+		if err != nil {
+			return fmt.Errorf("loading timer state: %w", err)
+		}
+
+		line := idle
+		if state.Active {
+			l, err := loadLocale()
+			if err != nil {
+				return err
+			}
+			line = renderPrompt(tmpl, state, l.Now())
+		}
+		if line != "" {
+			fmt.Fprintln(cmd.OutOrStdout(), line)
+		}
+		return nil
+	},
+}
+
+// promptInitCmd prints shell integration snippets.
+var promptInitCmd = &cobra.Command{
+	Use:   "init <bash|zsh|fish|starship>",
+	Short: "Print a prompt integration snippet for a shell",
+	Long: `Print a snippet that shows the running timer in your prompt.
+
+Bash:
+  $ paymo prompt init bash >> ~/.bashrc
+
+Zsh:
+  $ paymo prompt init zsh >> ~/.zshrc
+
+Fish:
+  $ paymo prompt init fish > ~/.config/fish/functions/fish_right_prompt.fish
+
+Starship:
+  $ paymo prompt init starship >> ~/.config/starship.toml`,
+	ValidArgs: []string{"bash", "zsh", "fish", "starship"},
+	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		snippet, ok := promptSnippets[args[0]]
+		if !ok {
+			return fmt.Errorf("unsupported shell: %s", args[0])
+		}
+		fmt.Fprint(cmd.OutOrStdout(), snippet)
+		return nil
+	},
+}
+
+// promptSnippets holds the generated integration code per shell.
+var promptSnippets = map[string]string{
+	"bash": `# paymo timer in the prompt
+__paymo_prompt() {
+  PAYMO_STATUS="$(paymo prompt 2>/dev/null)"
+}
+PROMPT_COMMAND="__paymo_prompt${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
+PS1='${PAYMO_STATUS:+[$PAYMO_STATUS] }'"$PS1"
+`,
+	"zsh": `# paymo timer in the prompt
+setopt PROMPT_SUBST
+RPROMPT='$(paymo prompt 2>/dev/null)'"${RPROMPT:+ $RPROMPT}"
+`,
+	"fish": `# paymo timer in the prompt
+function fish_right_prompt
+    paymo prompt 2>/dev/null
+end
+`,
+	"starship": `# paymo timer in the prompt
+[custom.paymo]
+command = "paymo prompt"
+when = true
+format = "[⏱ $output]($style) "
+style = "bold yellow"
+`,
+}
+
+// renderPrompt expands the template placeholders for an active timer.
+// {start} is shown in the timezone of now.
+func renderPrompt(tmpl string, state *config.TimerState, now time.Time) string {
+	elapsed := time.Duration(0)
+	if !state.StartTime.IsZero() {
+		elapsed = now.Sub(state.StartTime)
+	}
+	start := ""
+	if !state.StartTime.IsZero() {
+		start = state.StartTime.In(now.Location()).Format("15:04")
+	}
+	r := strings.NewReplacer(
+		"{project}", state.ProjectName,
+		"{task}", state.TaskName,
+		"{description}", state.Description,
+		"{elapsed}", formatCompactElapsed(elapsed),
+		"{clock}", formatClock(int(elapsed.Seconds())),
+		"{start}", start,
+		"{entry_id}", strconv.Itoa(state.EntryID),
+	)
+	return strings.TrimSpace(r.Replace(tmpl))
+}
+
+// formatCompactElapsed formats a duration as e.g. "45m" or "1h05m".
+func formatCompactElapsed(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
+	if hours > 0 {
+		return fmt.Sprintf("%dh%02dm", hours, minutes)
+	}
+	return fmt.Sprintf("%dm", minutes)
+}
+
+func init() {
+	rootCmd.AddCommand(promptCmd)
+	promptCmd.AddCommand(promptInitCmd)
+
+	promptCmd.Flags().StringP("template", "t", "", "output template (default \""+defaultPromptTemplate+"\")")
+	promptCmd.Flags().String("idle", "", "text to print when no timer is running")
+}
@@ -0,0 +1,342 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/ComputClaw/paymo-cli/internal/api"
+	"github.com/ComputClaw/paymo-cli/internal/cache"
+)
+
+// nameIndexer is implemented by clients that keep a local name index
+// (the cached client). complete is false while the index only holds the
+// names fetched one by one.
+type nameIndexer interface {
+	IndexedNames(resourceType string, projectID int) (refs []cache.NameRef, complete bool)
+}
+
+// Picker I/O, defined as vars to allow test injection.
+var (
+	isInteractive           = func() bool { return term.IsTerminal(int(os.Stdin.Fd())) }
+	pickerIn      io.Reader = os.Stdin
+	pickerOut     io.Writer = os.Stderr
+)
+
+// Match scores, highest wins. A query is ambiguous when several candidates
+// share the best score.
+const (
+	scoreExactName  = 100
+	scoreExactCode  = 95
+	scoreNamePrefix = 80
+	scoreCodePrefix = 75
+	scoreWordPrefix = 70
+	scoreSubstring  = 60
+	scoreInitials   = 40
+	scoreTypo       = 30
+)
+
+// rankedRef is a candidate with its match score.
+type rankedRef struct {
+	ref   cache.NameRef
+	score int
+}
+
+// indexedCandidates returns the cached name index when it holds every name.
+func indexedCandidates(client api.PaymoAPI, resourceType string, projectID int) ([]cache.NameRef, bool) {
+	if idx, ok := client.(nameIndexer); ok {
+		if refs, complete := idx.IndexedNames(resourceType, projectID); complete {
+			return refs, true
+		}
+	}
+	return nil, false
+}
+
+// projectCandidates returns the projects to match names against: the cached
+// name index when it is complete, otherwise the (cached) project list.
+func projectCandidates(client api.PaymoAPI) ([]cache.NameRef, error) {
+	if refs, ok := indexedCandidates(client, "project", 0); ok {
+		return refs, nil
+	}
+	projects, err := client.GetProjects(nil)
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]cache.NameRef, len(projects))
+	for i, p := range projects {
+		refs[i] = cache.NameRef{ID: p.ID, Name: p.Name, Code: p.Code}
+	}
+	return refs, nil
+}
+
+// taskCandidates returns the tasks of a project to match names against.
+func taskCandidates(client api.PaymoAPI, projectID int) ([]cache.NameRef, error) {
+	if refs, ok := indexedCandidates(client, "task", projectID); ok {
+		return refs, nil
+	}
+	tasks, err := client.GetTasks(&api.TaskListOptions{ProjectID: projectID})
+	if err != nil {
+		return nil, err
+	}
+	var refs []cache.NameRef
+	for _, t := range tasks {
+		if t.ProjectID != projectID {
+			continue
+		}
+		refs = append(refs, cache.NameRef{ID: t.ID, Name: t.Name, Code: t.Code, ProjectID: t.ProjectID})
+	}
+	return refs, nil
+}
+
+// matchName picks the candidate for query. It returns (nil, nil) when nothing
+// matches, or only by a typo — a close spelling is never picked on its own,
+// it is offered as a suggestion — and prompts (or errors) when the best
+// matches are tied.
+func matchName(kind, query string, candidates []cache.NameRef) (*cache.NameRef, error) {
+	ranked := rankCandidates(query, candidates)
+	if len(ranked) == 0 || ranked[0].score <= scoreTypo {
+		return nil, nil
+	}
+
+	best := ranked[0].score
+	var tied []cache.NameRef
+	for _, r := range ranked {
+		if r.score == best {
+			tied = append(tied, r.ref)
+		}
+	}
+	if len(tied) == 1 {
+		return &tied[0], nil
+	}
+
+	if isInteractive() {
+		return pickName(fmt.Sprintf("Multiple %ss match %q:", kind, query), tied)
+	}
+	names := make([]string, len(tied))
+	for i, ref := range tied {
+		names[i] = describeRef(ref)
+	}
+	return nil, fmt.Errorf("%s %q is ambiguous, matches: %s\nUse the numeric ID or a more specific name", kind, query, strings.Join(names, ", "))
+}
+
+// rankCandidates scores every candidate against query and returns the
+// matches, best first.
+func rankCandidates(query string, candidates []cache.NameRef) []rankedRef {
+	var ranked []rankedRef
+	for _, ref := range candidates {
+		if score := matchScore(query, ref); score > 0 {
+			ranked = append(ranked, rankedRef{ref: ref, score: score})
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+		return ranked[i].ref.ID < ranked[j].ref.ID
+	})
+	return ranked
+}
+
+// matchScore rates how well query matches a candidate's name or code.
+// Zero means no match.
+func matchScore(query string, ref cache.NameRef) int {
+	q := strings.ToLower(strings.TrimSpace(query))
+	name := strings.ToLower(ref.Name)
+	code := strings.ToLower(ref.Code)
+	if q == "" {
+		return 0
+	}
+
+	switch {
+	case name == q:
+		return scoreExactName
+	case code != "" && code == q:
+		return scoreExactCode
+	case strings.HasPrefix(name, q):
+		return scoreNamePrefix
+	case code != "" && strings.HasPrefix(code, q):
+		return scoreCodePrefix
+	}
+	for _, word := range strings.Fields(name) {
+		if strings.HasPrefix(word, q) {
+			return scoreWordPrefix
+		}
+	}
+	if strings.Contains(name, q) {
+		return scoreSubstring
+	}
+	if len([]rune(q)) >= 2 && q == initials(name) {
+		return scoreInitials
+	}
+
+	// Tolerate small typos against the whole name or any of its words
+	maxTypos := len([]rune(q))/4 + 1
+	if maxTypos > 3 {
+		maxTypos = 3
+	}
+	best := levenshtein(q, name)
+	for _, word := range strings.Fields(name) {
+		if d := levenshtein(q, word); d < best {
+			best = d
+		}
+	}
+	if best <= maxTypos {
+		return scoreTypo - best
+	}
+	return 0
+}
+
+// suggestNames returns up to three candidates closest to query, for
+// "did you mean" hints.
+func suggestNames(query string, candidates []cache.NameRef) []string {
+	q := strings.ToLower(query)
+	type scored struct {
+		ref  cache.NameRef
+		dist float64
+	}
+	var all []scored
+	for _, ref := range candidates {
+		name := strings.ToLower(ref.Name)
+		longest := len([]rune(name))
+		if n := len([]rune(q)); n > longest {
+			longest = n
+		}
+		if longest == 0 {
+			continue
+		}
+		d := float64(levenshtein(q, name)) / float64(longest)
+		for _, word := range strings.Fields(name) {
+			wl := len([]rune(word))
+			if n := len([]rune(q)); n > wl {
+				wl = n
+			}
+			if wd := float64(levenshtein(q, word)) / float64(wl); wd < d {
+				d = wd
+			}
+		}
+		if d <= 0.6 {
+			all = append(all, scored{ref: ref, dist: d})
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].dist < all[j].dist })
+
+	var out []string
+	for i := 0; i < len(all) && i < 3; i++ {
+		out = append(out, describeRef(all[i].ref))
+	}
+	return out
+}
+
+// notFoundError wraps err with "did you mean" suggestions.
+func notFoundError(kind, query string, err error, candidates []cache.NameRef) error {
+	if suggestions := suggestNames(query, candidates); len(suggestions) > 0 {
+		return fmt.Errorf("%s not found: %w\nDid you mean: %s?", kind, err, strings.Join(suggestions, ", "))
+	}
+	return fmt.Errorf("%s not found: %w", kind, err)
+}
+
+// describeRef formats a candidate as `"Name" (#ID, CODE)`.
+func describeRef(ref cache.NameRef) string {
+	if ref.Code != "" {
+		return fmt.Sprintf("%q (#%d, %s)", ref.Name, ref.ID, ref.Code)
+	}
+	return fmt.Sprintf("%q (#%d)", ref.Name, ref.ID)
+}
+
+// pickName shows a numbered list on pickerOut and reads the choice from pickerIn.
+func pickName(title string, refs []cache.NameRef) (*cache.NameRef, error) {
+	if len(refs) == 0 {
+		return nil, fmt.Errorf("nothing to choose from")
+	}
+	fmt.Fprintln(pickerOut, title)
+	for i, ref := range refs {
+		fmt.Fprintf(pickerOut, "  %d) %s\n", i+1, describeRef(ref))
+	}
+
+	for {
+		fmt.Fprintf(pickerOut, "Select [1-%d]: ", len(refs))
+		line, err := readLine(pickerIn)
+		choice, convErr := strconv.Atoi(strings.TrimSpace(line))
+		if convErr == nil && choice >= 1 && choice <= len(refs) {
+			return &refs[choice-1], nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("no selection made")
+		}
+		fmt.Fprintln(pickerOut, "Invalid choice.")
+	}
+}
+
+// readLine reads up to and including the next newline. It reads a byte at a
+// time so that consecutive prompts sharing pickerIn don't lose buffered input.
+func readLine(r io.Reader) (string, error) {
+	var b strings.Builder
+	buf := make([]byte, 1)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			b.WriteByte(buf[0])
+			if buf[0] == '\n' {
+				return b.String(), nil
+			}
+		}
+		if err != nil {
+			return b.String(), err
+		}
+	}
+}
+
+// pickProject prompts for a project from all candidates.
+func pickProject(client api.PaymoAPI) (*cache.NameRef, error) {
+	candidates, err := projectCandidates(client)
+	if err != nil {
+		return nil, fmt.Errorf("fetching projects: %w", err)
+	}
+	return pickName("Select a project:", candidates)
+}
+
+// pickTask prompts for a task of the given project.
+func pickTask(client api.PaymoAPI, projectID int) (*cache.NameRef, error) {
+	candidates, err := taskCandidates(client, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching tasks: %w", err)
+	}
+	return pickName("Select a task:", candidates)
+}
+
+// initials returns the first letter of every word in s.
+func initials(s string) string {
+	var b strings.Builder
+	for _, word := range strings.Fields(s) {
+		r := []rune(word)
+		b.WriteRune(r[0])
+	}
+	return b.String()
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -7,6 +7,8 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+
+	"github.com/ComputClaw/paymo-cli/internal/output"
 )
 
 var (
@@ -41,6 +43,36 @@ var rootCmd = &cobra.Command{
 
 Check for updates: https://github.com/mbundgaard/paymo-cli/releases`,
 	Version: version,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		sessionCache = nil
+		startSession()
+		// Reject bad output options before any work is done
+		if _, err := output.LookupRenderer(viper.GetString("format")); err != nil {
+			return err
+		}
+		if _, err := loadLocale(); err != nil {
+			return err
+		}
+		if _, err := cacheTTLs(); err != nil {
+			return err
+		}
+		if _, err := cacheGrace(); err != nil {
+			return err
+		}
+		// Validate --columns, --template, --filter, --sort and --group-by
+		// now, and keep the formatter for the command
+		f, err := newResourceFormatter(cmd)
+		if err != nil {
+			return err
+		}
+		sessionFormatter, sessionFormatterCmd = f, cmd
+		return nil
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		printStaleBanner()
+		waitForRefresh()
+		saveCacheStats()
+	},
 }
 
 // helpCmd provides help for commands (standard CLI convention)
@@ -84,19 +116,24 @@ func GetOutputFormat() string {
 
 func init() {
 	cobra.OnInitialize(initConfig)
+	cobra.OnFinalize(endSession, closeCache)
 
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is ~/.config/paymo-cli/config.yaml)")
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "verbose output")
-	rootCmd.PersistentFlags().StringP("format", "f", "table", "output format: table, json, csv")
+	rootCmd.PersistentFlags().StringP("format", "f", "table", "output format: "+strings.Join(output.Formats(), ", "))
 	rootCmd.PersistentFlags().BoolP("quiet", "q", false, "minimal output (IDs only for create/mutate commands)")
 	rootCmd.PersistentFlags().Bool("no-cache", false, "bypass cache, force fresh API calls")
+	rootCmd.PersistentFlags().Bool("offline", false, "never touch the network: read from the cache, even if expired, and refuse writes (env PAYMO_OFFLINE)")
+	rootCmd.PersistentFlags().Bool("envelope", false, "wrap structured list output with count, filters and cache metadata")
 
 	// Bind flags to viper
 	viper.BindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose"))
 	viper.BindPFlag("format", rootCmd.PersistentFlags().Lookup("format"))
 	viper.BindPFlag("quiet", rootCmd.PersistentFlags().Lookup("quiet"))
 	viper.BindPFlag("no_cache", rootCmd.PersistentFlags().Lookup("no-cache"))
+	viper.BindPFlag("offline", rootCmd.PersistentFlags().Lookup("offline"))
+	viper.BindPFlag("envelope", rootCmd.PersistentFlags().Lookup("envelope"))
 
 	// Let main.go handle error output (needed for JSON structured errors)
 	rootCmd.SilenceErrors = true
@@ -130,6 +167,7 @@ func initConfig() {
 	// Environment variables
 	viper.SetEnvPrefix("PAYMO")
 	viper.AutomaticEnv() // read in environment variables that match
+	viper.BindEnv("api.base_url", "PAYMO_BASE_URL")
 
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err == nil && viper.GetBool("verbose") {
@@ -6,6 +6,8 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+
+	"github.com/ComputClaw/paymo-cli/internal/output"
 )
 
 // SchemaCommand describes a single CLI command for machine discovery
@@ -15,9 +17,25 @@ type SchemaCommand struct {
 	Usage       string          `json:"usage"`
 	Aliases     []string        `json:"aliases,omitempty"`
 	Flags       []SchemaFlag    `json:"flags,omitempty"`
+	Output      *SchemaOutput   `json:"output,omitempty"`
 	Subcommands []SchemaCommand `json:"subcommands,omitempty"`
 }
 
+// SchemaOutput describes the resource a list or show command renders,
+// i.e. the values accepted by --columns and the fields seen by --template
+type SchemaOutput struct {
+	Resource       string         `json:"resource"`
+	Columns        []SchemaColumn `json:"columns"`
+	DefaultColumns []string       `json:"default_columns"`
+}
+
+// SchemaColumn describes a single selectable column
+type SchemaColumn struct {
+	Name    string   `json:"name"`
+	Header  string   `json:"header"`
+	Aliases []string `json:"aliases,omitempty"`
+}
+
 // SchemaFlag describes a single flag
 type SchemaFlag struct {
 	Name      string `json:"name"`
@@ -58,6 +76,7 @@ func buildSchema(cmd *cobra.Command) map[string]interface{} {
 		"description":  cmd.Short,
 		"commands":     commands,
 		"global_flags": collectFlags(cmd),
+		"formats":      output.Formats(),
 	}
 }
 
@@ -68,6 +87,7 @@ func buildCommandSchema(cmd *cobra.Command) SchemaCommand {
 		Usage:       cmd.UseLine(),
 		Aliases:     cmd.Aliases,
 		Flags:       collectFlags(cmd),
+		Output:      buildOutputSchema(cmd),
 	}
 	for _, child := range cmd.Commands() {
 		if child.Hidden || child.Name() == "help" {
@@ -78,6 +98,18 @@ func buildCommandSchema(cmd *cobra.Command) SchemaCommand {
 	return sc
 }
 
+func buildOutputSchema(cmd *cobra.Command) *SchemaOutput {
+	res := output.LookupResource(cmd.Annotations[resourceAnnotation])
+	if res == nil {
+		return nil
+	}
+	so := &SchemaOutput{Resource: res.Name, DefaultColumns: res.TableColumns}
+	for _, fd := range res.Fields {
+		so.Columns = append(so.Columns, SchemaColumn{Name: fd.Name, Header: fd.Header, Aliases: fd.Aliases})
+	}
+	return so
+}
+
 func collectFlags(cmd *cobra.Command) []SchemaFlag {
 	var flags []SchemaFlag
 	cmd.NonInheritedFlags().VisitAll(func(f *pflag.Flag) {
@@ -3,31 +3,52 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/ComputClaw/paymo-cli/internal/api"
 	"github.com/ComputClaw/paymo-cli/internal/cache"
 	"github.com/ComputClaw/paymo-cli/internal/config"
+	"github.com/ComputClaw/paymo-cli/internal/locale"
 	"github.com/ComputClaw/paymo-cli/internal/output"
 )
 
-var validSyncTargets = []string{"all", "me", "clients", "projects", "tasks"}
+var validSyncTargets = []string{"all", "me", "clients", "projects", "tasks", "tasklists", "entries"}
+
+// defaultEntrySyncDays is how many days of time entries `sync entries`
+// fetches when --from is not given.
+const defaultEntrySyncDays = 30
 
 // cacheTypesForTarget maps a sync target to the cache resource types that
 // must be invalidated before fetching fresh data.
 var cacheTypesForTarget = map[string][]string{
-	"me":       {"me"},
-	"clients":  {"clients"},
-	"projects": {"projects", "project", "project_by_name"},
-	"tasks":    {"tasks", "task", "task_by_name", "tasklists"},
+	"me":        {"me"},
+	"clients":   {"clients"},
+	"projects":  {"projects", "project", "project_by_name"},
+	"tasks":     {"tasks", "task", "task_by_name", "tasklists"},
+	"tasklists": {"tasklists"},
+	"entries":   {"entries", "entry"},
 }
 
 // coreTargets are synced by default (no args) and after login.
 var coreTargets = []string{"me", "clients", "projects"}
 
+// syncer is implemented by clients that keep synced records (the cached
+// client).
+type syncer interface {
+	Sync(resource string, full bool) (cache.SyncResult, error)
+	SyncEntries(userID int, from, to time.Time, full bool) (cache.SyncResult, error)
+}
+
+// syncOptions are the settings of a sync run.
+type syncOptions struct {
+	full     bool
+	userID   int       // whose time entries to sync
+	from, to time.Time // time entries starting in [from, to)
+}
+
 var syncCmd = &cobra.Command{
 	Use:   "sync [targets...]",
 	Short: "Sync Paymo data into the local cache",
@@ -36,19 +57,34 @@ var syncCmd = &cobra.Command{
 With no arguments, syncs core data (me, clients, projects).
 Specify targets to sync specific resources.
 
-Valid targets: all, me, clients, projects, tasks
+Valid targets: all, me, clients, projects, tasks, tasklists, entries
+
+Syncs are incremental: after the first sync of a resource, only records
+updated since the last one are fetched and merged into the cache. Records
+deleted in Paymo are only noticed by a full sync, which runs at least
+once a day; --full refetches everything and rebuilds the synced set now.
+
+entries syncs your time entries from --from to --to (default: the last
+30 days). Entry lists for any date range inside a synced range, such as
+'time log --date last-week', are then answered from the cache, including
+when Paymo can't be reached.
 
 Examples:
   paymo sync                    # Sync core data
   paymo sync all                # Sync everything
   paymo sync projects clients   # Sync specific resources
-  paymo sync tasks              # Sync only tasks`,
+  paymo sync tasks --full       # Refetch all tasks
+  paymo sync entries --from 2026-01-01 --to 2026-03-31`,
 	Args: cobra.ArbitraryArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		targets, err := parseSyncTargets(args)
 		if err != nil {
 			return err
 		}
+		opts, err := parseSyncOptions(cmd)
+		if err != nil {
+			return err
+		}
 
 		client, err := getAPIClient()
 		if err != nil {
@@ -56,16 +92,18 @@ Examples:
 		}
 
 		formatter := newFormatter()
-
-		// Invalidate cache for the requested targets before fetching
-		invalidateCacheForSync(targets...)
-
+		var results []cache.SyncResult
 		for _, target := range targets {
-			if err := syncResource(client, target, formatter); err != nil {
+			result, err := syncResource(client, target, opts, formatter)
+			if err != nil {
 				return err
 			}
+			results = append(results, result)
 		}
 
+		if formatter.Structured() {
+			return formatter.FormatTimerStatus(results)
+		}
 		return nil
 	},
 }
@@ -85,13 +123,48 @@ func parseSyncTargets(args []string) ([]string, error) {
 	// Expand "all"
 	for _, arg := range args {
 		if arg == "all" {
-			return []string{"me", "clients", "projects", "tasks"}, nil
+			return []string{"me", "clients", "projects", "tasks", "tasklists", "entries"}, nil
 		}
 	}
 
 	return args, nil
 }
 
+// parseSyncOptions reads --full and the --from/--to entry range. --to is
+// inclusive.
+func parseSyncOptions(cmd *cobra.Command) (syncOptions, error) {
+	var opts syncOptions
+	opts.full, _ = cmd.Flags().GetBool("full")
+	fromFlag, _ := cmd.Flags().GetString("from")
+	toFlag, _ := cmd.Flags().GetString("to")
+
+	loc, err := loadLocale()
+	if err != nil {
+		return opts, err
+	}
+	last := loc.Today()
+	if toFlag != "" {
+		if last, err = loc.ParseDate(toFlag); err != nil {
+			return opts, err
+		}
+	}
+	opts.from = last.AddDate(0, 0, 1-defaultEntrySyncDays)
+	if fromFlag != "" {
+		if opts.from, err = loc.ParseDate(fromFlag); err != nil {
+			return opts, err
+		}
+	}
+	if opts.from.After(last) {
+		return opts, fmt.Errorf("--from %s is after --to %s", loc.Date(opts.from), loc.Date(last))
+	}
+	opts.to = last.AddDate(0, 0, 1)
+
+	if creds, _ := config.LoadCredentials(); creds != nil {
+		opts.userID = creds.UserID
+	}
+	return opts, nil
+}
+
 func isValidTarget(target string) bool {
 	for _, v := range validSyncTargets {
 		if v == target {
@@ -104,17 +177,16 @@ func isValidTarget(target string) bool {
 // invalidateCacheForSync opens the cache store and invalidates the resource
 // types associated with the given sync targets.
 func invalidateCacheForSync(targets ...string) {
-	cacheDir, err := config.GetConfigDir()
+	cachePath, err := cacheFilePath()
 	if err != nil {
 		return
 	}
-	cachePath := filepath.Join(cacheDir, "cache.json")
 
 	if _, err := os.Stat(cachePath); os.IsNotExist(err) {
 		return
 	}
 
-	store, err := cache.Open(cachePath)
+	store, err := openCache(cachePath)
 	if err != nil {
 		return
 	}
@@ -129,30 +201,77 @@ func invalidateCacheForSync(targets ...string) {
 	}
 }
 
-// syncResource fetches a single resource type from the API and prints progress.
-func syncResource(client api.PaymoAPI, target string, formatter *output.Formatter) error {
-	if formatter.Format != "json" && !formatter.Quiet {
+// syncResource syncs a single resource type and prints progress. Clients
+// that keep synced records merge the changes since the last sync (or all
+// records with full); others refetch the resource into the cache.
+func syncResource(client api.PaymoAPI, target string, opts syncOptions, formatter *output.Formatter) (cache.SyncResult, error) {
+	text := !formatter.Structured() && !formatter.Quiet
+	if text {
 		fmt.Fprintf(formatter.Writer, "Syncing %s... ", target)
 	}
 
-	count, err := fetchResource(client, target)
+	var result cache.SyncResult
+	var err error
+	s, ok := client.(syncer)
+	switch {
+	case ok && target == "entries":
+		result, err = s.SyncEntries(opts.userID, opts.from, opts.to, opts.full)
+	case ok && target != "me":
+		result, err = s.Sync(target, opts.full)
+	default:
+		// Invalidate the cache for the target before fetching
+		invalidateCacheForSync(target)
+		var count int
+		count, err = fetchResource(client, target, opts)
+		result = cache.SyncResult{Resource: target, Full: true, Fetched: count, MergeStats: cache.MergeStats{Total: count}}
+		if target == "entries" {
+			result.Range = &cache.Coverage{UserID: opts.userID, From: opts.from, To: opts.to}
+		}
+	}
 	if err != nil {
-		if formatter.Format != "json" && !formatter.Quiet {
+		if text {
 			fmt.Fprintln(formatter.Writer, "failed")
 		}
-		return fmt.Errorf("syncing %s: %w", target, err)
+		return result, fmt.Errorf("syncing %s: %w", target, err)
 	}
 
-	if formatter.Format != "json" && !formatter.Quiet {
-		fmt.Fprintf(formatter.Writer, "done (%d items)\n", count)
+	if text {
+		fmt.Fprintf(formatter.Writer, "done (%s)\n", describeSync(result, formatter))
 	}
+	return result, nil
+}
 
-	return nil
+// describeSync summarizes a sync result for the progress line.
+func describeSync(r cache.SyncResult, formatter *output.Formatter) string {
+	loc := formatter.Locale
+	if loc == nil {
+		loc = locale.Default()
+	}
+	var span string
+	if r.Range != nil {
+		span = fmt.Sprintf("%s to %s, ", loc.Date(r.Range.From), loc.Date(r.Range.To.AddDate(0, 0, -1)))
+	}
+	return span + describeChanges(r, loc)
+}
+
+// describeChanges summarizes the changes a sync made.
+func describeChanges(r cache.SyncResult, loc *locale.Locale) string {
+	if r.Added+r.Changed+r.Removed == 0 && r.Full && r.Fetched == r.Total {
+		return fmt.Sprintf("%d items", r.Total)
+	}
+	changes := fmt.Sprintf("%d added, %d changed", r.Added, r.Changed)
+	if r.Removed > 0 {
+		changes += fmt.Sprintf(", %d removed", r.Removed)
+	}
+	if r.Full {
+		return fmt.Sprintf("%d items: %s", r.Total, changes)
+	}
+	return fmt.Sprintf("%s since %s; %d items", changes, loc.DateTime(r.Since), r.Total)
 }
 
 // fetchResource calls the appropriate API method for the target and returns
 // the number of items fetched.
-func fetchResource(client api.PaymoAPI, target string) (int, error) {
+func fetchResource(client api.PaymoAPI, target string, opts syncOptions) (int, error) {
 	switch target {
 	case "me":
 		_, err := client.GetMe()
@@ -178,6 +297,24 @@ func fetchResource(client api.PaymoAPI, target string) (int, error) {
 			return 0, err
 		}
 		return len(tasks), nil
+	case "tasklists":
+		lists, err := client.GetTaskLists(0)
+		if err != nil {
+			return 0, err
+		}
+		return len(lists), nil
+	case "entries":
+		entries, err := client.GetEntries(&api.EntryListOptions{
+			UserID:         opts.userID,
+			StartDate:      opts.from,
+			EndDate:        opts.to,
+			IncludeTask:    true,
+			IncludeProject: true,
+		})
+		if err != nil {
+			return 0, err
+		}
+		return len(entries), nil
 	default:
 		return 0, fmt.Errorf("unknown target: %s", target)
 	}
@@ -193,23 +330,22 @@ func syncAfterLogin(formatter *output.Formatter, user *api.User) {
 		return
 	}
 
-	if formatter.Format != "json" && !formatter.Quiet {
+	if !formatter.Structured() && !formatter.Quiet {
 		fmt.Fprintln(formatter.Writer)
 	}
 
 	// Seed "me" into the cache from the already-fetched user
 	seedMeCache(user)
-	if formatter.Format != "json" && !formatter.Quiet {
+	if !formatter.Structured() && !formatter.Quiet {
 		fmt.Fprintf(formatter.Writer, "Syncing me... done (1 items)\n")
 	}
 
 	// Sync the remaining core targets (clients, projects)
 	remaining := []string{"clients", "projects"}
-	invalidateCacheForSync(remaining...)
 
 	for _, target := range remaining {
-		if err := syncResource(client, target, formatter); err != nil {
-			if formatter.Format != "json" && !formatter.Quiet {
+		if _, err := syncResource(client, target, syncOptions{}, formatter); err != nil {
+			if !formatter.Structured() && !formatter.Quiet {
 				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
 			}
 			return
@@ -219,12 +355,11 @@ func syncAfterLogin(formatter *output.Formatter, user *api.User) {
 
 // seedMeCache writes the user directly into the cache store.
 func seedMeCache(user *api.User) {
-	cacheDir, err := config.GetConfigDir()
+	cachePath, err := cacheFilePath()
 	if err != nil {
 		return
 	}
-	cachePath := filepath.Join(cacheDir, "cache.json")
-	store, err := cache.Open(cachePath)
+	store, err := openCache(cachePath)
 	if err != nil {
 		return
 	}
@@ -234,4 +369,8 @@ func seedMeCache(user *api.User) {
 
 func init() {
 	rootCmd.AddCommand(syncCmd)
+
+	syncCmd.Flags().Bool("full", false, "refetch every record instead of only those changed since the last sync")
+	syncCmd.Flags().String("from", "", "first day of time entries to sync (YYYY-MM-DD, default: 30 days ago)")
+	syncCmd.Flags().String("to", "", "last day of time entries to sync (YYYY-MM-DD, default: today)")
 }
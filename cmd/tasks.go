@@ -6,6 +6,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/ComputClaw/paymo-cli/internal/api"
+	"github.com/ComputClaw/paymo-cli/internal/output"
 )
 
 // tasksCmd represents the tasks command
@@ -54,7 +55,14 @@ Examples:
 			return fmt.Errorf("fetching tasks: %w", err)
 		}
 
-		formatter := newFormatter()
+		formatter, err := newResourceFormatter(cmd)
+		if err != nil {
+			return err
+		}
+		withEnvelope(formatter, client, map[string]interface{}{
+			"project_id":        opts.ProjectID,
+			"include_completed": opts.IncludeCompleted,
+		})
 		return formatter.FormatTasks(tasks)
 	},
 }
@@ -82,7 +90,10 @@ Examples:
 			return err
 		}
 
-		formatter := newFormatter()
+		formatter, err := newResourceFormatter(cmd)
+		if err != nil {
+			return err
+		}
 		return formatter.FormatTask(task)
 	},
 }
@@ -109,9 +120,17 @@ Examples:
 		billable, _ := cmd.Flags().GetBool("billable")
 		dueDate, _ := cmd.Flags().GetString("due")
 
+		ctx, err := loadContext()
+		if err != nil {
+			return err
+		}
+		if projectFlag == "" {
+			projectFlag = ctx.Project.Value
+		}
 		if projectFlag == "" {
 			return fmt.Errorf("project is required - use --project flag")
 		}
+		billable = contextBillable(cmd, billable)
 
 		projectID, err := resolveProjectID(client, projectFlag)
 		if err != nil {
@@ -175,6 +194,11 @@ func init() {
 	tasksCmd.AddCommand(createTaskCmd)
 	tasksCmd.AddCommand(completeTaskCmd)
 
+	// Output flags for list and show commands
+	addOutputFlags(listTasksCmd, output.TaskResource)
+	addOutputFlags(showTaskCmd, output.TaskResource)
+	addListFlags(listTasksCmd)
+
 	// Flags for list command
 	listTasksCmd.Flags().StringP("project", "p", "", "filter by project ID or name")
 	listTasksCmd.Flags().Bool("all", false, "include completed tasks")
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"strconv"
 	"time"
 
@@ -9,6 +10,7 @@ import (
 
 	"github.com/ComputClaw/paymo-cli/internal/api"
 	"github.com/ComputClaw/paymo-cli/internal/config"
+	"github.com/ComputClaw/paymo-cli/internal/output"
 )
 
 // timeCmd represents the time command
@@ -24,10 +26,16 @@ var startCmd = &cobra.Command{
 	Short: "Start a new time tracking session",
 	Long: `Start tracking time for a project and task.
 
+Project, task and description default to the values in the nearest
+.paymo-project.yaml context file (see 'paymo context show'). Names are
+matched fuzzily (prefix, project code; typos only get suggestions); when
+run in a terminal, a missing or ambiguous project/task opens a picker.
+
 Examples:
   paymo time start "My Project" "Development"   # By name
   paymo time start -p 123 -t 456 "Bug fixing"   # By ID with description
-  paymo time start -p "My Project" -t "Dev"      # By name with flags`,
+  paymo time start -p "My Project" -t "Dev"      # By name with flags
+  paymo time start                               # From .paymo-project.yaml`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client, err := getAPIClient()
 		if err != nil {
@@ -57,8 +65,45 @@ Examples:
 		if projectArg == "" && len(args) > 0 {
 			projectArg = args[0]
 		}
+		taskArg := taskFlag
+		if taskArg == "" && len(args) > 1 {
+			taskArg = args[1]
+		}
+		description := descFlag
+		if description == "" && len(args) > 2 {
+			description = args[2]
+		}
+
+		// Defaults from .paymo-project.yaml context files, read only when they
+		// could fill something in. A broken file is only an error when the
+		// project (and with it the task) has to come from it.
+		ctx := &config.Context{}
+		if projectArg == "" || description == "" {
+			loaded, err := loadContext()
+			switch {
+			case err == nil:
+				ctx = loaded
+			case projectArg == "":
+				return err
+			default:
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+		}
+
+		projectFromContext := false
+		if projectArg == "" && ctx.Project.IsSet() {
+			projectArg = ctx.Project.Value
+			projectFromContext = true
+		}
+		if projectArg == "" && isInteractive() {
+			ref, err := pickProject(client)
+			if err != nil {
+				return err
+			}
+			projectArg = strconv.Itoa(ref.ID)
+		}
 		if projectArg == "" {
-			return fmt.Errorf("project is required - use 'paymo time start <project>' or '-p <id>'")
+			return fmt.Errorf("project is required - use 'paymo time start <project>', '-p <id>' or a %s context file", config.ContextFile)
 		}
 
 		project, err := resolveProject(client, projectArg)
@@ -69,9 +114,16 @@ Examples:
 		projectName = project.Name
 
 		// Determine task
-		taskArg := taskFlag
-		if taskArg == "" && len(args) > 1 {
-			taskArg = args[1]
+		// The context task belongs to the context project
+		if taskArg == "" && projectFromContext && ctx.Task.IsSet() {
+			taskArg = ctx.Task.Value
+		}
+		if taskArg == "" && isInteractive() {
+			ref, err := pickTask(client, projectID)
+			if err != nil {
+				return err
+			}
+			taskArg = strconv.Itoa(ref.ID)
 		}
 		if taskArg == "" {
 			return fmt.Errorf("task is required - use 'paymo time start <project> <task>' or '-t <id>'")
@@ -86,35 +138,22 @@ Examples:
 		taskName = task.Name
 
 		// Get description
-		description := descFlag
-		if description == "" && len(args) > 2 {
-			description = args[2]
+		if description == "" && ctx.Description.IsSet() {
+			l, err := loadLocale()
+			if err != nil {
+				return err
+			}
+			description = expandDescriptionTemplate(ctx.Description.Value, ctx.Description.Source, l.Now())
 		}
 
-		// Start the entry via API
-		entry, err := client.StartEntry(taskID, description)
+		// Start the entry via API and save timer state locally
+		entry, err := startTimer(client, projectID, projectName, taskID, taskName, description)
 		if err != nil {
-			return fmt.Errorf("starting timer: %w", err)
-		}
-
-		// Save timer state locally
-		timerState := &config.TimerState{
-			Active:      true,
-			EntryID:     entry.ID,
-			ProjectID:   projectID,
-			TaskID:      taskID,
-			ProjectName: projectName,
-			TaskName:    taskName,
-			Description: description,
-			StartTime:   time.Now(),
-		}
-
-		if err := config.SaveTimerState(timerState); err != nil {
-			return fmt.Errorf("saving timer state: %w", err)
+			return err
 		}
 
 		formatter := newFormatter()
-		if formatter.Format == "json" {
+		if formatter.Structured() {
 			return formatter.FormatTimeEntry(entry)
 		}
 		if !formatter.Quiet {
@@ -124,7 +163,7 @@ Examples:
 			if description != "" {
 				fmt.Fprintf(formatter.Writer, "  Description: %s\n", description)
 			}
-			fmt.Fprintf(formatter.Writer, "  Started:     %s\n", time.Now().Format("15:04:05"))
+			fmt.Fprintf(formatter.Writer, "  Started:     %s\n", formatter.Locale.Time(time.Now()))
 		} else {
 			fmt.Fprintf(formatter.Writer, "%d\n", entry.ID)
 		}
@@ -166,7 +205,7 @@ var stopCmd = &cobra.Command{
 		}
 
 		formatter := newFormatter()
-		if formatter.Format == "json" {
+		if formatter.Structured() {
 			return formatter.FormatTimeEntry(entry)
 		}
 		if !formatter.Quiet {
@@ -188,7 +227,22 @@ var stopCmd = &cobra.Command{
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show current time tracking status",
+	Long: `Show the running timer, if any.
+
+With --watch the display is redrawn every second with the elapsed time,
+today's logged total and progress towards the daily target. Press Ctrl-C
+to exit. Combined with --format json, one JSON object is emitted per tick.
+
+Examples:
+  paymo time status
+  paymo time status --watch
+  paymo time status --watch --target 7h30m
+  paymo time status --watch --format json`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if watch, _ := cmd.Flags().GetBool("watch"); watch {
+			return watchTimerStatus(cmd)
+		}
+
 		state, err := config.LoadTimerState()
 		if err != nil {
 			return fmt.Errorf("loading timer state: %w", err)
@@ -197,7 +251,7 @@ var statusCmd = &cobra.Command{
 		formatter := newFormatter()
 
 		if !state.Active {
-			if formatter.Format == "json" {
+			if formatter.Structured() {
 				return formatter.FormatTimerStatus(map[string]interface{}{
 					"active": false,
 				})
@@ -209,7 +263,7 @@ var statusCmd = &cobra.Command{
 			return nil
 		}
 
-		if formatter.Format == "json" {
+		if formatter.Structured() {
 			return formatter.FormatTimerStatus(map[string]interface{}{
 				"active":       true,
 				"entry_id":     state.EntryID,
@@ -218,7 +272,7 @@ var statusCmd = &cobra.Command{
 				"task_id":      state.TaskID,
 				"task_name":    state.TaskName,
 				"description":  state.Description,
-				"start_time":   state.StartTime.Format(time.RFC3339),
+				"start_time":   formatter.Locale.RFC3339(state.StartTime),
 				"elapsed":      state.FormatElapsedTime(),
 			})
 		}
@@ -229,7 +283,7 @@ var statusCmd = &cobra.Command{
 			if state.Description != "" {
 				fmt.Fprintf(formatter.Writer, "  Description: %s\n", state.Description)
 			}
-			fmt.Fprintf(formatter.Writer, "  Started:     %s\n", state.StartTime.Format("15:04:05"))
+			fmt.Fprintf(formatter.Writer, "  Started:     %s\n", formatter.Locale.Time(state.StartTime))
 			fmt.Fprintf(formatter.Writer, "  Elapsed:     %s\n", state.FormatElapsedTime())
 		}
 
@@ -237,6 +291,30 @@ var statusCmd = &cobra.Command{
 	},
 }
 
+// startTimer starts a time entry via the API and records it as the running
+// timer in the local timer state.
+func startTimer(client api.PaymoAPI, projectID int, projectName string, taskID int, taskName, description string) (*api.TimeEntry, error) {
+	entry, err := client.StartEntry(taskID, description)
+	if err != nil {
+		return nil, fmt.Errorf("starting timer: %w", err)
+	}
+
+	timerState := &config.TimerState{
+		Active:      true,
+		EntryID:     entry.ID,
+		ProjectID:   projectID,
+		TaskID:      taskID,
+		ProjectName: projectName,
+		TaskName:    taskName,
+		Description: description,
+		StartTime:   time.Now(),
+	}
+	if err := config.SaveTimerState(timerState); err != nil {
+		return nil, fmt.Errorf("saving timer state: %w", err)
+	}
+	return entry, nil
+}
+
 // logCmd shows time entries
 var logCmd = &cobra.Command{
 	Use:   "log",
@@ -248,7 +326,9 @@ Examples:
   paymo time log --date yesterday   # Yesterday's entries
   paymo time log --date 2026-02-01  # Specific date
   paymo time log --project 123      # Filter by project
-  paymo time log --project "Proj"   # Filter by project name`,
+  paymo time log --project "Proj"   # Filter by project name
+  paymo time log --date this-week --group-by day
+  paymo time log --sort -duration   # Longest entries first`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client, err := getAPIClient()
 		if err != nil {
@@ -272,38 +352,14 @@ Examples:
 			IncludeProject: true,
 		}
 
-		// Handle date filter
-		now := time.Now()
-		switch dateFlag {
-		case "today", "":
-			opts.StartDate = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-			opts.EndDate = opts.StartDate.Add(24 * time.Hour)
-		case "yesterday":
-			opts.StartDate = time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, now.Location())
-			opts.EndDate = opts.StartDate.Add(24 * time.Hour)
-		case "this-week":
-			weekday := int(now.Weekday())
-			if weekday == 0 {
-				weekday = 7
-			}
-			opts.StartDate = time.Date(now.Year(), now.Month(), now.Day()-weekday+1, 0, 0, 0, 0, now.Location())
-			opts.EndDate = now
-		case "last-week":
-			weekday := int(now.Weekday())
-			if weekday == 0 {
-				weekday = 7
-			}
-			endOfLastWeek := time.Date(now.Year(), now.Month(), now.Day()-weekday, 23, 59, 59, 0, now.Location())
-			opts.StartDate = endOfLastWeek.AddDate(0, 0, -6)
-			opts.EndDate = endOfLastWeek
-		default:
-			// Try to parse as date
-			date, err := time.Parse("2006-01-02", dateFlag)
-			if err != nil {
-				return fmt.Errorf("invalid date format: %s (use YYYY-MM-DD)", dateFlag)
-			}
-			opts.StartDate = date
-			opts.EndDate = date.Add(24 * time.Hour)
+		// Handle date filter, in the configured timezone
+		loc, err := loadLocale()
+		if err != nil {
+			return err
+		}
+		opts.StartDate, opts.EndDate, err = loc.DateRange(dateFlag)
+		if err != nil {
+			return err
 		}
 
 		// Handle project filter
@@ -322,7 +378,17 @@ Examples:
 		}
 
 		// Format output
-		formatter := newFormatter()
+		formatter, err := newResourceFormatter(cmd)
+		if err != nil {
+			return err
+		}
+		withEnvelope(formatter, client, map[string]interface{}{
+			"date":       dateFlag,
+			"from":       loc.RFC3339(opts.StartDate),
+			"to":         loc.RFC3339(opts.EndDate),
+			"project_id": opts.ProjectID,
+			"user_id":    opts.UserID,
+		})
 		return formatter.FormatTimeEntries(entries)
 	},
 }
@@ -352,7 +418,10 @@ Examples:
 			return fmt.Errorf("fetching entry: %w", err)
 		}
 
-		formatter := newFormatter()
+		formatter, err := newResourceFormatter(cmd)
+		if err != nil {
+			return err
+		}
 		return formatter.FormatTimeEntry(entry)
 	},
 }
@@ -458,11 +527,20 @@ func init() {
 	timeCmd.AddCommand(editEntryCmd)
 	timeCmd.AddCommand(deleteEntryCmd)
 
+	// Output flags for list and show commands
+	addOutputFlags(logCmd, output.EntryResource)
+	addOutputFlags(showEntryCmd, output.EntryResource)
+	addListFlags(logCmd)
+
 	// Flags for start command
 	startCmd.Flags().StringP("project", "p", "", "project name or ID")
 	startCmd.Flags().StringP("task", "t", "", "task name or ID")
 	startCmd.Flags().StringP("description", "d", "", "time entry description")
 
+	// Flags for status command
+	statusCmd.Flags().BoolP("watch", "w", false, "continuously redraw the status every second")
+	statusCmd.Flags().String("target", "8h", "daily target used for progress in --watch mode (e.g. 7h30m)")
+
 	// Flags for edit command
 	editEntryCmd.Flags().StringP("description", "d", "", "update description")
 	editEntryCmd.Flags().String("duration", "", "update duration (e.g. 2h30m)")
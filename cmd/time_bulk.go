@@ -0,0 +1,357 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ComputClaw/paymo-cli/internal/api"
+	"github.com/ComputClaw/paymo-cli/internal/config"
+	"github.com/ComputClaw/paymo-cli/internal/output"
+)
+
+// defaultBulkConcurrency is how many requests a bulk operation runs at once.
+const defaultBulkConcurrency = 4
+
+// bulkSetFields are the fields `time bulk-edit --set` can change.
+var bulkSetFields = []string{"description", "duration", "project", "task"}
+
+// bulkResult is the outcome of a bulk operation on one entry.
+type bulkResult struct {
+	ID     int    `json:"id"`
+	Status string `json:"status"` // "updated", "deleted" or "failed"
+	Error  string `json:"error,omitempty"`
+}
+
+// bulkReport is the structured output of `time bulk-edit` and `time bulk-delete`.
+type bulkReport struct {
+	Action    string       `json:"action"`
+	Succeeded int          `json:"succeeded"`
+	Failed    int          `json:"failed"`
+	Results   []bulkResult `json:"results"`
+}
+
+// bulkEditCmd updates every entry matching a selection
+var bulkEditCmd = &cobra.Command{
+	Use:   "bulk-edit",
+	Short: "Edit many time entries at once",
+	Long: `Update every time entry matching --date, --project and --filter.
+
+--set field=value changes a field on every matching entry and can be
+repeated. Fields: description, duration (e.g. 1h30m), task (name or ID)
+and project (together with task, to move entries to another project).
+Task names are looked up in the --set project, else in --project.
+
+The matching entries are shown first and the edit runs after you confirm;
+pass --yes to skip the question (required when not in a terminal).
+
+Examples:
+  paymo time bulk-edit --date last-week --project Website --set task=Design
+  paymo time bulk-edit --date 2026-02-09 --filter 'description ~ "standup"' --set duration=15m
+  paymo time bulk-edit --date this-week --set project=Internal --set task=Meetings --yes`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		setFlags, _ := cmd.Flags().GetStringArray("set")
+		if len(setFlags) == 0 {
+			return fmt.Errorf("nothing to change — use --set field=value (fields: %s)", strings.Join(bulkSetFields, ", "))
+		}
+		values, err := parseBulkSet(setFlags)
+		if err != nil {
+			return err
+		}
+
+		client, err := getAPIClient()
+		if err != nil {
+			return err
+		}
+		projectFlag, _ := cmd.Flags().GetString("project")
+		req, err := bulkUpdateRequest(client, values, projectFlag)
+		if err != nil {
+			return err
+		}
+
+		return runBulk(cmd, client, "update", func(id int) error {
+			_, err := client.UpdateEntry(id, req)
+			return err
+		})
+	},
+}
+
+// bulkDeleteCmd deletes every entry matching a selection
+var bulkDeleteCmd = &cobra.Command{
+	Use:   "bulk-delete",
+	Short: "Delete many time entries at once",
+	Long: `Delete every time entry matching --date, --project and --filter.
+--date has no default here: a whole day is never deleted by accident.
+
+The matching entries are shown first and deleted after you confirm;
+pass --yes to skip the question (required when not in a terminal).
+Deleting the entry of the running timer stops the local timer too.
+
+Examples:
+  paymo time bulk-delete --date yesterday --filter 'duration < 1m'
+  paymo time bulk-delete --date last-week --project Website --filter 'task = "Scratch"' --yes`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if date, _ := cmd.Flags().GetString("date"); date == "" {
+			return fmt.Errorf("--date is required, e.g. --date today")
+		}
+		client, err := getAPIClient()
+		if err != nil {
+			return err
+		}
+		state, err := config.LoadTimerState()
+		if err != nil {
+			return fmt.Errorf("loading timer state: %w", err)
+		}
+		return runBulk(cmd, client, "delete", func(id int) error {
+			if err := client.DeleteEntry(id); err != nil {
+				return err
+			}
+			// Don't leave the timer pointing at a deleted entry
+			if state.Active && id == state.EntryID {
+				return config.ClearTimerState()
+			}
+			return nil
+		})
+	},
+}
+
+// parseBulkSet parses --set field=value flags.
+func parseBulkSet(flags []string) (map[string]string, error) {
+	values := map[string]string{}
+	for _, f := range flags {
+		field, value, ok := strings.Cut(f, "=")
+		field = strings.ToLower(strings.TrimSpace(field))
+		if !ok || field == "" {
+			return nil, fmt.Errorf("invalid --set %q (use field=value)", f)
+		}
+		known := false
+		for _, name := range bulkSetFields {
+			known = known || name == field
+		}
+		if !known {
+			return nil, fmt.Errorf("cannot set %q (fields: %s)", field, strings.Join(bulkSetFields, ", "))
+		}
+		if _, dup := values[field]; dup {
+			return nil, fmt.Errorf("--set %s given more than once", field)
+		}
+		values[field] = value
+	}
+	if _, ok := values["project"]; ok && values["task"] == "" {
+		return nil, fmt.Errorf("--set project needs --set task (entries belong to a task)")
+	}
+	return values, nil
+}
+
+// bulkUpdateRequest builds the update applied to every entry.
+func bulkUpdateRequest(client api.PaymoAPI, values map[string]string, projectFlag string) (*api.UpdateTimeEntryRequest, error) {
+	req := &api.UpdateTimeEntryRequest{}
+	if desc, ok := values["description"]; ok {
+		req.Description = &desc
+	}
+	if s, ok := values["duration"]; ok {
+		dur, err := time.ParseDuration(s)
+		if err != nil || dur <= 0 {
+			return nil, fmt.Errorf("invalid duration: %s (use Go duration format, e.g. 2h30m)", s)
+		}
+		secs := int(dur.Seconds())
+		req.Duration = &secs
+	}
+	if taskArg, ok := values["task"]; ok {
+		project := projectFlag
+		if p, ok := values["project"]; ok {
+			project = p
+		}
+		task, err := resolveTask(client, taskArg, project)
+		if err != nil {
+			return nil, err
+		}
+		req.TaskID = &task.ID
+	}
+	return req, nil
+}
+
+// runBulk selects entries from the command's flags, shows them, asks for
+// confirmation and applies op to each. verb is "update" or "delete".
+func runBulk(cmd *cobra.Command, client api.PaymoAPI, verb string, op func(id int) error) error {
+	yes, _ := cmd.Flags().GetBool("yes")
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	if concurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+
+	formatter, err := newResourceFormatter(cmd)
+	if err != nil {
+		return err
+	}
+	entries, err := selectBulkEntries(cmd, client, formatter.Filter)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return formatter.FormatSuccess("No time entries match.", 0)
+	}
+
+	// Show the selection unless the only output should be the report
+	if !yes || !formatter.Structured() {
+		if err := formatter.FormatTimeEntries(entries); err != nil {
+			return err
+		}
+	}
+	if !yes {
+		if !isInteractive() {
+			return fmt.Errorf("%d entries would be %sd — pass --yes to confirm", len(entries), verb)
+		}
+		if !confirm(fmt.Sprintf("%s %d time entries?", strings.ToUpper(verb[:1])+verb[1:], len(entries))) {
+			return fmt.Errorf("cancelled")
+		}
+	}
+
+	ids := make([]int, len(entries))
+	for i, e := range entries {
+		ids[i] = e.ID
+	}
+	results := bulkApply(client, ids, concurrency, verb+"d", op)
+	return reportBulk(formatter, verb, results)
+}
+
+// selectBulkEntries fetches the entries matching --date and --project, then
+// applies the --filter expression.
+func selectBulkEntries(cmd *cobra.Command, client api.PaymoAPI, filter *output.Filter) ([]api.TimeEntry, error) {
+	dateFlag, _ := cmd.Flags().GetString("date")
+	projectFlag, _ := cmd.Flags().GetString("project")
+
+	opts := &api.EntryListOptions{IncludeTask: true, IncludeProject: true}
+	if creds, _ := config.LoadCredentials(); creds != nil {
+		opts.UserID = creds.UserID
+	}
+	loc, err := loadLocale()
+	if err != nil {
+		return nil, err
+	}
+	if opts.StartDate, opts.EndDate, err = loc.DateRange(dateFlag); err != nil {
+		return nil, err
+	}
+	if projectFlag != "" {
+		if opts.ProjectID, err = resolveProjectID(client, projectFlag); err != nil {
+			return nil, err
+		}
+	}
+
+	entries, err := client.GetEntries(opts)
+	if err != nil {
+		return nil, fmt.Errorf("fetching entries: %w", err)
+	}
+	if filter == nil {
+		return entries, nil
+	}
+	matched := entries[:0:0]
+	for i := range entries {
+		if filter.Match(&entries[i]) {
+			matched = append(matched, entries[i])
+		}
+	}
+	return matched, nil
+}
+
+// confirm asks a yes/no question on pickerOut; anything but y or yes is no.
+func confirm(question string) bool {
+	fmt.Fprintf(pickerOut, "%s [y/N]: ", question)
+	line, _ := readLine(pickerIn)
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer == "y" || answer == "yes"
+}
+
+// bulkApply runs op on every ID with up to concurrency requests in flight.
+// Before each round it waits for the rate limit to allow that many requests.
+// Results are in the order of ids; done is the status of a successful op.
+func bulkApply(client api.PaymoAPI, ids []int, concurrency int, done string, op func(id int) error) []bulkResult {
+	results := make([]bulkResult, len(ids))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(concurrency, len(ids)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = bulkResult{ID: ids[i], Status: done}
+				if err := op(ids[i]); err != nil {
+					results[i].Status, results[i].Error = "failed", err.Error()
+				}
+			}
+		}()
+	}
+	for i := range ids {
+		if i%concurrency == 0 {
+			waitForRateLimit(client, min(concurrency, len(ids)-i))
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// reportBulk prints one line per entry and a summary. It returns an error
+// when any entry failed.
+func reportBulk(formatter *output.Formatter, verb string, results []bulkResult) error {
+	report := bulkReport{Action: verb, Results: results}
+	for _, r := range results {
+		if r.Status == "failed" {
+			report.Failed++
+		} else {
+			report.Succeeded++
+		}
+	}
+
+	switch {
+	case formatter.Structured():
+		if err := formatter.FormatTimerStatus(report); err != nil {
+			return err
+		}
+	case !formatter.Quiet:
+		w := formatter.Writer
+		fmt.Fprintln(w)
+		for _, r := range results {
+			if r.Error != "" {
+				fmt.Fprintf(w, "✗ %d %s: %s\n", r.ID, r.Status, r.Error)
+			} else {
+				fmt.Fprintf(w, "✓ %d %s\n", r.ID, r.Status)
+			}
+		}
+		fmt.Fprintf(w, "\n%sd %d of %d entries", strings.ToUpper(verb[:1])+verb[1:], report.Succeeded, len(results))
+		if report.Failed > 0 {
+			fmt.Fprintf(w, " (%d failed)", report.Failed)
+		}
+		fmt.Fprintln(w)
+	}
+
+	if report.Failed > 0 {
+		return fmt.Errorf("%d of %d entries failed to %s", report.Failed, len(results), verb)
+	}
+	return nil
+}
+
+// addBulkFlags adds the selection and confirmation flags shared by the bulk
+// commands. defaultDate is the --date default; empty makes it required.
+func addBulkFlags(cmd *cobra.Command, defaultDate string) {
+	addOutputFlags(cmd, output.EntryResource)
+	addListFlags(cmd)
+	cmd.Flags().String("date", defaultDate, "entries to act on (today, yesterday, this-week, last-week, YYYY-MM-DD)")
+	cmd.Flags().StringP("project", "p", "", "only entries of this project")
+	cmd.Flags().BoolP("yes", "y", false, "don't ask for confirmation")
+	cmd.Flags().Int("concurrency", defaultBulkConcurrency, "requests to run at once (paced by the API rate limit)")
+}
+
+func init() {
+	timeCmd.AddCommand(bulkEditCmd)
+	timeCmd.AddCommand(bulkDeleteCmd)
+
+	addBulkFlags(bulkEditCmd, "today")
+	addBulkFlags(bulkDeleteCmd, "")
+	bulkEditCmd.Flags().StringArray("set", nil, "field=value to set on every entry: "+strings.Join(bulkSetFields, ", ")+" (repeatable)")
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ComputClaw/paymo-cli/internal/api"
+	"github.com/ComputClaw/paymo-cli/internal/config"
+	"github.com/ComputClaw/paymo-cli/internal/ical"
+)
+
+// exportCmd exports time entries to other formats
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export time entries to a calendar",
+	Long: `Export time entries as iCalendar (.ics) events, to overlay logged time
+on a calendar.
+
+Each entry becomes a VEVENT with "Project / Task" as the summary and the
+entry description as the event body. --from and --to are inclusive dates
+in your timezone; --to defaults to today. Running timers are left out.
+
+Examples:
+  paymo time export --ics --from 2026-02-01 --to 2026-02-28 > february.ics
+  paymo time export --ics --from last-week -o week.ics
+  paymo time export --ics --from 2026-02-01 --project "Website"`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if ics, _ := cmd.Flags().GetBool("ics"); !ics {
+			return fmt.Errorf("no export format given — use --ics")
+		}
+		fromFlag, _ := cmd.Flags().GetString("from")
+		toFlag, _ := cmd.Flags().GetString("to")
+		projectFlag, _ := cmd.Flags().GetString("project")
+		outputFlag, _ := cmd.Flags().GetString("output")
+
+		loc, err := loadLocale()
+		if err != nil {
+			return err
+		}
+		start, _, err := loc.DateRange(fromFlag)
+		if err != nil {
+			return fmt.Errorf("--from: %w", err)
+		}
+		_, end, err := loc.DateRange(toFlag)
+		if err != nil {
+			return fmt.Errorf("--to: %w", err)
+		}
+		if !end.After(start) {
+			return fmt.Errorf("--to must not be before --from")
+		}
+
+		client, err := getAPIClient()
+		if err != nil {
+			return err
+		}
+
+		opts := &api.EntryListOptions{StartDate: start, EndDate: end, IncludeTask: true, IncludeProject: true}
+		if creds, _ := config.LoadCredentials(); creds != nil {
+			opts.UserID = creds.UserID
+		}
+		if projectFlag != "" {
+			if opts.ProjectID, err = resolveProjectID(client, projectFlag); err != nil {
+				return err
+			}
+		}
+		entries, err := client.GetEntries(opts)
+		if err != nil {
+			return fmt.Errorf("fetching entries: %w", err)
+		}
+
+		events := entryEvents(entries, projectNames(client))
+
+		formatter := newFormatter()
+		if outputFlag == "" || outputFlag == "-" {
+			return ical.Encode(formatter.Writer, events, loc.Now())
+		}
+		if err := writeCalendar(outputFlag, events, loc.Now()); err != nil {
+			return err
+		}
+		return formatter.FormatSuccess(fmt.Sprintf("Exported %d entries to %s", len(events), outputFlag), 0)
+	},
+}
+
+// entryEvents converts time entries to calendar events, skipping running
+// timers. projects maps project IDs to names for entries without an
+// included project.
+func entryEvents(entries []api.TimeEntry, projects map[int]string) []ical.Event {
+	events := make([]ical.Event, 0, len(entries))
+	for _, e := range entries {
+		end := e.EndTime
+		if end.IsZero() {
+			if e.Duration == 0 {
+				continue // running timer
+			}
+			end = e.StartTime.Add(time.Duration(e.Duration) * time.Second)
+		}
+
+		projectName, taskName := "", fmt.Sprintf("Task %d", e.TaskID)
+		if e.Task != nil {
+			taskName = e.Task.Name
+			projectName = projects[e.Task.ProjectID]
+		}
+		if e.Project != nil {
+			projectName = e.Project.Name
+		}
+		summary := taskName
+		if projectName != "" {
+			summary = projectName + " / " + taskName
+		}
+
+		event := ical.Event{
+			UID:         fmt.Sprintf("paymo-entry-%d@paymo-cli", e.ID),
+			Summary:     summary,
+			Description: strings.TrimSpace(e.Description),
+			Start:       e.StartTime,
+			End:         end,
+		}
+		if projectName != "" {
+			event.Categories = []string{projectName}
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// projectNames returns project names by ID. Errors leave the map empty;
+// summaries then show only the task.
+func projectNames(client api.PaymoAPI) map[int]string {
+	names := map[int]string{}
+	projects, err := client.GetProjects(nil)
+	if err != nil {
+		return names
+	}
+	for _, p := range projects {
+		names[p.ID] = p.Name
+	}
+	return names
+}
+
+// writeCalendar writes events to an .ics file.
+func writeCalendar(path string, events []ical.Event, stamp time.Time) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating %s: %w", path, err)
+	}
+	if err := ical.Encode(f, events, stamp); err != nil {
+		f.Close()
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+func init() {
+	timeCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().Bool("ics", false, "export as iCalendar (.ics) events")
+	exportCmd.Flags().String("from", "this-week", "first day to export (YYYY-MM-DD, today, yesterday, this-week, last-week)")
+	exportCmd.Flags().String("to", "today", "last day to export, inclusive")
+	exportCmd.Flags().StringP("project", "p", "", "only export entries of this project")
+	exportCmd.Flags().StringP("output", "o", "", "write to a file instead of stdout")
+}
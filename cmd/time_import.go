@@ -0,0 +1,718 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ComputClaw/paymo-cli/internal/api"
+	"github.com/ComputClaw/paymo-cli/internal/config"
+	"github.com/ComputClaw/paymo-cli/internal/ical"
+	"github.com/ComputClaw/paymo-cli/internal/locale"
+	"github.com/ComputClaw/paymo-cli/internal/output"
+)
+
+// Import actions, in the order they are reported.
+const (
+	importCreate    = "create"
+	importCreated   = "created"
+	importDone      = "done" // created by an earlier, interrupted run
+	importDuplicate = "duplicate"
+	importSkip      = "skip"
+	importInvalid   = "invalid"
+	importFailed    = "failed"
+	importPending   = "pending" // not attempted because the import stopped
+)
+
+// importMarks prefix rows in the human-readable plan, diff style.
+var importMarks = map[string]string{
+	importCreate:    "+",
+	importCreated:   "+",
+	importDone:      "=",
+	importDuplicate: "=",
+	importSkip:      "-",
+	importInvalid:   "!",
+	importFailed:    "!",
+	importPending:   "~",
+}
+
+// duplicateTolerance is how far apart the start times and durations of an
+// imported row and an existing entry on the same task may be for the row to
+// count as already imported.
+const duplicateTolerance = time.Minute
+
+// defaultImportBatch is how many entries are created between rate limit
+// checks and progress reports.
+const defaultImportBatch = 25
+
+// importSleep is defined as a var to allow test injection.
+var importSleep = time.Sleep
+
+// importRow is one planned (and, unless --dry-run, executed) time entry.
+type importRow struct {
+	Source      string    `json:"source"` // where the row came from, e.g. "line 12"
+	Action      string    `json:"action"`
+	Reason      string    `json:"reason,omitempty"` // why the row is skipped or a duplicate
+	Start       time.Time `json:"start_time"`
+	DateOnly    bool      `json:"date_only,omitempty"` // no time of day; Start is midnight
+	Duration    int       `json:"duration"`            // seconds
+	ProjectID   int       `json:"project_id,omitempty"`
+	Project     string    `json:"project,omitempty"`
+	TaskID      int       `json:"task_id,omitempty"`
+	Task        string    `json:"task,omitempty"`
+	Description string    `json:"description,omitempty"`
+	EntryID     int       `json:"entry_id,omitempty"` // created or duplicated entry
+	Error       string    `json:"error,omitempty"`
+}
+
+// end returns when the row's entry ends; date-only rows span their day.
+func (r importRow) end() time.Time {
+	if r.DateOnly {
+		return r.Start.AddDate(0, 0, 1)
+	}
+	return r.Start.Add(time.Duration(r.Duration) * time.Second)
+}
+
+// request returns the API request creating the row's entry.
+func (r importRow) request() *api.CreateTimeEntryRequest {
+	req := &api.CreateTimeEntryRequest{TaskID: r.TaskID, Description: r.Description}
+	if r.DateOnly {
+		req.Date = r.Start.Format("2006-01-02")
+		req.Duration = r.Duration
+		return req
+	}
+	req.StartTime = r.Start.UTC().Format("2006-01-02T15:04:05Z")
+	req.EndTime = r.end().UTC().Format("2006-01-02T15:04:05Z")
+	return req
+}
+
+// importReport is the structured output of `paymo time import`.
+type importReport struct {
+	DryRun     bool        `json:"dry_run"`
+	Created    int         `json:"created"`
+	Done       int         `json:"previously_imported"`
+	Duplicates int         `json:"duplicates"`
+	Skipped    int         `json:"skipped"`
+	Invalid    int         `json:"invalid"`
+	Failed     int         `json:"failed"`
+	Pending    int         `json:"pending"`
+	Progress   string      `json:"progress_file,omitempty"` // kept to resume an unfinished import
+	Rows       []importRow `json:"rows"`
+}
+
+// importCmd creates time entries from a file
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import time entries from a calendar, CSV or JSON file",
+	Long: `Create time entries from an iCalendar (.ics), CSV (.csv, .tsv) or JSON
+(.json) file.
+
+CALENDARS
+A rules file (--map) decides which project and task each event is booked
+to, matching on the event summary. The first matching rule wins:
+
+  rules:
+    - match: standup              # text in the summary, any case
+      project: Internal
+      task: Meetings
+    - match: /^1:1 /              # /.../ is a regular expression
+      project: Internal
+      task: 1:1s
+      description: "{summary} ({location})"
+    - match: lunch
+      skip: true
+  default:                        # optional, for events no rule matches
+    project: Internal
+    task: Meetings
+
+Without --map, --project and --task book every event to one task.
+All-day, cancelled and recurring events are skipped.
+
+SPREADSHEETS
+CSV files need a header row; JSON files hold an array of objects. Columns
+are recognized by name, including Toggl and Harvest exports:
+
+  date          Date, Start date, Spent date
+  start, end    Start time / End time: 09:30, 9:30 AM or a full timestamp
+  duration      Duration, Hours: 1:30, 1:30:00, 1.5 (hours) or 1h30m
+  project       Project name or ID (default --project)
+  task          Task name or ID (default --task)
+  description   Description, Notes
+
+Use --column to map other headers, e.g. --column "description=Work done".
+A row needs a start with an end or duration, or a date and a duration.
+
+Rows that already have an entry on the same task at the same time are
+skipped, so running an import twice creates nothing new. Use --dry-run to
+preview the plan. Entries are created in batches that respect the API rate
+limit; progress is saved next to the file (--progress) after every entry,
+so an interrupted import resumes where it stopped when run again.
+
+Examples:
+  paymo time import meetings.ics --map rules.yaml --dry-run
+  paymo time import meetings.ics --project Internal --task Meetings --from this-week
+  paymo time import toggl.csv --dry-run
+  paymo time import harvest.csv --column "description=Notes" --task Development
+  paymo time import entries.json --skip-invalid`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := args[0]
+		projectFlag, _ := cmd.Flags().GetString("project")
+		taskFlag, _ := cmd.Flags().GetString("task")
+		fromFlag, _ := cmd.Flags().GetString("from")
+		toFlag, _ := cmd.Flags().GetString("to")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		skipInvalid, _ := cmd.Flags().GetBool("skip-invalid")
+		batchSize, _ := cmd.Flags().GetInt("batch-size")
+		progressFlag, _ := cmd.Flags().GetString("progress")
+		restart, _ := cmd.Flags().GetBool("restart")
+
+		if batchSize < 1 {
+			return fmt.Errorf("--batch-size must be at least 1")
+		}
+		if (projectFlag == "") != (taskFlag == "") && importFileKind(path) == "ics" {
+			return fmt.Errorf("--project and --task must be given together")
+		}
+
+		loc, err := loadLocale()
+		if err != nil {
+			return err
+		}
+		var from, to time.Time
+		if fromFlag != "" {
+			if from, _, err = loc.DateRange(fromFlag); err != nil {
+				return fmt.Errorf("--from: %w", err)
+			}
+		}
+		if toFlag != "" {
+			if _, to, err = loc.DateRange(toFlag); err != nil {
+				return fmt.Errorf("--to: %w", err)
+			}
+		}
+
+		var plan func(client api.PaymoAPI) ([]importRow, error)
+		switch importFileKind(path) {
+		case "ics":
+			mapFlag, _ := cmd.Flags().GetString("map")
+			rules, err := calendarRules(mapFlag, projectFlag, taskFlag)
+			if err != nil {
+				return err
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				return fmt.Errorf("opening %s: %w", path, err)
+			}
+			events, err := ical.Parse(f, loc.Location)
+			f.Close()
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			plan = func(client api.PaymoAPI) ([]importRow, error) {
+				return planCalendarImport(client, events, rules, from, to)
+			}
+		case "csv", "tsv", "json":
+			columnFlags, _ := cmd.Flags().GetStringArray("column")
+			records, columns, err := readImportRecords(path)
+			if err != nil {
+				return err
+			}
+			mapping, err := importColumnMapping(columns, columnFlags)
+			if err != nil {
+				return err
+			}
+			defaults := importDefaults{Project: projectFlag, Task: taskFlag}
+			plan = func(client api.PaymoAPI) ([]importRow, error) {
+				return planRecordImport(client, records, mapping, defaults, loc, from, to), nil
+			}
+		default:
+			return fmt.Errorf("unsupported import file %s (expected .ics, .csv, .tsv or .json)", path)
+		}
+
+		progress, err := openImportProgress(path, progressFlag, restart)
+		if err != nil {
+			return err
+		}
+
+		client, err := getAPIClient()
+		if err != nil {
+			return err
+		}
+		rows, err := plan(client)
+		if err != nil {
+			return err
+		}
+		progress.apply(rows)
+		if err := markDuplicates(client, rows, loc); err != nil {
+			return err
+		}
+
+		formatter := newFormatter()
+		if invalid := countActions(rows, importInvalid); invalid > 0 && !skipInvalid && !dryRun {
+			if err := reportImport(formatter, rows, true, loc, ""); err != nil {
+				return err
+			}
+			return fmt.Errorf("%d rows are invalid — fix them or pass --skip-invalid", invalid)
+		}
+		if dryRun {
+			return reportImport(formatter, rows, true, loc, "")
+		}
+
+		execErr := executeImport(client, rows, batchSize, progress)
+		kept := ""
+		if execErr == nil && countActions(rows, importFailed) == 0 {
+			if err := progress.remove(); err != nil {
+				return err
+			}
+		} else if progress.saved() {
+			kept = progress.path
+		}
+		if err := reportImport(formatter, rows, false, loc, kept); err != nil {
+			return err
+		}
+		return execErr
+	},
+}
+
+// importFileKind returns the import format for a file name: ics, csv, tsv
+// or json.
+func importFileKind(path string) string {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".ics", ".ical":
+		return "ics"
+	case ".csv", ".tsv", ".json":
+		return ext[1:]
+	}
+	return ""
+}
+
+// calendarRules loads the --map rules, with --project/--task as the default.
+func calendarRules(mapFlag, projectFlag, taskFlag string) (*ical.Rules, error) {
+	rules := &ical.Rules{}
+	if mapFlag != "" {
+		var err error
+		if rules, err = ical.LoadRules(mapFlag); err != nil {
+			return nil, err
+		}
+	}
+	if projectFlag != "" {
+		rules.Default = &ical.Rule{Project: projectFlag, Task: taskFlag}
+	}
+	if len(rules.Rules) == 0 && rules.Default == nil {
+		return nil, fmt.Errorf("no mapping given — use --map rules.yaml or --project and --task")
+	}
+	return rules, nil
+}
+
+// planCalendarImport maps calendar events to import rows. Events outside
+// [from, to) are skipped; zero bounds are open.
+func planCalendarImport(client api.PaymoAPI, events []ical.Event, rules *ical.Rules, from, to time.Time) ([]importRow, error) {
+	resolver := newTaskResolver(client)
+	seen := map[string]bool{}
+	rows := make([]importRow, 0, len(events))
+	for _, e := range events {
+		row := importRow{
+			Source:      fmt.Sprintf("line %d", e.Line),
+			Action:      importCreate,
+			Start:       e.Start,
+			Duration:    int(e.Duration().Seconds()),
+			Description: e.Summary,
+		}
+		rule := rules.Find(e)
+		key := e.UID + "|" + e.Start.UTC().Format(time.RFC3339)
+
+		switch {
+		case outsideRange(e.Start, from, to):
+			row.Action, row.Reason = importSkip, "outside --from/--to"
+		case e.Status == "CANCELLED":
+			row.Action, row.Reason = importSkip, "cancelled"
+		case e.AllDay:
+			row.Action, row.Reason = importSkip, "all-day event"
+		case e.Recurring:
+			row.Action, row.Reason = importSkip, "recurring event (only single events are imported)"
+		case row.Duration <= 0:
+			row.Action, row.Reason = importSkip, "no duration"
+		case e.UID != "" && seen[key]:
+			row.Action, row.Reason = importDuplicate, "repeated in file"
+		case rule == nil:
+			row.Action, row.Reason = importSkip, "no matching rule"
+		case rule.Skip:
+			row.Action, row.Reason = importSkip, fmt.Sprintf("skipped by rule %q", rule.Match)
+		}
+		seen[key] = true
+
+		if row.Action == importCreate {
+			project, task, err := resolver.resolve(rule.Project, rule.Task)
+			if err != nil {
+				if rule.Match != "" {
+					return nil, fmt.Errorf("rule %q: %w", rule.Match, err)
+				}
+				return nil, err
+			}
+			row.ProjectID, row.Project = project.ID, project.Name
+			row.TaskID, row.Task = task.ID, task.Name
+			row.Description = rule.EntryDescription(e)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// outsideRange reports whether t falls outside [from, to); zero bounds are open.
+func outsideRange(t, from, to time.Time) bool {
+	return (!from.IsZero() && t.Before(from)) || (!to.IsZero() && !t.Before(to))
+}
+
+// taskResolver resolves project/task names once per import, so rows that
+// share a mapping don't repeat lookups (or pickers).
+type taskResolver struct {
+	client   api.PaymoAPI
+	projects map[string]*api.Project
+	tasks    map[string]*api.Task
+	errs     map[string]error
+}
+
+func newTaskResolver(client api.PaymoAPI) *taskResolver {
+	return &taskResolver{client: client, projects: map[string]*api.Project{}, tasks: map[string]*api.Task{}, errs: map[string]error{}}
+}
+
+func (r *taskResolver) resolve(projectArg, taskArg string) (*api.Project, *api.Task, error) {
+	if err := r.errs[projectArg]; err != nil {
+		return nil, nil, err
+	}
+	project, ok := r.projects[projectArg]
+	if !ok {
+		var err error
+		if project, err = resolveProject(r.client, projectArg); err != nil {
+			r.errs[projectArg] = err
+			return nil, nil, err
+		}
+		r.projects[projectArg] = project
+	}
+
+	key := strconv.Itoa(project.ID) + "|" + taskArg
+	if err := r.errs[key]; err != nil {
+		return nil, nil, err
+	}
+	task, ok := r.tasks[key]
+	if !ok {
+		var err error
+		if task, err = resolveTask(r.client, taskArg, strconv.Itoa(project.ID)); err != nil {
+			r.errs[key] = err
+			return nil, nil, err
+		}
+		r.tasks[key] = task
+	}
+	return project, task, nil
+}
+
+// markDuplicates flags rows to be created that already have an entry on the
+// same task starting and lasting within duplicateTolerance. Date-only rows
+// match entries on the same day in loc.
+func markDuplicates(client api.PaymoAPI, rows []importRow, loc *locale.Locale) error {
+	var start, end time.Time
+	for _, r := range rows {
+		if r.Action != importCreate {
+			continue
+		}
+		if start.IsZero() || r.Start.Before(start) {
+			start = r.Start
+		}
+		if r.end().After(end) {
+			end = r.end()
+		}
+	}
+	if start.IsZero() {
+		return nil
+	}
+
+	opts := &api.EntryListOptions{
+		StartDate: start.Add(-duplicateTolerance),
+		EndDate:   end.Add(duplicateTolerance),
+	}
+	if creds, _ := config.LoadCredentials(); creds != nil {
+		opts.UserID = creds.UserID
+	}
+	existing, err := client.GetEntries(opts)
+	if err != nil {
+		return fmt.Errorf("fetching existing entries: %w", err)
+	}
+
+	for i := range rows {
+		r := &rows[i]
+		if r.Action != importCreate {
+			continue
+		}
+		for _, e := range existing {
+			if e.TaskID != r.TaskID || !within(time.Duration(e.Duration-r.Duration)*time.Second) {
+				continue
+			}
+			sameTime := within(e.StartTime.Sub(r.Start))
+			if r.DateOnly {
+				sameTime = loc.StartOfDay(e.StartTime).Equal(loc.StartOfDay(r.Start))
+			}
+			if sameTime {
+				r.Action, r.Reason, r.EntryID = importDuplicate, fmt.Sprintf("matches entry %d", e.ID), e.ID
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func within(d time.Duration) bool {
+	return d > -duplicateTolerance && d < duplicateTolerance
+}
+
+// executeImport creates the entries of rows marked for creation in batches,
+// waiting for the rate limit to reset between batches when the next batch
+// would exceed it. Each created entry is recorded in progress. The import
+// stops at the first failure; the rows after it are left pending.
+func executeImport(client api.PaymoAPI, rows []importRow, batchSize int, progress *importProgress) error {
+	var todo []int
+	for i, r := range rows {
+		if r.Action == importCreate {
+			todo = append(todo, i)
+		}
+	}
+
+	for n, i := range todo {
+		if n%batchSize == 0 {
+			waitForRateLimit(client, min(batchSize, len(todo)-n))
+		}
+		r := &rows[i]
+		entry, err := client.CreateEntry(r.request())
+		if err != nil {
+			r.Action, r.Error = importFailed, err.Error()
+			for _, j := range todo[n+1:] {
+				rows[j].Action = importPending
+			}
+			return fmt.Errorf("import stopped at %s: %w", r.Source, err)
+		}
+		r.Action, r.EntryID = importCreated, entry.ID
+		if err := progress.record(*r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitForRateLimit sleeps until the rate limit resets when fewer than n
+// requests remain. Clients that don't report a rate limit never wait.
+func waitForRateLimit(client api.PaymoAPI, n int) {
+	rl, ok := client.(api.RateLimiter)
+	if !ok {
+		return
+	}
+	status := rl.RateLimit()
+	wait := time.Until(status.Reset)
+	if status.Limit == 0 || status.Remaining >= n || wait <= 0 {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Rate limit: %d requests left, waiting %s for it to reset...\n", status.Remaining, wait.Round(time.Second))
+	importSleep(wait)
+}
+
+func countActions(rows []importRow, action string) int {
+	n := 0
+	for _, r := range rows {
+		if r.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// reportImport prints the import plan or result as a diff: + creates,
+// = already exists, - skipped, ! invalid or failed, ~ pending. progressPath
+// names the progress file kept to resume an unfinished import.
+func reportImport(formatter *output.Formatter, rows []importRow, dryRun bool, loc *locale.Locale, progressPath string) error {
+	report := importReport{DryRun: dryRun, Progress: progressPath, Rows: rows}
+	for _, r := range rows {
+		switch r.Action {
+		case importCreate, importCreated:
+			report.Created++
+		case importDone:
+			report.Done++
+		case importDuplicate:
+			report.Duplicates++
+		case importSkip:
+			report.Skipped++
+		case importInvalid:
+			report.Invalid++
+		case importFailed:
+			report.Failed++
+		case importPending:
+			report.Pending++
+		}
+	}
+
+	if formatter.Structured() {
+		return formatter.FormatTimerStatus(report)
+	}
+	if formatter.Quiet {
+		return nil
+	}
+
+	w := formatter.Writer
+	for _, r := range rows {
+		target := "-"
+		if r.TaskID != 0 {
+			target = r.Project + " / " + r.Task
+		}
+		when := "-"
+		switch {
+		case r.Start.IsZero():
+		case r.DateOnly:
+			when = loc.Date(r.Start)
+		default:
+			when = loc.DateTime(r.Start)
+		}
+		fmt.Fprintf(w, "%s %-9s %-9s %-16s %6s  %-30s %s\n", importMarks[r.Action], r.Action, r.Source,
+			when, formatHours(r.Duration), target, r.Description)
+		switch {
+		case r.Error != "":
+			fmt.Fprintf(w, "  %-9s %s\n", "", r.Error)
+		case r.Reason != "":
+			fmt.Fprintf(w, "  %-9s (%s)\n", "", r.Reason)
+		}
+	}
+
+	verb := "Created"
+	if dryRun {
+		verb = "Would create"
+	}
+	fmt.Fprintf(w, "\n%s %d entries (%d duplicates, %d skipped", verb, report.Created, report.Duplicates, report.Skipped)
+	for _, extra := range []struct {
+		n    int
+		what string
+	}{
+		{report.Done, "imported earlier"},
+		{report.Invalid, "invalid"},
+		{report.Failed, "failed"},
+		{report.Pending, "pending"},
+	} {
+		if extra.n > 0 {
+			fmt.Fprintf(w, ", %d %s", extra.n, extra.what)
+		}
+	}
+	fmt.Fprintln(w, ")")
+	if progressPath != "" {
+		fmt.Fprintf(w, "Progress saved to %s — run the same command again to resume.\n", progressPath)
+	}
+	return nil
+}
+
+// importProgress records the entries created by an import so that an
+// interrupted run resumes where it stopped. It is tied to the input file by
+// checksum, and removed once the import completes.
+type importProgress struct {
+	path      string
+	Source    string         `json:"source"`
+	Checksum  string         `json:"checksum"` // sha256 of the input file
+	Done      map[string]int `json:"done"`     // row source -> created entry ID
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// openImportProgress loads the progress of an earlier run on input from
+// path (default <input>.progress.json), or starts afresh.
+func openImportProgress(input, path string, restart bool) (*importProgress, error) {
+	if path == "" {
+		path = input + ".progress.json"
+	}
+	data, err := os.ReadFile(input)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", input, err)
+	}
+	sum := sha256.Sum256(data)
+	p := &importProgress{path: path, Source: input, Checksum: hex.EncodeToString(sum[:]), Done: map[string]int{}}
+
+	existing, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err), restart && err == nil:
+		return p, nil
+	case err != nil:
+		return nil, fmt.Errorf("reading progress file: %w", err)
+	}
+	var prev importProgress
+	if err := json.Unmarshal(existing, &prev); err != nil {
+		return nil, fmt.Errorf("invalid progress file %s: %w (delete it or pass --restart)", path, err)
+	}
+	if prev.Checksum != p.Checksum {
+		return nil, fmt.Errorf("%s has changed since the interrupted import recorded in %s — pass --restart to import it from the start", input, path)
+	}
+	for source, id := range prev.Done {
+		p.Done[source] = id
+	}
+	return p, nil
+}
+
+// apply marks the rows created by an earlier run as done.
+func (p *importProgress) apply(rows []importRow) {
+	for i := range rows {
+		r := &rows[i]
+		if id, ok := p.Done[r.Source]; ok && r.Action == importCreate {
+			r.Action, r.Reason, r.EntryID = importDone, "imported by an earlier run", id
+		}
+	}
+}
+
+// record saves a created row.
+func (p *importProgress) record(r importRow) error {
+	p.Done[r.Source] = r.EntryID
+	p.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding progress: %w", err)
+	}
+	tmp := p.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("writing progress file: %w", err)
+	}
+	if err := os.Rename(tmp, p.path); err != nil {
+		return fmt.Errorf("writing progress file: %w", err)
+	}
+	return nil
+}
+
+// saved reports whether the progress file exists.
+func (p *importProgress) saved() bool {
+	_, err := os.Stat(p.path)
+	return err == nil
+}
+
+// remove deletes the progress file.
+func (p *importProgress) remove() error {
+	if err := os.Remove(p.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing progress file: %w", err)
+	}
+	return nil
+}
+
+// formatHours formats seconds as H:MM.
+func formatHours(seconds int) string {
+	return fmt.Sprintf("%d:%02d", seconds/3600, (seconds/60)%60)
+}
+
+func init() {
+	timeCmd.AddCommand(importCmd)
+
+	importCmd.Flags().String("map", "", "YAML rules mapping calendar events to projects and tasks")
+	importCmd.Flags().StringArray("column", nil, "map a file column to a field, e.g. \"description=Notes\" (repeatable)")
+	importCmd.Flags().StringP("project", "p", "", "project for events no rule matches / rows without a project")
+	importCmd.Flags().StringP("task", "t", "", "task for events no rule matches / rows without a task")
+	importCmd.Flags().String("from", "", "skip entries before this date (YYYY-MM-DD, today, this-week, ...)")
+	importCmd.Flags().String("to", "", "skip entries after this date, inclusive")
+	importCmd.Flags().Bool("dry-run", false, "show what would be imported without creating entries")
+	importCmd.Flags().Bool("skip-invalid", false, "import the valid rows even if some are invalid")
+	importCmd.Flags().Int("batch-size", defaultImportBatch, "entries created between rate limit checks")
+	importCmd.Flags().String("progress", "", "progress file for resuming (default <file>.progress.json)")
+	importCmd.Flags().Bool("restart", false, "ignore the progress of an earlier run and start over")
+}
@@ -0,0 +1,382 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ComputClaw/paymo-cli/internal/api"
+	"github.com/ComputClaw/paymo-cli/internal/locale"
+)
+
+// importFields are the entry fields a CSV or JSON row can provide.
+var importFields = []string{"date", "start", "end", "duration", "project", "task", "description"}
+
+// importColumnAliases are the column names recognized for each field,
+// normalized by normalizeColumn. They cover Toggl and Harvest exports.
+var importColumnAliases = map[string][]string{
+	"date":        {"date", "start date", "spent date", "day"},
+	"start":       {"start", "start time", "started at", "from"},
+	"end":         {"end", "end time", "ended at", "stop", "to"},
+	"duration":    {"duration", "hours", "time", "time spent"},
+	"project":     {"project", "project name", "project id"},
+	"task":        {"task", "task name", "task id"},
+	"description": {"description", "notes", "note", "comment"},
+}
+
+// importRecord is one row of a CSV or JSON import file.
+type importRecord struct {
+	source string            // e.g. "line 12" or "item 3"
+	values map[string]string // by column name as written in the file
+}
+
+// importDefaults fill in fields a row leaves empty.
+type importDefaults struct {
+	Project string
+	Task    string
+}
+
+// readImportRecords reads the rows of a CSV, TSV or JSON file and returns
+// them with the column names in file order.
+func readImportRecords(path string) ([]importRecord, []string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	data = bytes.TrimPrefix(data, []byte("\uFEFF")) // byte order mark from spreadsheet exports
+
+	var records []importRecord
+	var columns []string
+	if importFileKind(path) == "json" {
+		records, columns, err = readJSONRecords(data)
+	} else {
+		records, columns, err = readCSVRecords(data, importFileKind(path) == "tsv")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(records) == 0 {
+		return nil, nil, fmt.Errorf("%s: no rows to import", path)
+	}
+	return records, columns, nil
+}
+
+// readCSVRecords reads CSV rows keyed by the header row.
+func readCSVRecords(data []byte, tabs bool) ([]importRecord, []string, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	if tabs {
+		r.Comma = '\t'
+	}
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+
+	var records []importRecord
+	for {
+		fields, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		line, _ := r.FieldPos(0)
+		rec := importRecord{source: fmt.Sprintf("line %d", line), values: map[string]string{}}
+		blank := true
+		for i, v := range fields {
+			if i < len(header) {
+				rec.values[header[i]] = strings.TrimSpace(v)
+			}
+			blank = blank && strings.TrimSpace(v) == ""
+		}
+		if !blank {
+			records = append(records, rec)
+		}
+	}
+	return records, header, nil
+}
+
+// readJSONRecords reads an array of objects, or an object holding one under
+// "entries" or "time_entries".
+func readJSONRecords(data []byte) ([]importRecord, []string, error) {
+	var items []map[string]interface{}
+	if err := json.Unmarshal(data, &items); err != nil {
+		var wrapped map[string][]map[string]interface{}
+		if json.Unmarshal(data, &wrapped) != nil {
+			return nil, nil, fmt.Errorf("expected an array of objects: %w", err)
+		}
+		items = wrapped["entries"]
+		if items == nil {
+			items = wrapped["time_entries"]
+		}
+	}
+
+	var records []importRecord
+	var columns []string
+	seen := map[string]bool{}
+	for i, item := range items {
+		rec := importRecord{source: fmt.Sprintf("item %d", i+1), values: map[string]string{}}
+		for k, v := range item {
+			rec.values[k] = jsonText(v)
+			if !seen[k] {
+				seen[k] = true
+				columns = append(columns, k)
+			}
+		}
+		records = append(records, rec)
+	}
+	return records, columns, nil
+}
+
+// jsonText converts a JSON value to the text a CSV cell would hold.
+func jsonText(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// normalizeColumn lowercases a column name and treats _ and - as spaces.
+func normalizeColumn(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.Join(strings.Fields(strings.NewReplacer("_", " ", "-", " ").Replace(name)), " ")
+}
+
+// importColumnMapping maps entry fields to file columns: --column
+// overrides ("field=Column") first, then the recognized aliases.
+func importColumnMapping(columns, overrides []string) (map[string]string, error) {
+	byName := map[string]string{}
+	for _, c := range columns {
+		byName[normalizeColumn(c)] = c
+	}
+
+	mapping := map[string]string{}
+	for _, o := range overrides {
+		field, column, ok := strings.Cut(o, "=")
+		field = strings.ToLower(strings.TrimSpace(field))
+		if !ok || field == "" || strings.TrimSpace(column) == "" {
+			return nil, fmt.Errorf("invalid --column %q (use field=Column)", o)
+		}
+		if _, known := importColumnAliases[field]; !known {
+			return nil, fmt.Errorf("unknown import field %q in --column (available: %s)", field, strings.Join(importFields, ", "))
+		}
+		actual, found := byName[normalizeColumn(column)]
+		if !found {
+			return nil, fmt.Errorf("column %q not found in file (columns: %s)", column, strings.Join(columns, ", "))
+		}
+		mapping[field] = actual
+	}
+
+	for _, field := range importFields {
+		if _, set := mapping[field]; set {
+			continue
+		}
+		for _, alias := range importColumnAliases[field] {
+			if actual, found := byName[alias]; found {
+				mapping[field] = actual
+				break
+			}
+		}
+	}
+
+	if mapping["duration"] == "" && (mapping["start"] == "" || mapping["end"] == "") {
+		return nil, fmt.Errorf("no duration column, and no start and end columns (columns: %s) — use --column to map them", strings.Join(columns, ", "))
+	}
+	return mapping, nil
+}
+
+// planRecordImport converts CSV or JSON rows to import rows. Rows that can't
+// be converted are marked invalid with the reason.
+func planRecordImport(client api.PaymoAPI, records []importRecord, mapping map[string]string, defaults importDefaults, loc *locale.Locale, from, to time.Time) []importRow {
+	resolver := newTaskResolver(client)
+	rows := make([]importRow, 0, len(records))
+	for _, rec := range records {
+		value := func(field string) string {
+			if column := mapping[field]; column != "" {
+				return rec.values[column]
+			}
+			return ""
+		}
+
+		row := importRow{Source: rec.source, Action: importCreate, Description: value("description")}
+		if err := row.setTimes(value("date"), value("start"), value("end"), value("duration"), loc); err != nil {
+			row.Action, row.Error = importInvalid, err.Error()
+			rows = append(rows, row)
+			continue
+		}
+		if outsideRange(row.Start, from, to) {
+			row.Action, row.Reason = importSkip, "outside --from/--to"
+			rows = append(rows, row)
+			continue
+		}
+
+		projectArg, taskArg := firstNonEmpty(value("project"), defaults.Project), firstNonEmpty(value("task"), defaults.Task)
+		switch {
+		case projectArg == "":
+			row.Action, row.Error = importInvalid, "no project (add a project column or pass --project)"
+		case taskArg == "":
+			row.Action, row.Error = importInvalid, "no task (add a task column or pass --task)"
+		default:
+			project, task, err := resolver.resolve(projectArg, taskArg)
+			if err != nil {
+				row.Action, row.Error = importInvalid, err.Error()
+				break
+			}
+			row.ProjectID, row.Project = project.ID, project.Name
+			row.TaskID, row.Task = task.ID, task.Name
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// setTimes sets the row's start and duration from the date, start, end and
+// duration cells. Rows with only a date and a duration are date-only.
+func (r *importRow) setTimes(date, start, end, duration string, loc *locale.Locale) error {
+	var day time.Time
+	if date != "" {
+		var err error
+		if day, err = loc.ParseDate(date); err != nil {
+			return err
+		}
+	}
+	var seconds int
+	if duration != "" {
+		d, err := parseImportDuration(duration)
+		if err != nil {
+			return err
+		}
+		seconds = int(d.Seconds())
+	}
+
+	if start == "" {
+		if day.IsZero() || duration == "" {
+			return fmt.Errorf("needs a start time, or a date and a duration")
+		}
+		r.Start, r.DateOnly, r.Duration = day, true, seconds
+		return checkImportDuration(seconds)
+	}
+
+	startAt, err := parseImportTime(start, day, loc)
+	if err != nil {
+		return fmt.Errorf("start: %w", err)
+	}
+	r.Start = startAt
+	if duration == "" {
+		if end == "" {
+			return fmt.Errorf("needs an end time or a duration")
+		}
+		endAt, err := parseImportTime(end, loc.StartOfDay(startAt), loc)
+		if err != nil {
+			return fmt.Errorf("end: %w", err)
+		}
+		if endAt.Before(startAt) && !hasDate(end) {
+			endAt = endAt.AddDate(0, 0, 1) // ends after midnight
+		}
+		seconds = int(endAt.Sub(startAt).Seconds())
+	}
+	r.Duration = seconds
+	return checkImportDuration(seconds)
+}
+
+func checkImportDuration(seconds int) error {
+	if seconds <= 0 {
+		return fmt.Errorf("duration must be positive")
+	}
+	return nil
+}
+
+// importTimestampLayouts are full timestamps accepted in start and end cells.
+var importTimestampLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+}
+
+// importClockLayouts are times of day accepted in start and end cells.
+var importClockLayouts = []string{"15:04:05", "15:04", "3:04:05 PM", "3:04 PM", "3:04:05PM", "3:04PM"}
+
+// hasDate reports whether a start or end cell holds a full timestamp.
+func hasDate(s string) bool {
+	return len(s) >= 10 && s[4] == '-' && s[7] == '-'
+}
+
+// parseImportTime parses a timestamp, or a time of day on day, in loc.
+func parseImportTime(s string, day time.Time, loc *locale.Locale) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range importTimestampLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc.Location); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range importClockLayouts {
+		t, err := time.Parse(layout, strings.ToUpper(s))
+		if err != nil {
+			continue
+		}
+		if day.IsZero() {
+			return time.Time{}, fmt.Errorf("time of day %q needs a date column", s)
+		}
+		return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), t.Second(), 0, loc.Location), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (use 09:30, 9:30 AM or YYYY-MM-DD HH:MM)", s)
+}
+
+// parseImportDuration parses H:MM, H:MM:SS, decimal hours (1.5 or 1,5) and
+// Go durations (1h30m).
+func parseImportDuration(s string) (time.Duration, error) {
+	invalid := fmt.Errorf("invalid duration %q (use 1:30, 1.5 or 1h30m)", s)
+	if parts := strings.Split(s, ":"); len(parts) == 2 || len(parts) == 3 {
+		var total time.Duration
+		units := []time.Duration{time.Hour, time.Minute, time.Second}
+		for i, p := range parts {
+			n, err := strconv.Atoi(p)
+			if err != nil || n < 0 {
+				return 0, invalid
+			}
+			total += time.Duration(n) * units[i]
+		}
+		return total, nil
+	}
+	if hours, err := strconv.ParseFloat(strings.Replace(s, ",", ".", 1), 64); err == nil {
+		return time.Duration(hours * float64(time.Hour)).Round(time.Second), nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return d, nil
+	}
+	return 0, invalid
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
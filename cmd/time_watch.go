@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ComputClaw/paymo-cli/internal/api"
+	"github.com/ComputClaw/paymo-cli/internal/config"
+	"github.com/ComputClaw/paymo-cli/internal/output"
+)
+
+var (
+	// watchInterval is how often `time status --watch` redraws.
+	watchInterval = time.Second
+	// watchRefreshInterval is how often today's entries are refetched.
+	watchRefreshInterval = time.Minute
+)
+
+// watchTick is a single sample emitted by `time status --watch`.
+type watchTick struct {
+	Time           string  `json:"time"`
+	Active         bool    `json:"active"`
+	EntryID        int     `json:"entry_id,omitempty"`
+	ProjectName    string  `json:"project_name,omitempty"`
+	TaskName       string  `json:"task_name,omitempty"`
+	ElapsedSeconds int     `json:"elapsed_seconds"`
+	Elapsed        string  `json:"elapsed,omitempty"`
+	TodaySeconds   int     `json:"today_seconds"`
+	TargetSeconds  int     `json:"target_seconds"`
+	Progress       float64 `json:"progress"`
+}
+
+// watchTimerStatus redraws the timer status every watchInterval until the
+// command is interrupted.
+func watchTimerStatus(cmd *cobra.Command) error {
+	targetFlag, _ := cmd.Flags().GetString("target")
+	target, err := time.ParseDuration(targetFlag)
+	if err != nil || target <= 0 {
+		return fmt.Errorf("invalid target: %s (use Go duration format, e.g. 8h or 7h30m)", targetFlag)
+	}
+
+	client, err := getAPIClient()
+	if err != nil {
+		return err
+	}
+
+	creds, _ := config.LoadCredentials()
+	userID := 0
+	if creds != nil {
+		userID = creds.UserID
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	formatter := newFormatter()
+	w := &statusWatcher{
+		client:    client,
+		userID:    userID,
+		target:    target,
+		formatter: formatter,
+	}
+	return w.run(ctx)
+}
+
+// statusWatcher holds the state carried between ticks of the watch loop.
+type statusWatcher struct {
+	client    api.PaymoAPI
+	userID    int
+	target    time.Duration
+	formatter *output.Formatter
+
+	loggedSeconds int
+	lastFetch     time.Time
+	lastEntryID   int
+	lastDay       time.Time
+}
+
+func (w *statusWatcher) run(ctx context.Context) error {
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.tick(w.formatter.Locale.Now()); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			if !w.formatter.Structured() {
+				fmt.Fprintln(w.formatter.Writer)
+			}
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// tick samples the timer state and renders it once.
+func (w *statusWatcher) tick(now time.Time) error {
+	state, err := config.LoadTimerState()
+	if err != nil {
+		return fmt.Errorf("loading timer state: %w", err)
+	}
+
+	// Refetch today's entries periodically, when the running entry changes
+	// (started/stopped elsewhere) and when the day rolls over in the
+	// configured timezone. A failed fetch keeps the previous total and is
+	// retried at the next refresh.
+	day := w.formatter.Locale.StartOfDay(now)
+	if w.lastFetch.IsZero() || now.Sub(w.lastFetch) >= watchRefreshInterval ||
+		state.EntryID != w.lastEntryID || !day.Equal(w.lastDay) {
+		entries, err := w.client.GetTodayEntries(w.userID, day)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "\r\033[KWarning: fetching today's entries: %v\n", err)
+		} else {
+			runningID := 0
+			if state.Active {
+				runningID = state.EntryID
+			}
+			w.loggedSeconds = todayLoggedSeconds(entries, runningID)
+		}
+		w.lastFetch = now
+		w.lastEntryID = state.EntryID
+		w.lastDay = day
+	}
+
+	t := buildWatchTick(state, w.loggedSeconds, w.target, now)
+	if w.formatter.Structured() {
+		return json.NewEncoder(w.formatter.Writer).Encode(t)
+	}
+	// Carriage return + clear line redraws in place.
+	fmt.Fprintf(w.formatter.Writer, "\r\033[K%s", formatWatchLine(t))
+	return nil
+}
+
+// todayLoggedSeconds sums the durations of today's entries, skipping the
+// running entry whose time is accounted for by the local timer.
+func todayLoggedSeconds(entries []api.TimeEntry, runningEntryID int) int {
+	total := 0
+	for _, e := range entries {
+		if runningEntryID > 0 && e.ID == runningEntryID {
+			continue
+		}
+		total += e.Duration
+	}
+	return total
+}
+
+// buildWatchTick combines the timer state with today's logged total.
+func buildWatchTick(state *config.TimerState, loggedSeconds int, target time.Duration, now time.Time) watchTick {
+	t := watchTick{
+		Time:          now.Format(time.RFC3339),
+		Active:        state.Active,
+		TodaySeconds:  loggedSeconds,
+		TargetSeconds: int(target.Seconds()),
+	}
+	if state.Active && !state.StartTime.IsZero() {
+		t.EntryID = state.EntryID
+		t.ProjectName = state.ProjectName
+		t.TaskName = state.TaskName
+		t.ElapsedSeconds = int(now.Sub(state.StartTime).Seconds())
+		t.Elapsed = config.FormatElapsed(now.Sub(state.StartTime))
+		t.TodaySeconds += t.ElapsedSeconds
+	}
+	if t.TargetSeconds > 0 {
+		t.Progress = float64(t.TodaySeconds) / float64(t.TargetSeconds)
+	}
+	return t
+}
+
+// formatWatchLine renders a tick as a single status line.
+func formatWatchLine(t watchTick) string {
+	var b strings.Builder
+	if t.Active {
+		fmt.Fprintf(&b, "● %s / %s  %s", t.ProjectName, t.TaskName, formatClock(t.ElapsedSeconds))
+	} else {
+		b.WriteString("○ No timer running")
+	}
+	fmt.Fprintf(&b, "  │  Today %s / %s %s %3.0f%%",
+		formatClock(t.TodaySeconds), formatClock(t.TargetSeconds),
+		progressBar(t.Progress, 20), t.Progress*100)
+	return b.String()
+}
+
+// formatClock formats seconds as H:MM:SS.
+func formatClock(seconds int) string {
+	return fmt.Sprintf("%d:%02d:%02d", seconds/3600, (seconds/60)%60, seconds%60)
+}
+
+// progressBar renders ratio (clamped to 0..1) as a bar of the given width.
+func progressBar(ratio float64, width int) string {
+	if ratio < 0 {
+		ratio = 0
+	}
+	if ratio > 1 {
+		ratio = 1
+	}
+	filled := int(ratio * float64(width))
+	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", width-filled) + "]"
+}
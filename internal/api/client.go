@@ -1,7 +1,9 @@
 package api
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -96,6 +98,8 @@ type APIError struct {
 	Code       string // e.g., "AUTH_FAILED", "NOT_FOUND", "RATE_LIMITED"
 	Message    string
 	Details    map[string]interface{}
+
+	retryAfter time.Duration // Retry-After of a rate-limited response
 }
 
 func (e *APIError) Error() string {
@@ -136,21 +140,107 @@ func classifyHTTPStatus(statusCode int) string {
 	}
 }
 
-// Request makes an authenticated request to the Paymo API
+// maxRateLimitRetries is how often a request answered with HTTP 429 is
+// retried after waiting for the rate limit to reset.
+const maxRateLimitRetries = 3
+
+// maxTransientRetries is how often a GET request that failed with a
+// transient error (IsTransient) is retried, transientBackoff apart.
+const (
+	maxTransientRetries = 1
+	transientBackoff    = 500 * time.Millisecond
+)
+
+// sleep is defined as a var to allow test injection.
+var sleep = time.Sleep
+
+// RateLimitStatus is the rate limit reported by the most recent response.
+// A zero Limit means no response has reported one yet.
+type RateLimitStatus struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time // when Remaining is replenished
+}
+
+// RateLimiter is implemented by clients that track the API rate limit, so
+// bulk operations can pace themselves.
+type RateLimiter interface {
+	RateLimit() RateLimitStatus
+}
+
+// RateLimit returns the rate limit reported by the most recent response.
+func (c *Client) RateLimit() RateLimitStatus {
+	c.rateMu.Lock()
+	defer c.rateMu.Unlock()
+	return RateLimitStatus{Limit: c.rateLimit, Remaining: c.rateRemaining, Reset: c.rateReset}
+}
+
+// Request makes an authenticated request to the Paymo API. Requests are
+// held back while the rate limit is exhausted, and a request answered with
+// HTTP 429 is retried after the wait the server asks for. A GET request is
+// also retried once after a transient error, unless Paymo couldn't be
+// reached at all.
 func (c *Client) Request(method, path string, body io.Reader, result interface{}) error {
+	// Buffer the body so it can be resent
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = io.ReadAll(body); err != nil {
+			return fmt.Errorf("reading request body: %w", err)
+		}
+	}
+
+	rateLimited, transient := 0, 0
+	for {
+		err := c.do(method, path, payload, body != nil, result)
+		var apiErr *APIError
+		switch {
+		case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusTooManyRequests && rateLimited < maxRateLimitRetries:
+			sleep(c.retryAfter(apiErr, rateLimited))
+			rateLimited++
+		case method == http.MethodGet && transient < maxTransientRetries && IsTransient(err) && !unreachable(err):
+			transient++
+			sleep(time.Duration(transient) * transientBackoff)
+		default:
+			return err
+		}
+	}
+}
+
+// retryAfter returns how long to wait before retrying a rate-limited request:
+// the Retry-After header when given, else until the rate limit resets.
+func (c *Client) retryAfter(apiErr *APIError, attempt int) time.Duration {
+	if apiErr.retryAfter > 0 {
+		return apiErr.retryAfter
+	}
+	c.rateMu.Lock()
+	reset := c.rateReset
+	c.rateMu.Unlock()
+	if wait := time.Until(reset); wait > 0 {
+		return wait
+	}
+	return time.Duration(attempt+1) * time.Second
+}
+
+// do performs a single request.
+func (c *Client) do(method, path string, payload []byte, hasBody bool, result interface{}) error {
 	// Check rate limiting
 	c.rateMu.Lock()
 	if c.rateRemaining == 0 && time.Now().Before(c.rateReset) {
 		waitTime := time.Until(c.rateReset)
 		c.rateMu.Unlock()
-		time.Sleep(waitTime)
+		sleep(waitTime)
 		c.rateMu.Lock()
 	}
 	c.rateMu.Unlock()
 
 	// Build URL
 	reqURL := fmt.Sprintf("%s/%s", c.BaseURL, strings.TrimPrefix(path, "/"))
-	
+
+	var body io.Reader
+	if hasBody {
+		body = bytes.NewReader(payload)
+	}
 	req, err := http.NewRequest(method, reqURL, body)
 	if err != nil {
 		return fmt.Errorf("creating request: %w", err)
@@ -158,7 +248,7 @@ func (c *Client) Request(method, path string, body io.Reader, result interface{}
 
 	// Set headers
 	req.Header.Set("Accept", "application/json")
-	if body != nil {
+	if hasBody {
 		req.Header.Set("Content-Type", "application/json")
 	}
 
@@ -189,6 +279,9 @@ func (c *Client) Request(method, path string, body io.Reader, result interface{}
 	if resp.StatusCode >= 400 {
 		apiErr := &APIError{StatusCode: resp.StatusCode}
 		apiErr.Code = classifyHTTPStatus(resp.StatusCode)
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+			apiErr.retryAfter = time.Duration(secs) * time.Second
+		}
 
 		// Try to parse error message
 		var errResp map[string]interface{}
@@ -247,6 +340,15 @@ func (c *Client) GetWithParams(path string, params url.Values, result interface{
 	return c.Get(path, result)
 }
 
+// updatedSinceClause returns the where clause selecting records changed at
+// or after t, or "" for a zero t.
+func updatedSinceClause(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("updated_on>=\"%s\"", t.UTC().Format("2006-01-02T15:04:05Z"))
+}
+
 // Post makes a POST request
 func (c *Client) Post(path string, body interface{}, result interface{}) error {
 	var bodyReader io.Reader
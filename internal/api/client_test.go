@@ -2,9 +2,15 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/ComputClaw/paymo-cli/internal/mockserver"
 )
 
 func TestNewClient(t *testing.T) {
@@ -210,4 +216,247 @@ func TestClient_RateLimitHeaders(t *testing.T) {
 	if client.rateRemaining != 99 {
 		t.Errorf("expected rate remaining 99, got %d", client.rateRemaining)
 	}
-}
\ No newline at end of file
+}
+func TestClient_RateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Ratelimit-Limit", "5")
+		w.Header().Set("X-Ratelimit-Remaining", "2")
+		w.Header().Set("X-Ratelimit-Decay-Period", "10")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL(server.URL, &APIKeyAuth{APIKey: "test-key"})
+	if status := client.RateLimit(); status.Limit != 0 {
+		t.Errorf("expected unknown rate limit before any request, got %+v", status)
+	}
+	if err := client.Get("test", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	status := client.RateLimit()
+	if status.Limit != 5 || status.Remaining != 2 || time.Until(status.Reset) <= 0 {
+		t.Errorf("unexpected rate limit: %+v", status)
+	}
+}
+
+func TestClient_RetriesRateLimited(t *testing.T) {
+	var waits []time.Duration
+	orig := sleep
+	sleep = func(d time.Duration) { waits = append(waits, d) }
+	defer func() { sleep = orig }()
+
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		if len(bodies) < 3 {
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"message":"Rate limit exceeded"}`))
+			return
+		}
+		w.Write([]byte(`{"entries":[{"id":7}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL(server.URL, &APIKeyAuth{APIKey: "test-key"})
+	entry, err := client.CreateEntry(&CreateTimeEntryRequest{TaskID: 1, Description: "retry"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entry.ID != 7 || len(bodies) != 3 {
+		t.Fatalf("expected success on the third attempt, got entry %d after %d", entry.ID, len(bodies))
+	}
+	if bodies[2] != bodies[0] || !strings.Contains(bodies[2], `"retry"`) {
+		t.Errorf("expected the body to be resent, got %q", bodies)
+	}
+	if len(waits) != 2 || waits[0] != 2*time.Second {
+		t.Errorf("expected two 2s waits, got %v", waits)
+	}
+}
+
+func TestClient_RateLimitedGivesUp(t *testing.T) {
+	orig := sleep
+	sleep = func(time.Duration) {}
+	defer func() { sleep = orig }()
+
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL(server.URL, &APIKeyAuth{APIKey: "test-key"})
+	err := client.Get("test", nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != "RATE_LIMITED" {
+		t.Fatalf("expected rate limit error, got %v", err)
+	}
+	if calls != maxRateLimitRetries+1 {
+		t.Errorf("expected %d attempts, got %d", maxRateLimitRetries+1, calls)
+	}
+}
+
+func TestClient_RetriesTransientGet(t *testing.T) {
+	var waits []time.Duration
+	orig := sleep
+	sleep = func(d time.Duration) { waits = append(waits, d) }
+	defer func() { sleep = orig }()
+
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL(server.URL, &APIKeyAuth{APIKey: "test-key"})
+	if err := client.Get("test", nil); err != nil {
+		t.Fatalf("expected the retry to succeed, got %v", err)
+	}
+	if calls != 2 || len(waits) != 1 || waits[0] != transientBackoff {
+		t.Errorf("expected one retry after %v, got %d calls and waits %v", transientBackoff, calls, waits)
+	}
+}
+
+func TestClient_TransientNotRetried(t *testing.T) {
+	retries := 0
+	orig := sleep
+	sleep = func(time.Duration) { retries++ }
+	defer func() { sleep = orig }()
+
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	client := NewClientWithBaseURL(server.URL, &APIKeyAuth{APIKey: "test-key"})
+
+	// Writes aren't retried: the first attempt may have gone through
+	err := client.Post("test", map[string]string{}, nil)
+	if !IsTransient(err) || calls != 1 {
+		t.Errorf("expected one attempt and a transient error, got %d attempts and %v", calls, err)
+	}
+
+	// Nor are requests to a server that can't be reached
+	server.Close()
+	if err := client.Get("test", nil); !IsTransient(err) || !unreachable(err) {
+		t.Errorf("expected an unreachable error, got %v", err)
+	}
+	if retries != 0 {
+		t.Errorf("expected no retries, got %d", retries)
+	}
+}
+
+func TestClient_CertificateErrorFinal(t *testing.T) {
+	retries := 0
+	orig := sleep
+	sleep = func(time.Duration) { retries++ }
+	defer func() { sleep = orig }()
+
+	// A certificate the client doesn't trust, like an intercepting proxy's
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL(server.URL, &APIKeyAuth{APIKey: "test-key"})
+	err := client.Get("test", nil)
+	if err == nil || IsTransient(err) {
+		t.Errorf("expected a final certificate error, got %v", err)
+	}
+	if retries != 0 {
+		t.Errorf("expected no retries, got %d", retries)
+	}
+}
+
+// newMockServerClient returns a client talking to a fresh mock Paymo
+// server over HTTP.
+func newMockServerClient(t *testing.T) (*Client, *mockserver.Server) {
+	t.Helper()
+	srv := mockserver.New()
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+	return NewClientWithBaseURL(ts.URL+mockserver.BasePath, &APIKeyAuth{APIKey: "test-key"}), srv
+}
+
+func TestClient_MockServer_Auth(t *testing.T) {
+	client, srv := newMockServerClient(t)
+	srv.Add("users", User{Name: "Dana", Email: "dana@example.com", Active: true})
+
+	user, err := client.GetMe()
+	if err != nil || user.Name != "Dana" || user.ID != 1 {
+		t.Fatalf("GetMe = %+v, %v", user, err)
+	}
+
+	srv.SetAPIKey("other-key")
+	err = client.ValidateAuth()
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != "AUTH_FAILED" || apiErr.ExitCode() != 3 {
+		t.Errorf("expected AUTH_FAILED with the wrong key, got %v", err)
+	}
+}
+
+func TestClient_MockServer_RateLimit(t *testing.T) {
+	var waits []time.Duration
+	orig := sleep
+	sleep = func(d time.Duration) { waits = append(waits, d) }
+	defer func() { sleep = orig }()
+
+	client, srv := newMockServerClient(t)
+	srv.SetRateLimit(1, time.Minute)
+
+	if _, err := client.GetClients(); err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+	if status := client.RateLimit(); status.Limit != 1 || status.Remaining != 0 || time.Until(status.Reset) <= 0 {
+		t.Errorf("rate limit after the first request = %+v", status)
+	}
+
+	_, err := client.GetClients()
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != "RATE_LIMITED" {
+		t.Fatalf("expected RATE_LIMITED once the limit is used up, got %v", err)
+	}
+	// One wait for the exhausted limit before each attempt, one per retry
+	if len(waits) != 2*(maxRateLimitRetries+1)-1 {
+		t.Errorf("waits = %v", waits)
+	}
+}
+
+func TestClient_MockServer_Failures(t *testing.T) {
+	orig := sleep
+	sleep = func(time.Duration) {}
+	defer func() { sleep = orig }()
+
+	client, srv := newMockServerClient(t)
+	srv.Add("clients", PaymoClient{Name: "Acme", Active: true})
+
+	// A transient failure of a read is retried
+	srv.Fail(mockserver.Failure{Path: "clients", Status: http.StatusServiceUnavailable, Times: 1})
+	if clients, err := client.GetClients(); err != nil || len(clients) != 1 {
+		t.Errorf("expected the retry to succeed, got %v, %v", clients, err)
+	}
+	if n := len(srv.Requests()); n != 2 {
+		t.Errorf("expected 2 requests, got %d", n)
+	}
+
+	// A dropped connection is transient too
+	srv.Fail(mockserver.Failure{Path: "clients", Drop: true})
+	if _, err := client.GetClients(); !IsTransient(err) {
+		t.Errorf("expected a transient error, got %v", err)
+	}
+	srv.ClearFailures()
+
+	srv.Fail(mockserver.Failure{Method: "POST", Path: "projects", Status: http.StatusBadRequest, Message: "name taken"})
+	_, err := client.CreateProject(&CreateProjectRequest{Name: "Website"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != "USAGE_ERROR" || apiErr.Message != "name taken" {
+		t.Errorf("expected the injected error, got %v", err)
+	}
+}
@@ -29,7 +29,7 @@ func (c *Client) GetEntries(opts *EntryListOptions) ([]TimeEntry, error) {
 			}
 		}
 		if !opts.StartDate.IsZero() {
-			dateStr := opts.StartDate.Format("2006-01-02T15:04:05Z")
+			dateStr := opts.StartDate.UTC().Format("2006-01-02T15:04:05Z")
 			if params.Get("where") != "" {
 				params.Set("where", params.Get("where")+fmt.Sprintf(" and start_time>=\"%s\"", dateStr))
 			} else {
@@ -37,11 +37,18 @@ func (c *Client) GetEntries(opts *EntryListOptions) ([]TimeEntry, error) {
 			}
 		}
 		if !opts.EndDate.IsZero() {
-			dateStr := opts.EndDate.Format("2006-01-02T15:04:05Z")
+			dateStr := opts.EndDate.UTC().Format("2006-01-02T15:04:05Z")
 			if params.Get("where") != "" {
-				params.Set("where", params.Get("where")+fmt.Sprintf(" and start_time<=\"%s\"", dateStr))
+				params.Set("where", params.Get("where")+fmt.Sprintf(" and start_time<\"%s\"", dateStr))
 			} else {
-				params.Set("where", fmt.Sprintf("start_time<=\"%s\"", dateStr))
+				params.Set("where", fmt.Sprintf("start_time<\"%s\"", dateStr))
+			}
+		}
+		if clause := updatedSinceClause(opts.UpdatedSince); clause != "" {
+			if params.Get("where") != "" {
+				params.Set("where", params.Get("where")+" and "+clause)
+			} else {
+				params.Set("where", clause)
 			}
 		}
 		if opts.IncludeTask {
@@ -69,10 +76,11 @@ type EntryListOptions struct {
 	UserID         int
 	ProjectID      int
 	TaskID         int
-	StartDate      time.Time
-	EndDate        time.Time
+	StartDate      time.Time // entries starting at or after this time
+	EndDate        time.Time // entries starting before this time
 	IncludeTask    bool
 	IncludeProject bool
+	UpdatedSince   time.Time // only entries changed at or after this time
 }
 
 // GetEntry returns a single time entry by ID
@@ -122,16 +130,13 @@ func (c *Client) DeleteEntry(id int) error {
 	return c.Delete(fmt.Sprintf("entries/%d", id))
 }
 
-// GetTodayEntries returns entries for today
-func (c *Client) GetTodayEntries(userID int) ([]TimeEntry, error) {
-	now := time.Now()
-	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-	endOfDay := startOfDay.Add(24 * time.Hour)
-	
+// GetTodayEntries returns the entries of the day starting at today, midnight
+// in the user's timezone (locale.Today), not the system's
+func (c *Client) GetTodayEntries(userID int, today time.Time) ([]TimeEntry, error) {
 	return c.GetEntries(&EntryListOptions{
 		UserID:         userID,
-		StartDate:      startOfDay,
-		EndDate:        endOfDay,
+		StartDate:      today,
+		EndDate:        today.AddDate(0, 0, 1),
 		IncludeTask:    true,
 		IncludeProject: true,
 	})
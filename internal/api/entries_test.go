@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -86,6 +87,76 @@ func TestClient_GetEntries_WithFilters(t *testing.T) {
 	}
 }
 
+func TestClient_GetEntries_DateRange(t *testing.T) {
+	var where string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		where = r.URL.Query().Get("where")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(TimeEntriesResponse{})
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL(server.URL, &APIKeyAuth{APIKey: "test"})
+	_, err := client.GetEntries(&EntryListOptions{
+		StartDate: time.Date(2026, 2, 2, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2026, 2, 9, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The end is exclusive, so an entry starting at the next Monday's
+	// midnight isn't counted in the week before
+	want := `start_time>="2026-02-02T00:00:00Z" and start_time<"2026-02-09T00:00:00Z"`
+	if where != want {
+		t.Errorf("expected where %q, got %q", want, where)
+	}
+}
+
+func TestClient_GetTodayEntries_Day(t *testing.T) {
+	var where string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		where = r.URL.Query().Get("where")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(TimeEntriesResponse{})
+	}))
+	defer server.Close()
+
+	// Midnight in the user's timezone, whatever the system's is
+	tokyo := time.FixedZone("JST", 9*3600)
+	client := NewClientWithBaseURL(server.URL, &APIKeyAuth{APIKey: "test"})
+	if _, err := client.GetTodayEntries(7, time.Date(2026, 2, 9, 0, 0, 0, 0, tokyo)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `user_id=7 and start_time>="2026-02-08T15:00:00Z" and start_time<"2026-02-09T15:00:00Z"`
+	if where != want {
+		t.Errorf("expected where %q, got %q", want, where)
+	}
+}
+
+func TestClient_GetEntries_UpdatedSince(t *testing.T) {
+	var where string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		where = r.URL.Query().Get("where")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(TimeEntriesResponse{})
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL(server.URL, &APIKeyAuth{APIKey: "test"})
+	_, err := client.GetEntries(&EntryListOptions{
+		UserID:       7,
+		StartDate:    time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+		UpdatedSince: time.Date(2026, 2, 9, 9, 30, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `user_id=7 and start_time>="2026-02-01T00:00:00Z" and updated_on>="2026-02-09T09:30:00Z"`
+	if where != want {
+		t.Errorf("expected where %q, got %q", want, where)
+	}
+}
+
 func TestClient_CreateEntry(t *testing.T) {
 	var receivedBody CreateTimeEntryRequest
 
@@ -219,7 +290,7 @@ func TestClient_StopEntry(t *testing.T) {
 func TestClient_GetActiveEntry(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		query := r.URL.Query().Get("where")
-		
+
 		if !strings.Contains(query, "end_time=\"\"") {
 			t.Errorf("expected end_time filter, got: %s", query)
 		}
@@ -272,4 +343,59 @@ func TestClient_GetActiveEntry_NoActive(t *testing.T) {
 	if entry != nil {
 		t.Error("expected nil entry when no active timer")
 	}
-}
\ No newline at end of file
+}
+
+func TestClient_Entries_MockServer(t *testing.T) {
+	client, srv := newMockServerClient(t)
+	me := srv.Add("users", User{Name: "Dana", Active: true})
+	project := srv.Add("projects", Project{Name: "Website", Active: true})
+	task := srv.Add("tasks", Task{Name: "Design", ProjectID: project})
+	other := srv.Add("tasks", Task{Name: "Review", ProjectID: project})
+	day := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	srv.Add("entries", TimeEntry{TaskID: task, UserID: me, StartTime: day, EndTime: day.Add(time.Hour), Duration: 3600})
+	srv.Add("entries", TimeEntry{TaskID: other, UserID: me, StartTime: day.AddDate(0, 0, 1), EndTime: day.AddDate(0, 0, 1).Add(time.Hour), Duration: 3600})
+
+	entries, err := client.GetEntries(&EntryListOptions{UserID: me, StartDate: day.Add(-time.Hour), EndDate: day.Add(time.Hour), IncludeTask: true})
+	if err != nil || len(entries) != 1 || entries[0].Task == nil || entries[0].Task.Name != "Design" {
+		t.Fatalf("GetEntries by date = %+v, %v", entries, err)
+	}
+	if entries, err := client.GetEntries(&EntryListOptions{StartDate: day.Add(-time.Hour), EndDate: day.AddDate(0, 0, 1)}); err != nil || len(entries) != 1 {
+		t.Errorf("GetEntries up to the next entry's start = %+v, %v", entries, err)
+	}
+	if entries, err := client.GetEntries(&EntryListOptions{TaskID: other}); err != nil || len(entries) != 1 {
+		t.Errorf("GetEntries by task = %+v, %v", entries, err)
+	}
+
+	if active, err := client.GetActiveEntry(me); err != nil || active != nil {
+		t.Fatalf("GetActiveEntry with no timer = %+v, %v", active, err)
+	}
+	started, err := client.StartEntry(task, "Sketching")
+	if err != nil || started.UserID != me || !started.EndTime.IsZero() {
+		t.Fatalf("StartEntry = %+v, %v", started, err)
+	}
+	active, err := client.GetActiveEntry(me)
+	if err != nil || active == nil || active.ID != started.ID {
+		t.Fatalf("GetActiveEntry = %+v, %v", active, err)
+	}
+	stopped, err := client.StopEntry(started.ID)
+	if err != nil || stopped.EndTime.IsZero() {
+		t.Fatalf("StopEntry = %+v, %v", stopped, err)
+	}
+	if active, _ := client.GetActiveEntry(me); active != nil {
+		t.Errorf("expected no running timer after StopEntry, got %+v", active)
+	}
+
+	description := "Reviewed"
+	updated, err := client.UpdateEntry(started.ID, &UpdateTimeEntryRequest{Description: &description})
+	if err != nil || updated.Description != "Reviewed" {
+		t.Errorf("UpdateEntry = %+v, %v", updated, err)
+	}
+	if err := client.DeleteEntry(started.ID); err != nil {
+		t.Fatalf("DeleteEntry: %v", err)
+	}
+	_, err = client.GetEntry(started.ID)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != "NOT_FOUND" {
+		t.Errorf("expected NOT_FOUND for a deleted entry, got %v", err)
+	}
+}
@@ -1,5 +1,7 @@
 package api
 
+import "time"
+
 // PaymoAPI defines the contract for all Paymo API operations.
 // Both the raw Client and the cached wrapper implement this interface.
 type PaymoAPI interface {
@@ -31,7 +33,7 @@ type PaymoAPI interface {
 	CreateEntry(req *CreateTimeEntryRequest) (*TimeEntry, error)
 	UpdateEntry(id int, req *UpdateTimeEntryRequest) (*TimeEntry, error)
 	DeleteEntry(id int) error
-	GetTodayEntries(userID int) ([]TimeEntry, error)
+	GetTodayEntries(userID int, today time.Time) ([]TimeEntry, error)
 	GetActiveEntry(userID int) (*TimeEntry, error)
 	StartEntry(taskID int, description string) (*TimeEntry, error)
 	StopEntry(id int) (*TimeEntry, error)
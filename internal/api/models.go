@@ -132,8 +132,9 @@ type TimeEntryResponse struct {
 // CreateTimeEntryRequest is the request body for creating a time entry
 type CreateTimeEntryRequest struct {
 	TaskID      int    `json:"task_id"`
-	StartTime   string `json:"start_time"`
+	StartTime   string `json:"start_time,omitempty"`
 	EndTime     string `json:"end_time,omitempty"`
+	Date        string `json:"date,omitempty"` // YYYY-MM-DD, with Duration instead of start/end times
 	Duration    int    `json:"duration,omitempty"`
 	Description string `json:"description,omitempty"`
 }
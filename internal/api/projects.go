@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"time"
 )
 
 // GetProjects returns all projects with optional filtering
@@ -30,6 +31,13 @@ func (c *Client) GetProjects(opts *ProjectListOptions) ([]Project, error) {
 				whereClause = fmt.Sprintf("users in (%d)", opts.UserID)
 			}
 		}
+		if clause := updatedSinceClause(opts.UpdatedSince); clause != "" {
+			if whereClause != "" {
+				whereClause += " and " + clause
+			} else {
+				whereClause = clause
+			}
+		}
 		
 		if whereClause != "" {
 			params.Set("where", whereClause)
@@ -62,6 +70,7 @@ type ProjectListOptions struct {
 	UserID        int
 	IncludeTasks  bool
 	IncludeClient bool
+	UpdatedSince  time.Time // only projects changed at or after this time
 }
 
 // GetProject returns a single project by ID
@@ -6,6 +6,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestClient_GetProjects(t *testing.T) {
@@ -62,6 +63,28 @@ func TestClient_GetProjects_ActiveOnly(t *testing.T) {
 	}
 }
 
+func TestClient_GetProjects_UpdatedSince(t *testing.T) {
+	var where string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		where = r.URL.Query().Get("where")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ProjectsResponse{Projects: []Project{}})
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL(server.URL, &APIKeyAuth{APIKey: "test"})
+	since := time.Date(2026, 2, 9, 10, 30, 0, 0, time.FixedZone("CET", 3600))
+	if _, err := client.GetProjects(&ProjectListOptions{ActiveOnly: true, UpdatedSince: since}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `active=true and updated_on>="2026-02-09T09:30:00Z"`
+	if where != want {
+		t.Errorf("where = %q, want %q", where, want)
+	}
+}
+
 func TestClient_GetProject(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if !strings.Contains(r.URL.Path, "/projects/123") {
@@ -96,7 +119,7 @@ func TestClient_GetProject(t *testing.T) {
 func TestClient_GetProjectByName(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		query := r.URL.Query().Get("where")
-		
+
 		if !strings.Contains(query, "name like") {
 			t.Errorf("expected name filter, got: %s", query)
 		}
@@ -220,4 +243,52 @@ func TestClient_ArchiveProject(t *testing.T) {
 	if receivedActive != false {
 		t.Error("expected active=false in request body")
 	}
-}
\ No newline at end of file
+}
+
+func TestClient_Projects_MockServer(t *testing.T) {
+	client, srv := newMockServerClient(t)
+	acme := srv.Add("clients", PaymoClient{Name: "Acme", Active: true})
+	srv.Add("projects", Project{Name: "Website Redesign", ClientID: acme, Active: true, Users: []int{7}})
+	srv.Add("projects", Project{Name: "Mobile App", Active: true})
+	srv.Add("projects", Project{Name: "Old Website", ClientID: acme})
+
+	for _, tc := range []struct {
+		opts *ProjectListOptions
+		want int
+	}{
+		{nil, 3},
+		{&ProjectListOptions{ActiveOnly: true}, 2},
+		{&ProjectListOptions{ClientID: acme}, 2},
+		{&ProjectListOptions{ActiveOnly: true, ClientID: acme}, 1},
+		{&ProjectListOptions{UserID: 7}, 1},
+		{&ProjectListOptions{UpdatedSince: time.Now().Add(time.Hour)}, 0},
+	} {
+		projects, err := client.GetProjects(tc.opts)
+		if err != nil || len(projects) != tc.want {
+			t.Errorf("GetProjects(%+v) = %d projects, %v; want %d", tc.opts, len(projects), err, tc.want)
+		}
+	}
+
+	project, err := client.GetProjectByName("website")
+	if err != nil || project.Name != "Website Redesign" {
+		t.Errorf("GetProjectByName = %+v, %v", project, err)
+	}
+	if _, err := client.GetProjectByName("nothing"); err == nil {
+		t.Error("expected an error for an unknown name")
+	}
+
+	created, err := client.CreateProject(&CreateProjectRequest{Name: "Intranet", ClientID: &acme, Billable: true})
+	if err != nil || created.ID != 4 || !created.Active || created.ClientID != acme {
+		t.Fatalf("CreateProject = %+v, %v", created, err)
+	}
+	if err := client.ArchiveProject(created.ID); err != nil {
+		t.Fatalf("ArchiveProject: %v", err)
+	}
+	project, err = client.GetProject(created.ID)
+	if err != nil || project.Active {
+		t.Errorf("archived project = %+v, %v", project, err)
+	}
+	if _, err := client.GetProject(99); err == nil {
+		t.Error("expected an error for an unknown project")
+	}
+}
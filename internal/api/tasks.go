@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"time"
 )
 
 // GetTasks returns tasks with optional filtering
@@ -37,6 +38,13 @@ func (c *Client) GetTasks(opts *TaskListOptions) ([]Task, error) {
 				whereClause = fmt.Sprintf("users in (%d)", opts.UserID)
 			}
 		}
+		if clause := updatedSinceClause(opts.UpdatedSince); clause != "" {
+			if whereClause != "" {
+				whereClause += " and " + clause
+			} else {
+				whereClause = clause
+			}
+		}
 		
 		if whereClause != "" {
 			params.Set("where", whereClause)
@@ -62,6 +70,7 @@ type TaskListOptions struct {
 	UserID           int
 	IncludeCompleted bool
 	IncludeProject   bool
+	UpdatedSince     time.Time // only tasks changed at or after this time
 }
 
 // GetTask returns a single task by ID
@@ -121,10 +130,13 @@ func (c *Client) CompleteTask(id int) error {
 	return c.Put(fmt.Sprintf("tasks/%d", id), &completeReq{Complete: true}, nil)
 }
 
-// GetTaskLists returns task lists for a project
+// GetTaskLists returns task lists for a project, or every task list when
+// projectID is 0
 func (c *Client) GetTaskLists(projectID int) ([]TaskList, error) {
 	params := url.Values{}
-	params.Set("where", fmt.Sprintf("project_id=%d", projectID))
+	if projectID > 0 {
+		params.Set("where", fmt.Sprintf("project_id=%d", projectID))
+	}
 	
 	var resp struct {
 		TaskLists []TaskList `json:"tasklists"`
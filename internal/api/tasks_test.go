@@ -351,3 +351,78 @@ func TestClient_GetTaskLists(t *testing.T) {
 		t.Errorf("expected name 'To Do', got '%s'", lists[0].Name)
 	}
 }
+
+func TestClient_GetTaskLists_AllProjects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if where := r.URL.Query().Get("where"); where != "" {
+			t.Errorf("expected no filter, got: %s", where)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"tasklists":[{"id":1,"project_id":10},{"id":2,"project_id":20}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL(server.URL, &APIKeyAuth{APIKey: "test"})
+	lists, err := client.GetTaskLists(0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(lists) != 2 {
+		t.Errorf("expected 2 task lists, got %d", len(lists))
+	}
+}
+
+func TestClient_Tasks_MockServer(t *testing.T) {
+	client, srv := newMockServerClient(t)
+	website := srv.Add("projects", Project{Name: "Website", Active: true})
+	app := srv.Add("projects", Project{Name: "App", Active: true})
+	design := srv.Add("tasklists", TaskList{Name: "Design", ProjectID: website})
+	srv.Add("tasklists", TaskList{Name: "Backlog", ProjectID: app})
+	srv.Add("tasks", Task{Name: "Wireframes", ProjectID: website, TaskListID: design, Users: []int{7}})
+	srv.Add("tasks", Task{Name: "Visual Design", ProjectID: website, TaskListID: design, Complete: true})
+	srv.Add("tasks", Task{Name: "Login Screen", ProjectID: app})
+
+	for _, tc := range []struct {
+		opts *TaskListOptions
+		want int
+	}{
+		{nil, 3},
+		{&TaskListOptions{}, 2},
+		{&TaskListOptions{ProjectID: website}, 1},
+		{&TaskListOptions{ProjectID: website, IncludeCompleted: true}, 2},
+		{&TaskListOptions{TaskListID: design, IncludeCompleted: true}, 2},
+		{&TaskListOptions{UserID: 7}, 1},
+	} {
+		tasks, err := client.GetTasks(tc.opts)
+		if err != nil || len(tasks) != tc.want {
+			t.Errorf("GetTasks(%+v) = %d tasks, %v; want %d", tc.opts, len(tasks), err, tc.want)
+		}
+	}
+
+	task, err := client.GetTaskByName(website, "design")
+	if err != nil || task.Name != "Visual Design" {
+		t.Errorf("GetTaskByName = %+v, %v", task, err)
+	}
+	if _, err := client.GetTaskByName(app, "design"); err == nil {
+		t.Error("expected no match in another project")
+	}
+
+	created, err := client.CreateTask(&CreateTaskRequest{Name: "Icons", ProjectID: app})
+	if err != nil || created.TaskListID == 0 || created.Complete {
+		t.Fatalf("CreateTask = %+v, %v", created, err)
+	}
+	if err := client.CompleteTask(created.ID); err != nil {
+		t.Fatalf("CompleteTask: %v", err)
+	}
+	if task, err := client.GetTask(created.ID); err != nil || !task.Complete {
+		t.Errorf("completed task = %+v, %v", task, err)
+	}
+
+	lists, err := client.GetTaskLists(website)
+	if err != nil || len(lists) != 1 || lists[0].Name != "Design" {
+		t.Errorf("GetTaskLists = %+v, %v", lists, err)
+	}
+	if lists, err := client.GetTaskLists(0); err != nil || len(lists) != 2 {
+		t.Errorf("GetTaskLists(0) = %+v, %v", lists, err)
+	}
+}
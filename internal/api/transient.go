@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+)
+
+// IsTransient reports whether err is a failure that may go away by itself:
+// Paymo or the network being unreachable, a timeout, a dropped connection,
+// or a 502, 503 or 504 response. Callers retry on it or fall back to cached
+// data; any other error, such as a 4xx response, is final.
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || isTimeout(err) {
+		return true
+	}
+	// A certificate that fails verification, from an intercepting proxy or
+	// a misconfigured server, is final: the user has to see it rather than
+	// cached data
+	if isCertificateError(err) {
+		return false
+	}
+
+	var dnsErr *net.DNSError
+	var opErr *net.OpError
+	if errors.As(err, &dnsErr) || errors.As(err, &opErr) {
+		return true
+	}
+	// TLS record errors mostly come from captive portals standing in for
+	// Paymo until the network is signed in to
+	var recordErr tls.RecordHeaderError
+	if errors.As(err, &recordErr) {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ENETUNREACH) ||
+		errors.Is(err, syscall.EHOSTUNREACH) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		isDroppedConnection(err)
+}
+
+// isCertificateError reports whether err is a failed verification of the
+// server's TLS certificate.
+func isCertificateError(err error) bool {
+	var certErr *tls.CertificateVerificationError
+	var authorityErr x509.UnknownAuthorityError
+	var invalidErr x509.CertificateInvalidError
+	var hostnameErr x509.HostnameError
+	return errors.As(err, &certErr) || errors.As(err, &authorityErr) ||
+		errors.As(err, &invalidErr) || errors.As(err, &hostnameErr)
+}
+
+// isTimeout reports whether err is a network timeout.
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// isDroppedConnection reports whether the server closed the connection
+// before answering, which net/http reports as a *url.Error wrapping io.EOF.
+func isDroppedConnection(err error) bool {
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) && errors.Is(urlErr.Err, io.EOF)
+}
+
+// unreachable reports whether a transient error means Paymo couldn't be
+// reached or didn't answer in time. Such requests aren't retried: the
+// network is likely down, and retrying would only delay the fallback to
+// cached data.
+func unreachable(err error) bool {
+	var dnsErr *net.DNSError
+	var opErr *net.OpError
+	if errors.As(err, &dnsErr) || (errors.As(err, &opErr) && opErr.Op == "dial") {
+		return true
+	}
+	return errors.Is(err, context.DeadlineExceeded) || isTimeout(err) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ENETUNREACH) ||
+		errors.Is(err, syscall.EHOSTUNREACH)
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"syscall"
+	"testing"
+)
+
+// timeoutError is a net.Error that timed out.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestIsTransient(t *testing.T) {
+	dial := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"connection refused", &url.Error{Op: "Get", URL: "https://app.paymoapp.com/api/me", Err: dial}, true},
+		{"wrapped", fmt.Errorf("executing request: %w", dial), true},
+		{"dns", &net.DNSError{Err: "no such host", Name: "app.paymoapp.com", IsNotFound: true}, true},
+		{"timeout", &url.Error{Op: "Get", URL: "https://x", Err: timeoutError{}}, true},
+		{"deadline", fmt.Errorf("request: %w", context.DeadlineExceeded), true},
+		{"reset", fmt.Errorf("read: %w", syscall.ECONNRESET), true},
+		{"dropped connection", &url.Error{Op: "Get", URL: "https://x", Err: io.EOF}, true},
+		{"tls record", &url.Error{Op: "Get", URL: "https://x", Err: tls.RecordHeaderError{Msg: "not TLS"}}, true},
+		{"certificate", &url.Error{Op: "Get", URL: "https://x", Err: &tls.CertificateVerificationError{Err: errors.New("unknown authority")}}, false},
+		{"unknown authority", &url.Error{Op: "Get", URL: "https://x", Err: x509.UnknownAuthorityError{}}, false},
+		{"hostname", &url.Error{Op: "Get", URL: "https://x", Err: x509.HostnameError{Host: "x"}}, false},
+		{"certificate in op error", &net.OpError{Op: "remote error", Err: x509.CertificateInvalidError{Reason: x509.Expired}}, false},
+		{"bad gateway", &APIError{StatusCode: 502}, true},
+		{"unavailable", &APIError{StatusCode: 503}, true},
+		{"gateway timeout", fmt.Errorf("listing: %w", &APIError{StatusCode: 504}), true},
+		{"server error", &APIError{StatusCode: 500}, false},
+		{"not found", &APIError{StatusCode: 404}, false},
+		{"rate limited", &APIError{StatusCode: 429}, false},
+		{"canceled", context.Canceled, false},
+		{"bad scheme", &url.Error{Op: "Get", URL: "ftp://x", Err: errors.New("unsupported protocol scheme")}, false},
+		{"error text only", errors.New("dial tcp: connection refused"), false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := IsTransient(tc.err); got != tc.want {
+				t.Errorf("IsTransient(%v) = %v, want %v", tc.err, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestUnreachable(t *testing.T) {
+	if !unreachable(&net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}) {
+		t.Error("a failed dial should count as unreachable")
+	}
+	if !unreachable(&url.Error{Op: "Get", URL: "https://x", Err: timeoutError{}}) {
+		t.Error("a timeout should count as unreachable")
+	}
+	if unreachable(&APIError{StatusCode: 503}) || unreachable(fmt.Errorf("read: %w", syscall.ECONNRESET)) {
+		t.Error("a 503 or reset connection reached Paymo")
+	}
+}
@@ -0,0 +1,189 @@
+package cache
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// errReadOnly is returned for writes in a View transaction.
+var errReadOnly = errors.New("write in a read-only cache transaction")
+
+// Backend is the storage under a Store: named buckets of key/value pairs,
+// read and written in transactions. The default backend is a bbolt file
+// (OpenBolt); NewMemoryBackend keeps everything in memory.
+type Backend interface {
+	// View runs fn in a read-only transaction.
+	View(fn func(tx Tx) error) error
+	// Update runs fn in a read-write transaction. Its changes are committed
+	// atomically if fn returns nil, and discarded otherwise.
+	Update(fn func(tx Tx) error) error
+	Close() error
+}
+
+// Tx is a transaction on a Backend. Values returned by Get and passed to
+// ForEach are only valid during the transaction.
+type Tx interface {
+	// Get returns the value of key in bucket, or nil if either is missing.
+	Get(bucket, key string) []byte
+	// Put stores a value, creating the bucket if needed. Only valid in Update.
+	Put(bucket, key string, value []byte) error
+	// Delete removes a key. Missing buckets and keys are not an error.
+	Delete(bucket, key string) error
+	// DeleteBucket removes a bucket and its keys. A missing bucket is not
+	// an error.
+	DeleteBucket(bucket string) error
+	// ForEach calls fn for each key of bucket in key order.
+	ForEach(bucket string, fn func(key string, value []byte) error) error
+	// ForEachPrefix calls fn for each key of bucket that starts with
+	// prefix, in key order.
+	ForEachPrefix(bucket, prefix string, fn func(key string, value []byte) error) error
+	// Buckets returns the bucket names in order.
+	Buckets() []string
+}
+
+// memoryBackend is a Backend without persistence, for tests and for
+// callers that only need a cache for the lifetime of the process.
+type memoryBackend struct {
+	mu      sync.RWMutex
+	buckets map[string]map[string][]byte
+}
+
+// NewMemoryBackend returns an empty in-memory Backend.
+func NewMemoryBackend() Backend {
+	return &memoryBackend{buckets: map[string]map[string][]byte{}}
+}
+
+func (m *memoryBackend) View(fn func(tx Tx) error) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return fn(&memoryTx{buckets: m.buckets})
+}
+
+// Update runs fn on a copy of the changed buckets and swaps them in when fn
+// succeeds, so a failed transaction leaves no partial writes.
+func (m *memoryBackend) Update(fn func(tx Tx) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	tx := &memoryTx{buckets: m.buckets, writable: true, changed: map[string]map[string][]byte{}, deleted: map[string]bool{}}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	for name := range tx.deleted {
+		delete(m.buckets, name)
+	}
+	for name, b := range tx.changed {
+		m.buckets[name] = b
+	}
+	return nil
+}
+
+func (m *memoryBackend) Close() error { return nil }
+
+// memoryTx reads through to the committed buckets until a bucket is
+// written, then works on a copy of it.
+type memoryTx struct {
+	buckets  map[string]map[string][]byte
+	writable bool
+	changed  map[string]map[string][]byte
+	deleted  map[string]bool
+}
+
+func (tx *memoryTx) bucket(name string) map[string][]byte {
+	if b, ok := tx.changed[name]; ok {
+		return b
+	}
+	if tx.deleted[name] {
+		return nil
+	}
+	return tx.buckets[name]
+}
+
+func (tx *memoryTx) writableBucket(name string) map[string][]byte {
+	if b, ok := tx.changed[name]; ok {
+		return b
+	}
+	b := map[string][]byte{}
+	if !tx.deleted[name] {
+		for k, v := range tx.buckets[name] {
+			b[k] = v
+		}
+	}
+	delete(tx.deleted, name)
+	tx.changed[name] = b
+	return b
+}
+
+func (tx *memoryTx) Get(bucket, key string) []byte {
+	return tx.bucket(bucket)[key]
+}
+
+func (tx *memoryTx) Put(bucket, key string, value []byte) error {
+	if !tx.writable {
+		return errReadOnly
+	}
+	tx.writableBucket(bucket)[key] = append([]byte(nil), value...)
+	return nil
+}
+
+func (tx *memoryTx) Delete(bucket, key string) error {
+	if !tx.writable {
+		return errReadOnly
+	}
+	if _, ok := tx.bucket(bucket)[key]; ok {
+		delete(tx.writableBucket(bucket), key)
+	}
+	return nil
+}
+
+func (tx *memoryTx) DeleteBucket(bucket string) error {
+	if !tx.writable {
+		return errReadOnly
+	}
+	delete(tx.changed, bucket)
+	tx.deleted[bucket] = true
+	return nil
+}
+
+func (tx *memoryTx) ForEach(bucket string, fn func(key string, value []byte) error) error {
+	b := tx.bucket(bucket)
+	keys := make([]string, 0, len(b))
+	for k := range b {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := fn(k, b[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (tx *memoryTx) ForEachPrefix(bucket, prefix string, fn func(key string, value []byte) error) error {
+	return tx.ForEach(bucket, func(key string, value []byte) error {
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		return fn(key, value)
+	})
+}
+
+func (tx *memoryTx) Buckets() []string {
+	seen := map[string]bool{}
+	for name := range tx.buckets {
+		seen[name] = !tx.deleted[name]
+	}
+	for name := range tx.changed {
+		seen[name] = true
+	}
+	var names []string
+	for name, ok := range seen {
+		if ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// backends returns each Backend implementation, for tests that must hold
+// for all of them.
+func backends(t *testing.T) map[string]Backend {
+	t.Helper()
+	b, err := OpenBolt(filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatalf("opening bolt backend: %v", err)
+	}
+	return map[string]Backend{"memory": NewMemoryBackend(), "bolt": b}
+}
+
+func TestBackend_PutGetDelete(t *testing.T) {
+	for name, b := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			err := b.Update(func(tx Tx) error {
+				tx.Put("a", "2", []byte("two"))
+				tx.Put("a", "1", []byte("one"))
+				return tx.Put("b", "x", []byte("ex"))
+			})
+			if err != nil {
+				t.Fatalf("Update error: %v", err)
+			}
+
+			b.View(func(tx Tx) error {
+				if got := string(tx.Get("a", "1")); got != "one" {
+					t.Errorf("Get = %q, want one", got)
+				}
+				if tx.Get("a", "missing") != nil || tx.Get("missing", "1") != nil {
+					t.Error("expected nil for missing keys and buckets")
+				}
+				var keys []string
+				tx.ForEach("a", func(k string, _ []byte) error {
+					keys = append(keys, k)
+					return nil
+				})
+				if !reflect.DeepEqual(keys, []string{"1", "2"}) {
+					t.Errorf("ForEach keys = %v, want [1 2]", keys)
+				}
+				if got := tx.Buckets(); !reflect.DeepEqual(got, []string{"a", "b"}) {
+					t.Errorf("Buckets = %v", got)
+				}
+				if err := tx.Put("a", "3", nil); err == nil {
+					t.Error("expected Put to fail in a read-only transaction")
+				}
+				return nil
+			})
+
+			b.Update(func(tx Tx) error {
+				tx.Delete("a", "1")
+				tx.Delete("missing", "1")
+				return tx.DeleteBucket("b")
+			})
+			b.View(func(tx Tx) error {
+				if tx.Get("a", "1") != nil || tx.Get("a", "2") == nil {
+					t.Error("Delete removed the wrong keys")
+				}
+				if got := tx.Buckets(); !reflect.DeepEqual(got, []string{"a"}) {
+					t.Errorf("Buckets after DeleteBucket = %v", got)
+				}
+				return nil
+			})
+		})
+	}
+}
+
+func TestBackend_FailedUpdateIsRolledBack(t *testing.T) {
+	for name, b := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			b.Update(func(tx Tx) error { return tx.Put("a", "1", []byte("old")) })
+
+			boom := errors.New("boom")
+			err := b.Update(func(tx Tx) error {
+				tx.Put("a", "1", []byte("new"))
+				tx.Put("c", "1", []byte("new"))
+				tx.DeleteBucket("a")
+				return boom
+			})
+			if !errors.Is(err, boom) {
+				t.Fatalf("expected the transaction error, got %v", err)
+			}
+
+			b.View(func(tx Tx) error {
+				if got := string(tx.Get("a", "1")); got != "old" {
+					t.Errorf("expected rollback, got %q", got)
+				}
+				if tx.Get("c", "1") != nil {
+					t.Error("expected no partial writes")
+				}
+				return nil
+			})
+		})
+	}
+}
+
+func TestBackend_ForEachPrefix(t *testing.T) {
+	for name, b := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			b.Update(func(tx Tx) error {
+				for _, k := range []string{"5/1", "55/2", "5/3", "6/1"} {
+					tx.Put("idx", k, []byte{})
+				}
+				return nil
+			})
+			b.View(func(tx Tx) error {
+				var keys []string
+				tx.ForEachPrefix("idx", "5/", func(k string, _ []byte) error {
+					keys = append(keys, k)
+					return nil
+				})
+				if !reflect.DeepEqual(keys, []string{"5/1", "5/3"}) {
+					t.Errorf("ForEachPrefix keys = %v, want [5/1 5/3]", keys)
+				}
+				return tx.ForEachPrefix("missing", "5/", func(string, []byte) error {
+					t.Error("unexpected key in missing bucket")
+					return nil
+				})
+			})
+		})
+	}
+}
+
+func TestNewStore_MemoryBackend(t *testing.T) {
+	store := NewStore(NewMemoryBackend())
+	if err := store.Set("project", "1", map[string]int{"id": 1}); err != nil {
+		t.Fatalf("Set error: %v", err)
+	}
+	var got struct{ ID int }
+	if err := store.Get("project", "1", &got); err != nil || got.ID != 1 {
+		t.Errorf("Get = %+v, %v", got, err)
+	}
+}
@@ -0,0 +1,172 @@
+package cache
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// lockTimeout is how long a transaction waits for another paymo process to
+// release the cache file before giving up. Defined as a var to allow test
+// injection.
+var lockTimeout = 5 * time.Second
+
+// ErrLocked is returned when the cache file stays locked by another process
+// for longer than lockTimeout.
+var ErrLocked = errors.New("cache is locked by another paymo process")
+
+// boltBackend stores buckets in a bbolt database file. The file is opened
+// at the first transaction and stays open, holding the file lock, until
+// Close, so a run of cache reads and writes opens it once. A transaction
+// after Close opens the file again: CachedClient closes it before every
+// API call, so the lock is never held while waiting for the network, and
+// concurrent paymo processes take turns instead of overwriting each
+// other's changes.
+type boltBackend struct {
+	path string
+
+	mu sync.Mutex // guards db; serializes opening and closing
+	db *bolt.DB
+}
+
+// OpenBolt opens (or creates) a bbolt cache database.
+func OpenBolt(path string) (Backend, error) {
+	b := &boltBackend{path: path}
+	// Create the file and check that it is a database
+	if err := b.Update(func(Tx) error { return nil }); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// acquire returns the open database, opening it if needed. The caller
+// holds b.mu.
+func (b *boltBackend) acquire() (*bolt.DB, error) {
+	if b.db != nil {
+		return b.db, nil
+	}
+	db, err := bolt.Open(b.path, 0600, &bolt.Options{Timeout: lockTimeout})
+	if errors.Is(err, bolt.ErrTimeout) {
+		return nil, fmt.Errorf("%w (%s)", ErrLocked, b.path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("opening cache database: %w", err)
+	}
+	b.db = db
+	return db, nil
+}
+
+func (b *boltBackend) View(fn func(tx Tx) error) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	db, err := b.acquire()
+	if err != nil {
+		return err
+	}
+	return db.View(func(tx *bolt.Tx) error { return fn(boltTx{tx}) })
+}
+
+func (b *boltBackend) Update(fn func(tx Tx) error) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	db, err := b.acquire()
+	if err != nil {
+		return err
+	}
+	return db.Update(func(tx *bolt.Tx) error { return fn(boltTx{tx}) })
+}
+
+// Close closes the database file, releasing the file lock.
+func (b *boltBackend) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.db == nil {
+		return nil
+	}
+	err := b.db.Close()
+	b.db = nil
+	return err
+}
+
+// boltTx adapts a bbolt transaction to Tx, with one top-level bbolt bucket
+// per bucket name.
+type boltTx struct {
+	tx *bolt.Tx
+}
+
+func (t boltTx) Get(bucket, key string) []byte {
+	b := t.tx.Bucket([]byte(bucket))
+	if b == nil {
+		return nil
+	}
+	return b.Get([]byte(key))
+}
+
+func (t boltTx) Put(bucket, key string, value []byte) error {
+	if !t.tx.Writable() {
+		return errReadOnly
+	}
+	b, err := t.tx.CreateBucketIfNotExists([]byte(bucket))
+	if err != nil {
+		return err
+	}
+	return b.Put([]byte(key), value)
+}
+
+func (t boltTx) Delete(bucket, key string) error {
+	if !t.tx.Writable() {
+		return errReadOnly
+	}
+	b := t.tx.Bucket([]byte(bucket))
+	if b == nil {
+		return nil
+	}
+	return b.Delete([]byte(key))
+}
+
+func (t boltTx) DeleteBucket(bucket string) error {
+	if !t.tx.Writable() {
+		return errReadOnly
+	}
+	err := t.tx.DeleteBucket([]byte(bucket))
+	if errors.Is(err, bolt.ErrBucketNotFound) {
+		return nil
+	}
+	return err
+}
+
+func (t boltTx) ForEach(bucket string, fn func(key string, value []byte) error) error {
+	b := t.tx.Bucket([]byte(bucket))
+	if b == nil {
+		return nil
+	}
+	return b.ForEach(func(k, v []byte) error { return fn(string(k), v) })
+}
+
+func (t boltTx) ForEachPrefix(bucket, prefix string, fn func(key string, value []byte) error) error {
+	b := t.tx.Bucket([]byte(bucket))
+	if b == nil {
+		return nil
+	}
+	c := b.Cursor()
+	p := []byte(prefix)
+	for k, v := c.Seek(p); k != nil && bytes.HasPrefix(k, p); k, v = c.Next() {
+		if err := fn(string(k), v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t boltTx) Buckets() []string {
+	var names []string
+	t.tx.ForEach(func(name []byte, _ *bolt.Bucket) error {
+		names = append(names, string(name))
+		return nil
+	})
+	return names
+}
@@ -0,0 +1,151 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestOpenBolt_FileMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.db")
+	if _, err := OpenBolt(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("expected mode 0600, got %o", mode)
+	}
+}
+
+func TestBoltBackend_Locked(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.db")
+	b, err := OpenBolt(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Close()
+
+	orig := lockTimeout
+	defer func() { lockTimeout = orig }()
+	lockTimeout = 50 * time.Millisecond
+
+	// Another process holding the database open for writing
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = b.Update(func(tx Tx) error { return nil })
+	if !errors.Is(err, ErrLocked) {
+		t.Errorf("expected ErrLocked while the file is held, got %v", err)
+	}
+
+	db.Close()
+	if err := b.Update(func(tx Tx) error { return nil }); err != nil {
+		t.Errorf("expected the lock to be released, got %v", err)
+	}
+	b.Close()
+}
+
+func TestBoltBackend_HoldsLockUntilClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.db")
+	b, err := OpenBolt(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Another process can't open the file between transactions
+	if _, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 50 * time.Millisecond}); !errors.Is(err, bolt.ErrTimeout) {
+		t.Fatalf("expected the file to stay locked, got %v", err)
+	}
+
+	// After Close it can, and the next transaction opens the file again
+	b.Close()
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("expected the lock to be released by Close, got %v", err)
+	}
+	db.Close()
+	if err := b.Update(func(tx Tx) error { return tx.Put("b", "k", []byte("v")) }); err != nil {
+		t.Errorf("expected a transaction after Close to reopen the file, got %v", err)
+	}
+	b.Close()
+}
+
+func TestBoltBackend_ConcurrentGoroutines(t *testing.T) {
+	b, err := OpenBolt(filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				err := b.Update(func(tx Tx) error {
+					return tx.Put("b", fmt.Sprintf("%d-%d", i, j), []byte("v"))
+				})
+				if err != nil {
+					t.Errorf("Update error: %v", err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	count := 0
+	b.View(func(tx Tx) error {
+		return tx.ForEach("b", func(string, []byte) error { count++; return nil })
+	})
+	if count != 40 {
+		t.Errorf("expected 40 keys, got %d", count)
+	}
+}
+
+func TestStore_ConcurrentWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.db")
+
+	// Two goroutines opening the file stand in for two paymo processes:
+	// each command holds the file until it closes the store, so they take
+	// turns without losing each other's writes
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s, err := Open(path)
+			if err != nil {
+				t.Errorf("Open error: %v", err)
+				return
+			}
+			defer s.Close()
+			for j := 0; j < 10; j++ {
+				if err := s.Set("project", fmt.Sprintf("%d-%d", i, j), map[string]int{"id": j}); err != nil {
+					t.Errorf("Set error: %v", err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	stats, _ := s.Stats()
+	if stats["project"] != 20 {
+		t.Errorf("expected 20 entries from both writers, got %d", stats["project"])
+	}
+}
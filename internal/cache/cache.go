@@ -1,14 +1,17 @@
 package cache
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
-	"sync"
 	"time"
+
+	bolt "go.etcd.io/bbolt"
 )
 
 // ErrCacheMiss indicates the requested key was not found or has expired.
@@ -37,71 +40,233 @@ type cacheEntry struct {
 	TTLSeconds int64           `json:"ttl_seconds"`
 }
 
-// cacheData is the top-level JSON structure persisted to disk.
+// NameRef is a name index entry used for project/task lookups by name.
+type NameRef struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	Code      string `json:"code,omitempty"`
+	ProjectID int    `json:"project_id,omitempty"`
+}
+
+// cacheData is the JSON file format of earlier versions, read once to
+// migrate it into the database.
 type cacheData struct {
-	Entries map[string]map[string]cacheEntry `json:"entries"` // resource_type -> cache_key -> entry
+	Entries map[string]map[string]cacheEntry `json:"entries"`         // resource_type -> cache_key -> entry
+	Names   map[string]map[string]NameRef    `json:"names,omitempty"` // resource_type -> id -> name ref
 }
 
-// Store is the JSON file-backed cache store.
+// FileName is the cache database in the config directory; LegacyFileName is
+// the JSON cache it replaces.
+const (
+	FileName       = "cache.db"
+	LegacyFileName = "cache.json"
+)
+
+// Bucket name prefixes: cached responses and the name index are kept in
+// one bucket per resource type. The scopes the name index fully covers are
+// kept next to it.
+const (
+	entriesPrefix  = "entries/"
+	namesPrefix    = "names/"
+	completePrefix = "names-complete/"
+)
+
+// Store is the cache store, on top of a pluggable Backend.
 type Store struct {
-	mu   sync.Mutex
-	path string
-	data cacheData
+	backend Backend
+	ttls    map[string]time.Duration // overrides of DefaultTTL
+}
+
+// NewStore returns a store on the given backend.
+func NewStore(backend Backend) *Store {
+	return &Store{backend: backend}
 }
 
-// Open opens (or creates) the cache file at the given path.
+// Open opens (or creates) the cache database at the given path. A JSON
+// cache left by an earlier version, either at the path itself or as
+// cache.json next to it, is migrated into the database and removed. The
+// cached data is not encrypted; see OpenEncrypted.
 func Open(cachePath string) (*Store, error) {
+	return OpenEncrypted(cachePath, nil)
+}
+
+// migratingSuffix is appended to the path of a JSON cache found where the
+// database goes. The file waits there until it has been migrated.
+const migratingSuffix = ".migrating"
+
+// OpenEncrypted opens the cache database like Open, with the cached data
+// encrypted with key (see NewEncryptedBackend). A nil key leaves it
+// unencrypted.
+func OpenEncrypted(cachePath string, key []byte) (*Store, error) {
 	if err := os.MkdirAll(filepath.Dir(cachePath), 0700); err != nil {
 		return nil, fmt.Errorf("creating cache dir: %w", err)
 	}
-	s := &Store{
-		path: cachePath,
-		data: cacheData{
-			Entries: make(map[string]map[string]cacheEntry),
-		},
+
+	// A JSON cache at the path itself is moved aside for the database
+	pending := cachePath + migratingSuffix
+	if readLegacy(cachePath) != nil {
+		if err := os.Rename(cachePath, pending); err != nil {
+			return nil, fmt.Errorf("moving JSON cache aside: %w", err)
+		}
+	}
+	backend, err := OpenBolt(cachePath)
+	if isCorrupt(err) {
+		// Corrupt cache — start fresh
+		if os.Remove(cachePath) == nil {
+			backend, err = OpenBolt(cachePath)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	s, err := openStore(cachePath, backend, key)
+	if err != nil {
+		backend.Close()
+		return nil, err
+	}
+
+	legacyPaths := []string{pending}
+	if sibling := filepath.Join(filepath.Dir(cachePath), LegacyFileName); sibling != cachePath {
+		legacyPaths = append(legacyPaths, sibling)
 	}
-	// Try to load existing cache
-	raw, err := os.ReadFile(cachePath)
-	if err == nil && len(raw) > 0 {
-		if json.Unmarshal(raw, &s.data) != nil {
-			// Corrupt cache — start fresh
-			s.data.Entries = make(map[string]map[string]cacheEntry)
+	for _, path := range legacyPaths {
+		raw := readLegacy(path)
+		if raw == nil {
+			continue
 		}
-		if s.data.Entries == nil {
-			s.data.Entries = make(map[string]map[string]cacheEntry)
+		if err := s.migrate(raw); err != nil {
+			s.Close()
+			return nil, fmt.Errorf("migrating JSON cache: %w", err)
 		}
+		// Only removed once its data is safely in the database
+		os.Remove(path)
 	}
 	return s, nil
 }
 
-// Close flushes the cache to disk.
-func (s *Store) Close() error {
-	return s.flush()
+// openStore secures the database file and sets up encryption on it.
+func openStore(cachePath string, backend Backend, key []byte) (*Store, error) {
+	// Files of earlier versions may be readable by others
+	if err := os.Chmod(cachePath, 0600); err != nil {
+		return nil, fmt.Errorf("securing cache file: %w", err)
+	}
+	if key != nil {
+		var err error
+		if backend, err = NewEncryptedBackend(backend, key); err != nil {
+			return nil, err
+		}
+	}
+	return NewStore(backend), nil
 }
 
-func (s *Store) flush() error {
-	s.mu.Lock()
-	raw, err := json.Marshal(s.data)
-	s.mu.Unlock()
+// isCorrupt reports whether err means the file isn't a usable database,
+// as opposed to one that couldn't be opened (permissions, I/O errors, a
+// lock held by another process).
+func isCorrupt(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, bolt.ErrInvalid) || errors.Is(err, bolt.ErrVersionMismatch) ||
+		errors.Is(err, bolt.ErrChecksum) {
+		return true
+	}
+	// bbolt doesn't export the error for a file too short to be a database
+	return strings.Contains(err.Error(), "file size too small")
+}
+
+// readLegacy reads a JSON cache file. It returns nil if path doesn't hold
+// one.
+func readLegacy(path string) []byte {
+	raw, err := os.ReadFile(path)
+	if err != nil || !bytes.HasPrefix(bytes.TrimSpace(raw), []byte("{")) {
+		return nil
+	}
+	return raw
+}
+
+// migrate imports a JSON cache in one transaction. Corrupt files are
+// ignored, like a corrupt cache always was.
+func (s *Store) migrate(raw []byte) error {
+	var data cacheData
+	if json.Unmarshal(raw, &data) != nil {
+		return nil
+	}
+	return s.backend.Update(func(tx Tx) error {
+		for rt, bucket := range data.Entries {
+			for key, entry := range bucket {
+				if err := putJSON(tx, entriesPrefix+rt, key, entry); err != nil {
+					return err
+				}
+			}
+		}
+		for rt, bucket := range data.Names {
+			for key, ref := range bucket {
+				if err := putJSON(tx, namesPrefix+rt, key, ref); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func putJSON(tx Tx, bucket, key string, value interface{}) error {
+	data, err := json.Marshal(value)
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(s.path, raw, 0644)
+	return tx.Put(bucket, key, data)
 }
 
-// Get retrieves a cached entry. Returns ErrCacheMiss if not found or expired.
-func (s *Store) Get(resourceType, cacheKey string, dest interface{}) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	bucket, ok := s.data.Entries[resourceType]
+// Rekey re-encrypts the cached data with a new key. The store must have
+// been opened with OpenEncrypted.
+func (s *Store) Rekey(key []byte) error {
+	eb, ok := s.backend.(*encryptedBackend)
 	if !ok {
-		return ErrCacheMiss
+		return errors.New("the cache is not encrypted")
 	}
-	entry, ok := bucket[cacheKey]
-	if !ok {
-		return ErrCacheMiss
+	return eb.Rekey(key)
+}
+
+// Close closes the backend. Every write is committed as it happens.
+func (s *Store) Close() error {
+	return s.backend.Close()
+}
+
+// entry reads a cached entry, expired or not.
+func (s *Store) entry(resourceType, cacheKey string) (cacheEntry, error) {
+	var entry cacheEntry
+	err := s.backend.View(func(tx Tx) error {
+		raw := tx.Get(entriesPrefix+resourceType, cacheKey)
+		if raw == nil {
+			return ErrCacheMiss
+		}
+		return json.Unmarshal(raw, &entry)
+	})
+	return entry, err
+}
+
+// ttl is the lifetime of an entry: the TTL it was stored with, or the
+// current TTL of its type if that is shorter.
+func (s *Store) ttl(resourceType string, e cacheEntry) time.Duration {
+	ttl := time.Duration(e.TTLSeconds) * time.Second
+	if current := s.TTL(resourceType); current < ttl {
+		ttl = current
+	}
+	return ttl
+}
+
+func (s *Store) expired(resourceType string, e cacheEntry, now time.Time) bool {
+	return now.Unix()-e.CachedAt > int64(s.ttl(resourceType, e).Seconds())
+}
+
+// Get retrieves a cached entry. Returns ErrCacheMiss if not found or expired.
+func (s *Store) Get(resourceType, cacheKey string, dest interface{}) error {
+	entry, err := s.entry(resourceType, cacheKey)
+	if err != nil {
+		return err
 	}
-	if time.Now().Unix()-entry.CachedAt > entry.TTLSeconds {
+	if s.expired(resourceType, entry, time.Now()) {
 		return ErrCacheMiss
 	}
 	return json.Unmarshal(entry.Data, dest)
@@ -109,131 +274,350 @@ func (s *Store) Get(resourceType, cacheKey string, dest interface{}) error {
 
 // GetStale retrieves cached data even if expired (for offline fallback).
 func (s *Store) GetStale(resourceType, cacheKey string, dest interface{}) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	bucket, ok := s.data.Entries[resourceType]
-	if !ok {
-		return ErrCacheMiss
-	}
-	entry, ok := bucket[cacheKey]
-	if !ok {
-		return ErrCacheMiss
+	entry, err := s.entry(resourceType, cacheKey)
+	if err != nil {
+		return err
 	}
 	return json.Unmarshal(entry.Data, dest)
 }
 
-// Set stores a value in the cache and flushes to disk.
-func (s *Store) Set(resourceType, cacheKey string, value interface{}) error {
-	ttl := getTTL(resourceType)
-	if ttl == 0 {
-		return nil
-	}
-	data, err := json.Marshal(value)
+// CachedAt returns when an entry was stored, expired or not. It reports
+// false if the entry is not cached.
+func (s *Store) CachedAt(resourceType, cacheKey string) (time.Time, bool) {
+	entry, err := s.entry(resourceType, cacheKey)
 	if err != nil {
-		return err
+		return time.Time{}, false
 	}
-	s.mu.Lock()
-	if s.data.Entries[resourceType] == nil {
-		s.data.Entries[resourceType] = make(map[string]cacheEntry)
-	}
-	s.data.Entries[resourceType][cacheKey] = cacheEntry{
-		Data:       data,
-		CachedAt:   time.Now().Unix(),
-		TTLSeconds: int64(ttl.Seconds()),
+	return time.Unix(entry.CachedAt, 0), true
+}
+
+// Set stores a value in the cache.
+func (s *Store) Set(resourceType, cacheKey string, value interface{}) error {
+	return s.SetMany(resourceType, map[string]interface{}{cacheKey: value})
+}
+
+// SetMany stores several values of a resource type in one transaction.
+func (s *Store) SetMany(resourceType string, values map[string]interface{}) error {
+	ttl := s.TTL(resourceType)
+	if ttl == 0 || len(values) == 0 {
+		return nil
 	}
-	s.mu.Unlock()
-	return s.flush()
+	now := time.Now().Unix()
+	return s.backend.Update(func(tx Tx) error {
+		for cacheKey, value := range values {
+			data, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			entry := cacheEntry{
+				Data:       data,
+				CachedAt:   now,
+				TTLSeconds: int64(ttl.Seconds()),
+			}
+			if err := putJSON(tx, entriesPrefix+resourceType, cacheKey, entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
 // InvalidateType removes all entries for the given resource types.
 func (s *Store) InvalidateType(resourceTypes ...string) error {
-	s.mu.Lock()
-	for _, rt := range resourceTypes {
-		delete(s.data.Entries, rt)
-	}
-	s.mu.Unlock()
-	return s.flush()
+	return s.backend.Update(func(tx Tx) error {
+		for _, rt := range resourceTypes {
+			if err := tx.DeleteBucket(entriesPrefix + rt); err != nil {
+				return err
+			}
+			if err := tx.DeleteBucket(namesPrefix + rt); err != nil {
+				return err
+			}
+			if err := tx.DeleteBucket(completePrefix + rt); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
 // Clear removes all cached data.
 func (s *Store) Clear() error {
-	s.mu.Lock()
-	s.data.Entries = make(map[string]map[string]cacheEntry)
-	s.mu.Unlock()
-	return s.flush()
+	return s.backend.Update(func(tx Tx) error {
+		for _, name := range tx.Buckets() {
+			if err := tx.DeleteBucket(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
-// Prune removes expired entries.
-func (s *Store) Prune() error {
-	now := time.Now().Unix()
-	s.mu.Lock()
-	for rt, bucket := range s.data.Entries {
-		for key, entry := range bucket {
-			if now-entry.CachedAt > entry.TTLSeconds {
-				delete(bucket, key)
+// Prune removes expired entries and returns how many it removed.
+func (s *Store) Prune() (int, error) {
+	now := time.Now()
+	removed := 0
+	err := s.backend.Update(func(tx Tx) error {
+		for _, name := range tx.Buckets() {
+			if !strings.HasPrefix(name, entriesPrefix) {
+				continue
+			}
+			rt := strings.TrimPrefix(name, entriesPrefix)
+			var expired []string
+			kept := 0
+			tx.ForEach(name, func(key string, raw []byte) error {
+				var entry cacheEntry
+				if json.Unmarshal(raw, &entry) != nil || s.expired(rt, entry, now) {
+					expired = append(expired, key)
+				} else {
+					kept++
+				}
+				return nil
+			})
+			removed += len(expired)
+			if kept == 0 {
+				if err := tx.DeleteBucket(name); err != nil {
+					return err
+				}
+				continue
+			}
+			for _, key := range expired {
+				if err := tx.Delete(name, key); err != nil {
+					return err
+				}
 			}
 		}
-		if len(bucket) == 0 {
-			delete(s.data.Entries, rt)
-		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
 	}
-	s.mu.Unlock()
-	return s.flush()
+	return removed, nil
 }
 
-// IndexName is a no-op in the JSON store — name lookups scan cached entries directly.
-func (s *Store) IndexName(resourceType, nameLower string, id, projectID int) {
-	// Name index is implicit from cached entries
+// IndexName records names in the name index.
+// The index has no TTL; it is dropped with its resource type.
+func (s *Store) IndexName(resourceType string, refs ...NameRef) error {
+	if len(refs) == 0 {
+		return nil
+	}
+	return s.backend.Update(func(tx Tx) error {
+		for _, ref := range refs {
+			if err := putJSON(tx, namesPrefix+resourceType, fmt.Sprintf("%d", ref.ID), ref); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Names returns the indexed names for a resource type, ordered by ID.
+// A non-zero projectID restricts the result to that project.
+func (s *Store) Names(resourceType string, projectID int) []NameRef {
+	var refs []NameRef
+	s.backend.View(func(tx Tx) error {
+		return tx.ForEach(namesPrefix+resourceType, func(_ string, raw []byte) error {
+			var ref NameRef
+			if json.Unmarshal(raw, &ref) != nil || (projectID > 0 && ref.ProjectID != projectID) {
+				return nil
+			}
+			refs = append(refs, ref)
+			return nil
+		})
+	})
+	sort.Slice(refs, func(i, j int) bool { return refs[i].ID < refs[j].ID })
+	return refs
+}
+
+// MarkNamesComplete records that the name index holds every name of a
+// resource type, as after an unfiltered list or a sync. For tasks a
+// non-zero projectID covers that project only. The mark is dropped with
+// the index.
+func (s *Store) MarkNamesComplete(resourceType string, projectID int) error {
+	return s.backend.Update(func(tx Tx) error {
+		return putJSON(tx, completePrefix+resourceType, fmt.Sprintf("%d", projectID), time.Now())
+	})
+}
+
+// NamesComplete reports whether the name index holds every name of a
+// resource type (for tasks: of projectID, or of all projects), so a name
+// missing from it doesn't exist. A partial index only holds the names that
+// were fetched one by one.
+func (s *Store) NamesComplete(resourceType string, projectID int) bool {
+	complete := false
+	s.backend.View(func(tx Tx) error {
+		complete = tx.Get(completePrefix+resourceType, "0") != nil ||
+			(projectID > 0 && tx.Get(completePrefix+resourceType, fmt.Sprintf("%d", projectID)) != nil)
+		return nil
+	})
+	return complete
 }
 
 // LookupName searches cached individual entries for a name match.
 func (s *Store) LookupName(resourceType, nameLower string, projectID int) (int, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	if resourceType == "project" {
-		bucket := s.data.Entries["project"]
-		for _, entry := range bucket {
-			var p struct {
-				ID   int    `json:"id"`
-				Name string `json:"name"`
-			}
-			if json.Unmarshal(entry.Data, &p) == nil {
-				if strings.Contains(strings.ToLower(p.Name), nameLower) {
-					return p.ID, nil
-				}
-			}
-		}
+	if resourceType != "project" && resourceType != "task" {
+		return 0, ErrCacheMiss
 	}
-
-	if resourceType == "task" {
-		bucket := s.data.Entries["task"]
-		for _, entry := range bucket {
-			var t struct {
+	found := 0
+	s.backend.View(func(tx Tx) error {
+		return tx.ForEach(entriesPrefix+resourceType, func(_ string, raw []byte) error {
+			var entry cacheEntry
+			var item struct {
 				ID        int    `json:"id"`
 				Name      string `json:"name"`
 				ProjectID int    `json:"project_id"`
 			}
-			if json.Unmarshal(entry.Data, &t) == nil {
-				if t.ProjectID == projectID && strings.Contains(strings.ToLower(t.Name), nameLower) {
-					return t.ID, nil
-				}
+			if json.Unmarshal(raw, &entry) != nil || json.Unmarshal(entry.Data, &item) != nil {
+				return nil
 			}
-		}
+			if resourceType == "task" && item.ProjectID != projectID {
+				return nil
+			}
+			if strings.Contains(strings.ToLower(item.Name), nameLower) {
+				found = item.ID
+				return errStop
+			}
+			return nil
+		})
+	})
+	if found == 0 {
+		return 0, ErrCacheMiss
 	}
-
-	return 0, ErrCacheMiss
+	return found, nil
 }
 
+// errStop ends a ForEach early.
+var errStop = errors.New("stop")
+
 // Stats returns cache statistics.
 func (s *Store) Stats() (map[string]int, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
 	stats := make(map[string]int)
-	for rt, bucket := range s.data.Entries {
-		stats[rt] = len(bucket)
+	err := s.backend.View(func(tx Tx) error {
+		for _, name := range tx.Buckets() {
+			if !strings.HasPrefix(name, entriesPrefix) {
+				continue
+			}
+			n := 0
+			tx.ForEach(name, func(string, []byte) error {
+				n++
+				return nil
+			})
+			if n > 0 {
+				stats[strings.TrimPrefix(name, entriesPrefix)] = n
+			}
+		}
+		return nil
+	})
+	return stats, err
+}
+
+// EntryInfo describes a cached entry.
+type EntryInfo struct {
+	Key      string
+	CachedAt time.Time
+	TTL      time.Duration
+	Expired  bool
+	Size     int // bytes of cached data
+}
+
+func (s *Store) entryInfo(resourceType, key string, entry cacheEntry, now time.Time) EntryInfo {
+	return EntryInfo{
+		Key:      key,
+		CachedAt: time.Unix(entry.CachedAt, 0),
+		TTL:      s.ttl(resourceType, entry),
+		Expired:  s.expired(resourceType, entry, now),
+		Size:     len(entry.Data),
+	}
+}
+
+// Entries describes the cached entries of a resource type, expired or
+// not, ordered by key.
+func (s *Store) Entries(resourceType string) ([]EntryInfo, error) {
+	now := time.Now()
+	var infos []EntryInfo
+	err := s.backend.View(func(tx Tx) error {
+		return tx.ForEach(entriesPrefix+resourceType, func(key string, raw []byte) error {
+			var entry cacheEntry
+			if json.Unmarshal(raw, &entry) != nil {
+				return nil
+			}
+			infos = append(infos, s.entryInfo(resourceType, key, entry, now))
+			return nil
+		})
+	})
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Key < infos[j].Key })
+	return infos, err
+}
+
+// Entry describes a cached entry, expired or not, and returns its data.
+// It returns ErrCacheMiss if the entry is not cached.
+func (s *Store) Entry(resourceType, cacheKey string) (EntryInfo, json.RawMessage, error) {
+	entry, err := s.entry(resourceType, cacheKey)
+	if err != nil {
+		return EntryInfo{}, nil, err
+	}
+	return s.entryInfo(resourceType, cacheKey, entry, time.Now()), entry.Data, nil
+}
+
+// ttlParents are the list types whose TTL override also applies to the
+// single-item types derived from them.
+var ttlParents = map[string]string{
+	"project":         "projects",
+	"project_by_name": "projects",
+	"task":            "tasks",
+	"task_by_name":    "tasks",
+	"entry":           "entries",
+}
+
+// ParseTTLs parses TTL overrides keyed by resource type, such as
+// "tasks": "10m". A zero duration turns caching of the type off.
+func ParseTTLs(raw map[string]string) (map[string]time.Duration, error) {
+	ttls := make(map[string]time.Duration, len(raw))
+	for rt, value := range raw {
+		if _, ok := DefaultTTL[rt]; !ok {
+			return nil, fmt.Errorf("unknown cache type %q (known: %s)", rt, strings.Join(ResourceTypes(), ", "))
+		}
+		if DefaultTTL[rt] == 0 {
+			return nil, fmt.Errorf("%s is never cached and has no TTL", rt)
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TTL for %s: %w", rt, err)
+		}
+		if d < 0 {
+			return nil, fmt.Errorf("invalid TTL for %s: %s is negative", rt, value)
+		}
+		ttls[rt] = d
+	}
+	return ttls, nil
+}
+
+// ResourceTypes returns the cached resource types, sorted.
+func ResourceTypes() []string {
+	types := make([]string, 0, len(DefaultTTL))
+	for rt := range DefaultTTL {
+		types = append(types, rt)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// SetTTLs overrides the default TTL of resource types (see ParseTTLs). An
+// override of a list type such as "tasks" also applies to its single items
+// unless they have their own. Entries expire by the shorter of the TTL they
+// were stored with and the current one, so shorter TTLs apply at once.
+func (s *Store) SetTTLs(ttls map[string]time.Duration) {
+	s.ttls = ttls
+}
+
+// TTL returns the cache lifetime of a resource type.
+func (s *Store) TTL(resourceType string) time.Duration {
+	if ttl, ok := s.ttls[resourceType]; ok {
+		return ttl
+	}
+	if ttl, ok := s.ttls[ttlParents[resourceType]]; ok {
+		return ttl
 	}
-	return stats, nil
+	return getTTL(resourceType)
 }
 
 func getTTL(resourceType string) time.Duration {
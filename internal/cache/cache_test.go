@@ -64,6 +64,93 @@ func TestOpen_CorruptFile(t *testing.T) {
 	}
 }
 
+func TestOpen_MigratesLegacyJSON(t *testing.T) {
+	dir := t.TempDir()
+	legacy := filepath.Join(dir, LegacyFileName)
+	data := `{"entries":{"projects":{"all":{"data":[{"id":1}],"cached_at":` + itoa(time.Now().Unix()) +
+		`,"ttl_seconds":3600}}},"names":{"project":{"1":{"id":1,"name":"Alpha"}}}}`
+	os.WriteFile(legacy, []byte(data), 0644)
+
+	store, err := Open(filepath.Join(dir, FileName))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer store.Close()
+
+	var got []struct{ ID int }
+	if err := store.Get("projects", "all", &got); err != nil || len(got) != 1 {
+		t.Errorf("expected migrated entry, got %v, %v", got, err)
+	}
+	if names := store.Names("project", 0); len(names) != 1 || names[0].Name != "Alpha" {
+		t.Errorf("expected migrated name index, got %v", names)
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be removed after migration", LegacyFileName)
+	}
+}
+
+func TestOpen_KeepsLegacyJSONOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	legacy := filepath.Join(dir, LegacyFileName)
+	data := `{"names":{"project":{"1":{"id":1,"name":"Alpha"}}}}`
+	os.WriteFile(legacy, []byte(data), 0644)
+
+	// An invalid key fails the encryption setup before the migration
+	if _, err := OpenEncrypted(filepath.Join(dir, FileName), []byte("short")); err == nil {
+		t.Fatal("expected an error for an invalid key")
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		t.Errorf("expected %s to be kept when the database couldn't be set up, got %v", LegacyFileName, err)
+	}
+
+	store, err := Open(filepath.Join(dir, FileName))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer store.Close()
+	if names := store.Names("project", 0); len(names) != 1 {
+		t.Errorf("expected the JSON cache to be migrated on the next open, got %v", names)
+	}
+}
+
+func TestOpen_KeepsLegacyJSONAtPathOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cache.json")
+	data := `{"names":{"project":{"1":{"id":1,"name":"Alpha"}}}}`
+	os.WriteFile(path, []byte(data), 0644)
+
+	if _, err := OpenEncrypted(path, []byte("short")); err == nil {
+		t.Fatal("expected an error for an invalid key")
+	}
+
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer store.Close()
+	if names := store.Names("project", 0); len(names) != 1 {
+		t.Errorf("expected the JSON cache to survive the failed open, got %v", names)
+	}
+	if _, err := os.Stat(path + migratingSuffix); !os.IsNotExist(err) {
+		t.Error("expected the JSON cache to be removed after migration")
+	}
+}
+
+func TestOpen_KeepsUnreadableFile(t *testing.T) {
+	// A path that can't be opened (here a directory) isn't a corrupt cache
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.Mkdir(path, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Open(path); err == nil {
+		t.Fatal("expected an error for a path that can't be opened")
+	}
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		t.Errorf("expected the path to be left alone, got %v", err)
+	}
+}
+
 func TestSetAndGet(t *testing.T) {
 	store := newTestStore(t)
 	defer store.Close()
@@ -89,6 +176,28 @@ func TestSetAndGet(t *testing.T) {
 	}
 }
 
+func TestSetMany(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	err := store.SetMany("task", map[string]interface{}{
+		"1": map[string]string{"name": "One"},
+		"2": map[string]string{"name": "Two"},
+	})
+	if err != nil {
+		t.Fatalf("SetMany error: %v", err)
+	}
+
+	var got map[string]string
+	if err := store.Get("task", "2", &got); err != nil || got["name"] != "Two" {
+		t.Errorf("expected task 2, got %v, %v", got, err)
+	}
+	stats, _ := store.Stats()
+	if stats["task"] != 2 {
+		t.Errorf("expected 2 task entries, got %d", stats["task"])
+	}
+}
+
 func TestGet_Miss(t *testing.T) {
 	store := newTestStore(t)
 	defer store.Close()
@@ -116,15 +225,11 @@ func TestGet_Expired(t *testing.T) {
 	defer store.Close()
 
 	// Manually inject an expired entry
-	store.mu.Lock()
-	store.data.Entries["project"] = map[string]cacheEntry{
-		"1": {
-			Data:       []byte(`{"id":1}`),
-			CachedAt:   time.Now().Add(-2 * time.Hour).Unix(),
-			TTLSeconds: 3600, // 1 hour
-		},
-	}
-	store.mu.Unlock()
+	putEntry(t, store, "project", "1", cacheEntry{
+		Data:       []byte(`{"id":1}`),
+		CachedAt:   time.Now().Add(-2 * time.Hour).Unix(),
+		TTLSeconds: 3600, // 1 hour
+	})
 
 	var got struct{ ID int }
 	err := store.Get("project", "1", &got)
@@ -138,15 +243,11 @@ func TestGetStale_ReturnsExpired(t *testing.T) {
 	defer store.Close()
 
 	// Manually inject an expired entry
-	store.mu.Lock()
-	store.data.Entries["project"] = map[string]cacheEntry{
-		"1": {
-			Data:       []byte(`{"id":1,"name":"Stale"}`),
-			CachedAt:   time.Now().Add(-2 * time.Hour).Unix(),
-			TTLSeconds: 3600,
-		},
-	}
-	store.mu.Unlock()
+	putEntry(t, store, "project", "1", cacheEntry{
+		Data:       []byte(`{"id":1,"name":"Stale"}`),
+		CachedAt:   time.Now().Add(-2 * time.Hour).Unix(),
+		TTLSeconds: 3600,
+	})
 
 	var got struct {
 		ID   int    `json:"id"`
@@ -162,6 +263,21 @@ func TestGetStale_ReturnsExpired(t *testing.T) {
 	}
 }
 
+func TestCachedAt(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	if _, ok := store.CachedAt("project", "1"); ok {
+		t.Error("expected no timestamp for a missing entry")
+	}
+	before := time.Now().Add(-time.Second)
+	store.Set("project", "1", map[string]int{"id": 1})
+	at, ok := store.CachedAt("project", "1")
+	if !ok || at.Before(before) {
+		t.Errorf("expected recent timestamp, got %v (%v)", at, ok)
+	}
+}
+
 func TestGetStale_Miss(t *testing.T) {
 	store := newTestStore(t)
 	defer store.Close()
@@ -217,6 +333,59 @@ func TestInvalidateType(t *testing.T) {
 	}
 }
 
+func TestIndexName_Names(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	store.IndexName("task",
+		NameRef{ID: 11, Name: "Development", ProjectID: 1},
+		NameRef{ID: 10, Name: "Design", ProjectID: 1},
+		NameRef{ID: 20, Name: "Design", ProjectID: 2},
+	)
+
+	all := store.Names("task", 0)
+	if len(all) != 3 || all[0].ID != 10 || all[1].ID != 11 {
+		t.Errorf("expected 3 names ordered by ID, got %+v", all)
+	}
+	p2 := store.Names("task", 2)
+	if len(p2) != 1 || p2[0].ID != 20 {
+		t.Errorf("expected only task 20 for project 2, got %+v", p2)
+	}
+
+	// Re-indexing replaces the entry
+	store.IndexName("task", NameRef{ID: 10, Name: "UI Design", ProjectID: 1})
+	if got := store.Names("task", 1); got[0].Name != "UI Design" {
+		t.Errorf("expected renamed task, got %q", got[0].Name)
+	}
+
+	store.InvalidateType("task")
+	if got := store.Names("task", 0); len(got) != 0 {
+		t.Errorf("expected names dropped with their type, got %+v", got)
+	}
+}
+
+func TestNamesComplete(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	if store.NamesComplete("task", 1) {
+		t.Error("expected an unmarked index to be partial")
+	}
+	store.MarkNamesComplete("task", 1)
+	if !store.NamesComplete("task", 1) || store.NamesComplete("task", 2) || store.NamesComplete("task", 0) {
+		t.Error("expected only project 1's tasks to be complete")
+	}
+	store.MarkNamesComplete("task", 0)
+	if !store.NamesComplete("task", 2) {
+		t.Error("expected every project to be complete after a full list")
+	}
+
+	store.InvalidateType("task")
+	if store.NamesComplete("task", 1) {
+		t.Error("expected the mark dropped with the index")
+	}
+}
+
 func TestClear(t *testing.T) {
 	store := newTestStore(t)
 	defer store.Close()
@@ -243,21 +412,19 @@ func TestPrune(t *testing.T) {
 	store.Set("project", "1", map[string]int{"id": 1})
 
 	// Manually inject an expired entry
-	store.mu.Lock()
-	if store.data.Entries["project"] == nil {
-		store.data.Entries["project"] = make(map[string]cacheEntry)
-	}
-	store.data.Entries["project"]["2"] = cacheEntry{
+	putEntry(t, store, "project", "2", cacheEntry{
 		Data:       []byte(`{"id":2}`),
 		CachedAt:   time.Now().Add(-2 * time.Hour).Unix(),
 		TTLSeconds: 3600,
-	}
-	store.mu.Unlock()
+	})
 
-	err := store.Prune()
+	removed, err := store.Prune()
 	if err != nil {
 		t.Fatalf("Prune error: %v", err)
 	}
+	if removed != 1 {
+		t.Errorf("Prune removed %d entries, want 1", removed)
+	}
 
 	// Fresh entry should remain
 	var got struct{ ID int }
@@ -404,6 +571,98 @@ func TestGetTTL(t *testing.T) {
 	}
 }
 
+func TestSetTTLs(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	store.Set("task", "1", map[string]int{"id": 1})
+	store.SetTTLs(map[string]time.Duration{"tasks": 10 * time.Minute, "projects": 2 * time.Hour})
+
+	tests := map[string]time.Duration{
+		"tasks":    10 * time.Minute,
+		"task":     10 * time.Minute, // inherited from tasks
+		"projects": 2 * time.Hour,
+		"entries":  5 * time.Minute, // default
+	}
+	for rt, want := range tests {
+		if got := store.TTL(rt); got != want {
+			t.Errorf("TTL(%q) = %v, want %v", rt, got, want)
+		}
+	}
+
+	// A shorter TTL applies to entries already cached
+	putEntry(t, store, "task", "2", cacheEntry{
+		Data:       []byte(`{"id":2}`),
+		CachedAt:   time.Now().Add(-15 * time.Minute).Unix(),
+		TTLSeconds: 1800,
+	})
+	var got struct{ ID int }
+	if err := store.Get("task", "2", &got); err != ErrCacheMiss {
+		t.Errorf("expected the 10m override to expire a 15m-old task, got %v", err)
+	}
+	if err := store.Get("task", "1", &got); err != nil {
+		t.Errorf("fresh task should still be cached: %v", err)
+	}
+}
+
+func TestParseTTLs(t *testing.T) {
+	ttls, err := ParseTTLs(map[string]string{"tasks": "10m", "me": "0"})
+	if err != nil {
+		t.Fatalf("ParseTTLs error: %v", err)
+	}
+	if ttls["tasks"] != 10*time.Minute || ttls["me"] != 0 {
+		t.Errorf("unexpected TTLs %v", ttls)
+	}
+
+	for _, raw := range []map[string]string{
+		{"widgets": "1h"},
+		{"tasks": "soon"},
+		{"tasks": "-5m"},
+		{"active_entry": "1m"},
+	} {
+		if _, err := ParseTTLs(raw); err == nil {
+			t.Errorf("ParseTTLs(%v) should fail", raw)
+		}
+	}
+}
+
+func TestEntries(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	store.Set("project", "2", map[string]int{"id": 2})
+	putEntry(t, store, "project", "1", cacheEntry{
+		Data:       []byte(`{"id":1}`),
+		CachedAt:   time.Now().Add(-2 * time.Hour).Unix(),
+		TTLSeconds: 3600,
+	})
+
+	entries, err := store.Entries("project")
+	if err != nil {
+		t.Fatalf("Entries error: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Key != "1" || entries[1].Key != "2" {
+		t.Fatalf("expected entries 1 and 2 in key order, got %+v", entries)
+	}
+	if !entries[0].Expired || entries[1].Expired {
+		t.Errorf("expected only entry 1 expired, got %+v", entries)
+	}
+	if entries[1].TTL != time.Hour || entries[1].Size != len(`{"id":2}`) {
+		t.Errorf("unexpected entry info %+v", entries[1])
+	}
+
+	info, data, err := store.Entry("project", "1")
+	if err != nil {
+		t.Fatalf("Entry error: %v", err)
+	}
+	if string(data) != `{"id":1}` || !info.Expired {
+		t.Errorf("unexpected entry %+v %s", info, data)
+	}
+	if _, _, err := store.Entry("project", "3"); err != ErrCacheMiss {
+		t.Errorf("expected ErrCacheMiss, got %v", err)
+	}
+}
+
 // --- helpers ---
 
 func newTestStore(t *testing.T) *Store {
@@ -417,6 +676,17 @@ func newTestStore(t *testing.T) *Store {
 	return store
 }
 
+// putEntry writes a raw entry, e.g. an expired one, into the store.
+func putEntry(t *testing.T, store *Store, resourceType, key string, entry cacheEntry) {
+	t.Helper()
+	err := store.backend.Update(func(tx Tx) error {
+		return putJSON(tx, entriesPrefix+resourceType, key, entry)
+	})
+	if err != nil {
+		t.Fatalf("writing entry: %v", err)
+	}
+}
+
 func itoa(n int64) string {
 	return fmt.Sprintf("%d", n)
 }
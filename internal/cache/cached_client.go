@@ -3,16 +3,36 @@ package cache
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/ComputClaw/paymo-cli/internal/api"
 )
 
-// CachedClient wraps a PaymoAPI implementation with SQLite caching.
+// CachedClient wraps a PaymoAPI implementation with a cache (Store).
 // Read methods check cache first; mutations pass through and invalidate.
 type CachedClient struct {
 	inner api.PaymoAPI
 	store *Store
+
+	grace time.Duration // stale-while-revalidate window (revalidate.go)
+
+	mu      sync.Mutex
+	last    ReadInfo
+	oldest  time.Time                // fetch time of the oldest stale data served
+	graced  time.Time                // fetch time of the oldest data served in the grace window
+	counts  map[string]HitStats      // reads since the last SaveStats
+	pending map[string]chan struct{} // running background refreshes by what they fetch, closed when done
+}
+
+// ReadInfo describes where the result of the most recent read came from.
+type ReadInfo struct {
+	Cached     bool      // served from the cache rather than the API
+	Stale      bool      // expired cache data served because the API was unreachable
+	Refreshing bool      // expired cache data served while it is refreshed in the background
+	CachedAt   time.Time // when the served data was fetched from the API
 }
 
 // NewCachedClient creates a new cached wrapper around the given client.
@@ -20,24 +40,142 @@ func NewCachedClient(inner api.PaymoAPI, store *Store) *CachedClient {
 	return &CachedClient{inner: inner, store: store}
 }
 
+// LastRead reports where the result of the most recent read came from.
+func (c *CachedClient) LastRead() ReadInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.last
+}
+
+// fromCache reads a fresh cache entry into dest and records the read.
+// A miss records that the result will come from the API.
+func (c *CachedClient) fromCache(resourceType, key string, dest interface{}) bool {
+	if c.store.Get(resourceType, key, dest) != nil {
+		c.setLast(ReadInfo{})
+		return false
+	}
+	c.setLast(c.readInfo(resourceType, key, false))
+	c.count(resourceType, HitStats{Hits: 1})
+	return true
+}
+
+// fromStale reads an expired cache entry into dest and records the read.
+func (c *CachedClient) fromStale(resourceType, key string, dest interface{}) bool {
+	if c.store.GetStale(resourceType, key, dest) != nil {
+		return false
+	}
+	c.setLast(c.readInfo(resourceType, key, true))
+	c.count(resourceType, HitStats{Stale: 1})
+	return true
+}
+
+func (c *CachedClient) readInfo(resourceType, key string, stale bool) ReadInfo {
+	info := ReadInfo{Cached: true, Stale: stale}
+	info.CachedAt, _ = c.store.CachedAt(resourceType, key)
+	return info
+}
+
+func (c *CachedClient) setLast(info ReadInfo) {
+	c.mu.Lock()
+	c.last = info
+	if info.Stale && (c.oldest.IsZero() || info.CachedAt.Before(c.oldest)) {
+		c.oldest = info.CachedAt
+	}
+	if info.Refreshing && (c.graced.IsZero() || info.CachedAt.Before(c.graced)) {
+		c.graced = info.CachedAt
+	}
+	c.mu.Unlock()
+}
+
+// count adds to the read counters of a resource type.
+func (c *CachedClient) count(resourceType string, add HitStats) {
+	c.mu.Lock()
+	if c.counts == nil {
+		c.counts = make(map[string]HitStats)
+	}
+	c.counts[resourceType] = c.counts[resourceType].Add(add)
+	c.mu.Unlock()
+}
+
+// miss counts a read of a resource type that goes to the API.
+func (c *CachedClient) miss(resourceType string) {
+	c.count(resourceType, HitStats{Misses: 1})
+}
+
+// SaveStats adds the reads counted since the last call to the store's
+// counters (see Store.HitStats).
+func (c *CachedClient) SaveStats() error {
+	c.mu.Lock()
+	counts := c.counts
+	c.counts = nil
+	c.mu.Unlock()
+	return c.store.AddHitStats(counts)
+}
+
+// Release closes the cache file so other paymo processes can use it. The
+// next read or write opens it again.
+func (c *CachedClient) Release() error {
+	return c.store.Close()
+}
+
+// remote returns the API client after releasing the cache file, so the
+// file lock is only held while reading and writing the cache, never while
+// waiting for the network.
+func (c *CachedClient) remote() api.PaymoAPI {
+	c.Release()
+	return c.inner
+}
+
+// OldestStale reports when the oldest expired data served by the client was
+// fetched from the API. It reports false if every read was fresh.
+func (c *CachedClient) OldestStale() (time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.oldest, !c.oldest.IsZero()
+}
+
+// OldestRefreshing reports when the oldest expired data the client served
+// while refreshing it (in the grace window) was fetched from the API. It
+// reports false if no read was answered that way.
+func (c *CachedClient) OldestRefreshing() (time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.graced, !c.graced.IsZero()
+}
+
 // --- Auth (not cached) ---
 
 func (c *CachedClient) GetMe() (*api.User, error) {
 	key := "me"
 	var cached api.User
-	if err := c.store.Get("me", key, &cached); err == nil {
+	if c.fromCache("me", key, &cached) {
 		return &cached, nil
 	}
-	user, err := c.inner.GetMe()
+	if c.fromGrace("me", key, &cached, func() error { _, err := c.fetchMe(); return err }) {
+		return &cached, nil
+	}
+	c.miss("me")
+	user, err := c.fetchMe()
 	if err != nil {
+		if isNetworkError(err) && c.fromStale("me", key, &cached) {
+			return &cached, nil
+		}
 		return nil, err
 	}
-	c.store.Set("me", key, user)
+	return user, nil
+}
+
+func (c *CachedClient) fetchMe() (*api.User, error) {
+	user, err := c.remote().GetMe()
+	if err != nil {
+		return nil, err
+	}
+	c.store.Set("me", "me", user)
 	return user, nil
 }
 
 func (c *CachedClient) ValidateAuth() error {
-	return c.inner.ValidateAuth()
+	return c.remote().ValidateAuth()
 }
 
 // --- Clients ---
@@ -45,20 +183,32 @@ func (c *CachedClient) ValidateAuth() error {
 func (c *CachedClient) GetClients() ([]api.PaymoClient, error) {
 	key := "all"
 	var cached []api.PaymoClient
-	if err := c.store.Get("clients", key, &cached); err == nil {
+	if c.fromCache("clients", key, &cached) {
 		return cached, nil
 	}
-	clients, err := c.inner.GetClients()
+	if c.fromGrace("clients", key, &cached, func() error { _, err := c.fetchClients(); return err }) {
+		return cached, nil
+	}
+	c.miss("clients")
+	clients, err := c.fetchClients()
 	if err != nil {
 		if isNetworkError(err) {
 			var stale []api.PaymoClient
-			if c.store.GetStale("clients", key, &stale) == nil {
+			if c.fromStale("clients", key, &stale) {
 				return stale, nil
 			}
 		}
 		return nil, err
 	}
-	c.store.Set("clients", key, clients)
+	return clients, nil
+}
+
+func (c *CachedClient) fetchClients() ([]api.PaymoClient, error) {
+	clients, err := c.remote().GetClients()
+	if err != nil {
+		return nil, err
+	}
+	c.store.Set("clients", "all", clients)
 	return clients, nil
 }
 
@@ -67,41 +217,77 @@ func (c *CachedClient) GetClients() ([]api.PaymoClient, error) {
 func (c *CachedClient) GetProjects(opts *api.ProjectListOptions) ([]api.Project, error) {
 	key := projectsKey(opts)
 	var cached []api.Project
-	if err := c.store.Get("projects", key, &cached); err == nil {
+	if c.fromCache("projects", key, &cached) {
+		return cached, nil
+	}
+	if synced, ok := c.syncedProjects(opts, false); ok {
+		return synced, nil
+	}
+	if c.fromGrace("projects", key, &cached, func() error { _, err := c.fetchProjects(opts); return err }) {
 		return cached, nil
 	}
-	projects, err := c.inner.GetProjects(opts)
+	c.miss("projects")
+	projects, err := c.fetchProjects(opts)
 	if err != nil {
 		if isNetworkError(err) {
 			var stale []api.Project
-			if c.store.GetStale("projects", key, &stale) == nil {
+			if c.fromStale("projects", key, &stale) {
 				return stale, nil
 			}
+			if synced, ok := c.syncedProjects(opts, true); ok {
+				return synced, nil
+			}
 		}
 		return nil, err
 	}
-	c.store.Set("projects", key, projects)
+	return projects, nil
+}
+
+func (c *CachedClient) fetchProjects(opts *api.ProjectListOptions) ([]api.Project, error) {
+	projects, err := c.remote().GetProjects(opts)
+	if err != nil {
+		return nil, err
+	}
+	c.store.Set("projects", projectsKey(opts), projects)
 	c.indexProjects(projects)
+	if opts == nil || (!opts.ActiveOnly && opts.ClientID == 0 && opts.UserID == 0 && opts.UpdatedSince.IsZero()) {
+		c.store.MarkNamesComplete("project", 0)
+	}
 	return projects, nil
 }
 
 func (c *CachedClient) GetProject(id int) (*api.Project, error) {
 	key := fmt.Sprintf("%d", id)
 	var cached api.Project
-	if err := c.store.Get("project", key, &cached); err == nil {
+	if c.fromCache("project", key, &cached) {
+		return &cached, nil
+	}
+	if c.fromRecord("projects", id, &cached, false) {
+		return &cached, nil
+	}
+	if c.fromGrace("project", key, &cached, func() error { _, err := c.fetchProject(id); return err }) {
 		return &cached, nil
 	}
-	project, err := c.inner.GetProject(id)
+	c.miss("project")
+	project, err := c.fetchProject(id)
 	if err != nil {
 		if isNetworkError(err) {
 			var stale api.Project
-			if c.store.GetStale("project", key, &stale) == nil {
+			if c.fromStale("project", key, &stale) || c.fromRecord("projects", id, &stale, true) {
 				return &stale, nil
 			}
 		}
 		return nil, err
 	}
-	c.store.Set("project", key, project)
+	return project, nil
+}
+
+func (c *CachedClient) fetchProject(id int) (*api.Project, error) {
+	project, err := c.remote().GetProject(id)
+	if err != nil {
+		return nil, err
+	}
+	c.store.Set("project", fmt.Sprintf("%d", id), project)
 	c.indexProject(project)
 	return project, nil
 }
@@ -113,7 +299,8 @@ func (c *CachedClient) GetProjectByName(name string) (*api.Project, error) {
 		return c.GetProject(id)
 	}
 	// Cache miss — hit the API
-	project, err := c.inner.GetProjectByName(name)
+	c.miss("project")
+	project, err := c.remote().GetProjectByName(name)
 	if err != nil {
 		return nil, err
 	}
@@ -123,21 +310,29 @@ func (c *CachedClient) GetProjectByName(name string) (*api.Project, error) {
 }
 
 func (c *CachedClient) CreateProject(req *api.CreateProjectRequest) (*api.Project, error) {
-	project, err := c.inner.CreateProject(req)
+	c.settle()
+	project, err := c.remote().CreateProject(req)
 	if err != nil {
 		return nil, err
 	}
 	c.store.InvalidateType("projects")
 	c.store.Set("project", fmt.Sprintf("%d", project.ID), project)
 	c.indexProject(project)
+	c.store.PutRecord("projects", Record{ID: project.ID, UpdatedOn: project.UpdatedOn, Value: project})
 	return project, nil
 }
 
 func (c *CachedClient) ArchiveProject(id int) error {
-	if err := c.inner.ArchiveProject(id); err != nil {
+	c.settle()
+	if err := c.remote().ArchiveProject(id); err != nil {
 		return err
 	}
 	c.store.InvalidateType("projects", "project", "project_by_name")
+	var synced api.Project
+	if c.store.Record("projects", id, &synced) == nil {
+		synced.Active = false
+		c.store.PutRecord("projects", Record{ID: id, UpdatedOn: synced.UpdatedOn, Value: synced})
+	}
 	return nil
 }
 
@@ -146,41 +341,81 @@ func (c *CachedClient) ArchiveProject(id int) error {
 func (c *CachedClient) GetTasks(opts *api.TaskListOptions) ([]api.Task, error) {
 	key := tasksKey(opts)
 	var cached []api.Task
-	if err := c.store.Get("tasks", key, &cached); err == nil {
+	if c.fromCache("tasks", key, &cached) {
+		return cached, nil
+	}
+	if synced, ok := c.syncedTasks(opts, false); ok {
+		return synced, nil
+	}
+	if c.fromGrace("tasks", key, &cached, func() error { _, err := c.fetchTasks(opts); return err }) {
 		return cached, nil
 	}
-	tasks, err := c.inner.GetTasks(opts)
+	c.miss("tasks")
+	tasks, err := c.fetchTasks(opts)
 	if err != nil {
 		if isNetworkError(err) {
 			var stale []api.Task
-			if c.store.GetStale("tasks", key, &stale) == nil {
+			if c.fromStale("tasks", key, &stale) {
 				return stale, nil
 			}
+			if synced, ok := c.syncedTasks(opts, true); ok {
+				return synced, nil
+			}
 		}
 		return nil, err
 	}
-	c.store.Set("tasks", key, tasks)
+	return tasks, nil
+}
+
+func (c *CachedClient) fetchTasks(opts *api.TaskListOptions) ([]api.Task, error) {
+	tasks, err := c.remote().GetTasks(opts)
+	if err != nil {
+		return nil, err
+	}
+	c.store.Set("tasks", tasksKey(opts), tasks)
 	c.indexTasks(tasks)
+	// Open tasks are enough to resolve task names; completed ones are
+	// still found through the API's name search.
+	if opts == nil {
+		c.store.MarkNamesComplete("task", 0)
+	} else if opts.TaskListID == 0 && opts.UserID == 0 && opts.UpdatedSince.IsZero() {
+		c.store.MarkNamesComplete("task", opts.ProjectID)
+	}
 	return tasks, nil
 }
 
 func (c *CachedClient) GetTask(id int) (*api.Task, error) {
 	key := fmt.Sprintf("%d", id)
 	var cached api.Task
-	if err := c.store.Get("task", key, &cached); err == nil {
+	if c.fromCache("task", key, &cached) {
 		return &cached, nil
 	}
-	task, err := c.inner.GetTask(id)
+	if c.fromRecord("tasks", id, &cached, false) {
+		return &cached, nil
+	}
+	if c.fromGrace("task", key, &cached, func() error { _, err := c.fetchTask(id); return err }) {
+		return &cached, nil
+	}
+	c.miss("task")
+	task, err := c.fetchTask(id)
 	if err != nil {
 		if isNetworkError(err) {
 			var stale api.Task
-			if c.store.GetStale("task", key, &stale) == nil {
+			if c.fromStale("task", key, &stale) || c.fromRecord("tasks", id, &stale, true) {
 				return &stale, nil
 			}
 		}
 		return nil, err
 	}
-	c.store.Set("task", key, task)
+	return task, nil
+}
+
+func (c *CachedClient) fetchTask(id int) (*api.Task, error) {
+	task, err := c.remote().GetTask(id)
+	if err != nil {
+		return nil, err
+	}
+	c.store.Set("task", fmt.Sprintf("%d", id), task)
 	c.indexTask(task)
 	return task, nil
 }
@@ -190,7 +425,8 @@ func (c *CachedClient) GetTaskByName(projectID int, name string) (*api.Task, err
 	if id, err := c.store.LookupName("task", nameLower, projectID); err == nil {
 		return c.GetTask(id)
 	}
-	task, err := c.inner.GetTaskByName(projectID, name)
+	c.miss("task")
+	task, err := c.remote().GetTaskByName(projectID, name)
 	if err != nil {
 		return nil, err
 	}
@@ -200,35 +436,66 @@ func (c *CachedClient) GetTaskByName(projectID int, name string) (*api.Task, err
 }
 
 func (c *CachedClient) CreateTask(req *api.CreateTaskRequest) (*api.Task, error) {
-	task, err := c.inner.CreateTask(req)
+	c.settle()
+	task, err := c.remote().CreateTask(req)
 	if err != nil {
 		return nil, err
 	}
 	c.store.InvalidateType("tasks")
 	c.store.Set("task", fmt.Sprintf("%d", task.ID), task)
 	c.indexTask(task)
+	c.store.PutRecord("tasks", Record{ID: task.ID, UpdatedOn: task.UpdatedOn, Value: task})
 	return task, nil
 }
 
 func (c *CachedClient) CompleteTask(id int) error {
-	if err := c.inner.CompleteTask(id); err != nil {
+	c.settle()
+	if err := c.remote().CompleteTask(id); err != nil {
 		return err
 	}
 	c.store.InvalidateType("tasks", "task", "task_by_name")
+	var synced api.Task
+	if c.store.Record("tasks", id, &synced) == nil {
+		synced.Complete = true
+		c.store.PutRecord("tasks", Record{ID: id, UpdatedOn: synced.UpdatedOn, Value: synced})
+	}
 	return nil
 }
 
 func (c *CachedClient) GetTaskLists(projectID int) ([]api.TaskList, error) {
 	key := fmt.Sprintf("project=%d", projectID)
 	var cached []api.TaskList
-	if err := c.store.Get("tasklists", key, &cached); err == nil {
+	if c.fromCache("tasklists", key, &cached) {
 		return cached, nil
 	}
-	lists, err := c.inner.GetTaskLists(projectID)
+	if synced, ok := c.syncedTaskLists(projectID, false); ok {
+		return synced, nil
+	}
+	if c.fromGrace("tasklists", key, &cached, func() error { _, err := c.fetchTaskLists(projectID); return err }) {
+		return cached, nil
+	}
+	c.miss("tasklists")
+	lists, err := c.fetchTaskLists(projectID)
+	if err != nil {
+		if isNetworkError(err) {
+			if c.fromStale("tasklists", key, &cached) {
+				return cached, nil
+			}
+			if synced, ok := c.syncedTaskLists(projectID, true); ok {
+				return synced, nil
+			}
+		}
+		return nil, err
+	}
+	return lists, nil
+}
+
+func (c *CachedClient) fetchTaskLists(projectID int) ([]api.TaskList, error) {
+	lists, err := c.remote().GetTaskLists(projectID)
 	if err != nil {
 		return nil, err
 	}
-	c.store.Set("tasklists", key, lists)
+	c.store.Set("tasklists", fmt.Sprintf("project=%d", projectID), lists)
 	return lists, nil
 }
 
@@ -237,125 +504,390 @@ func (c *CachedClient) GetTaskLists(projectID int) ([]api.TaskList, error) {
 func (c *CachedClient) GetEntries(opts *api.EntryListOptions) ([]api.TimeEntry, error) {
 	key := entriesKey(opts)
 	var cached []api.TimeEntry
-	if err := c.store.Get("entries", key, &cached); err == nil {
+	if c.fromCache("entries", key, &cached) {
+		return cached, nil
+	}
+	if synced, ok := c.syncedEntries(opts, false); ok {
+		return synced, nil
+	}
+	if c.fromGrace("entries", key, &cached, func() error { _, err := c.fetchEntries(opts); return err }) {
 		return cached, nil
 	}
-	entries, err := c.inner.GetEntries(opts)
+	c.miss("entries")
+	entries, err := c.fetchEntries(opts)
 	if err != nil {
 		if isNetworkError(err) {
 			var stale []api.TimeEntry
-			if c.store.GetStale("entries", key, &stale) == nil {
+			if c.fromStale("entries", key, &stale) {
 				return stale, nil
 			}
+			if synced, ok := c.syncedEntries(opts, true); ok {
+				return synced, nil
+			}
 		}
 		return nil, err
 	}
-	c.store.Set("entries", key, entries)
+	return entries, nil
+}
+
+func (c *CachedClient) fetchEntries(opts *api.EntryListOptions) ([]api.TimeEntry, error) {
+	entries, err := c.remote().GetEntries(opts)
+	if err != nil {
+		return nil, err
+	}
+	c.store.Set("entries", entriesKey(opts), entries)
 	return entries, nil
 }
 
 func (c *CachedClient) GetEntry(id int) (*api.TimeEntry, error) {
 	key := fmt.Sprintf("%d", id)
 	var cached api.TimeEntry
-	if err := c.store.Get("entry", key, &cached); err == nil {
+	if c.fromCache("entry", key, &cached) {
 		return &cached, nil
 	}
-	entry, err := c.inner.GetEntry(id)
+	if c.fromGrace("entry", key, &cached, func() error { _, err := c.fetchEntry(id); return err }) {
+		return &cached, nil
+	}
+	c.miss("entry")
+	entry, err := c.fetchEntry(id)
+	if err != nil {
+		if isNetworkError(err) && (c.fromStale("entry", key, &cached) || c.fromRecord("entries", id, &cached, true)) {
+			return &cached, nil
+		}
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (c *CachedClient) fetchEntry(id int) (*api.TimeEntry, error) {
+	entry, err := c.remote().GetEntry(id)
 	if err != nil {
 		return nil, err
 	}
-	c.store.Set("entry", key, entry)
+	c.store.Set("entry", fmt.Sprintf("%d", id), entry)
 	return entry, nil
 }
 
 func (c *CachedClient) CreateEntry(req *api.CreateTimeEntryRequest) (*api.TimeEntry, error) {
-	entry, err := c.inner.CreateEntry(req)
+	c.settle()
+	entry, err := c.remote().CreateEntry(req)
 	if err != nil {
 		return nil, err
 	}
 	c.store.InvalidateType("entries", "active_entry")
+	c.keepEntry(entry)
 	return entry, nil
 }
 
 func (c *CachedClient) UpdateEntry(id int, req *api.UpdateTimeEntryRequest) (*api.TimeEntry, error) {
-	entry, err := c.inner.UpdateEntry(id, req)
+	c.settle()
+	entry, err := c.remote().UpdateEntry(id, req)
 	if err != nil {
 		return nil, err
 	}
 	c.store.InvalidateType("entries", "entry")
+	c.keepEntry(entry)
 	return entry, nil
 }
 
 func (c *CachedClient) DeleteEntry(id int) error {
-	if err := c.inner.DeleteEntry(id); err != nil {
+	c.settle()
+	if err := c.remote().DeleteEntry(id); err != nil {
 		return err
 	}
 	c.store.InvalidateType("entries", "entry")
+	c.store.DeleteRecord("entries", id)
 	return nil
 }
 
-func (c *CachedClient) GetTodayEntries(userID int) ([]api.TimeEntry, error) {
+func (c *CachedClient) GetTodayEntries(userID int, today time.Time) ([]api.TimeEntry, error) {
 	// Delegate to inner — this is a convenience wrapper that calls GetEntries
 	// with date ranges, and the short TTL on "entries" already covers it.
-	return c.inner.GetTodayEntries(userID)
+	c.miss("entries")
+	return c.remote().GetTodayEntries(userID, today)
 }
 
 func (c *CachedClient) GetActiveEntry(userID int) (*api.TimeEntry, error) {
 	// Never cache active entries — stale data here is dangerous
-	return c.inner.GetActiveEntry(userID)
+	return c.remote().GetActiveEntry(userID)
 }
 
 func (c *CachedClient) StartEntry(taskID int, description string) (*api.TimeEntry, error) {
-	entry, err := c.inner.StartEntry(taskID, description)
+	c.settle()
+	entry, err := c.remote().StartEntry(taskID, description)
 	if err != nil {
 		return nil, err
 	}
 	c.store.InvalidateType("entries", "active_entry")
+	c.keepEntry(entry)
 	return entry, nil
 }
 
 func (c *CachedClient) StopEntry(id int) (*api.TimeEntry, error) {
-	entry, err := c.inner.StopEntry(id)
+	c.settle()
+	entry, err := c.remote().StopEntry(id)
 	if err != nil {
 		return nil, err
 	}
 	c.store.InvalidateType("entries", "active_entry")
+	c.keepEntry(entry)
 	return entry, nil
 }
 
+// --- Synced records ---
+
+// fromRecords answers a read of the given cache type from the synced
+// records of a resource once it has had a full sync. Unless stale is set
+// (the API is unreachable), the last sync must be within the TTL of the
+// resource's list and the last full sync within FullSyncAge, or the grace
+// window after each of them: the records are then served while a sync
+// refreshes them in the background. Time entries are synced by range and aren't
+// refreshed this way.
+func (c *CachedClient) fromRecords(resourceType, resource string, stale bool, read func() error) bool {
+	state, ok := c.store.SyncState(resource)
+	if !ok || state.FullAt.IsZero() || !state.Indexed {
+		return false
+	}
+	refresh := false
+	for _, limit := range []struct {
+		at  time.Time
+		ttl time.Duration
+	}{
+		{state.SyncedAt, c.store.TTL(resource)},
+		{state.FullAt, FullSyncAge}, // may hold deleted records
+	} {
+		age := time.Since(limit.at) - limit.ttl
+		if stale || age <= 0 {
+			continue
+		}
+		if age > c.window(limit.ttl) || resource == "entries" {
+			return false
+		}
+		refresh = true
+	}
+	if read() != nil {
+		return false
+	}
+	c.setLast(ReadInfo{Cached: true, Stale: stale, Refreshing: refresh, CachedAt: state.SyncedAt})
+	if refresh {
+		c.revalidate("sync/"+resource, func() error {
+			_, err := c.Sync(resource, false)
+			return err
+		})
+	}
+	switch {
+	case stale:
+		c.count(resourceType, HitStats{Stale: 1})
+	case refresh:
+		c.count(resourceType, HitStats{Grace: 1})
+	default:
+		c.count(resourceType, HitStats{Hits: 1})
+	}
+	return true
+}
+
+// recordTypes are the cache types of single synced records.
+var recordTypes = map[string]string{
+	"projects": "project",
+	"tasks":    "task",
+	"entries":  "entry",
+}
+
+// fromRecord reads one synced record by ID into dest.
+func (c *CachedClient) fromRecord(resource string, id int, dest interface{}, stale bool) bool {
+	return c.fromRecords(recordTypes[resource], resource, stale, func() error { return c.store.Record(resource, id, dest) })
+}
+
+// syncedProjects answers a project query from the synced projects.
+func (c *CachedClient) syncedProjects(opts *api.ProjectListOptions, stale bool) ([]api.Project, bool) {
+	where := map[string]int{}
+	if opts != nil {
+		if !opts.UpdatedSince.IsZero() {
+			return nil, false
+		}
+		if opts.ClientID > 0 {
+			where["client_id"] = opts.ClientID
+		}
+		if opts.UserID > 0 {
+			where["users"] = opts.UserID
+		}
+	}
+	var all []api.Project
+	if !c.fromRecords("projects", "projects", stale, func() error { return c.store.RecordsWhere("projects", where, &all) }) {
+		return nil, false
+	}
+	projects := all[:0]
+	for _, p := range all {
+		if opts == nil || !opts.ActiveOnly || p.Active {
+			projects = append(projects, p)
+		}
+	}
+	return projects, true
+}
+
+// syncedTasks answers a task query from the synced tasks.
+func (c *CachedClient) syncedTasks(opts *api.TaskListOptions, stale bool) ([]api.Task, bool) {
+	where := map[string]int{}
+	if opts != nil {
+		if !opts.UpdatedSince.IsZero() {
+			return nil, false
+		}
+		if opts.ProjectID > 0 {
+			where["project_id"] = opts.ProjectID
+		}
+		if opts.TaskListID > 0 {
+			where["tasklist_id"] = opts.TaskListID
+		}
+		if opts.UserID > 0 {
+			where["users"] = opts.UserID
+		}
+	}
+	var all []api.Task
+	if !c.fromRecords("tasks", "tasks", stale, func() error { return c.store.RecordsWhere("tasks", where, &all) }) {
+		return nil, false
+	}
+	tasks := all[:0]
+	for _, t := range all {
+		if opts == nil || opts.IncludeCompleted || !t.Complete {
+			tasks = append(tasks, t)
+		}
+	}
+	return tasks, true
+}
+
+// syncedTaskLists answers a task list query from the synced task lists.
+func (c *CachedClient) syncedTaskLists(projectID int, stale bool) ([]api.TaskList, bool) {
+	where := map[string]int{}
+	if projectID > 0 {
+		where["project_id"] = projectID
+	}
+	var lists []api.TaskList
+	if !c.fromRecords("tasklists", "tasklists", stale, func() error { return c.store.RecordsWhere("tasklists", where, &lists) }) {
+		return nil, false
+	}
+	sort.SliceStable(lists, func(i, j int) bool { return lists[i].Seq < lists[j].Seq })
+	return lists, true
+}
+
+// syncedEntries answers an entry query from the synced time entries when a
+// synced range covers it.
+func (c *CachedClient) syncedEntries(opts *api.EntryListOptions, stale bool) ([]api.TimeEntry, bool) {
+	if opts == nil || !opts.UpdatedSince.IsZero() {
+		return nil, false
+	}
+	state, ok := c.store.SyncState("entries")
+	if !ok {
+		return nil, false
+	}
+	if _, ok := state.covering(opts.UserID, opts.StartDate, opts.EndDate); !ok {
+		return nil, false
+	}
+	where := map[string]int{}
+	if opts.UserID > 0 {
+		where["user_id"] = opts.UserID
+	}
+	if opts.ProjectID > 0 {
+		where["project_id"] = opts.ProjectID
+	}
+	if opts.TaskID > 0 {
+		where["task_id"] = opts.TaskID
+	}
+	var all []api.TimeEntry
+	if !c.fromRecords("entries", "entries", stale, func() error { return c.store.RecordsWhere("entries", where, &all) }) {
+		return nil, false
+	}
+
+	var entries []api.TimeEntry
+	for _, e := range all {
+		if e.StartTime.Before(opts.StartDate) || !e.StartTime.Before(opts.EndDate) {
+			continue
+		}
+		if !opts.IncludeProject {
+			e.Project = nil
+			if !opts.IncludeTask {
+				e.Task = nil
+			}
+		}
+		entries = append(entries, e)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].StartTime.Before(entries[j].StartTime) })
+	return entries, true
+}
+
+// keepEntry updates the synced record of an entry written through the
+// client, with the task and project synced entries carry.
+func (c *CachedClient) keepEntry(entry *api.TimeEntry) {
+	if _, ok := c.store.SyncState("entries"); !ok || entry == nil {
+		return
+	}
+	e := *entry
+	if e.Task == nil {
+		if task, err := c.GetTask(e.TaskID); err == nil {
+			e.Task = task
+		}
+	}
+	if e.Project == nil && e.Task != nil {
+		if project, err := c.GetProject(e.Task.ProjectID); err == nil {
+			e.Project = project
+		}
+	}
+	c.store.PutRecord("entries", Record{ID: e.ID, UpdatedOn: e.UpdatedOn, Value: e})
+}
+
+// RateLimit reports the wrapped client's rate limit, if it tracks one.
+func (c *CachedClient) RateLimit() api.RateLimitStatus {
+	if rl, ok := c.inner.(api.RateLimiter); ok {
+		return rl.RateLimit()
+	}
+	return api.RateLimitStatus{}
+}
+
 // --- Name indexing helpers ---
 
+// IndexedNames returns the cached name index for "project" or "task", and
+// whether it holds every name rather than only those fetched so far.
+// A non-zero projectID restricts tasks to that project.
+func (c *CachedClient) IndexedNames(resourceType string, projectID int) ([]NameRef, bool) {
+	return c.store.Names(resourceType, projectID), c.store.NamesComplete(resourceType, projectID)
+}
+
 func (c *CachedClient) indexProject(p *api.Project) {
-	c.store.IndexName("project", strings.ToLower(p.Name), p.ID, 0)
+	c.store.IndexName("project", projectRef(p))
 }
 
 func (c *CachedClient) indexProjects(projects []api.Project) {
+	refs := make([]NameRef, len(projects))
 	for i := range projects {
-		c.indexProject(&projects[i])
+		refs[i] = projectRef(&projects[i])
 	}
+	c.store.IndexName("project", refs...)
 }
 
 func (c *CachedClient) indexTask(t *api.Task) {
-	c.store.IndexName("task", strings.ToLower(t.Name), t.ID, t.ProjectID)
+	c.store.IndexName("task", taskRef(t))
 }
 
 func (c *CachedClient) indexTasks(tasks []api.Task) {
+	refs := make([]NameRef, len(tasks))
 	for i := range tasks {
-		c.indexTask(&tasks[i])
+		refs[i] = taskRef(&tasks[i])
 	}
+	c.store.IndexName("task", refs...)
+}
+
+func projectRef(p *api.Project) NameRef {
+	return NameRef{ID: p.ID, Name: p.Name, Code: p.Code}
+}
+
+func taskRef(t *api.Task) NameRef {
+	return NameRef{ID: t.ID, Name: t.Name, Code: t.Code, ProjectID: t.ProjectID}
 }
 
 // --- Network error detection ---
 
+// isNetworkError reports whether a read failed because the API couldn't
+// answer it (api.IsTransient) or the client is offline, so cached data may
+// stand in.
 func isNetworkError(err error) bool {
-	var apiErr *api.APIError
-	if errors.As(err, &apiErr) {
-		return false // server responded, not a network error
-	}
-	errStr := err.Error()
-	return strings.Contains(errStr, "connection refused") ||
-		strings.Contains(errStr, "no such host") ||
-		strings.Contains(errStr, "network is unreachable") ||
-		strings.Contains(errStr, "i/o timeout") ||
-		strings.Contains(errStr, "dial tcp")
+	return errors.Is(err, ErrOffline) || api.IsTransient(err)
 }
@@ -1,14 +1,21 @@
 package cache
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/ComputClaw/paymo-cli/internal/api"
+	bolt "go.etcd.io/bbolt"
 )
 
+// errNetwork is the error the mocks fail with when the API is unreachable.
+var errNetwork = &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+
 // mockAPI is a mock implementation of api.PaymoAPI for testing.
 type mockAPI struct {
 	getMeCalls        int
@@ -29,7 +36,7 @@ type mockAPI struct {
 func (m *mockAPI) GetMe() (*api.User, error) {
 	m.getMeCalls++
 	if m.networkErr {
-		return nil, errors.New("dial tcp: connection refused")
+		return nil, errNetwork
 	}
 	return &api.User{ID: 1, Name: "Test User", Email: "test@test.com"}, nil
 }
@@ -38,7 +45,7 @@ func (m *mockAPI) ValidateAuth() error { return nil }
 
 func (m *mockAPI) GetClients() ([]api.PaymoClient, error) {
 	if m.networkErr {
-		return nil, errors.New("dial tcp: connection refused")
+		return nil, errNetwork
 	}
 	return []api.PaymoClient{
 		{ID: 1, Name: "Client One", Active: true},
@@ -48,7 +55,7 @@ func (m *mockAPI) GetClients() ([]api.PaymoClient, error) {
 func (m *mockAPI) GetProjects(opts *api.ProjectListOptions) ([]api.Project, error) {
 	m.getProjectsCalls++
 	if m.networkErr {
-		return nil, errors.New("dial tcp: connection refused")
+		return nil, errNetwork
 	}
 	return []api.Project{
 		{ID: 1, Name: "Project One", Active: true},
@@ -59,7 +66,7 @@ func (m *mockAPI) GetProjects(opts *api.ProjectListOptions) ([]api.Project, erro
 func (m *mockAPI) GetProject(id int) (*api.Project, error) {
 	m.getProjectCalls++
 	if m.networkErr {
-		return nil, errors.New("dial tcp: connection refused")
+		return nil, errNetwork
 	}
 	return &api.Project{ID: id, Name: fmt.Sprintf("Project %d", id), Active: true}, nil
 }
@@ -82,7 +89,7 @@ func (m *mockAPI) ArchiveProject(id int) error {
 func (m *mockAPI) GetTasks(opts *api.TaskListOptions) ([]api.Task, error) {
 	m.getTasksCalls++
 	if m.networkErr {
-		return nil, errors.New("dial tcp: connection refused")
+		return nil, errNetwork
 	}
 	return []api.Task{
 		{ID: 1, Name: "Task One", ProjectID: 10},
@@ -93,7 +100,7 @@ func (m *mockAPI) GetTasks(opts *api.TaskListOptions) ([]api.Task, error) {
 func (m *mockAPI) GetTask(id int) (*api.Task, error) {
 	m.getTaskCalls++
 	if m.networkErr {
-		return nil, errors.New("dial tcp: connection refused")
+		return nil, errNetwork
 	}
 	return &api.Task{ID: id, Name: fmt.Sprintf("Task %d", id), ProjectID: 10}, nil
 }
@@ -122,7 +129,7 @@ func (m *mockAPI) GetTaskLists(projectID int) ([]api.TaskList, error) {
 func (m *mockAPI) GetEntries(opts *api.EntryListOptions) ([]api.TimeEntry, error) {
 	m.getEntriesCalls++
 	if m.networkErr {
-		return nil, errors.New("dial tcp: connection refused")
+		return nil, errNetwork
 	}
 	return []api.TimeEntry{
 		{ID: 1, TaskID: 100, Duration: 3600},
@@ -147,7 +154,7 @@ func (m *mockAPI) UpdateEntry(id int, req *api.UpdateTimeEntryRequest) (*api.Tim
 
 func (m *mockAPI) DeleteEntry(id int) error { return nil }
 
-func (m *mockAPI) GetTodayEntries(userID int) ([]api.TimeEntry, error) {
+func (m *mockAPI) GetTodayEntries(userID int, today time.Time) ([]api.TimeEntry, error) {
 	return []api.TimeEntry{{ID: 1, TaskID: 100}}, nil
 }
 
@@ -455,7 +462,7 @@ func TestCachedClient_GetActiveEntry_NeverCached(t *testing.T) {
 func TestCachedClient_GetTodayEntries_PassesThrough(t *testing.T) {
 	cc, _ := newTestCachedClient(t)
 
-	entries, err := cc.GetTodayEntries(1)
+	entries, err := cc.GetTodayEntries(1, time.Date(2026, 2, 9, 0, 0, 0, 0, time.UTC))
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -598,13 +605,13 @@ func TestIsNetworkError(t *testing.T) {
 		err      error
 		expected bool
 	}{
-		{"connection refused", errors.New("dial tcp 127.0.0.1:443: connection refused"), true},
-		{"no such host", errors.New("dial tcp: lookup example.com: no such host"), true},
-		{"network unreachable", errors.New("network is unreachable"), true},
-		{"timeout", errors.New("i/o timeout"), true},
-		{"dial tcp", errors.New("dial tcp error"), true},
+		{"connection refused", fmt.Errorf("executing request: %w", errNetwork), true},
+		{"no such host", &net.DNSError{Err: "no such host", Name: "example.com", IsNotFound: true}, true},
+		{"offline", offlineRead("projects"), true},
+		{"unavailable", &api.APIError{StatusCode: 503}, true},
 		{"API error", &api.APIError{StatusCode: 500, Message: "server error"}, false},
 		{"generic error", errors.New("something went wrong"), false},
+		{"error text only", errors.New("dial tcp: connection refused"), false},
 	}
 
 	for _, tc := range tests {
@@ -628,3 +635,153 @@ func TestCachedClient_DifferentKeysAreSeparate(t *testing.T) {
 		t.Errorf("expected 2 API calls for different keys, got %d", mock.getProjectsCalls)
 	}
 }
+
+func TestCachedClient_LastRead(t *testing.T) {
+	cc, mock := newTestCachedClient(t)
+
+	cc.GetProjects(nil)
+	if info := cc.LastRead(); info.Cached {
+		t.Errorf("first read should come from the API, got %+v", info)
+	}
+
+	cc.GetProjects(nil)
+	info := cc.LastRead()
+	if !info.Cached || info.Stale || info.CachedAt.IsZero() {
+		t.Errorf("second read should be a fresh cache hit, got %+v", info)
+	}
+
+	// Expire the entry and take the API offline: the stale fallback is reported
+	expired := map[string]cacheEntry{}
+	cc.store.backend.View(func(tx Tx) error {
+		return tx.ForEach(entriesPrefix+"projects", func(key string, raw []byte) error {
+			var entry cacheEntry
+			json.Unmarshal(raw, &entry)
+			entry.CachedAt -= 24 * 3600
+			expired[key] = entry
+			return nil
+		})
+	})
+	for key, entry := range expired {
+		putEntry(t, cc.store, "projects", key, entry)
+	}
+	mock.networkErr = true
+
+	projects, err := cc.GetProjects(nil)
+	if err != nil || len(projects) != 2 {
+		t.Fatalf("expected stale projects, got %v, %v", projects, err)
+	}
+	if info := cc.LastRead(); !info.Cached || !info.Stale {
+		t.Errorf("expected stale cache read, got %+v", info)
+	}
+}
+
+func TestCachedClient_SaveStats(t *testing.T) {
+	cc, _ := newTestCachedClient(t)
+
+	cc.GetProjects(nil)
+	cc.GetProjects(nil)
+	cc.GetProject(1)
+	if err := cc.SaveStats(); err != nil {
+		t.Fatalf("SaveStats error: %v", err)
+	}
+	cc.SaveStats() // nothing new to add
+
+	counts, _ := cc.store.HitStats()
+	if counts["projects"] != (HitStats{Hits: 1, Misses: 1}) {
+		t.Errorf("projects counters = %+v, want 1 hit and 1 miss", counts["projects"])
+	}
+	if counts["project"] != (HitStats{Misses: 1}) {
+		t.Errorf("project counters = %+v, want 1 miss", counts["project"])
+	}
+}
+
+// rateLimitedAPI is a mockAPI that reports a rate limit.
+type rateLimitedAPI struct {
+	mockAPI
+}
+
+func (m *rateLimitedAPI) RateLimit() api.RateLimitStatus {
+	return api.RateLimitStatus{Limit: 5, Remaining: 1}
+}
+
+func TestCachedClient_RateLimit(t *testing.T) {
+	cc, _ := newTestCachedClient(t)
+	if status := cc.RateLimit(); status.Limit != 0 {
+		t.Errorf("expected no rate limit from a client without one, got %+v", status)
+	}
+
+	cc = NewCachedClient(&rateLimitedAPI{}, cc.store)
+	if status := cc.RateLimit(); status.Limit != 5 || status.Remaining != 1 {
+		t.Errorf("expected the inner rate limit, got %+v", status)
+	}
+}
+
+// lockCheckingAPI is a mockAPI that tries to open the cache file from
+// within GetProjects, as another paymo process would.
+type lockCheckingAPI struct {
+	mockAPI
+	path    string
+	openErr error
+}
+
+func (m *lockCheckingAPI) GetProjects(opts *api.ProjectListOptions) ([]api.Project, error) {
+	db, err := bolt.Open(m.path, 0600, &bolt.Options{Timeout: 50 * time.Millisecond})
+	if err == nil {
+		db.Close()
+	}
+	m.openErr = err
+	return m.mockAPI.GetProjects(opts)
+}
+
+func TestCachedClient_ReleasesLockDuringAPICalls(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.db")
+	store, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock := &lockCheckingAPI{path: path}
+	cc := NewCachedClient(mock, store)
+	defer cc.Release()
+
+	// Reading the (empty) cache first opens the file and takes the lock
+	if _, err := cc.GetProjects(nil); err != nil {
+		t.Fatal(err)
+	}
+	if mock.openErr != nil {
+		t.Errorf("expected the cache file to be unlocked during the API call, got %v", mock.openErr)
+	}
+
+	// The response is still written to the cache afterwards
+	if _, err := cc.GetProjects(nil); err != nil {
+		t.Fatal(err)
+	}
+	if mock.getProjectsCalls != 1 {
+		t.Errorf("expected the second call to be served from the cache, got %d API calls", mock.getProjectsCalls)
+	}
+}
+
+func TestIndexedNames_Complete(t *testing.T) {
+	cc, _ := newTestCachedClient(t)
+
+	// Single lookups only fill the index partially
+	cc.GetProject(7)
+	if refs, complete := cc.IndexedNames("project", 0); len(refs) != 1 || complete {
+		t.Errorf("after GetProject: %d names, complete %v; want 1, partial", len(refs), complete)
+	}
+	cc.GetProjects(&api.ProjectListOptions{ActiveOnly: true})
+	if _, complete := cc.IndexedNames("project", 0); complete {
+		t.Error("a filtered list must not complete the index")
+	}
+	cc.GetProjects(nil)
+	if refs, complete := cc.IndexedNames("project", 0); len(refs) != 3 || !complete {
+		t.Errorf("after the full list: %d names, complete %v; want 3, complete", len(refs), complete)
+	}
+
+	cc.GetTasks(&api.TaskListOptions{ProjectID: 10})
+	if _, complete := cc.IndexedNames("task", 10); !complete {
+		t.Error("expected the project's task list to complete its index")
+	}
+	if _, complete := cc.IndexedNames("task", 11); complete {
+		t.Error("expected other projects' tasks to stay partial")
+	}
+}
@@ -0,0 +1,246 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// KeySize is the size of a cache encryption key (AES-256).
+const KeySize = 32
+
+// NewKey returns a random cache encryption key.
+func NewKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("generating cache key: %w", err)
+	}
+	return key, nil
+}
+
+// metaBucket holds what an encrypted backend needs to know about its data.
+// It is hidden from the transactions of the backend, so Clear keeps it.
+const metaBucket = "meta"
+
+// keyCheck is encrypted into the meta bucket to tell whether a key opens
+// the data.
+var keyCheck = []byte("paymo-cache")
+
+// encryptedBackend encrypts the values of another backend with AES-GCM.
+// Bucket names and keys (resource types, IDs, query filters) stay in the
+// clear; the cached data does not.
+type encryptedBackend struct {
+	inner Backend
+	aead  cipher.AEAD
+}
+
+// NewEncryptedBackend encrypts the values stored in inner with key, a
+// KeySize key. Data stored unencrypted by an earlier version is encrypted
+// in place. If the data was encrypted with another key, it can't be read
+// and is dropped: the cache starts over.
+func NewEncryptedBackend(inner Backend, key []byte) (Backend, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	b := &encryptedBackend{inner: inner, aead: aead}
+	err = inner.Update(func(tx Tx) error {
+		if check := tx.Get(metaBucket, "check"); check != nil {
+			if opened, err := unseal(aead, check); err == nil && bytes.Equal(opened, keyCheck) {
+				return nil
+			}
+			// Encrypted with a lost key: start over
+			for _, name := range tx.Buckets() {
+				if err := tx.DeleteBucket(name); err != nil {
+					return err
+				}
+			}
+		} else if err := reseal(tx, nil, aead); err != nil {
+			return err
+		}
+		return tx.Put(metaBucket, "check", seal(aead, keyCheck))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("setting up cache encryption: %w", err)
+	}
+	return b, nil
+}
+
+// KeyOpens reports whether the data in inner is encrypted with key.
+func KeyOpens(inner Backend, key []byte) (bool, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return false, err
+	}
+	opens := false
+	err = inner.View(func(tx Tx) error {
+		if check := tx.Get(metaBucket, "check"); check != nil {
+			opened, err := unseal(aead, check)
+			opens = err == nil && bytes.Equal(opened, keyCheck)
+		}
+		return nil
+	})
+	return opens, err
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("cache key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts a value: a random nonce followed by the ciphertext.
+func seal(aead cipher.AEAD, value []byte) []byte {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(value)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		panic(fmt.Sprintf("cache: reading random nonce: %v", err))
+	}
+	return aead.Seal(nonce, nonce, value, nil)
+}
+
+var errUnreadable = errors.New("cache value can't be decrypted")
+
+// unseal decrypts a sealed value.
+func unseal(aead cipher.AEAD, sealed []byte) ([]byte, error) {
+	n := aead.NonceSize()
+	if len(sealed) < n+aead.Overhead() {
+		return nil, errUnreadable
+	}
+	value, err := aead.Open(nil, sealed[:n], sealed[n:], nil)
+	if err != nil {
+		return nil, errUnreadable
+	}
+	return value, nil
+}
+
+// reseal re-encrypts every value of tx from one key to another. A nil from
+// means the values are not encrypted yet.
+func reseal(tx Tx, from, to cipher.AEAD) error {
+	for _, name := range tx.Buckets() {
+		if name == metaBucket {
+			continue
+		}
+		values := map[string][]byte{}
+		tx.ForEach(name, func(key string, value []byte) error {
+			if from == nil {
+				values[key] = append([]byte(nil), value...)
+			} else if opened, err := unseal(from, value); err == nil {
+				values[key] = opened
+			}
+			return nil
+		})
+		for key, value := range values {
+			if err := tx.Put(name, key, seal(to, value)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Rekey re-encrypts the data with a new key in one transaction.
+func (b *encryptedBackend) Rekey(key []byte) error {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+	err = b.inner.Update(func(tx Tx) error {
+		if err := reseal(tx, b.aead, aead); err != nil {
+			return err
+		}
+		return tx.Put(metaBucket, "check", seal(aead, keyCheck))
+	})
+	if err != nil {
+		return err
+	}
+	b.aead = aead
+	return nil
+}
+
+func (b *encryptedBackend) View(fn func(tx Tx) error) error {
+	return b.inner.View(func(tx Tx) error { return fn(encryptedTx{tx, b.aead}) })
+}
+
+func (b *encryptedBackend) Update(fn func(tx Tx) error) error {
+	return b.inner.Update(func(tx Tx) error { return fn(encryptedTx{tx, b.aead}) })
+}
+
+func (b *encryptedBackend) Close() error { return b.inner.Close() }
+
+// encryptedTx encrypts values on the way in and decrypts them on the way
+// out. Values that can't be decrypted read as missing.
+type encryptedTx struct {
+	tx   Tx
+	aead cipher.AEAD
+}
+
+func (t encryptedTx) Get(bucket, key string) []byte {
+	if bucket == metaBucket {
+		return nil
+	}
+	sealed := t.tx.Get(bucket, key)
+	if sealed == nil {
+		return nil
+	}
+	value, err := unseal(t.aead, sealed)
+	if err != nil {
+		return nil
+	}
+	return value
+}
+
+func (t encryptedTx) Put(bucket, key string, value []byte) error {
+	if bucket == metaBucket {
+		return fmt.Errorf("bucket %q is reserved", metaBucket)
+	}
+	return t.tx.Put(bucket, key, seal(t.aead, value))
+}
+
+func (t encryptedTx) Delete(bucket, key string) error {
+	if bucket == metaBucket {
+		return nil
+	}
+	return t.tx.Delete(bucket, key)
+}
+
+func (t encryptedTx) DeleteBucket(bucket string) error {
+	if bucket == metaBucket {
+		return nil
+	}
+	return t.tx.DeleteBucket(bucket)
+}
+
+func (t encryptedTx) ForEach(bucket string, fn func(key string, value []byte) error) error {
+	return t.ForEachPrefix(bucket, "", fn)
+}
+
+func (t encryptedTx) ForEachPrefix(bucket, prefix string, fn func(key string, value []byte) error) error {
+	if bucket == metaBucket {
+		return nil
+	}
+	return t.tx.ForEachPrefix(bucket, prefix, func(key string, sealed []byte) error {
+		value, err := unseal(t.aead, sealed)
+		if err != nil {
+			return nil
+		}
+		return fn(key, value)
+	})
+}
+
+func (t encryptedTx) Buckets() []string {
+	var names []string
+	for _, name := range t.tx.Buckets() {
+		if name != metaBucket {
+			names = append(names, name)
+		}
+	}
+	return names
+}
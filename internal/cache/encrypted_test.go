@@ -0,0 +1,205 @@
+package cache
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testKey(t *testing.T) []byte {
+	t.Helper()
+	key, err := NewKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestEncryptedBackend_RoundTrip(t *testing.T) {
+	for name, inner := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			b, err := NewEncryptedBackend(inner, testKey(t))
+			if err != nil {
+				t.Fatalf("NewEncryptedBackend: %v", err)
+			}
+			b.Update(func(tx Tx) error {
+				tx.Put("records/clients", "1", []byte(`{"name":"Acme"}`))
+				return tx.Put("records/clients", "2", []byte(`{"name":"Globex"}`))
+			})
+
+			b.View(func(tx Tx) error {
+				if got := string(tx.Get("records/clients", "1")); got != `{"name":"Acme"}` {
+					t.Errorf("Get = %q", got)
+				}
+				n := 0
+				tx.ForEach("records/clients", func(string, []byte) error { n++; return nil })
+				if n != 2 {
+					t.Errorf("ForEach saw %d values, want 2", n)
+				}
+				if got := tx.Buckets(); len(got) != 1 || got[0] != "records/clients" {
+					t.Errorf("Buckets = %v, want the meta bucket hidden", got)
+				}
+				return nil
+			})
+
+			inner.View(func(tx Tx) error {
+				if raw := tx.Get("records/clients", "1"); raw == nil || bytes.Contains(raw, []byte("Acme")) {
+					t.Errorf("stored value = %q, want it encrypted", raw)
+				}
+				return nil
+			})
+		})
+	}
+}
+
+func TestEncryptedBackend_EncryptsPlainData(t *testing.T) {
+	inner := NewMemoryBackend()
+	inner.Update(func(tx Tx) error { return tx.Put("entries/project", "1", []byte("plain")) })
+
+	b, err := NewEncryptedBackend(inner, testKey(t))
+	if err != nil {
+		t.Fatalf("NewEncryptedBackend: %v", err)
+	}
+	b.View(func(tx Tx) error {
+		if got := string(tx.Get("entries/project", "1")); got != "plain" {
+			t.Errorf("Get = %q, want the plain value kept", got)
+		}
+		return nil
+	})
+	inner.View(func(tx Tx) error {
+		if string(tx.Get("entries/project", "1")) == "plain" {
+			t.Error("expected the plain value to be encrypted in place")
+		}
+		return nil
+	})
+}
+
+func TestEncryptedBackend_WrongKeyStartsOver(t *testing.T) {
+	inner := NewMemoryBackend()
+	b, _ := NewEncryptedBackend(inner, testKey(t))
+	b.Update(func(tx Tx) error { return tx.Put("entries/project", "1", []byte("secret")) })
+
+	other, err := NewEncryptedBackend(inner, testKey(t))
+	if err != nil {
+		t.Fatalf("NewEncryptedBackend: %v", err)
+	}
+	other.View(func(tx Tx) error {
+		if tx.Get("entries/project", "1") != nil || len(tx.Buckets()) != 0 {
+			t.Error("expected data of another key to be dropped")
+		}
+		return nil
+	})
+	other.Update(func(tx Tx) error { return tx.Put("entries/project", "1", []byte("new")) })
+	other.View(func(tx Tx) error {
+		if got := string(tx.Get("entries/project", "1")); got != "new" {
+			t.Errorf("Get = %q, want new", got)
+		}
+		return nil
+	})
+}
+
+func TestKeyOpens(t *testing.T) {
+	inner := NewMemoryBackend()
+	if opens, err := KeyOpens(inner, testKey(t)); err != nil || opens {
+		t.Errorf("expected no key to open an empty backend, got %v, %v", opens, err)
+	}
+
+	key := testKey(t)
+	NewEncryptedBackend(inner, key)
+	if opens, err := KeyOpens(inner, key); err != nil || !opens {
+		t.Errorf("expected the key to open its data, got %v, %v", opens, err)
+	}
+	if opens, err := KeyOpens(inner, testKey(t)); err != nil || opens {
+		t.Errorf("expected another key not to, got %v, %v", opens, err)
+	}
+}
+
+func TestEncryptedBackend_BadKey(t *testing.T) {
+	if _, err := NewEncryptedBackend(NewMemoryBackend(), []byte("short")); err == nil {
+		t.Error("expected a short key to be rejected")
+	}
+}
+
+func TestEncryptedBackend_MetaReserved(t *testing.T) {
+	b, _ := NewEncryptedBackend(NewMemoryBackend(), testKey(t))
+	b.Update(func(tx Tx) error {
+		if err := tx.Put(metaBucket, "check", []byte("x")); err == nil {
+			t.Error("expected writes to the meta bucket to fail")
+		}
+		tx.DeleteBucket(metaBucket)
+		return nil
+	})
+	b.View(func(tx Tx) error {
+		if tx.Get(metaBucket, "check") != nil {
+			t.Error("expected the meta bucket to be hidden")
+		}
+		return nil
+	})
+}
+
+func TestOpenEncrypted_Rekey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	key := testKey(t)
+
+	store, err := OpenEncrypted(path, key)
+	if err != nil {
+		t.Fatalf("OpenEncrypted: %v", err)
+	}
+	store.Set("project", "1", map[string]string{"name": "Secret Project"})
+	store.Clear()
+	store.Set("project", "1", map[string]string{"name": "Secret Project"})
+
+	newKey := testKey(t)
+	if err := store.Rekey(newKey); err != nil {
+		t.Fatalf("Rekey: %v", err)
+	}
+	var got map[string]string
+	if err := store.Get("project", "1", &got); err != nil || got["name"] != "Secret Project" {
+		t.Errorf("Get after Rekey = %v", got)
+	}
+	store.Close()
+
+	raw, _ := os.ReadFile(path)
+	if bytes.Contains(raw, []byte("Secret Project")) {
+		t.Error("expected the cache file not to contain the data in the clear")
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("cache file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	store, _ = OpenEncrypted(path, newKey)
+	if err := store.Get("project", "1", &got); err != nil {
+		t.Error("expected the new key to open the cache")
+	}
+	store.Close()
+
+	store, _ = OpenEncrypted(path, key)
+	defer store.Close()
+	if err := store.Get("project", "1", &got); err == nil {
+		t.Error("expected the old key to no longer open the cache")
+	}
+}
+
+func TestRekey_Unencrypted(t *testing.T) {
+	store := newTestStore(t)
+	if err := store.Rekey(testKey(t)); err == nil {
+		t.Error("expected Rekey to fail on an unencrypted store")
+	}
+}
+
+func TestOpen_TightensMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	store, _ := Open(path)
+	store.Close()
+	os.Chmod(path, 0644)
+
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	store.Close()
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("cache file mode = %v, want 0600", info.Mode().Perm())
+	}
+}
@@ -0,0 +1,196 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Synced records are indexed by their foreign keys so that filtered lists
+// can be answered without reading every record: bucket
+// "index/<resource>/<field>" holds a key "<value>/<record key>" for each
+// record with that field value.
+const indexPrefix = "index/"
+
+// indexedFields are the fields indexed for each synced resource. Records
+// are also found by ID, their key.
+var indexedFields = map[string][]string{
+	"projects":  {"client_id", "users"},
+	"tasks":     {"project_id", "tasklist_id", "users"},
+	"tasklists": {"project_id"},
+	"entries":   {"user_id", "task_id", "project_id"},
+}
+
+// indexFields are the foreign keys of a synced record. Time entries get
+// their project from the included task.
+type indexFields struct {
+	ClientID   int   `json:"client_id"`
+	ProjectID  int   `json:"project_id"`
+	TaskListID int   `json:"tasklist_id"`
+	TaskID     int   `json:"task_id"`
+	UserID     int   `json:"user_id"`
+	Users      []int `json:"users"`
+	Task       *struct {
+		ProjectID int `json:"project_id"`
+	} `json:"task"`
+}
+
+// values returns the values of field, leaving out zero IDs.
+func (f indexFields) values(field string) []int {
+	var ids []int
+	switch field {
+	case "client_id":
+		ids = []int{f.ClientID}
+	case "project_id":
+		ids = []int{f.ProjectID}
+		if f.Task != nil {
+			ids = []int{f.Task.ProjectID}
+		}
+	case "tasklist_id":
+		ids = []int{f.TaskListID}
+	case "task_id":
+		ids = []int{f.TaskID}
+	case "user_id":
+		ids = []int{f.UserID}
+	case "users":
+		ids = f.Users
+	}
+	var out []int
+	for _, id := range ids {
+		if id > 0 {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+func indexBucket(resourceType, field string) string {
+	return indexPrefix + resourceType + "/" + field
+}
+
+// indexRecord adds (or with remove, deletes) the index keys of a record.
+func indexRecord(tx Tx, resourceType, key string, raw []byte, remove bool) error {
+	fields := indexedFields[resourceType]
+	if len(fields) == 0 {
+		return nil
+	}
+	var f indexFields
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return nil // not indexable, found by ID only
+	}
+	for _, field := range fields {
+		for _, id := range f.values(field) {
+			ik := fmt.Sprintf("%d/%s", id, key)
+			var err error
+			if remove {
+				err = tx.Delete(indexBucket(resourceType, field), ik)
+			} else {
+				err = tx.Put(indexBucket(resourceType, field), ik, []byte{})
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// reindex rebuilds the indexes of a resource from its records.
+func reindex(tx Tx, resourceType string) error {
+	for _, field := range indexedFields[resourceType] {
+		if err := tx.DeleteBucket(indexBucket(resourceType, field)); err != nil {
+			return err
+		}
+	}
+	type record struct {
+		key string
+		raw []byte
+	}
+	var records []record
+	tx.ForEach(recordsPrefix+resourceType, func(key string, raw []byte) error {
+		records = append(records, record{key, append([]byte(nil), raw...)})
+		return nil
+	})
+	for _, r := range records {
+		if err := indexRecord(tx, resourceType, r.key, r.raw, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecordsWhere decodes into dest, a pointer to a slice, the synced records
+// of a resource whose indexed fields have the given values, in ID order.
+// With no conditions it returns every record.
+func (s *Store) RecordsWhere(resourceType string, where map[string]int, dest interface{}) error {
+	if len(where) == 0 {
+		return s.Records(resourceType, dest)
+	}
+	for field := range where {
+		if !isIndexed(resourceType, field) {
+			return fmt.Errorf("%s records are not indexed by %s", resourceType, field)
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	err := s.backend.View(func(tx Tx) error {
+		var keys map[string]bool
+		for field, value := range where {
+			matched := map[string]bool{}
+			prefix := fmt.Sprintf("%d/", value)
+			tx.ForEachPrefix(indexBucket(resourceType, field), prefix, func(ik string, _ []byte) error {
+				if key := strings.TrimPrefix(ik, prefix); keys == nil || keys[key] {
+					matched[key] = true
+				}
+				return nil
+			})
+			keys = matched
+		}
+
+		sorted := make([]string, 0, len(keys))
+		for key := range keys {
+			sorted = append(sorted, key)
+		}
+		sort.Strings(sorted)
+		for _, key := range sorted {
+			raw := tx.Get(recordsPrefix+resourceType, key)
+			if raw == nil {
+				continue
+			}
+			if buf.Len() > 1 {
+				buf.WriteByte(',')
+			}
+			buf.Write(raw)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	buf.WriteByte(']')
+	return json.Unmarshal(buf.Bytes(), dest)
+}
+
+// Record decodes one synced record into dest. It returns ErrCacheMiss if
+// the record is not synced.
+func (s *Store) Record(resourceType string, id int, dest interface{}) error {
+	return s.backend.View(func(tx Tx) error {
+		raw := tx.Get(recordsPrefix+resourceType, recordKey(id))
+		if raw == nil {
+			return ErrCacheMiss
+		}
+		return json.Unmarshal(raw, dest)
+	})
+}
+
+func isIndexed(resourceType, field string) bool {
+	for _, f := range indexedFields[resourceType] {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+type indexedTask struct {
+	ID         int   `json:"id"`
+	ProjectID  int   `json:"project_id"`
+	TaskListID int   `json:"tasklist_id"`
+	Users      []int `json:"users,omitempty"`
+}
+
+func taskRecords(tasks ...indexedTask) []Record {
+	records := make([]Record, len(tasks))
+	for i, t := range tasks {
+		records[i] = Record{ID: t.ID, Value: t}
+	}
+	return records
+}
+
+func recordIDs(t *testing.T, store *Store, resourceType string, where map[string]int) []int {
+	t.Helper()
+	var got []indexedTask
+	if err := store.RecordsWhere(resourceType, where, &got); err != nil {
+		t.Fatalf("RecordsWhere(%v) error: %v", where, err)
+	}
+	ids := []int{}
+	for _, r := range got {
+		ids = append(ids, r.ID)
+	}
+	return ids
+}
+
+func equalIDs(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestRecordsWhere(t *testing.T) {
+	for name, b := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			store := NewStore(b)
+			store.MergeRecords("tasks", taskRecords(
+				indexedTask{ID: 1, ProjectID: 5, TaskListID: 50, Users: []int{7}},
+				indexedTask{ID: 2, ProjectID: 5, TaskListID: 51, Users: []int{7, 8}},
+				indexedTask{ID: 3, ProjectID: 55, TaskListID: 50},
+			), true, time.Now())
+
+			tests := []struct {
+				where map[string]int
+				want  []int
+			}{
+				{nil, []int{1, 2, 3}},
+				{map[string]int{"project_id": 5}, []int{1, 2}},
+				{map[string]int{"project_id": 55}, []int{3}},
+				{map[string]int{"users": 8}, []int{2}},
+				{map[string]int{"project_id": 5, "tasklist_id": 50}, []int{1}},
+				{map[string]int{"project_id": 55, "users": 7}, []int{}},
+				{map[string]int{"project_id": 9}, []int{}},
+			}
+			for _, tt := range tests {
+				if got := recordIDs(t, store, "tasks", tt.where); !equalIDs(got, tt.want) {
+					t.Errorf("RecordsWhere(%v) = %v, want %v", tt.where, got, tt.want)
+				}
+			}
+
+			var got []indexedTask
+			if err := store.RecordsWhere("tasks", map[string]int{"client_id": 1}, &got); err == nil {
+				t.Error("expected an error for a field that is not indexed")
+			}
+		})
+	}
+}
+
+func TestRecordsWhere_IndexFollowsChanges(t *testing.T) {
+	store := NewStore(NewMemoryBackend())
+	now := time.Now()
+	store.MergeRecords("tasks", taskRecords(
+		indexedTask{ID: 1, ProjectID: 5},
+		indexedTask{ID: 2, ProjectID: 5},
+	), true, now)
+
+	// Task 1 moves to project 6; a full sync drops task 2
+	store.MergeRecords("tasks", taskRecords(indexedTask{ID: 1, ProjectID: 6}), true, now)
+	if got := recordIDs(t, store, "tasks", map[string]int{"project_id": 5}); len(got) != 0 {
+		t.Errorf("expected no tasks in project 5, got %v", got)
+	}
+	if got := recordIDs(t, store, "tasks", map[string]int{"project_id": 6}); !equalIDs(got, []int{1}) {
+		t.Errorf("expected task 1 in project 6, got %v", got)
+	}
+
+	store.PutRecord("tasks", Record{ID: 3, Value: indexedTask{ID: 3, ProjectID: 6}})
+	store.DeleteRecord("tasks", 1)
+	if got := recordIDs(t, store, "tasks", map[string]int{"project_id": 6}); !equalIDs(got, []int{3}) {
+		t.Errorf("expected task 3 in project 6, got %v", got)
+	}
+}
+
+func TestMergeRecords_IndexesExistingRecords(t *testing.T) {
+	store := NewStore(NewMemoryBackend())
+	// Records synced before they were indexed
+	store.backend.Update(func(tx Tx) error {
+		putJSON(tx, recordsPrefix+"tasks", recordKey(1), indexedTask{ID: 1, ProjectID: 5})
+		return putJSON(tx, syncBucket, "tasks", SyncState{FullAt: time.Now()})
+	})
+
+	store.MergeRecords("tasks", taskRecords(indexedTask{ID: 2, ProjectID: 5}), false, time.Now())
+	if state, _ := store.SyncState("tasks"); !state.Indexed {
+		t.Error("expected the records to be marked indexed")
+	}
+	if got := recordIDs(t, store, "tasks", map[string]int{"project_id": 5}); !equalIDs(got, []int{1, 2}) {
+		t.Errorf("expected tasks 1 and 2 in project 5, got %v", got)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	store := NewStore(NewMemoryBackend())
+	store.MergeRecords("tasks", taskRecords(indexedTask{ID: 1, ProjectID: 5}), true, time.Now())
+
+	var got indexedTask
+	if err := store.Record("tasks", 1, &got); err != nil || got.ProjectID != 5 {
+		t.Errorf("Record = %+v, %v", got, err)
+	}
+	if err := store.Record("tasks", 2, &got); err != ErrCacheMiss {
+		t.Errorf("expected ErrCacheMiss, got %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package cache
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/ComputClaw/paymo-cli/internal/api"
 )
@@ -28,6 +29,9 @@ func projectsKey(opts *api.ProjectListOptions) string {
 	if opts.IncludeClient {
 		parts = append(parts, "inc_client")
 	}
+	if !opts.UpdatedSince.IsZero() {
+		parts = append(parts, "since="+opts.UpdatedSince.UTC().Format(time.RFC3339))
+	}
 	if len(parts) == 0 {
 		return "all"
 	}
@@ -57,6 +61,9 @@ func tasksKey(opts *api.TaskListOptions) string {
 	if opts.IncludeProject {
 		parts = append(parts, "inc_project")
 	}
+	if !opts.UpdatedSince.IsZero() {
+		parts = append(parts, "since="+opts.UpdatedSince.UTC().Format(time.RFC3339))
+	}
 	if len(parts) == 0 {
 		return "all"
 	}
@@ -84,6 +91,9 @@ func entriesKey(opts *api.EntryListOptions) string {
 	if !opts.EndDate.IsZero() {
 		parts = append(parts, fmt.Sprintf("end=%s", opts.EndDate.Format("2006-01-02")))
 	}
+	if !opts.UpdatedSince.IsZero() {
+		parts = append(parts, "since="+opts.UpdatedSince.UTC().Format(time.RFC3339))
+	}
 	if len(parts) == 0 {
 		return "all"
 	}
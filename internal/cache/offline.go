@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ComputClaw/paymo-cli/internal/api"
+)
+
+// ErrOffline is returned for anything an offline client can't answer from
+// the cache.
+var ErrOffline = errors.New("paymo is offline (--offline or PAYMO_OFFLINE)")
+
+// offlineError explains what couldn't be done offline.
+type offlineError struct {
+	msg string
+}
+
+func (e *offlineError) Error() string { return e.msg + ": " + ErrOffline.Error() }
+func (e *offlineError) Unwrap() error { return ErrOffline }
+
+func offlineRead(what string) error {
+	return &offlineError{fmt.Sprintf("%s not in the cache — run 'paymo sync' while online", what)}
+}
+
+func offlineWrite(action string) error {
+	return &offlineError{fmt.Sprintf("cannot %s", action)}
+}
+
+// NewOfflineClient returns a cached client that never touches the network.
+// Reads are served from the cache and synced records, expired or not (the
+// read is then reported stale); anything not cached, and every write, fails
+// with an error wrapping ErrOffline.
+func NewOfflineClient(store *Store) *CachedClient {
+	return NewCachedClient(offlineAPI{}, store)
+}
+
+// offlineAPI is the PaymoAPI under an offline client: every call fails as
+// if the API were unreachable, so the cached client falls back to the cache.
+type offlineAPI struct{}
+
+func (offlineAPI) GetMe() (*api.User, error) { return nil, offlineRead("current user") }
+func (offlineAPI) ValidateAuth() error       { return ErrOffline }
+
+func (offlineAPI) GetClients() ([]api.PaymoClient, error) { return nil, offlineRead("clients") }
+
+func (offlineAPI) GetProjects(*api.ProjectListOptions) ([]api.Project, error) {
+	return nil, offlineRead("projects")
+}
+func (offlineAPI) GetProject(id int) (*api.Project, error) {
+	return nil, offlineRead(fmt.Sprintf("project %d", id))
+}
+func (offlineAPI) GetProjectByName(name string) (*api.Project, error) {
+	return nil, offlineRead(fmt.Sprintf("project %q", name))
+}
+func (offlineAPI) CreateProject(*api.CreateProjectRequest) (*api.Project, error) {
+	return nil, offlineWrite("create a project")
+}
+func (offlineAPI) ArchiveProject(int) error { return offlineWrite("archive a project") }
+
+func (offlineAPI) GetTasks(*api.TaskListOptions) ([]api.Task, error) {
+	return nil, offlineRead("tasks")
+}
+func (offlineAPI) GetTask(id int) (*api.Task, error) {
+	return nil, offlineRead(fmt.Sprintf("task %d", id))
+}
+func (offlineAPI) GetTaskByName(projectID int, name string) (*api.Task, error) {
+	return nil, offlineRead(fmt.Sprintf("task %q", name))
+}
+func (offlineAPI) CreateTask(*api.CreateTaskRequest) (*api.Task, error) {
+	return nil, offlineWrite("create a task")
+}
+func (offlineAPI) CompleteTask(int) error { return offlineWrite("complete a task") }
+func (offlineAPI) GetTaskLists(int) ([]api.TaskList, error) {
+	return nil, offlineRead("task lists")
+}
+
+func (offlineAPI) GetEntries(*api.EntryListOptions) ([]api.TimeEntry, error) {
+	return nil, offlineRead("time entries")
+}
+func (offlineAPI) GetEntry(id int) (*api.TimeEntry, error) {
+	return nil, offlineRead(fmt.Sprintf("time entry %d", id))
+}
+func (offlineAPI) CreateEntry(*api.CreateTimeEntryRequest) (*api.TimeEntry, error) {
+	return nil, offlineWrite("create a time entry")
+}
+func (offlineAPI) UpdateEntry(int, *api.UpdateTimeEntryRequest) (*api.TimeEntry, error) {
+	return nil, offlineWrite("update a time entry")
+}
+func (offlineAPI) DeleteEntry(int) error { return offlineWrite("delete a time entry") }
+func (offlineAPI) GetTodayEntries(int, time.Time) ([]api.TimeEntry, error) {
+	return nil, offlineRead("today's time entries")
+}
+func (offlineAPI) GetActiveEntry(int) (*api.TimeEntry, error) {
+	return nil, &offlineError{"the running timer is never cached"}
+}
+func (offlineAPI) StartEntry(int, string) (*api.TimeEntry, error) {
+	return nil, offlineWrite("start a timer")
+}
+func (offlineAPI) StopEntry(int) (*api.TimeEntry, error) {
+	return nil, offlineWrite("stop a timer")
+}
@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ComputClaw/paymo-cli/internal/api"
+)
+
+func TestOfflineClient_ServesExpiredCache(t *testing.T) {
+	store := NewStore(NewMemoryBackend())
+	store.MergeRecords("projects", []Record{
+		{ID: 1, Value: api.Project{ID: 1, Name: "One", Active: true}},
+	}, true, time.Now().Add(-48*time.Hour))
+	putEntry(t, store, "me", "me", cacheEntry{
+		Data:       []byte(`{"id":7,"name":"Me"}`),
+		CachedAt:   time.Now().Add(-72 * time.Hour).Unix(),
+		TTLSeconds: 60,
+	})
+
+	client := NewOfflineClient(store)
+	if _, ok := client.OldestStale(); ok {
+		t.Error("expected no stale reads yet")
+	}
+	projects, err := client.GetProjects(&api.ProjectListOptions{ActiveOnly: true})
+	if err != nil || len(projects) != 1 {
+		t.Fatalf("expected the synced projects, got %+v, %v", projects, err)
+	}
+	user, err := client.GetMe()
+	if err != nil || user.ID != 7 {
+		t.Fatalf("expected the expired user, got %+v, %v", user, err)
+	}
+
+	oldest, ok := client.OldestStale()
+	if !ok || time.Since(oldest) < 71*time.Hour {
+		t.Errorf("expected the oldest stale read from 72h ago, got %v, %v", oldest, ok)
+	}
+}
+
+func TestOfflineClient_MissesAndWrites(t *testing.T) {
+	client := NewOfflineClient(NewStore(NewMemoryBackend()))
+
+	_, err := client.GetTasks(nil)
+	if !errors.Is(err, ErrOffline) || !strings.Contains(err.Error(), "paymo sync") {
+		t.Errorf("expected an offline cache miss, got %v", err)
+	}
+	_, err = client.CreateEntry(&api.CreateTimeEntryRequest{TaskID: 1})
+	if !errors.Is(err, ErrOffline) || !strings.Contains(err.Error(), "cannot create a time entry") {
+		t.Errorf("expected the write to be refused, got %v", err)
+	}
+	if err := client.DeleteEntry(1); !errors.Is(err, ErrOffline) {
+		t.Errorf("expected the delete to be refused, got %v", err)
+	}
+	if _, err := client.Sync("projects", false); !errors.Is(err, ErrOffline) {
+		t.Errorf("expected sync to fail offline, got %v", err)
+	}
+}
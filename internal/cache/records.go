@@ -0,0 +1,312 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Synced records are kept apart from cached responses: one bucket of
+// records per resource, keyed by ID, and the sync state of each resource.
+// They have no TTL and survive InvalidateType; `paymo sync` keeps them
+// current and Clear drops them.
+const (
+	recordsPrefix = "records/"
+	syncBucket    = "sync"
+)
+
+// Record is one synced resource item.
+type Record struct {
+	ID        int
+	UpdatedOn time.Time
+	Value     interface{}
+}
+
+// SyncState is the stored state of a synced resource.
+type SyncState struct {
+	Watermark time.Time  `json:"watermark"` // latest updated_on seen
+	SyncedAt  time.Time  `json:"synced_at"`
+	FullAt    time.Time  `json:"full_at"`            // last sync that fetched every record
+	Coverage  []Coverage `json:"coverage,omitempty"` // synced time entry ranges
+	Indexed   bool       `json:"indexed,omitempty"`  // the records are indexed (index.go)
+}
+
+// Coverage is a range of time entry start times, [From, To), whose entries
+// of a user (0 for every user) are all among the synced records. Entries
+// are synced by range, so each range has its own watermark.
+type Coverage struct {
+	UserID    int       `json:"user_id,omitempty"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+	Watermark time.Time `json:"watermark"`
+}
+
+// Contains reports whether the range covers the entries of userID that
+// start in [from, to).
+func (c Coverage) Contains(userID int, from, to time.Time) bool {
+	return (c.UserID == 0 || c.UserID == userID) && !from.IsZero() && !to.IsZero() &&
+		!from.Before(c.From) && !to.After(c.To)
+}
+
+// covering returns the synced range that covers [from, to) for userID.
+func (s SyncState) covering(userID int, from, to time.Time) (Coverage, bool) {
+	for _, c := range s.Coverage {
+		if c.Contains(userID, from, to) {
+			return c, true
+		}
+	}
+	return Coverage{}, false
+}
+
+// addCoverage records a synced range, joining it with the overlapping and
+// adjacent ranges of the same user. A joined range keeps the older
+// watermark, except of ranges the new one replaces entirely.
+func addCoverage(ranges []Coverage, c Coverage) []Coverage {
+	var out []Coverage
+	for _, r := range ranges {
+		if r.UserID != c.UserID || r.To.Before(c.From) || c.To.Before(r.From) {
+			out = append(out, r)
+			continue
+		}
+		if r.From.Before(c.From) || r.To.After(c.To) {
+			if r.Watermark.Before(c.Watermark) {
+				c.Watermark = r.Watermark
+			}
+			if r.From.Before(c.From) {
+				c.From = r.From
+			}
+			if r.To.After(c.To) {
+				c.To = r.To
+			}
+		}
+	}
+	return append(out, c)
+}
+
+// MergeStats counts how a sync changed the records of a resource.
+type MergeStats struct {
+	Added   int `json:"added"`
+	Changed int `json:"changed"`
+	Removed int `json:"removed"`
+	Total   int `json:"total"`
+}
+
+// recordKey zero-pads IDs so records are stored in ID order.
+func recordKey(id int) string {
+	return fmt.Sprintf("%012d", id)
+}
+
+// MergeRecords stores fetched records of a resource and advances its sync
+// state in one transaction. With replace, the records are the complete set
+// and any stored record not among them is removed; otherwise they are
+// merged into the stored set.
+func (s *Store) MergeRecords(resourceType string, records []Record, replace bool, syncedAt time.Time) (MergeStats, error) {
+	var inScope func([]byte) bool
+	if replace {
+		inScope = func([]byte) bool { return true }
+	}
+	return s.mergeRecords(resourceType, records, inScope, func(state *SyncState) {
+		for _, r := range records {
+			if r.UpdatedOn.After(state.Watermark) {
+				state.Watermark = r.UpdatedOn
+			}
+		}
+		state.SyncedAt = syncedAt
+		if replace {
+			state.FullAt = syncedAt
+		}
+	})
+}
+
+// MergeEntryRange stores time entries fetched for a range and records the
+// range as synced. With full, the records are every entry of the range and
+// stored entries of the range not among them are removed.
+func (s *Store) MergeEntryRange(records []Record, c Coverage, full bool, syncedAt time.Time) (MergeStats, error) {
+	var inScope func([]byte) bool
+	if full {
+		inScope = func(raw []byte) bool {
+			var e entryScope
+			return json.Unmarshal(raw, &e) == nil && c.Contains(e.UserID, e.StartTime, e.StartTime.Add(time.Nanosecond))
+		}
+	}
+	for _, r := range records {
+		if r.UpdatedOn.After(c.Watermark) {
+			c.Watermark = r.UpdatedOn
+		}
+	}
+	return s.mergeRecords("entries", records, inScope, func(state *SyncState) {
+		state.Coverage = addCoverage(state.Coverage, c)
+		state.SyncedAt = syncedAt
+		if full {
+			state.FullAt = syncedAt
+		}
+	})
+}
+
+// entryScope is the part of a synced time entry that decides which range
+// it belongs to.
+type entryScope struct {
+	UserID    int       `json:"user_id"`
+	StartTime time.Time `json:"start_time"`
+}
+
+// mergeRecords stores records in one transaction, removing the stored
+// records inScope that are not among them (none if inScope is nil), then
+// lets update adjust the sync state.
+func (s *Store) mergeRecords(resourceType string, records []Record, inScope func(raw []byte) bool, update func(state *SyncState)) (MergeStats, error) {
+	var stats MergeStats
+	err := s.backend.Update(func(tx Tx) error {
+		bucket := recordsPrefix + resourceType
+		var state SyncState
+		if raw := tx.Get(syncBucket, resourceType); raw != nil {
+			json.Unmarshal(raw, &state)
+		}
+
+		seen := make(map[string]bool, len(records))
+		for _, r := range records {
+			data, err := json.Marshal(r.Value)
+			if err != nil {
+				return err
+			}
+			key := recordKey(r.ID)
+			seen[key] = true
+			if err := putRecord(tx, resourceType, key, data, &stats); err != nil {
+				return err
+			}
+		}
+
+		stale := map[string][]byte{}
+		tx.ForEach(bucket, func(key string, raw []byte) error {
+			if inScope != nil && !seen[key] && inScope(raw) {
+				stale[key] = append([]byte(nil), raw...)
+			} else {
+				stats.Total++
+			}
+			return nil
+		})
+		for key, raw := range stale {
+			if err := indexRecord(tx, resourceType, key, raw, true); err != nil {
+				return err
+			}
+			if err := tx.Delete(bucket, key); err != nil {
+				return err
+			}
+		}
+		stats.Removed = len(stale)
+
+		if !state.Indexed {
+			if err := reindex(tx, resourceType); err != nil {
+				return err
+			}
+			state.Indexed = true
+		}
+		update(&state)
+		return putJSON(tx, syncBucket, resourceType, state)
+	})
+	return stats, err
+}
+
+// putRecord stores a record unless it is unchanged, keeping its index
+// keys current, and counts it in stats.
+func putRecord(tx Tx, resourceType, key string, data []byte, stats *MergeStats) error {
+	bucket := recordsPrefix + resourceType
+	switch old := tx.Get(bucket, key); {
+	case old == nil:
+		stats.Added++
+	case !bytes.Equal(old, data):
+		stats.Changed++
+		if err := indexRecord(tx, resourceType, key, append([]byte(nil), old...), true); err != nil {
+			return err
+		}
+	default:
+		return nil
+	}
+	if err := tx.Put(bucket, key, data); err != nil {
+		return err
+	}
+	return indexRecord(tx, resourceType, key, data, false)
+}
+
+// PutRecord stores or replaces one record of a resource that has been
+// synced, keeping the synced set current after a change made through the
+// CLI. The sync state is left alone, so the next sync still fetches
+// everything changed elsewhere since the last one.
+func (s *Store) PutRecord(resourceType string, r Record) error {
+	data, err := json.Marshal(r.Value)
+	if err != nil {
+		return err
+	}
+	return s.backend.Update(func(tx Tx) error {
+		if tx.Get(syncBucket, resourceType) == nil {
+			return nil
+		}
+		return putRecord(tx, resourceType, recordKey(r.ID), data, &MergeStats{})
+	})
+}
+
+// DeleteRecord removes one synced record of a resource.
+func (s *Store) DeleteRecord(resourceType string, id int) error {
+	return s.backend.Update(func(tx Tx) error {
+		key := recordKey(id)
+		raw := tx.Get(recordsPrefix+resourceType, key)
+		if raw == nil {
+			return nil
+		}
+		if err := indexRecord(tx, resourceType, key, append([]byte(nil), raw...), true); err != nil {
+			return err
+		}
+		return tx.Delete(recordsPrefix+resourceType, key)
+	})
+}
+
+// DropRecords removes the synced records of a resource with their indexes
+// and sync state, so that its next sync is a full one.
+func (s *Store) DropRecords(resourceType string) error {
+	return s.backend.Update(func(tx Tx) error {
+		for _, field := range indexedFields[resourceType] {
+			if err := tx.DeleteBucket(indexBucket(resourceType, field)); err != nil {
+				return err
+			}
+		}
+		if err := tx.DeleteBucket(recordsPrefix + resourceType); err != nil {
+			return err
+		}
+		return tx.Delete(syncBucket, resourceType)
+	})
+}
+
+// Records decodes the synced records of a resource, in ID order, into
+// dest, a pointer to a slice.
+func (s *Store) Records(resourceType string, dest interface{}) error {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	err := s.backend.View(func(tx Tx) error {
+		return tx.ForEach(recordsPrefix+resourceType, func(_ string, raw []byte) error {
+			if buf.Len() > 1 {
+				buf.WriteByte(',')
+			}
+			buf.Write(raw)
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+	buf.WriteByte(']')
+	return json.Unmarshal(buf.Bytes(), dest)
+}
+
+// SyncState returns the sync state of a resource. It reports false if the
+// resource was never synced.
+func (s *Store) SyncState(resourceType string) (SyncState, bool) {
+	var state SyncState
+	found := false
+	s.backend.View(func(tx Tx) error {
+		if raw := tx.Get(syncBucket, resourceType); raw != nil {
+			found = json.Unmarshal(raw, &state) == nil
+		}
+		return nil
+	})
+	return state, found
+}
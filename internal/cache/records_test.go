@@ -0,0 +1,161 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+type testRecord struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestMergeRecords(t *testing.T) {
+	store := NewStore(NewMemoryBackend())
+	t1 := time.Date(2026, 2, 9, 9, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+
+	stats, err := store.MergeRecords("projects", []Record{
+		{ID: 1, UpdatedOn: t1, Value: testRecord{1, "One"}},
+		{ID: 2, UpdatedOn: t1, Value: testRecord{2, "Two"}},
+	}, true, t1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats != (MergeStats{Added: 2, Total: 2}) {
+		t.Errorf("first merge: got %+v", stats)
+	}
+
+	// Changed, unchanged and new records in a delta merge
+	stats, err = store.MergeRecords("projects", []Record{
+		{ID: 1, UpdatedOn: t2, Value: testRecord{1, "One renamed"}},
+		{ID: 2, UpdatedOn: t1, Value: testRecord{2, "Two"}},
+		{ID: 3, UpdatedOn: t1, Value: testRecord{3, "Three"}},
+	}, false, t2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats != (MergeStats{Added: 1, Changed: 1, Total: 3}) {
+		t.Errorf("delta merge: got %+v", stats)
+	}
+
+	state, ok := store.SyncState("projects")
+	if !ok {
+		t.Fatal("expected sync state")
+	}
+	if !state.Watermark.Equal(t2) {
+		t.Errorf("expected watermark %v, got %v", t2, state.Watermark)
+	}
+	if !state.FullAt.Equal(t1) || !state.SyncedAt.Equal(t2) {
+		t.Errorf("unexpected state %+v", state)
+	}
+
+	var records []testRecord
+	if err := store.Records("projects", &records); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(records) != 3 || records[0].Name != "One renamed" || records[2].ID != 3 {
+		t.Errorf("unexpected records %+v", records)
+	}
+}
+
+func TestMergeRecords_ReplaceRemovesMissing(t *testing.T) {
+	store := NewStore(NewMemoryBackend())
+	now := time.Now()
+	store.MergeRecords("tasks", []Record{
+		{ID: 1, Value: testRecord{1, "One"}},
+		{ID: 2, Value: testRecord{2, "Two"}},
+	}, true, now)
+
+	stats, err := store.MergeRecords("tasks", []Record{{ID: 2, Value: testRecord{2, "Two"}}}, true, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats != (MergeStats{Removed: 1, Total: 1}) {
+		t.Errorf("got %+v", stats)
+	}
+
+	var records []testRecord
+	store.Records("tasks", &records)
+	if len(records) != 1 || records[0].ID != 2 {
+		t.Errorf("unexpected records %+v", records)
+	}
+}
+
+func TestRecords_SurviveInvalidate(t *testing.T) {
+	store := NewStore(NewMemoryBackend())
+	store.MergeRecords("clients", []Record{{ID: 1, Value: testRecord{1, "One"}}}, true, time.Now())
+	store.InvalidateType("clients")
+
+	var records []testRecord
+	store.Records("clients", &records)
+	if len(records) != 1 {
+		t.Errorf("expected records to survive invalidation, got %+v", records)
+	}
+
+	store.Clear()
+	records = nil
+	store.Records("clients", &records)
+	if len(records) != 0 {
+		t.Errorf("expected Clear to drop records, got %+v", records)
+	}
+	if _, ok := store.SyncState("clients"); ok {
+		t.Error("expected Clear to drop sync state")
+	}
+}
+
+func TestDropRecords(t *testing.T) {
+	store := NewStore(NewMemoryBackend())
+	store.MergeRecords("tasks", taskRecords(indexedTask{ID: 1, ProjectID: 5}), true, time.Now())
+	store.MergeRecords("clients", []Record{{ID: 1, Value: testRecord{1, "One"}}}, true, time.Now())
+
+	if err := store.DropRecords("tasks"); err != nil {
+		t.Fatalf("DropRecords error: %v", err)
+	}
+	var tasks []indexedTask
+	store.RecordsWhere("tasks", map[string]int{"project_id": 5}, &tasks)
+	if len(tasks) != 0 {
+		t.Errorf("expected the tasks and their index dropped, got %+v", tasks)
+	}
+	if _, ok := store.SyncState("tasks"); ok {
+		t.Error("expected the task sync state dropped")
+	}
+	if _, ok := store.SyncState("clients"); !ok {
+		t.Error("other resources should keep their records")
+	}
+}
+
+func TestAddCoverage(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 2, d, 0, 0, 0, 0, time.UTC) }
+	w1, w2 := day(20), day(25)
+
+	ranges := addCoverage(nil, Coverage{UserID: 7, From: day(1), To: day(10), Watermark: w2})
+	// Another user's range stays separate
+	ranges = addCoverage(ranges, Coverage{UserID: 8, From: day(5), To: day(15), Watermark: w2})
+	// Adjacent range of the same user joins, keeping the older watermark
+	ranges = addCoverage(ranges, Coverage{UserID: 7, From: day(10), To: day(15), Watermark: w1})
+	if len(ranges) != 2 {
+		t.Fatalf("expected 2 ranges, got %+v", ranges)
+	}
+	joined := ranges[1]
+	if !joined.From.Equal(day(1)) || !joined.To.Equal(day(15)) || !joined.Watermark.Equal(w1) {
+		t.Errorf("unexpected joined range %+v", joined)
+	}
+
+	// A range replacing another entirely takes its own watermark
+	ranges = addCoverage(ranges, Coverage{UserID: 7, From: day(1), To: day(20), Watermark: w2})
+	if len(ranges) != 2 || !ranges[1].Watermark.Equal(w2) || !ranges[1].To.Equal(day(20)) {
+		t.Errorf("unexpected ranges %+v", ranges)
+	}
+
+	state := SyncState{Coverage: ranges}
+	if _, ok := state.covering(7, day(3), day(19)); !ok {
+		t.Error("expected 3-19 to be covered for user 7")
+	}
+	if _, ok := state.covering(8, day(3), day(6)); ok {
+		t.Error("expected 3-6 not to be covered for user 8")
+	}
+	if _, ok := state.covering(0, day(5), day(6)); ok {
+		t.Error("a single user's range must not cover every user")
+	}
+}
@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DefaultGrace is the stale-while-revalidate window paymo uses unless
+// cache.grace in config.yaml says otherwise.
+const DefaultGrace = 1 * time.Hour
+
+// SetGrace sets the stale-while-revalidate window: a read of data that
+// expired less than grace ago is answered from the cache at once, and the
+// data is refetched in the background for the next read. Zero, the default,
+// makes every read of expired data wait for the API. The window of a
+// resource type is at most graceShare of its TTL.
+func (c *CachedClient) SetGrace(grace time.Duration) {
+	c.grace = grace
+}
+
+// graceShare caps the grace window at half the TTL. A refresh still
+// running when the command exits is dropped, so data read again and again
+// by short commands may never be refreshed in the background; the cap
+// keeps it from being served for long past its TTL.
+const graceShare = 0.5
+
+// window returns the grace window of data that is kept for ttl.
+func (c *CachedClient) window(ttl time.Duration) time.Duration {
+	if limit := time.Duration(float64(ttl) * graceShare); limit < c.grace {
+		return limit
+	}
+	return c.grace
+}
+
+// fromGrace reads an entry that expired within the grace window into dest,
+// records the read, and starts refreshing the entry with fetch.
+func (c *CachedClient) fromGrace(resourceType, key string, dest interface{}, fetch func() error) bool {
+	if c.grace <= 0 {
+		return false
+	}
+	info, data, err := c.store.Entry(resourceType, key)
+	if err != nil || !info.Expired || time.Since(info.CachedAt.Add(info.TTL)) > c.window(info.TTL) {
+		return false
+	}
+	if json.Unmarshal(data, dest) != nil {
+		return false
+	}
+	c.setLast(ReadInfo{Cached: true, Refreshing: true, CachedAt: info.CachedAt})
+	c.count(resourceType, HitStats{Grace: 1})
+	c.revalidate(resourceType+"/"+key, fetch)
+	return true
+}
+
+// revalidate runs fetch in a background goroutine, unless a refresh of the
+// same data is already running. Errors are dropped: the data stays expired
+// and the next read tries again.
+func (c *CachedClient) revalidate(what string, fetch func() error) {
+	c.mu.Lock()
+	if _, running := c.pending[what]; running {
+		c.mu.Unlock()
+		return
+	}
+	if c.pending == nil {
+		c.pending = make(map[string]chan struct{})
+	}
+	done := make(chan struct{})
+	c.pending[what] = done
+	c.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		fetch()
+		c.mu.Lock()
+		delete(c.pending, what)
+		c.mu.Unlock()
+	}()
+}
+
+// running returns the done channels of the background refreshes running
+// now. Refreshes started later (say by another goroutine's read) aren't
+// waited for.
+func (c *CachedClient) running() []chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	running := make([]chan struct{}, 0, len(c.pending))
+	for _, done := range c.pending {
+		running = append(running, done)
+	}
+	return running
+}
+
+// settle waits for background refreshes before a write, so that data they
+// fetched before it can't be cached after the write invalidated it.
+func (c *CachedClient) settle() {
+	for _, done := range c.running() {
+		<-done
+	}
+}
+
+// Wait waits up to timeout for background refreshes to finish, so that they
+// aren't cut short when the process exits. It reports whether they did.
+func (c *CachedClient) Wait(timeout time.Duration) bool {
+	deadline := time.After(timeout)
+	for _, done := range c.running() {
+		select {
+		case <-done:
+		case <-deadline:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,167 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ComputClaw/paymo-cli/internal/api"
+)
+
+// ageEntries moves the cached entries of a resource type back in time.
+func ageEntries(t *testing.T, store *Store, resourceType string, d time.Duration) {
+	t.Helper()
+	aged := map[string]cacheEntry{}
+	store.backend.View(func(tx Tx) error {
+		return tx.ForEach(entriesPrefix+resourceType, func(key string, raw []byte) error {
+			var entry cacheEntry
+			json.Unmarshal(raw, &entry)
+			entry.CachedAt -= int64(d.Seconds())
+			aged[key] = entry
+			return nil
+		})
+	})
+	for key, entry := range aged {
+		putEntry(t, store, resourceType, key, entry)
+	}
+}
+
+func TestCachedClient_Grace_ServesExpiredAndRefreshes(t *testing.T) {
+	cc, mock := newTestCachedClient(t)
+	cc.SetGrace(time.Hour)
+
+	cc.GetProjects(nil)
+	ageEntries(t, cc.store, "projects", 70*time.Minute) // expired 10m ago
+
+	projects, err := cc.GetProjects(nil)
+	if err != nil || len(projects) != 2 {
+		t.Fatalf("expected the expired projects, got %v, %v", projects, err)
+	}
+	if info := cc.LastRead(); !info.Cached || !info.Refreshing || info.Stale {
+		t.Errorf("expected a cached read being refreshed, got %+v", info)
+	}
+	if counts := cc.counts["projects"]; counts != (HitStats{Grace: 1, Misses: 1}) {
+		t.Errorf("expected the read to be counted as a grace read, got %+v", counts)
+	}
+	if _, ok := cc.OldestRefreshing(); !ok {
+		t.Error("expected the expired data to be reported for the banner")
+	}
+	if _, ok := cc.OldestStale(); ok {
+		t.Error("expected a grace read not to be reported as stale")
+	}
+	if !cc.Wait(time.Second) {
+		t.Fatal("background refresh did not finish")
+	}
+	if mock.getProjectsCalls != 2 {
+		t.Errorf("expected one background refresh, got %d API calls", mock.getProjectsCalls)
+	}
+
+	cc.GetProjects(nil)
+	if info := cc.LastRead(); !info.Cached || info.Refreshing {
+		t.Errorf("expected the refreshed entry to be fresh, got %+v", info)
+	}
+}
+
+func TestCachedClient_Grace_Expired(t *testing.T) {
+	cc, mock := newTestCachedClient(t)
+	cc.SetGrace(time.Hour)
+
+	cc.GetTask(1)
+	ageEntries(t, cc.store, "task", 3*time.Hour) // past TTL and grace
+
+	cc.GetTask(1)
+	if info := cc.LastRead(); info.Cached {
+		t.Errorf("data past the grace window should come from the API, got %+v", info)
+	}
+	if mock.getTaskCalls != 2 {
+		t.Errorf("expected 2 API calls, got %d", mock.getTaskCalls)
+	}
+}
+
+func TestCachedClient_Grace_CappedByTTL(t *testing.T) {
+	cc, mock := newTestCachedClient(t)
+	cc.SetGrace(time.Hour)
+
+	cc.GetProjects(nil)
+	ageEntries(t, cc.store, "projects", 100*time.Minute) // expired 40m ago, TTL 1h
+
+	cc.GetProjects(nil)
+	if info := cc.LastRead(); info.Cached {
+		t.Errorf("data expired for more than half its TTL should come from the API, got %+v", info)
+	}
+	if mock.getProjectsCalls != 2 {
+		t.Errorf("expected 2 API calls, got %d", mock.getProjectsCalls)
+	}
+}
+
+func TestCachedClient_Grace_OffByDefault(t *testing.T) {
+	cc, mock := newTestCachedClient(t)
+
+	cc.GetProjects(nil)
+	ageEntries(t, cc.store, "projects", 70*time.Minute)
+	cc.GetProjects(nil)
+	if info := cc.LastRead(); info.Cached {
+		t.Errorf("expired data should wait for the API without a grace window, got %+v", info)
+	}
+	if mock.getProjectsCalls != 2 {
+		t.Errorf("expected 2 API calls, got %d", mock.getProjectsCalls)
+	}
+}
+
+func TestCachedClient_Grace_RefreshesSyncedRecords(t *testing.T) {
+	mock := &syncMockAPI{projects: []api.Project{{ID: 1, Name: "Alpha", ClientID: 5}}}
+	store := NewStore(NewMemoryBackend())
+	cc := NewCachedClient(mock, store)
+	cc.SetGrace(time.Hour)
+
+	syncedAt := time.Now().Add(-80 * time.Minute) // projects TTL is 1h
+	store.MergeRecords("projects", []Record{{ID: 1, Value: mock.projects[0]}}, true, syncedAt)
+
+	projects, err := cc.GetProjects(&api.ProjectListOptions{ClientID: 5})
+	if err != nil || len(projects) != 1 {
+		t.Fatalf("expected the synced project, got %v, %v", projects, err)
+	}
+	if info := cc.LastRead(); !info.Refreshing {
+		t.Errorf("expected the records to be refreshed, got %+v", info)
+	}
+	cc.Wait(time.Second)
+	if len(mock.projectOpts) != 1 {
+		t.Errorf("expected one background sync, got %+v", mock.projectOpts)
+	}
+	if state, _ := store.SyncState("projects"); !state.SyncedAt.After(syncedAt) {
+		t.Errorf("expected the sync state to be refreshed, got %v", state.SyncedAt)
+	}
+}
+
+func TestCachedClient_SettleWhileRefreshing(t *testing.T) {
+	cc := NewCachedClient(&mockAPI{}, NewStore(NewMemoryBackend()))
+
+	// Reads starting refreshes while writes wait for them, as bulk commands
+	// do from their workers
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				cc.revalidate(fmt.Sprintf("test/%d/%d", i, j), func() error { return nil })
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				cc.settle()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if !cc.Wait(time.Second) {
+		t.Fatal("background refreshes did not finish")
+	}
+	if running := cc.running(); len(running) != 0 {
+		t.Errorf("expected no refreshes left running, got %d", len(running))
+	}
+}
@@ -0,0 +1,77 @@
+package cache
+
+import "encoding/json"
+
+// statsBucket holds the read counters of each resource type. Like synced
+// records they survive InvalidateType; Clear resets them.
+const statsBucket = "stats"
+
+// HitStats counts the reads of a resource type through a CachedClient.
+// Hits were answered from the cache or synced records; grace reads were
+// answered from data that had expired within the grace window while it was
+// refreshed. Misses went to the API, and of those, stale reads fell back to
+// expired data because the API was unreachable.
+type HitStats struct {
+	Hits   int `json:"hits"`
+	Grace  int `json:"grace"`
+	Misses int `json:"misses"`
+	Stale  int `json:"stale"`
+}
+
+// Add returns the sum of two counts.
+func (h HitStats) Add(o HitStats) HitStats {
+	return HitStats{Hits: h.Hits + o.Hits, Grace: h.Grace + o.Grace, Misses: h.Misses + o.Misses, Stale: h.Stale + o.Stale}
+}
+
+// HitRate returns the share of reads answered from unexpired data without
+// the API, from 0 to 1. It reports false if nothing was read.
+func (h HitStats) HitRate() (float64, bool) {
+	reads := h.Hits + h.Grace + h.Misses
+	if reads == 0 {
+		return 0, false
+	}
+	return float64(h.Hits) / float64(reads), true
+}
+
+// AddHitStats adds read counts to the stored counters.
+func (s *Store) AddHitStats(counts map[string]HitStats) error {
+	if len(counts) == 0 {
+		return nil
+	}
+	return s.backend.Update(func(tx Tx) error {
+		for rt, add := range counts {
+			var stats HitStats
+			if raw := tx.Get(statsBucket, rt); raw != nil {
+				json.Unmarshal(raw, &stats)
+			}
+			if err := putJSON(tx, statsBucket, rt, stats.Add(add)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// HitStats returns the stored read counters by resource type.
+func (s *Store) HitStats() (map[string]HitStats, error) {
+	counts := make(map[string]HitStats)
+	err := s.backend.View(func(tx Tx) error {
+		return tx.ForEach(statsBucket, func(rt string, raw []byte) error {
+			var stats HitStats
+			if json.Unmarshal(raw, &stats) == nil {
+				counts[rt] = stats
+			}
+			return nil
+		})
+	})
+	return counts, err
+}
+
+// TotalHitStats sums read counters over every resource type.
+func TotalHitStats(counts map[string]HitStats) HitStats {
+	var total HitStats
+	for _, stats := range counts {
+		total = total.Add(stats)
+	}
+	return total
+}
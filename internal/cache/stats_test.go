@@ -0,0 +1,48 @@
+package cache
+
+import "testing"
+
+func TestHitStats_HitRate(t *testing.T) {
+	if _, ok := (HitStats{}).HitRate(); ok {
+		t.Error("expected no hit rate without reads")
+	}
+	rate, ok := HitStats{Hits: 9, Misses: 1, Stale: 1}.HitRate()
+	if !ok || rate != 0.9 {
+		t.Errorf("HitRate = %v, %v; want 0.9", rate, ok)
+	}
+	// Grace reads served expired data, so they aren't hits
+	rate, ok = HitStats{Hits: 3, Grace: 1}.HitRate()
+	if !ok || rate != 0.75 {
+		t.Errorf("HitRate = %v, %v; want 0.75", rate, ok)
+	}
+}
+
+func TestAddHitStats(t *testing.T) {
+	for name, b := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			store := NewStore(b)
+			store.AddHitStats(map[string]HitStats{"projects": {Hits: 2, Misses: 1}})
+			store.AddHitStats(map[string]HitStats{"projects": {Hits: 1}, "task": {Misses: 1, Stale: 1}})
+
+			counts, err := store.HitStats()
+			if err != nil {
+				t.Fatalf("HitStats error: %v", err)
+			}
+			if counts["projects"] != (HitStats{Hits: 3, Misses: 1}) || counts["task"] != (HitStats{Misses: 1, Stale: 1}) {
+				t.Errorf("unexpected counters %v", counts)
+			}
+			if total := TotalHitStats(counts); total != (HitStats{Hits: 3, Misses: 2, Stale: 1}) {
+				t.Errorf("unexpected total %v", total)
+			}
+
+			store.InvalidateType("projects")
+			if counts, _ := store.HitStats(); len(counts) != 2 {
+				t.Error("counters should survive invalidation")
+			}
+			store.Clear()
+			if counts, _ := store.HitStats(); len(counts) != 0 {
+				t.Errorf("Clear should reset the counters, got %v", counts)
+			}
+		})
+	}
+}
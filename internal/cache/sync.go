@@ -0,0 +1,214 @@
+package cache
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ComputClaw/paymo-cli/internal/api"
+)
+
+// SyncResources are the resources Sync keeps as records. Time entries are
+// synced by date range with SyncEntries.
+var SyncResources = []string{"clients", "projects", "tasks", "tasklists"}
+
+// SyncResult reports the outcome of syncing one resource.
+type SyncResult struct {
+	Resource string    `json:"resource"`
+	Full     bool      `json:"full"`            // every record was fetched
+	Since    time.Time `json:"since,omitempty"` // watermark a delta sync started from
+	Fetched  int       `json:"fetched"`
+	Range    *Coverage `json:"range,omitempty"` // entry range synced
+	MergeStats
+}
+
+// syncedTypes are the cached response types derived from each resource,
+// dropped after a sync so reads see the merged records.
+var syncedTypes = map[string][]string{
+	"clients":   {"clients"},
+	"projects":  {"projects", "project", "project_by_name"},
+	"tasks":     {"tasks", "task", "task_by_name"},
+	"tasklists": {"tasklists"},
+	"entries":   {"entries", "entry"},
+}
+
+// FullSyncAge is how long the records of a resource go without a full
+// sync. A delta sync can't see deletions, so records deleted in Paymo are
+// only dropped by a full one.
+const FullSyncAge = 24 * time.Hour
+
+// Sync fetches the records of a resource changed since its last sync
+// (updated_on at or after the watermark) and merges them into the synced
+// set. The first sync of a resource, any sync with full set and any sync
+// more than FullSyncAge after the last full one fetches every record
+// instead and drops records that no longer exist; a delta sync can't see
+// deletions. Clients and task lists are always fetched in full, since the
+// API offers no filter for them.
+func (c *CachedClient) Sync(resource string, full bool) (SyncResult, error) {
+	result := SyncResult{Resource: resource}
+	state, synced := c.store.SyncState(resource)
+	if time.Since(state.FullAt) > FullSyncAge {
+		full = true
+	}
+	if synced && !full && resource != "clients" && resource != "tasklists" {
+		result.Since = state.Watermark
+	}
+	result.Full = result.Since.IsZero()
+
+	now := time.Now()
+	var records []Record
+	switch resource {
+	case "clients":
+		clients, err := c.remote().GetClients()
+		if err != nil {
+			return result, err
+		}
+		for i := range clients {
+			records = append(records, Record{ID: clients[i].ID, UpdatedOn: clients[i].UpdatedOn, Value: clients[i]})
+		}
+	case "projects":
+		projects, err := c.remote().GetProjects(&api.ProjectListOptions{UpdatedSince: result.Since})
+		if err != nil {
+			return result, err
+		}
+		for i := range projects {
+			records = append(records, Record{ID: projects[i].ID, UpdatedOn: projects[i].UpdatedOn, Value: projects[i]})
+		}
+	case "tasks":
+		tasks, err := c.remote().GetTasks(&api.TaskListOptions{IncludeCompleted: true, UpdatedSince: result.Since})
+		if err != nil {
+			return result, err
+		}
+		for i := range tasks {
+			records = append(records, Record{ID: tasks[i].ID, UpdatedOn: tasks[i].UpdatedOn, Value: tasks[i]})
+		}
+	case "tasklists":
+		lists, err := c.remote().GetTaskLists(0)
+		if err != nil {
+			return result, err
+		}
+		for i := range lists {
+			records = append(records, Record{ID: lists[i].ID, UpdatedOn: lists[i].UpdatedOn, Value: lists[i]})
+		}
+	default:
+		return result, fmt.Errorf("cannot sync %s", resource)
+	}
+	result.Fetched = len(records)
+
+	stats, err := c.store.MergeRecords(resource, records, result.Full, now)
+	if err != nil {
+		return result, fmt.Errorf("saving %s: %w", resource, err)
+	}
+	result.MergeStats = stats
+	if stats.Added+stats.Changed+stats.Removed > 0 {
+		if err := c.store.InvalidateType(syncedTypes[resource]...); err != nil {
+			return result, err
+		}
+	}
+	c.warm(resource)
+	return result, nil
+}
+
+// SyncEntries syncs the time entries of userID (0 for every user) that
+// start in [from, to). If an earlier sync already covers the range, only
+// entries changed since then are fetched, for the whole synced range they
+// fall in; otherwise, with full or more than FullSyncAge after the last
+// full sync, every entry of the range is fetched and entries of the range
+// that no longer exist are dropped. Entries are
+// fetched with their task and project, so the synced set can answer any
+// entry query within a synced range.
+func (c *CachedClient) SyncEntries(userID int, from, to time.Time, full bool) (SyncResult, error) {
+	result := SyncResult{Resource: "entries"}
+	cov := Coverage{UserID: userID, From: from, To: to}
+	if state, ok := c.store.SyncState("entries"); ok && !full && time.Since(state.FullAt) <= FullSyncAge {
+		if synced, ok := state.covering(userID, from, to); ok {
+			cov = synced
+			result.Since = synced.Watermark
+		}
+	}
+	result.Full = result.Since.IsZero()
+	result.Range = &cov
+
+	now := time.Now()
+	entries, err := c.remote().GetEntries(&api.EntryListOptions{
+		UserID:         cov.UserID,
+		StartDate:      cov.From,
+		EndDate:        cov.To,
+		IncludeTask:    true,
+		IncludeProject: true,
+		UpdatedSince:   result.Since,
+	})
+	if err != nil {
+		return result, err
+	}
+	records := make([]Record, 0, len(entries))
+	for i := range entries {
+		// The API's start_time filter includes the end of the range
+		if !entries[i].StartTime.Before(cov.To) {
+			continue
+		}
+		records = append(records, Record{ID: entries[i].ID, UpdatedOn: entries[i].UpdatedOn, Value: entries[i]})
+	}
+	result.Fetched = len(records)
+
+	stats, err := c.store.MergeEntryRange(records, cov, result.Full, now)
+	if err != nil {
+		return result, fmt.Errorf("saving entries: %w", err)
+	}
+	result.MergeStats = stats
+	if stats.Added+stats.Changed+stats.Removed > 0 {
+		if err := c.store.InvalidateType(syncedTypes["entries"]...); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// warm caches the unfiltered list and the single items of a resource from
+// its synced records, as if they had just been fetched, and rebuilds its
+// name index.
+func (c *CachedClient) warm(resource string) {
+	switch resource {
+	case "clients":
+		var clients []api.PaymoClient
+		if c.store.Records(resource, &clients) == nil {
+			c.store.Set("clients", "all", clients)
+		}
+	case "projects":
+		var projects []api.Project
+		if c.store.Records(resource, &projects) == nil {
+			c.store.Set("projects", projectsKey(nil), projects)
+			items := make(map[string]interface{}, len(projects))
+			for i := range projects {
+				items[fmt.Sprintf("%d", projects[i].ID)] = projects[i]
+			}
+			c.store.SetMany("project", items)
+			c.indexProjects(projects)
+			c.store.MarkNamesComplete("project", 0)
+		}
+	case "tasks":
+		var tasks []api.Task
+		if c.store.Records(resource, &tasks) == nil {
+			c.store.Set("tasks", tasksKey(nil), tasks)
+			items := make(map[string]interface{}, len(tasks))
+			for i := range tasks {
+				items[fmt.Sprintf("%d", tasks[i].ID)] = tasks[i]
+			}
+			c.store.SetMany("task", items)
+			c.indexTasks(tasks)
+			c.store.MarkNamesComplete("task", 0)
+		}
+	case "tasklists":
+		var lists []api.TaskList
+		if c.store.Records(resource, &lists) == nil {
+			sort.SliceStable(lists, func(i, j int) bool { return lists[i].Seq < lists[j].Seq })
+			byProject := map[string]interface{}{}
+			for _, l := range lists {
+				key := fmt.Sprintf("project=%d", l.ProjectID)
+				projectLists, _ := byProject[key].([]api.TaskList)
+				byProject[key] = append(projectLists, l)
+			}
+			c.store.SetMany("tasklists", byProject)
+		}
+	}
+}
@@ -0,0 +1,434 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ComputClaw/paymo-cli/internal/api"
+)
+
+// syncMockAPI serves mutable project and entry lists and records the
+// options of each call.
+type syncMockAPI struct {
+	mockAPI
+	projects    []api.Project
+	projectOpts []api.ProjectListOptions
+	entries     []api.TimeEntry
+	entryOpts   []api.EntryListOptions
+	tasks       []api.Task
+}
+
+func (m *syncMockAPI) GetTasks(opts *api.TaskListOptions) ([]api.Task, error) {
+	m.getTasksCalls++
+	if m.networkErr {
+		return nil, errNetwork
+	}
+	return m.tasks, nil
+}
+
+func (m *syncMockAPI) GetEntries(opts *api.EntryListOptions) ([]api.TimeEntry, error) {
+	m.getEntriesCalls++
+	if m.networkErr {
+		return nil, errNetwork
+	}
+	m.entryOpts = append(m.entryOpts, *opts)
+	var entries []api.TimeEntry
+	for _, e := range m.entries {
+		if (opts.UserID == 0 || e.UserID == opts.UserID) &&
+			!e.StartTime.Before(opts.StartDate) && !e.StartTime.After(opts.EndDate) &&
+			!e.UpdatedOn.Before(opts.UpdatedSince) {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+func (m *syncMockAPI) GetProjects(opts *api.ProjectListOptions) ([]api.Project, error) {
+	m.getProjectsCalls++
+	if m.networkErr {
+		return nil, errNetwork
+	}
+	m.projectOpts = append(m.projectOpts, *opts)
+	var projects []api.Project
+	for _, p := range m.projects {
+		if !p.UpdatedOn.Before(opts.UpdatedSince) {
+			projects = append(projects, p)
+		}
+	}
+	return projects, nil
+}
+
+func newSyncTestClient(t *testing.T) (*CachedClient, *syncMockAPI) {
+	t.Helper()
+	t1 := time.Date(2026, 2, 9, 9, 0, 0, 0, time.UTC)
+	mock := &syncMockAPI{projects: []api.Project{
+		{ID: 1, Name: "Project One", Active: true, UpdatedOn: t1},
+		{ID: 2, Name: "Project Two", Active: true, UpdatedOn: t1.Add(time.Hour)},
+	}}
+	return NewCachedClient(mock, NewStore(NewMemoryBackend())), mock
+}
+
+func TestSync_DeltaAfterFirstSync(t *testing.T) {
+	cc, mock := newSyncTestClient(t)
+
+	result, err := cc.Sync("projects", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Full || result.Added != 2 || result.Total != 2 {
+		t.Errorf("first sync: got %+v", result)
+	}
+	if !mock.projectOpts[0].UpdatedSince.IsZero() {
+		t.Errorf("first sync should fetch everything, got since %v", mock.projectOpts[0].UpdatedSince)
+	}
+
+	// Project Two changes; a delta sync fetches from the watermark
+	watermark := mock.projects[1].UpdatedOn
+	mock.projects[1].Name = "Project Two renamed"
+	mock.projects[1].UpdatedOn = watermark.Add(time.Hour)
+	result, err = cc.Sync("projects", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !mock.projectOpts[1].UpdatedSince.Equal(watermark) {
+		t.Errorf("expected delta since %v, got %v", watermark, mock.projectOpts[1].UpdatedSince)
+	}
+	if result.Full || result.Fetched != 1 || result.Changed != 1 || result.Total != 2 {
+		t.Errorf("delta sync: got %+v", result)
+	}
+
+	// The merged list is served from the cache
+	calls := mock.getProjectsCalls
+	projects, err := cc.GetProjects(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mock.getProjectsCalls != calls {
+		t.Error("expected the synced list to be served from the cache")
+	}
+	if len(projects) != 2 || projects[1].Name != "Project Two renamed" {
+		t.Errorf("unexpected projects %+v", projects)
+	}
+	p, err := cc.GetProjectByName("project two renamed")
+	if err != nil || p.ID != 2 {
+		t.Errorf("expected name lookup from the synced records, got %+v, %v", p, err)
+	}
+}
+
+func TestSync_FullRemovesDeleted(t *testing.T) {
+	cc, mock := newSyncTestClient(t)
+	cc.Sync("projects", false)
+
+	mock.projects = mock.projects[:1]
+	result, err := cc.Sync("projects", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Removed != 0 || result.Total != 2 {
+		t.Errorf("a delta sync can't see deletions, got %+v", result)
+	}
+
+	result, err = cc.Sync("projects", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Full || result.Removed != 1 || result.Total != 1 {
+		t.Errorf("full sync: got %+v", result)
+	}
+	if !mock.projectOpts[2].UpdatedSince.IsZero() {
+		t.Error("expected --full to fetch everything")
+	}
+}
+
+func TestSync_FullAfterFullSyncAge(t *testing.T) {
+	cc, mock := newSyncTestClient(t)
+	var records []Record
+	for _, p := range mock.projects {
+		records = append(records, Record{ID: p.ID, UpdatedOn: p.UpdatedOn, Value: p})
+	}
+	cc.store.MergeRecords("projects", records, true, time.Now().Add(-FullSyncAge-time.Hour))
+	cc.store.MergeRecords("projects", nil, false, time.Now())
+
+	// Project Two was deleted; only a full sync notices
+	mock.projects = mock.projects[:1]
+	result, err := cc.Sync("projects", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Full || result.Removed != 1 || result.Total != 1 {
+		t.Errorf("expected a full sync after FullSyncAge, got %+v", result)
+	}
+	if !mock.projectOpts[0].UpdatedSince.IsZero() {
+		t.Error("expected the sync to fetch everything")
+	}
+}
+
+func TestSync_UnknownResource(t *testing.T) {
+	cc, _ := newSyncTestClient(t)
+	if _, err := cc.Sync("invoices", false); err == nil {
+		t.Error("expected error for unknown resource")
+	}
+}
+
+func newEntrySyncTestClient(t *testing.T) (*CachedClient, *syncMockAPI) {
+	t.Helper()
+	cc, mock := newSyncTestClient(t)
+	updated := time.Date(2026, 2, 20, 9, 0, 0, 0, time.UTC)
+	task := &api.Task{ID: 100, Name: "Design", ProjectID: 10}
+	mock.entries = []api.TimeEntry{
+		{ID: 1, UserID: 7, TaskID: 100, Task: task, StartTime: time.Date(2026, 2, 9, 9, 0, 0, 0, time.UTC), UpdatedOn: updated},
+		{ID: 2, UserID: 7, TaskID: 101, Task: &api.Task{ID: 101, ProjectID: 11}, StartTime: time.Date(2026, 2, 10, 9, 0, 0, 0, time.UTC), UpdatedOn: updated},
+		{ID: 3, UserID: 8, TaskID: 100, Task: task, StartTime: time.Date(2026, 2, 9, 10, 0, 0, 0, time.UTC), UpdatedOn: updated},
+		{ID: 4, UserID: 7, TaskID: 100, Task: task, StartTime: time.Date(2026, 1, 20, 9, 0, 0, 0, time.UTC), UpdatedOn: updated},
+	}
+	return cc, mock
+}
+
+func TestSyncEntries_AnswersRangeQueries(t *testing.T) {
+	cc, mock := newEntrySyncTestClient(t)
+	feb1 := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	mar1 := feb1.AddDate(0, 1, 0)
+
+	result, err := cc.SyncEntries(7, feb1, mar1, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Full || result.Added != 2 || result.Range == nil || !result.Range.To.Equal(mar1) {
+		t.Errorf("unexpected result %+v", result)
+	}
+	opts := mock.entryOpts[0]
+	if opts.UserID != 7 || !opts.IncludeTask || !opts.IncludeProject || !opts.UpdatedSince.IsZero() {
+		t.Errorf("unexpected fetch options %+v", opts)
+	}
+
+	// Any range inside the synced one is answered without the API
+	calls := mock.getEntriesCalls
+	day := time.Date(2026, 2, 9, 0, 0, 0, 0, time.UTC)
+	entries, err := cc.GetEntries(&api.EntryListOptions{UserID: 7, StartDate: day, EndDate: day.AddDate(0, 0, 1)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mock.getEntriesCalls != calls {
+		t.Error("expected the synced entries to answer the query")
+	}
+	if len(entries) != 1 || entries[0].ID != 1 || entries[0].Task != nil {
+		t.Errorf("unexpected entries %+v", entries)
+	}
+	if info := cc.LastRead(); !info.Cached || info.Stale {
+		t.Errorf("unexpected read info %+v", info)
+	}
+
+	entries, _ = cc.GetEntries(&api.EntryListOptions{UserID: 7, ProjectID: 11, IncludeTask: true, StartDate: feb1, EndDate: mar1})
+	if len(entries) != 1 || entries[0].ID != 2 || entries[0].Task == nil {
+		t.Errorf("expected entry 2 of project 11, got %+v", entries)
+	}
+	if mock.getEntriesCalls != calls {
+		t.Error("expected the project query to be answered from the synced entries")
+	}
+
+	// Ranges outside the synced one, and other users, go to the API
+	cc.GetEntries(&api.EntryListOptions{UserID: 7, StartDate: feb1.AddDate(0, -1, 0), EndDate: feb1})
+	cc.GetEntries(&api.EntryListOptions{UserID: 8, StartDate: day, EndDate: day.AddDate(0, 0, 1)})
+	if mock.getEntriesCalls != calls+2 {
+		t.Errorf("expected 2 API calls for uncovered queries, got %d", mock.getEntriesCalls-calls)
+	}
+}
+
+func TestSyncEntries_DeltaAndFull(t *testing.T) {
+	cc, mock := newEntrySyncTestClient(t)
+	feb1 := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	mar1 := feb1.AddDate(0, 1, 0)
+	cc.SyncEntries(7, feb1, mar1, false)
+
+	// A sub-range of a synced range fetches the changes of the whole range
+	watermark := mock.entries[0].UpdatedOn
+	mock.entries[1].Description = "changed"
+	mock.entries[1].UpdatedOn = watermark.Add(time.Hour)
+	mock.entries = mock.entries[1:] // entry 1 was deleted
+	result, err := cc.SyncEntries(7, feb1.AddDate(0, 0, 7), feb1.AddDate(0, 0, 14), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	opts := mock.entryOpts[1]
+	if !opts.UpdatedSince.Equal(watermark) || !opts.StartDate.Equal(feb1) || !opts.EndDate.Equal(mar1) {
+		t.Errorf("unexpected delta options %+v", opts)
+	}
+	if result.Full || result.Changed != 1 || result.Removed != 0 || result.Total != 2 {
+		t.Errorf("delta sync: got %+v", result)
+	}
+
+	result, err = cc.SyncEntries(7, feb1, mar1, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Full || result.Removed != 1 || result.Total != 1 {
+		t.Errorf("full sync: got %+v", result)
+	}
+}
+
+func TestSyncEntries_OfflineAndWrites(t *testing.T) {
+	cc, mock := newEntrySyncTestClient(t)
+	feb1 := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	mar1 := feb1.AddDate(0, 1, 0)
+	cc.SyncEntries(7, feb1, mar1, false)
+
+	// Writes through the client keep the synced entries current
+	if err := cc.DeleteEntry(2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Once the sync is older than the entries TTL, it only answers when
+	// the API can't be reached
+	cc.store.MergeEntryRange(nil, Coverage{UserID: 7, From: feb1, To: mar1}, false, time.Now().Add(-time.Hour))
+	mock.networkErr = true
+	entries, err := cc.GetEntries(&api.EntryListOptions{UserID: 7, StartDate: feb1, EndDate: mar1})
+	if err != nil {
+		t.Fatalf("expected synced entries offline, got: %v", err)
+	}
+	if len(entries) != 1 || entries[0].ID != 1 {
+		t.Errorf("expected entry 1 only, got %+v", entries)
+	}
+	if info := cc.LastRead(); !info.Stale {
+		t.Errorf("expected a stale read, got %+v", info)
+	}
+
+	// An updated entry is stored with its task filled in
+	mock.networkErr = false
+	cc.UpdateEntry(1, &api.UpdateTimeEntryRequest{})
+	var records []api.TimeEntry
+	cc.store.Records("entries", &records)
+	if len(records) != 1 || records[0].Duration != 7200 || records[0].Task == nil {
+		t.Errorf("unexpected synced entries %+v", records)
+	}
+}
+
+func TestSyncedRecords_AnswerFilteredQueries(t *testing.T) {
+	cc, mock := newSyncTestClient(t)
+	mock.tasks = []api.Task{
+		{ID: 1, Name: "Design", ProjectID: 5, TaskListID: 50},
+		{ID: 2, Name: "Build", ProjectID: 5, TaskListID: 51, Complete: true},
+		{ID: 3, Name: "Test", ProjectID: 6, Users: []int{7}},
+	}
+
+	// Without a sync, filtered lists come from the API
+	cc.GetTasks(&api.TaskListOptions{ProjectID: 5})
+	if mock.getTasksCalls != 1 {
+		t.Fatalf("expected an API call before syncing, got %d", mock.getTasksCalls)
+	}
+
+	if _, err := cc.Sync("tasks", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	calls := mock.getTasksCalls
+
+	tests := []struct {
+		opts *api.TaskListOptions
+		want []int
+	}{
+		{&api.TaskListOptions{ProjectID: 5}, []int{1}},
+		{&api.TaskListOptions{ProjectID: 5, IncludeCompleted: true}, []int{1, 2}},
+		{&api.TaskListOptions{ProjectID: 5, TaskListID: 51, IncludeCompleted: true}, []int{2}},
+		{&api.TaskListOptions{UserID: 7, IncludeProject: true}, []int{3}},
+	}
+	for _, tt := range tests {
+		tasks, err := cc.GetTasks(tt.opts)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var ids []int
+		for _, task := range tasks {
+			ids = append(ids, task.ID)
+		}
+		if !equalIDs(ids, tt.want) {
+			t.Errorf("GetTasks(%+v) = %v, want %v", *tt.opts, ids, tt.want)
+		}
+	}
+	if mock.getTasksCalls != calls {
+		t.Errorf("expected filtered lists from the synced tasks, got %d API calls", mock.getTasksCalls-calls)
+	}
+
+	// Single tasks come from the records too, and writes keep them current
+	cc.store.InvalidateType("task")
+	cc.CompleteTask(1)
+	task, err := cc.GetTask(1)
+	if err != nil || !task.Complete {
+		t.Errorf("expected completed task 1 from the records, got %+v, %v", task, err)
+	}
+	if mock.getTaskCalls != 0 {
+		t.Errorf("expected no GetTask calls, got %d", mock.getTaskCalls)
+	}
+	if tasks, _ := cc.GetTasks(&api.TaskListOptions{ProjectID: 5}); len(tasks) != 0 {
+		t.Errorf("expected no open tasks in project 5, got %+v", tasks)
+	}
+}
+
+func TestSyncedRecords_OldFullSync(t *testing.T) {
+	cc, mock := newSyncTestClient(t)
+	fullAt := time.Now().Add(-FullSyncAge - 2*time.Hour)
+	var records []Record
+	for _, p := range mock.projects {
+		records = append(records, Record{ID: p.ID, UpdatedOn: p.UpdatedOn, Value: p})
+	}
+	cc.store.MergeRecords("projects", records, true, fullAt)
+	cc.store.MergeRecords("projects", nil, false, time.Now()) // a recent delta sync
+
+	// Past the grace window the records aren't served: they may hold
+	// projects deleted since the last full sync
+	cc.SetGrace(time.Hour)
+	calls := mock.getProjectsCalls
+	cc.GetProjects(&api.ProjectListOptions{ActiveOnly: true})
+	if mock.getProjectsCalls != calls+1 {
+		t.Error("expected records without a recent full sync to go to the API")
+	}
+
+	// Within it they are, while a full sync refreshes them
+	mock.projects = mock.projects[:1]
+	mock.projectOpts = nil
+	cc.store.InvalidateType("projects") // the response of the API call
+	cc.SetGrace(3 * time.Hour)
+	projects, err := cc.GetProjects(&api.ProjectListOptions{ActiveOnly: true})
+	if err != nil || len(projects) != 2 {
+		t.Fatalf("expected the synced projects, got %v, %v", projects, err)
+	}
+	if info := cc.LastRead(); !info.Refreshing {
+		t.Errorf("expected the records to be refreshed, got %+v", info)
+	}
+	if counts := cc.counts["projects"]; counts.Grace != 1 {
+		t.Errorf("expected the read to be counted as a grace read, got %+v", counts)
+	}
+	cc.Wait(time.Second)
+	if len(mock.projectOpts) != 1 || !mock.projectOpts[0].UpdatedSince.IsZero() {
+		t.Errorf("expected one full background sync, got %+v", mock.projectOpts)
+	}
+	var synced []api.Project
+	if cc.store.Records("projects", &synced); len(synced) != 1 {
+		t.Errorf("expected the deleted project to be dropped, got %+v", synced)
+	}
+}
+
+func TestSyncedRecords_ExpiredServeOffline(t *testing.T) {
+	cc, mock := newSyncTestClient(t)
+	cc.Sync("projects", false)
+
+	// Expired syncs answer only when the API can't be reached
+	cc.store.MergeRecords("projects", nil, false, time.Now().Add(-2*time.Hour))
+	calls := mock.getProjectsCalls
+	cc.GetProjects(&api.ProjectListOptions{ActiveOnly: true})
+	if mock.getProjectsCalls != calls+1 {
+		t.Error("expected an expired sync to go to the API")
+	}
+
+	// Archiving keeps the records current for offline reads
+	cc.ArchiveProject(2)
+	mock.networkErr = true
+	projects, err := cc.GetProjects(&api.ProjectListOptions{ActiveOnly: true})
+	if err != nil {
+		t.Fatalf("expected the synced projects offline, got: %v", err)
+	}
+	if len(projects) != 1 || projects[0].ID != 1 {
+		t.Errorf("expected active project 1 only, got %+v", projects)
+	}
+	if info := cc.LastRead(); !info.Stale {
+		t.Errorf("expected a stale read, got %+v", info)
+	}
+}
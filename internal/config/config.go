@@ -1,10 +1,12 @@
 package config
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/viper"
 	"gopkg.in/yaml.v3"
@@ -14,6 +16,7 @@ const (
 	DefaultConfigDir  = ".config/paymo-cli"
 	ConfigFile        = "config.yaml"
 	CredentialsFile   = "credentials"
+	CacheKeyFile      = "cache.key"
 	DefaultAPIBaseURL = "https://app.paymoapp.com/api"
 )
 
@@ -22,6 +25,7 @@ type Config struct {
 	API      APIConfig      `yaml:"api"`
 	Defaults DefaultsConfig `yaml:"defaults"`
 	Output   OutputConfig   `yaml:"output"`
+	Cache    CacheConfig    `yaml:"cache,omitempty"`
 }
 
 // APIConfig holds API-related configuration
@@ -44,6 +48,16 @@ type OutputConfig struct {
 	TableStyle string `yaml:"table_style"`
 }
 
+// CacheConfig holds cache options
+type CacheConfig struct {
+	// TTL overrides the cache lifetime of resource types, as durations
+	// such as "10m" keyed by type ("tasks")
+	TTL map[string]string `yaml:"ttl,omitempty"`
+	// Grace is how long expired data is still shown at once while it is
+	// refreshed in the background, at most half its TTL ("0" turns this off)
+	Grace string `yaml:"grace,omitempty"`
+}
+
 // Credentials holds authentication credentials (secrets)
 type Credentials struct {
 	AuthType string `json:"auth_type"` // "api_key" or "basic"
@@ -51,6 +65,9 @@ type Credentials struct {
 	Email    string `json:"email,omitempty"`
 	UserID   int    `json:"user_id,omitempty"`
 	UserName string `json:"user_name,omitempty"`
+	// UserTimezone is the Paymo user's timezone, used for output when
+	// defaults.timezone is not configured
+	UserTimezone string `json:"user_timezone,omitempty"`
 }
 
 // GetConfigDir returns the configuration directory path
@@ -177,6 +194,163 @@ func CheckCredentialsPermissions() error {
 	return nil
 }
 
+// --- Cache key (secret) ---
+
+// pendingCacheKeyFile holds the new key of a `cache rekey` until the cache
+// databases have been re-encrypted with it, followed by their paths, one
+// per line.
+const pendingCacheKeyFile = CacheKeyFile + ".new"
+
+// LoadCacheKey loads the key the cache is encrypted with. It returns nil if
+// no key is stored yet.
+func LoadCacheKey() ([]byte, error) {
+	key, _, err := loadCacheKey(CacheKeyFile)
+	return key, err
+}
+
+// LoadPendingCacheKey loads the key saved by SavePendingCacheKey and the
+// paths of the databases it was saved for. It returns a nil key if there
+// is none.
+func LoadPendingCacheKey() ([]byte, []string, error) {
+	return loadCacheKey(pendingCacheKeyFile)
+}
+
+func loadCacheKey(name string) ([]byte, []string, error) {
+	dir, err := GetConfigDir()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("reading cache key: %w", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[0]))
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing cache key: %w", err)
+	}
+	return key, lines[1:], nil
+}
+
+// SaveCacheKey stores the cache encryption key next to the credentials,
+// with the same permissions.
+func SaveCacheKey(key []byte) error {
+	return saveCacheKey(CacheKeyFile, key, nil)
+}
+
+// SavePendingCacheKey stores a new cache key beside the current one, with
+// the paths of the cache databases about to be encrypted with it, to be
+// put in its place by CommitPendingCacheKey once they are. Until then a
+// cut-off rekey leaves both keys on disk.
+func SavePendingCacheKey(key []byte, paths []string) error {
+	return saveCacheKey(pendingCacheKeyFile, key, paths)
+}
+
+// CommitPendingCacheKey replaces the cache key with the pending one.
+func CommitPendingCacheKey() error {
+	key, _, err := LoadPendingCacheKey()
+	if err != nil {
+		return err
+	}
+	if key == nil {
+		return fmt.Errorf("storing cache key: no pending key")
+	}
+	if err := SaveCacheKey(key); err != nil {
+		return err
+	}
+	return DiscardPendingCacheKey()
+}
+
+// DiscardPendingCacheKey removes the pending cache key, if any.
+func DiscardPendingCacheKey() error {
+	dir, err := GetConfigDir()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(dir, pendingCacheKeyFile)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing pending cache key: %w", err)
+	}
+	return nil
+}
+
+// CreateCacheKey stores key as the cache key unless one is stored already,
+// and returns the stored key. The file is created exclusively, so of
+// processes creating a key at the same time all end up with the first.
+func CreateCacheKey(key []byte) ([]byte, error) {
+	dir, err := EnsureConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	tmp, err := writeCacheKeyTemp(dir, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp)
+	// Linking fails if the file exists, like O_EXCL, and never shows
+	// another process a half-written key
+	err = os.Link(tmp, filepath.Join(dir, CacheKeyFile))
+	if os.IsExist(err) {
+		return LoadCacheKey()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("writing cache key: %w", err)
+	}
+	return key, nil
+}
+
+// saveCacheKey writes a key file in the config dir, replacing it
+// atomically.
+func saveCacheKey(name string, key []byte, paths []string) error {
+	dir, err := EnsureConfigDir()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := writeCacheKeyTemp(dir, key, paths)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(dir, name)); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("writing cache key: %w", err)
+	}
+	return nil
+}
+
+// writeCacheKeyTemp writes key, followed by paths, to a new 0600 file in
+// dir and returns its path.
+func writeCacheKeyTemp(dir string, key []byte, paths []string) (string, error) {
+	f, err := os.CreateTemp(dir, CacheKeyFile+".tmp*")
+	if err != nil {
+		return "", fmt.Errorf("writing cache key: %w", err)
+	}
+	data := base64.StdEncoding.EncodeToString(key) + "\n"
+	for _, path := range paths {
+		data += path + "\n"
+	}
+	_, err = f.WriteString(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(f.Name(), 0600)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("writing cache key: %w", err)
+	}
+	return f.Name(), nil
+}
+
 // --- Config (preferences) ---
 
 // GetConfigPath returns the path to the config file
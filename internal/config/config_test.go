@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -165,4 +166,97 @@ func TestGetAPIKeyFromEnv(t *testing.T) {
 	if key != "test-key-123" {
 		t.Errorf("expected 'test-key-123', got '%s'", key)
 	}
-}
\ No newline at end of file
+}
+
+func TestCacheKey_SaveAndLoad(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	key, err := LoadCacheKey()
+	if err != nil || key != nil {
+		t.Fatalf("LoadCacheKey with no key = %v, %v; want nil, nil", key, err)
+	}
+
+	want := []byte("0123456789abcdef0123456789abcdef")
+	if err := SaveCacheKey(want); err != nil {
+		t.Fatalf("SaveCacheKey: %v", err)
+	}
+	key, err = LoadCacheKey()
+	if err != nil || string(key) != string(want) {
+		t.Errorf("LoadCacheKey = %q, %v; want the saved key", key, err)
+	}
+
+	if os.PathSeparator == '/' {
+		dir, _ := GetConfigDir()
+		info, err := os.Stat(filepath.Join(dir, CacheKeyFile))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0600 {
+			t.Errorf("expected permissions 0600, got %o", perm)
+		}
+	}
+}
+
+func TestCreateCacheKey_KeepsExisting(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	first := []byte("0123456789abcdef0123456789abcdef")
+	key, err := CreateCacheKey(first)
+	if err != nil || string(key) != string(first) {
+		t.Fatalf("CreateCacheKey = %q, %v; want the new key", key, err)
+	}
+
+	// A second process generating a key at the same time gets the first
+	key, err = CreateCacheKey([]byte("fedcba9876543210fedcba9876543210"))
+	if err != nil || string(key) != string(first) {
+		t.Errorf("CreateCacheKey = %q, %v; want the stored key", key, err)
+	}
+	if stored, _ := LoadCacheKey(); string(stored) != string(first) {
+		t.Errorf("expected the stored key to be kept, got %q", stored)
+	}
+
+	dir, _ := GetConfigDir()
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("expected only %s in the config dir, got %v", CacheKeyFile, entries)
+	}
+}
+
+func TestPendingCacheKey(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	old := []byte("0123456789abcdef0123456789abcdef")
+	SaveCacheKey(old)
+
+	pending := []byte("fedcba9876543210fedcba9876543210")
+	paths := []string{"/home/me/.config/paymo-cli/cache.db", "/home/me/.config/paymo-cli/caches/0a1b2c3d4e5f6a7b.db"}
+	if err := SavePendingCacheKey(pending, paths); err != nil {
+		t.Fatalf("SavePendingCacheKey: %v", err)
+	}
+	if key, _ := LoadCacheKey(); string(key) != string(old) {
+		t.Errorf("expected the pending key to leave the stored one, got %q", key)
+	}
+	key, saved, err := LoadPendingCacheKey()
+	if err != nil || string(key) != string(pending) || strings.Join(saved, ",") != strings.Join(paths, ",") {
+		t.Errorf("LoadPendingCacheKey = %q, %q, %v; want the pending key and paths", key, saved, err)
+	}
+
+	if err := CommitPendingCacheKey(); err != nil {
+		t.Fatalf("CommitPendingCacheKey: %v", err)
+	}
+	if key, _ := LoadCacheKey(); string(key) != string(pending) {
+		t.Errorf("expected the pending key to be stored, got %q", key)
+	}
+	if key, _, _ := LoadPendingCacheKey(); key != nil {
+		t.Errorf("expected no pending key after commit, got %q", key)
+	}
+
+	SavePendingCacheKey(old, nil)
+	if err := DiscardPendingCacheKey(); err != nil {
+		t.Fatalf("DiscardPendingCacheKey: %v", err)
+	}
+	if key, _, _ := LoadPendingCacheKey(); key != nil {
+		t.Errorf("expected the pending key to be discarded, got %q", key)
+	}
+	if key, _ := LoadCacheKey(); string(key) != string(pending) {
+		t.Errorf("expected discarding to keep the stored key, got %q", key)
+	}
+}
@@ -0,0 +1,236 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ContextFile is the per-repository file mapping a directory to a Paymo project.
+// It isn't called .paymo.yaml: that is the name of the CLI config, which is
+// also looked up in the current and home directories.
+const ContextFile = ".paymo-project.yaml"
+
+// ProjectContext holds the contents of a .paymo-project.yaml context file
+type ProjectContext struct {
+	Project     string            `yaml:"project"`               // project name or ID
+	Task        string            `yaml:"task,omitempty"`        // default task name or ID
+	Billable    *bool             `yaml:"billable,omitempty"`    // default billable flag for new projects/tasks
+	Description string            `yaml:"description,omitempty"` // description template for new entries
+	Branches    map[string]string `yaml:"branches,omitempty"`    // branch glob -> task name or ID
+}
+
+// ContextValue is a merged context setting and the file that supplied it
+type ContextValue struct {
+	Value  string `json:"value"`
+	Source string `json:"source,omitempty"`
+}
+
+// IsSet reports whether any source supplied the value
+func (v ContextValue) IsSet() bool {
+	return v.Source != ""
+}
+
+// Context is the effective project context for a directory, merged from
+// every .paymo-project.yaml between the directory and the filesystem root (nearest
+// wins) and, for the project, the defaults in config.yaml.
+type Context struct {
+	Dir         string       `json:"dir"`
+	Project     ContextValue `json:"project"`
+	Task        ContextValue `json:"task"`
+	Billable    ContextValue `json:"billable"`
+	Description ContextValue `json:"description"`
+	Files       []string     `json:"files"` // context files found, nearest first
+}
+
+// ResolveContext discovers context files walking up from startDir and merges
+// them with cfg (which may be nil).
+func ResolveContext(startDir string, cfg *Config) (*Context, error) {
+	dir, err := filepath.Abs(startDir)
+	if err != nil {
+		return nil, fmt.Errorf("resolving %s: %w", startDir, err)
+	}
+
+	merged := &Context{Dir: dir, Files: []string{}}
+	for {
+		pc, err := LoadProjectContext(dir)
+		if err != nil {
+			return nil, err
+		}
+		if pc != nil {
+			src := filepath.Join(dir, ContextFile)
+			merged.Files = append(merged.Files, src)
+			setContextValue(&merged.Project, pc.Project, src)
+			// A task only makes sense alongside the project it belongs to, so
+			// tasks from files farther away than the project's are ignored.
+			if !merged.Project.IsSet() || merged.Project.Source == src {
+				setContextValue(&merged.Task, pc.Task, src)
+			}
+			if pc.Billable != nil {
+				setContextValue(&merged.Billable, strconv.FormatBool(*pc.Billable), src)
+			}
+			setContextValue(&merged.Description, pc.Description, src)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	// Nor does a task from a file when no context file names a project: the
+	// config.yaml default project below is unrelated to it.
+	if !merged.Project.IsSet() {
+		merged.Task = ContextValue{}
+	}
+
+	if cfg != nil && cfg.Defaults.ProjectID > 0 {
+		src := "config.yaml (defaults.project_id)"
+		if p, err := GetConfigPath(); err == nil {
+			src = p + " (defaults.project_id)"
+		}
+		setContextValue(&merged.Project, strconv.Itoa(cfg.Defaults.ProjectID), src)
+	}
+
+	return merged, nil
+}
+
+// setContextValue fills v unless a nearer source already set it.
+func setContextValue(v *ContextValue, value, source string) {
+	if v.IsSet() || value == "" {
+		return
+	}
+	v.Value = value
+	v.Source = source
+}
+
+// LoadProjectContext reads the context file in dir. Returns nil if none exists.
+func LoadProjectContext(dir string) (*ProjectContext, error) {
+	p := filepath.Join(dir, ContextFile)
+	data, err := os.ReadFile(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading %s: %w", p, err)
+	}
+
+	var ctx ProjectContext
+	if err := yaml.Unmarshal(data, &ctx); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", p, err)
+	}
+	return &ctx, nil
+}
+
+// SaveProjectContext writes the context file in dir
+func SaveProjectContext(dir string, ctx *ProjectContext) error {
+	data, err := yaml.Marshal(ctx)
+	if err != nil {
+		return fmt.Errorf("marshaling context: %w", err)
+	}
+
+	// Context files are meant to be committed, so keep them world-readable
+	if err := os.WriteFile(filepath.Join(dir, ContextFile), data, 0644); err != nil {
+		return fmt.Errorf("writing context: %w", err)
+	}
+	return nil
+}
+
+// UpdateProjectContext sets the project and task in the context file in dir,
+// creating it if needed; empty values are left as they are. An existing file
+// is edited in place, keeping its comments and any keys paymo doesn't know.
+func UpdateProjectContext(dir, project, task string) (*ProjectContext, error) {
+	p := filepath.Join(dir, ContextFile)
+	data, err := os.ReadFile(p)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading %s: %w", p, err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", p, err)
+	}
+	if doc.Kind != yaml.DocumentNode {
+		doc = yaml.Node{Kind: yaml.DocumentNode}
+	}
+	if len(doc.Content) == 0 {
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("parsing %s: expected keys such as project and task", p)
+	}
+	setMappingValue(root, "project", project)
+	setMappingValue(root, "task", task)
+
+	var ctx ProjectContext
+	if err := root.Decode(&ctx); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", p, err)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("marshaling context: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("marshaling context: %w", err)
+	}
+	if err := os.WriteFile(p, buf.Bytes(), 0644); err != nil {
+		return nil, fmt.Errorf("writing context: %w", err)
+	}
+	return &ctx, nil
+}
+
+// setMappingValue sets key to a string value in a YAML mapping, replacing
+// the existing value but keeping its comments. Empty values are skipped.
+func setMappingValue(m *yaml.Node, key, value string) {
+	if value == "" {
+		return
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			v := m.Content[i+1]
+			v.Kind, v.Tag, v.Style, v.Value, v.Content = yaml.ScalarNode, "!!str", 0, value, nil
+			return
+		}
+	}
+	m.Content = append(m.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value},
+	)
+}
+
+// TaskForBranch returns the task mapped to a branch. Exact branch names win
+// over glob patterns, longer patterns win over shorter ones, and the default
+// task is returned when nothing matches.
+func (c *ProjectContext) TaskForBranch(branch string) string {
+	if task, ok := c.Branches[branch]; ok {
+		return task
+	}
+
+	patterns := make([]string, 0, len(c.Branches))
+	for p := range c.Branches {
+		patterns = append(patterns, p)
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i]) != len(patterns[j]) {
+			return len(patterns[i]) > len(patterns[j])
+		}
+		return patterns[i] < patterns[j]
+	})
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, branch); ok {
+			return c.Branches[p]
+		}
+	}
+	return c.Task
+}
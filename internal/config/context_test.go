@@ -0,0 +1,222 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadProjectContext_Missing(t *testing.T) {
+	ctx, err := LoadProjectContext(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ctx != nil {
+		t.Errorf("expected nil context, got %+v", ctx)
+	}
+}
+
+func TestProjectContext_SaveAndLoad(t *testing.T) {
+	dir := t.TempDir()
+	in := &ProjectContext{
+		Project:  "Website",
+		Task:     "Development",
+		Branches: map[string]string{"fix/*": "Bug Fixing"},
+	}
+	if err := SaveProjectContext(dir, in); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out, err := LoadProjectContext(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Project != "Website" || out.Task != "Development" || out.Branches["fix/*"] != "Bug Fixing" {
+		t.Errorf("round trip mismatch: %+v", out)
+	}
+}
+
+func TestUpdateProjectContext_KeepsFile(t *testing.T) {
+	dir := t.TempDir()
+	original := `# Paymo context for this repo
+project: Website # the client site
+
+task: Development
+branches:
+  fix/*: Bug Fixing
+owner: web-team
+`
+	os.WriteFile(filepath.Join(dir, ContextFile), []byte(original), 0644)
+
+	ctx, err := UpdateProjectContext(dir, "Intranet", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ctx.Project != "Intranet" || ctx.Task != "Development" || ctx.Branches["fix/*"] != "Bug Fixing" {
+		t.Errorf("updated context = %+v", ctx)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, ContextFile))
+	for _, want := range []string{"# Paymo context for this repo", "project: Intranet # the client site", "owner: web-team", "  fix/*: Bug Fixing"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected %q to be kept, got:\n%s", want, data)
+		}
+	}
+}
+
+func TestUpdateProjectContext_New(t *testing.T) {
+	dir := t.TempDir()
+	ctx, err := UpdateProjectContext(dir, "123", "Design")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out, err := LoadProjectContext(dir)
+	if err != nil || out.Project != "123" || out.Task != "Design" || ctx.Project != "123" {
+		t.Errorf("new context = %+v, %v", out, err)
+	}
+
+	os.WriteFile(filepath.Join(dir, ContextFile), []byte("- not a mapping\n"), 0644)
+	if _, err := UpdateProjectContext(dir, "Website", ""); err == nil {
+		t.Error("expected an error for a file that isn't a mapping")
+	}
+}
+
+func TestLoadProjectContext_NumericIDs(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, ContextFile), []byte("project: 123\ntask: 456\n"), 0644)
+
+	ctx, err := LoadProjectContext(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ctx.Project != "123" || ctx.Task != "456" {
+		t.Errorf("expected numeric IDs as strings, got %+v", ctx)
+	}
+}
+
+func TestLoadProjectContext_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, ContextFile), []byte("project: [unclosed"), 0644)
+
+	if _, err := LoadProjectContext(dir); err == nil {
+		t.Fatal("expected parse error")
+	}
+}
+
+func TestProjectContext_TaskForBranch(t *testing.T) {
+	ctx := &ProjectContext{
+		Task: "Development",
+		Branches: map[string]string{
+			"main":      "Maintenance",
+			"fix/*":     "Bug Fixing",
+			"fix/ui-*":  "UI Fixes",
+			"release/*": "Releases",
+		},
+	}
+
+	tests := []struct {
+		branch   string
+		expected string
+	}{
+		{"main", "Maintenance"},
+		{"fix/login", "Bug Fixing"},
+		{"fix/ui-header", "UI Fixes"},
+		{"release/1.2", "Releases"},
+		{"feature/search", "Development"},
+	}
+	for _, tt := range tests {
+		if got := ctx.TaskForBranch(tt.branch); got != tt.expected {
+			t.Errorf("TaskForBranch(%q) = %q, want %q", tt.branch, got, tt.expected)
+		}
+	}
+}
+
+func TestResolveContext_NearestWins(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	root := t.TempDir()
+	sub := filepath.Join(root, "services", "api")
+	os.MkdirAll(sub, 0755)
+
+	os.WriteFile(filepath.Join(root, ContextFile),
+		[]byte("project: Website\ntask: Development\nbillable: true\ndescription: \"{branch}\"\n"), 0644)
+	os.WriteFile(filepath.Join(root, "services", ContextFile),
+		[]byte("task: Backend\nbillable: false\n"), 0644)
+
+	ctx, err := ResolveContext(sub, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rootFile := filepath.Join(root, ContextFile)
+	servicesFile := filepath.Join(root, "services", ContextFile)
+
+	if ctx.Project.Value != "Website" || ctx.Project.Source != rootFile {
+		t.Errorf("unexpected project: %+v", ctx.Project)
+	}
+	if ctx.Task.Value != "Backend" || ctx.Task.Source != servicesFile {
+		t.Errorf("unexpected task: %+v", ctx.Task)
+	}
+	if ctx.Billable.Value != "false" || ctx.Billable.Source != servicesFile {
+		t.Errorf("unexpected billable: %+v", ctx.Billable)
+	}
+	if ctx.Description.Value != "{branch}" {
+		t.Errorf("unexpected description: %+v", ctx.Description)
+	}
+	if len(ctx.Files) != 2 || ctx.Files[0] != servicesFile {
+		t.Errorf("expected files nearest first, got %v", ctx.Files)
+	}
+}
+
+func TestResolveContext_TaskFollowsProject(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	root := t.TempDir()
+	sub := filepath.Join(root, "client")
+	os.MkdirAll(sub, 0755)
+
+	os.WriteFile(filepath.Join(root, ContextFile), []byte("project: Internal\ntask: Admin\n"), 0644)
+	os.WriteFile(filepath.Join(sub, ContextFile), []byte("project: Client Work\n"), 0644)
+
+	ctx, err := ResolveContext(sub, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ctx.Project.Value != "Client Work" {
+		t.Errorf("expected nearest project, got %+v", ctx.Project)
+	}
+	if ctx.Task.IsSet() {
+		t.Errorf("task from another project's file must not apply, got %+v", ctx.Task)
+	}
+}
+
+func TestResolveContext_TaskWithoutProject(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, ContextFile), []byte("task: Backend\n"), 0644)
+
+	ctx, err := ResolveContext(dir, &Config{Defaults: DefaultsConfig{ProjectID: 42}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ctx.Project.Value != "42" {
+		t.Errorf("expected project from config defaults, got %+v", ctx.Project)
+	}
+	if ctx.Task.IsSet() {
+		t.Errorf("task must not pair with the config.yaml default project, got %+v", ctx.Task)
+	}
+}
+
+func TestResolveContext_ConfigDefaultProject(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cfg := &Config{Defaults: DefaultsConfig{ProjectID: 42}}
+
+	ctx, err := ResolveContext(t.TempDir(), cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ctx.Project.Value != "42" {
+		t.Errorf("expected project from config defaults, got %+v", ctx.Project)
+	}
+	if ctx.Project.Source == "" {
+		t.Error("expected config source to be reported")
+	}
+}
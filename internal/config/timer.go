@@ -90,7 +90,11 @@ func (s *TimerState) GetElapsedTime() time.Duration {
 
 // FormatElapsedTime returns a human-readable elapsed time
 func (s *TimerState) FormatElapsedTime() string {
-	elapsed := s.GetElapsedTime()
+	return FormatElapsed(s.GetElapsedTime())
+}
+
+// FormatElapsed formats an elapsed duration like "1h 5m 3s"
+func FormatElapsed(elapsed time.Duration) string {
 	hours := int(elapsed.Hours())
 	minutes := int(elapsed.Minutes()) % 60
 	seconds := int(elapsed.Seconds()) % 60
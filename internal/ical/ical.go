@@ -0,0 +1,349 @@
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Layouts for DATE-TIME and DATE values (RFC 5545 section 3.3).
+const (
+	utcLayout   = "20060102T150405Z"
+	localLayout = "20060102T150405"
+	dateLayout  = "20060102"
+)
+
+// prodID identifies paymo-cli as the producer of exported calendars.
+const prodID = "-//paymo-cli//Time Entries//EN"
+
+// Event is a VEVENT, reduced to the properties paymo reads and writes.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	Categories  []string
+	Start       time.Time
+	End         time.Time
+	AllDay      bool   // DTSTART is a DATE, not a DATE-TIME
+	Recurring   bool   // has an RRULE; only the first occurrence is in Start/End
+	Status      string // TENTATIVE, CONFIRMED or CANCELLED, uppercase
+	Line        int    // line of BEGIN:VEVENT in the parsed file
+}
+
+// Duration returns the length of the event.
+func (e Event) Duration() time.Duration {
+	return e.End.Sub(e.Start)
+}
+
+// Encode writes events as a VCALENDAR. Times are written in UTC.
+func Encode(w io.Writer, events []Event, stamp time.Time) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeFolded(bw, name+":"+value)
+	}
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", prodID)
+	line("CALSCALE", "GREGORIAN")
+	for _, e := range events {
+		line("BEGIN", "VEVENT")
+		line("UID", escapeText(e.UID))
+		line("DTSTAMP", stamp.UTC().Format(utcLayout))
+		if e.AllDay {
+			line("DTSTART;VALUE=DATE", e.Start.Format(dateLayout))
+			line("DTEND;VALUE=DATE", e.End.Format(dateLayout))
+		} else {
+			line("DTSTART", e.Start.UTC().Format(utcLayout))
+			line("DTEND", e.End.UTC().Format(utcLayout))
+		}
+		line("SUMMARY", escapeText(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION", escapeText(e.Description))
+		}
+		if e.Location != "" {
+			line("LOCATION", escapeText(e.Location))
+		}
+		if len(e.Categories) > 0 {
+			cats := make([]string, len(e.Categories))
+			for i, c := range e.Categories {
+				cats[i] = escapeText(c)
+			}
+			line("CATEGORIES", strings.Join(cats, ","))
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+// writeFolded writes a content line, folding it at 75 octets without
+// splitting UTF-8 sequences.
+func writeFolded(w *bufio.Writer, s string) {
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(s[cut]) {
+			cut--
+		}
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+		limit = 74 // the leading space counts towards the next line
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+// escapeText escapes a TEXT value.
+func escapeText(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return r.Replace(s)
+}
+
+// unescapeText reverses escapeText.
+func unescapeText(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// property is one unfolded content line.
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+	line   int
+}
+
+// Parse reads the VEVENTs of a calendar. Times without a timezone
+// ("floating" times) and TZIDs that are not IANA names are read in loc.
+func Parse(r io.Reader, loc *time.Location) ([]Event, error) {
+	props, err := readProperties(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	var cur *Event
+	var duration string
+	nested := 0 // depth of components inside the event, such as VALARM
+	for _, p := range props {
+		switch {
+		case cur != nil && p.name == "BEGIN":
+			nested++
+		case nested > 0:
+			if p.name == "END" {
+				nested--
+			}
+		case p.name == "BEGIN" && strings.EqualFold(p.value, "VEVENT"):
+			cur = &Event{Line: p.line}
+			duration = ""
+		case p.name == "END" && strings.EqualFold(p.value, "VEVENT"):
+			if cur == nil {
+				return nil, fmt.Errorf("line %d: END:VEVENT without BEGIN:VEVENT", p.line)
+			}
+			if cur.Start.IsZero() {
+				return nil, fmt.Errorf("line %d: event %q has no DTSTART", cur.Line, cur.Summary)
+			}
+			if cur.End.IsZero() {
+				cur.End = cur.Start
+				if duration != "" {
+					d, err := parseDuration(duration)
+					if err != nil {
+						return nil, fmt.Errorf("line %d: %w", cur.Line, err)
+					}
+					cur.End = cur.Start.Add(d)
+				} else if cur.AllDay {
+					cur.End = cur.Start.AddDate(0, 0, 1)
+				}
+			}
+			events = append(events, *cur)
+			cur = nil
+		case cur == nil:
+			// Calendar properties and other components (VTIMEZONE, VTODO) are ignored
+		case p.name == "UID":
+			cur.UID = p.value
+		case p.name == "SUMMARY":
+			cur.Summary = unescapeText(p.value)
+		case p.name == "DESCRIPTION":
+			cur.Description = unescapeText(p.value)
+		case p.name == "LOCATION":
+			cur.Location = unescapeText(p.value)
+		case p.name == "STATUS":
+			cur.Status = strings.ToUpper(p.value)
+		case p.name == "RRULE":
+			cur.Recurring = true
+		case p.name == "CATEGORIES":
+			for _, c := range splitList(p.value) {
+				cur.Categories = append(cur.Categories, unescapeText(c))
+			}
+		case p.name == "DURATION":
+			duration = p.value
+		case p.name == "DTSTART" || p.name == "DTEND":
+			t, allDay, err := parseTime(p, loc)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s: %w", p.line, p.name, err)
+			}
+			if p.name == "DTSTART" {
+				cur.Start, cur.AllDay = t, allDay
+			} else {
+				cur.End = t
+			}
+		}
+	}
+	if cur != nil {
+		return nil, fmt.Errorf("line %d: BEGIN:VEVENT without END:VEVENT", cur.Line)
+	}
+	return events, nil
+}
+
+// readProperties unfolds and splits the content lines of a calendar.
+func readProperties(r io.Reader) ([]property, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var props []property
+	var raw []string
+	var lines []int
+	n := 0
+	for scanner.Scan() {
+		n++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if n == 1 {
+			text = strings.TrimPrefix(text, "\uFEFF") // byte order mark
+		}
+		if (strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t")) && len(raw) > 0 {
+			raw[len(raw)-1] += text[1:]
+			continue
+		}
+		if text == "" {
+			continue
+		}
+		raw = append(raw, text)
+		lines = append(lines, n)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading calendar: %w", err)
+	}
+	if len(raw) == 0 || !strings.EqualFold(raw[0], "BEGIN:VCALENDAR") {
+		return nil, fmt.Errorf("not an iCalendar file (expected BEGIN:VCALENDAR)")
+	}
+
+	for i, text := range raw {
+		p, err := parseProperty(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lines[i], err)
+		}
+		p.line = lines[i]
+		props = append(props, p)
+	}
+	return props, nil
+}
+
+// parseProperty splits "NAME;PARAM=VALUE:value".
+func parseProperty(text string) (property, error) {
+	colon := -1
+	quoted := false
+	for i, r := range text {
+		if r == '"' {
+			quoted = !quoted
+		}
+		if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return property{}, fmt.Errorf("malformed line %q", text)
+	}
+
+	head := strings.Split(text[:colon], ";")
+	p := property{name: strings.ToUpper(head[0]), params: map[string]string{}, value: text[colon+1:]}
+	for _, param := range head[1:] {
+		if k, v, ok := strings.Cut(param, "="); ok {
+			p.params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+	}
+	return p, nil
+}
+
+// parseTime parses a DTSTART or DTEND value, honoring VALUE=DATE and TZID.
+func parseTime(p property, loc *time.Location) (time.Time, bool, error) {
+	value := strings.TrimSpace(p.value)
+	if strings.EqualFold(p.params["VALUE"], "DATE") || len(value) == len(dateLayout) {
+		t, err := time.ParseInLocation(dateLayout, value, loc)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(utcLayout, value)
+		return t, false, err
+	}
+	if tzid := p.params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation(localLayout, value, loc)
+	return t, false, err
+}
+
+// durationPattern matches RFC 5545 durations such as PT1H30M, P1D or P2W.
+var durationPattern = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// parseDuration parses a DURATION value.
+func parseDuration(s string) (time.Duration, error) {
+	m := durationPattern.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(s)))
+	if m == nil || s == "P" || s == "PT" {
+		return 0, fmt.Errorf("invalid DURATION %q", s)
+	}
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var d time.Duration
+	for i, unit := range units {
+		if m[i+2] == "" {
+			continue
+		}
+		n, _ := strconv.Atoi(m[i+2])
+		d += time.Duration(n) * unit
+	}
+	if m[1] == "-" {
+		d = -d
+	}
+	return d, nil
+}
+
+// splitList splits a comma-separated list, keeping escaped commas.
+func splitList(s string) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case ',':
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
@@ -0,0 +1,165 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+const sample = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//Example//Calendar//EN\r\n" +
+	"BEGIN:VTIMEZONE\r\n" +
+	"TZID:Europe/Copenhagen\r\n" +
+	"END:VTIMEZONE\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup-1@example.com\r\n" +
+	"DTSTART;TZID=Europe/Copenhagen:20260209T093000\r\n" +
+	"DTEND;TZID=Europe/Copenhagen:20260209T094500\r\n" +
+	"SUMMARY:Daily standup\\, team A\r\n" +
+	"DESCRIPTION:Agenda:\\nblockers\r\n" +
+	"  and demos\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"DESCRIPTION:Reminder\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:review@example.com\r\n" +
+	"DTSTART:20260209T130000Z\r\n" +
+	"DURATION:PT1H30M\r\n" +
+	"SUMMARY:Design review\r\n" +
+	"RRULE:FREQ=WEEKLY\r\n" +
+	"STATUS:cancelled\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:holiday@example.com\r\n" +
+	"DTSTART;VALUE=DATE:20260210\r\n" +
+	"SUMMARY:Holiday\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParse(t *testing.T) {
+	events, err := Parse(strings.NewReader(sample), time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(events))
+	}
+
+	standup := events[0]
+	if standup.Summary != "Daily standup, team A" || standup.Description != "Agenda:\nblockers and demos" {
+		t.Errorf("unexpected text: %q / %q", standup.Summary, standup.Description)
+	}
+	if want := time.Date(2026, 2, 9, 8, 30, 0, 0, time.UTC); !standup.Start.Equal(want) || standup.Duration() != 15*time.Minute {
+		t.Errorf("unexpected time: %v for %v", standup.Start, standup.Duration())
+	}
+	if standup.Line != 7 {
+		t.Errorf("expected event at line 7, got %d", standup.Line)
+	}
+
+	review := events[1]
+	if review.Duration() != 90*time.Minute || !review.Recurring || review.Status != "CANCELLED" {
+		t.Errorf("unexpected review event: %+v", review)
+	}
+
+	holiday := events[2]
+	if !holiday.AllDay || holiday.Duration() != 24*time.Hour {
+		t.Errorf("unexpected all-day event: %+v", holiday)
+	}
+}
+
+func TestParse_FloatingTimesUseLocation(t *testing.T) {
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	cal := "BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:20260209T090000\nDTEND:20260209T100000\nEND:VEVENT\nEND:VCALENDAR\n"
+	events, err := Parse(strings.NewReader(cal), tokyo)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := time.Date(2026, 2, 9, 0, 0, 0, 0, time.UTC); !events[0].Start.Equal(want) {
+		t.Errorf("Start = %v, want %v", events[0].Start, want)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		cal, want string
+	}{
+		{"hello", "not an iCalendar file"},
+		{"BEGIN:VCALENDAR\nBEGIN:VEVENT\nSUMMARY:x\nEND:VEVENT\nEND:VCALENDAR", "line 2: event \"x\" has no DTSTART"},
+		{"BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:tomorrow\nEND:VEVENT\nEND:VCALENDAR", "line 3: DTSTART"},
+		{"BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:20260209T090000Z\n", "BEGIN:VEVENT without END:VEVENT"},
+		{"BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:20260209T090000Z\nDURATION:soon\nEND:VEVENT\n", "invalid DURATION"},
+		{"BEGIN:VCALENDAR\nno colon here\n", "line 2: malformed line"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(strings.NewReader(tt.cal), time.UTC)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse(%q) error = %v, want %q", tt.cal, err, tt.want)
+		}
+	}
+}
+
+func TestEncode_RoundTrip(t *testing.T) {
+	start := time.Date(2026, 2, 9, 9, 0, 0, 0, time.UTC)
+	events := []Event{{
+		UID:         "paymo-entry-1@paymo-cli",
+		Summary:     "Website / Development",
+		Description: strings.Repeat("Refactored the checkout; fixed tests, ", 4) + "\nshipped ✓",
+		Categories:  []string{"Website", "Acme, Inc."},
+		Start:       start,
+		End:         start.Add(2 * time.Hour),
+	}}
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, events, start); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line longer than 75 octets: %q", line)
+		}
+	}
+	if !strings.Contains(buf.String(), "DTSTART:20260209T090000Z\r\n") {
+		t.Errorf("expected UTC DTSTART:\n%s", buf.String())
+	}
+
+	parsed, err := Parse(&buf, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := parsed[0]
+	if got.UID != events[0].UID || got.Summary != events[0].Summary || got.Description != events[0].Description {
+		t.Errorf("round trip changed text: %+v", got)
+	}
+	if len(got.Categories) != 2 || got.Categories[1] != "Acme, Inc." {
+		t.Errorf("round trip changed categories: %q", got.Categories)
+	}
+	if !got.Start.Equal(start) || got.Duration() != 2*time.Hour {
+		t.Errorf("round trip changed times: %v for %v", got.Start, got.Duration())
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"PT15M":    15 * time.Minute,
+		"PT1H30M":  90 * time.Minute,
+		"P1D":      24 * time.Hour,
+		"P1W":      7 * 24 * time.Hour,
+		"P1DT2H":   26 * time.Hour,
+		"-PT5M":    -5 * time.Minute,
+		"PT0H0M1S": time.Second,
+	}
+	for s, want := range tests {
+		got, err := parseDuration(s)
+		if err != nil || got != want {
+			t.Errorf("parseDuration(%q) = %v, %v; want %v", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"", "P", "PT", "1H", "PT1.5H"} {
+		if _, err := parseDuration(s); err == nil {
+			t.Errorf("parseDuration(%q): expected error", s)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package ical
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Rules map calendar events to Paymo projects and tasks. They are read from
+// a YAML file passed to `paymo time import --map`:
+//
+//	rules:
+//	  - match: standup              # text in the event summary, any case
+//	    project: Internal
+//	    task: Meetings
+//	  - match: /^1:1 /              # /.../ is a regular expression
+//	    project: Internal
+//	    task: 1:1s
+//	    description: "{summary} ({location})"
+//	  - match: lunch
+//	    skip: true
+//	default:                        # optional, for events no rule matches
+//	  project: Internal
+//	  task: Meetings
+type Rules struct {
+	Rules   []Rule `yaml:"rules"`
+	Default *Rule  `yaml:"default,omitempty"`
+}
+
+// Rule maps the events whose summary matches to a project and task.
+type Rule struct {
+	Match       string `yaml:"match"`
+	Project     string `yaml:"project,omitempty"`     // project name or ID
+	Task        string `yaml:"task,omitempty"`        // task name or ID
+	Description string `yaml:"description,omitempty"` // entry description template, default "{summary}"
+	Skip        bool   `yaml:"skip,omitempty"`        // ignore matching events
+
+	re *regexp.Regexp
+}
+
+// LoadRules reads and validates a rules file.
+func LoadRules(path string) (*Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading rules: %w", err)
+	}
+	var rules Rules
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if err := rules.compile(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &rules, nil
+}
+
+// compile validates the rules and compiles their patterns.
+func (r *Rules) compile() error {
+	for i := range r.Rules {
+		rule := &r.Rules[i]
+		if rule.Match == "" {
+			return fmt.Errorf("rule %d: match is required", i+1)
+		}
+		if p := rule.Match; len(p) > 1 && strings.HasPrefix(p, "/") && strings.HasSuffix(p, "/") {
+			re, err := regexp.Compile("(?i)" + p[1:len(p)-1])
+			if err != nil {
+				return fmt.Errorf("rule %d: invalid pattern %s: %w", i+1, p, err)
+			}
+			rule.re = re
+		}
+		if !rule.Skip && (rule.Project == "" || rule.Task == "") {
+			return fmt.Errorf("rule %d (%s): project and task are required unless skip is set", i+1, rule.Match)
+		}
+	}
+	if d := r.Default; d != nil && !d.Skip && (d.Project == "" || d.Task == "") {
+		return fmt.Errorf("default: project and task are required")
+	}
+	return nil
+}
+
+// Find returns the first rule matching the event, then the default rule, or
+// nil when neither applies.
+func (r *Rules) Find(e Event) *Rule {
+	for i := range r.Rules {
+		if r.Rules[i].matches(e.Summary) {
+			return &r.Rules[i]
+		}
+	}
+	return r.Default
+}
+
+func (rule *Rule) matches(summary string) bool {
+	if rule.re != nil {
+		return rule.re.MatchString(summary)
+	}
+	return strings.Contains(strings.ToLower(summary), strings.ToLower(rule.Match))
+}
+
+// EntryDescription expands the rule's description template for an event.
+// Placeholders are {summary}, {location} and {description}.
+func (rule *Rule) EntryDescription(e Event) string {
+	tmpl := rule.Description
+	if tmpl == "" {
+		tmpl = "{summary}"
+	}
+	r := strings.NewReplacer("{summary}", e.Summary, "{location}", e.Location, "{description}", e.Description)
+	return strings.TrimSpace(r.Replace(tmpl))
+}
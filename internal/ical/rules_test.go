@@ -0,0 +1,90 @@
+package ical
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeRules(t *testing.T, content string) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestLoadRules_Find(t *testing.T) {
+	rules, err := LoadRules(writeRules(t, `
+rules:
+  - match: standup
+    project: Internal
+    task: Meetings
+  - match: /^1:1 /
+    project: Internal
+    task: "1:1s"
+    description: "{summary} ({location})"
+  - match: lunch
+    skip: true
+default:
+  project: Internal
+  task: Other
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		summary, task string
+		skip          bool
+	}{
+		{"Daily STANDUP", "Meetings", false},
+		{"1:1 with Sam", "1:1s", false},
+		{"Team 1:1 sync", "Other", false},
+		{"Lunch & learn", "", true},
+	}
+	for _, tt := range tests {
+		rule := rules.Find(Event{Summary: tt.summary})
+		if rule == nil || rule.Task != tt.task || rule.Skip != tt.skip {
+			t.Errorf("Find(%q) = %+v", tt.summary, rule)
+		}
+	}
+
+	rule := rules.Find(Event{Summary: "1:1 with Sam", Location: "Room 2"})
+	if got := rule.EntryDescription(Event{Summary: "1:1 with Sam", Location: "Room 2"}); got != "1:1 with Sam (Room 2)" {
+		t.Errorf("EntryDescription = %q", got)
+	}
+}
+
+func TestRules_NoDefault(t *testing.T) {
+	rules, err := LoadRules(writeRules(t, "rules:\n  - match: standup\n    project: 1\n    task: 2\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rule := rules.Find(Event{Summary: "Dentist"}); rule != nil {
+		t.Errorf("expected no rule, got %+v", rule)
+	}
+}
+
+func TestLoadRules_Invalid(t *testing.T) {
+	tests := []struct {
+		content, want string
+	}{
+		{"rules:\n  - project: A\n    task: B\n", "rule 1: match is required"},
+		{"rules:\n  - match: standup\n    project: A\n", "rule 1 (standup): project and task are required"},
+		{"rules:\n  - match: /[/\n    skip: true\n", "invalid pattern"},
+		{"default:\n  project: A\n", "default: project and task are required"},
+		{"rules: [", "parsing"},
+	}
+	for _, tt := range tests {
+		_, err := LoadRules(writeRules(t, tt.content))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("LoadRules(%q) error = %v, want %q", tt.content, err, tt.want)
+		}
+	}
+	if _, err := LoadRules(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
@@ -0,0 +1,144 @@
+package locale
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	_ "time/tzdata" // timezone names resolve even without system zoneinfo
+)
+
+// Default layouts, used when config.yaml leaves output.date_format and
+// output.time_format empty.
+const (
+	DefaultDateFormat = "2006-01-02"
+	DefaultTimeFormat = "15:04"
+)
+
+// isoDate is always accepted when parsing dates, whatever the configured format.
+const isoDate = "2006-01-02"
+
+// now is defined as a var to allow test injection.
+var now = time.Now
+
+// Locale formats and parses dates and times in the user's timezone. Every
+// renderer and date flag goes through it, so output.date_format,
+// output.time_format and the timezone apply consistently.
+type Locale struct {
+	Location   *time.Location
+	DateFormat string // Go layout for dates
+	TimeFormat string // Go layout for times of day
+}
+
+// Default returns a locale using the system timezone and default layouts.
+func Default() *Locale {
+	return &Locale{Location: time.Local, DateFormat: DefaultDateFormat, TimeFormat: DefaultTimeFormat}
+}
+
+// New creates a locale from an IANA timezone name (empty for the system
+// timezone) and Go layouts (empty for the defaults).
+func New(timezone, dateFormat, timeFormat string) (*Locale, error) {
+	l := Default()
+	if timezone != "" {
+		loc, err := time.LoadLocation(timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", timezone, err)
+		}
+		l.Location = loc
+	}
+	if dateFormat != "" {
+		l.DateFormat = dateFormat
+	}
+	if timeFormat != "" {
+		l.TimeFormat = timeFormat
+	}
+	return l, nil
+}
+
+// In converts t to the locale's timezone.
+func (l *Locale) In(t time.Time) time.Time {
+	return t.In(l.Location)
+}
+
+// Date formats the calendar date of t.
+func (l *Locale) Date(t time.Time) string {
+	return l.In(t).Format(l.DateFormat)
+}
+
+// Time formats the time of day of t.
+func (l *Locale) Time(t time.Time) string {
+	return l.In(t).Format(l.TimeFormat)
+}
+
+// DateTime formats t as date and time of day.
+func (l *Locale) DateTime(t time.Time) string {
+	return l.In(t).Format(l.DateFormat + " " + l.TimeFormat)
+}
+
+// RFC3339 formats t as an RFC 3339 timestamp with the locale's offset.
+func (l *Locale) RFC3339(t time.Time) string {
+	return l.In(t).Format(time.RFC3339)
+}
+
+// Now returns the current time in the locale's timezone.
+func (l *Locale) Now() time.Time {
+	return now().In(l.Location)
+}
+
+// Today returns midnight at the start of the current day.
+func (l *Locale) Today() time.Time {
+	return l.StartOfDay(l.Now())
+}
+
+// StartOfDay returns midnight at the start of t's day in the locale's timezone.
+func (l *Locale) StartOfDay(t time.Time) time.Time {
+	t = l.In(t)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, l.Location)
+}
+
+// ParseDate parses a calendar date in YYYY-MM-DD or the configured date
+// format, returning midnight in the locale's timezone.
+func (l *Locale) ParseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{isoDate, l.DateFormat} {
+		if t, err := time.ParseInLocation(layout, s, l.Location); err == nil {
+			return l.StartOfDay(t), nil
+		}
+	}
+	if l.DateFormat != isoDate {
+		return time.Time{}, fmt.Errorf("invalid date: %s (use YYYY-MM-DD or %s)", s, l.DateFormat)
+	}
+	return time.Time{}, fmt.Errorf("invalid date format: %s (use YYYY-MM-DD)", s)
+}
+
+// DateRange resolves a --date value to a [start, end) range: today,
+// yesterday, this-week (Monday until now), last-week (Monday to Monday) or
+// a single date. The end is exclusive: an entry starting exactly at it
+// belongs to the next range.
+func (l *Locale) DateRange(spec string) (time.Time, time.Time, error) {
+	today := l.Today()
+	switch strings.ToLower(strings.TrimSpace(spec)) {
+	case "today", "":
+		return today, today.AddDate(0, 0, 1), nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), today, nil
+	case "this-week":
+		return startOfWeek(today), l.Now(), nil
+	case "last-week":
+		monday := startOfWeek(today)
+		return monday.AddDate(0, 0, -7), monday, nil
+	}
+	date, err := l.ParseDate(spec)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return date, date.AddDate(0, 0, 1), nil
+}
+
+// startOfWeek returns the Monday on or before day.
+func startOfWeek(day time.Time) time.Time {
+	weekday := int(day.Weekday())
+	if weekday == 0 {
+		weekday = 7
+	}
+	return day.AddDate(0, 0, 1-weekday)
+}
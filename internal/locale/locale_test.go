@@ -0,0 +1,110 @@
+package locale
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func fixNow(t *testing.T, at time.Time) {
+	t.Helper()
+	orig := now
+	now = func() time.Time { return at }
+	t.Cleanup(func() { now = orig })
+}
+
+func TestNew_Defaults(t *testing.T) {
+	l, err := New("", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if l.Location != time.Local || l.DateFormat != DefaultDateFormat || l.TimeFormat != DefaultTimeFormat {
+		t.Errorf("unexpected defaults: %+v", l)
+	}
+}
+
+func TestNew_InvalidTimezone(t *testing.T) {
+	_, err := New("Mars/Olympus_Mons", "", "")
+	if err == nil || !strings.Contains(err.Error(), "invalid timezone") {
+		t.Errorf("expected invalid timezone error, got %v", err)
+	}
+}
+
+func TestFormat_ConvertsTimezone(t *testing.T) {
+	l, err := New("America/New_York", "02/01/2006", "3:04PM")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 02:30 UTC is still the previous evening in New York
+	ts := time.Date(2026, 2, 7, 2, 30, 0, 0, time.UTC)
+	if got := l.Date(ts); got != "06/02/2026" {
+		t.Errorf("Date = %q", got)
+	}
+	if got := l.Time(ts); got != "9:30PM" {
+		t.Errorf("Time = %q", got)
+	}
+	if got := l.DateTime(ts); got != "06/02/2026 9:30PM" {
+		t.Errorf("DateTime = %q", got)
+	}
+	if got := l.RFC3339(ts); got != "2026-02-06T21:30:00-05:00" {
+		t.Errorf("RFC3339 = %q", got)
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	l, _ := New("Europe/Copenhagen", "02.01.2006", "")
+	for _, s := range []string{"2026-02-07", "07.02.2026"} {
+		got, err := l.ParseDate(s)
+		if err != nil {
+			t.Fatalf("ParseDate(%q): %v", s, err)
+		}
+		want := time.Date(2026, 2, 7, 0, 0, 0, 0, l.Location)
+		if !got.Equal(want) {
+			t.Errorf("ParseDate(%q) = %v, want %v", s, got, want)
+		}
+	}
+	if _, err := l.ParseDate("7th of Feb"); err == nil || !strings.Contains(err.Error(), "02.01.2006") {
+		t.Errorf("expected error naming the configured format, got %v", err)
+	}
+}
+
+func TestDateRange(t *testing.T) {
+	l, _ := New("Asia/Tokyo", "", "")
+	// Wednesday 2026-02-11 08:00 in Tokyo, still Tuesday in UTC
+	fixNow(t, time.Date(2026, 2, 10, 23, 0, 0, 0, time.UTC))
+	day := func(d int) time.Time { return time.Date(2026, 2, d, 0, 0, 0, 0, l.Location) }
+
+	tests := []struct {
+		spec       string
+		start, end time.Time
+	}{
+		{"today", day(11), day(12)},
+		{"", day(11), day(12)},
+		{"yesterday", day(10), day(11)},
+		{"this-week", day(9), l.Now()},
+		{"last-week", day(2), day(9)},
+		{"2026-02-01", day(1), day(2)},
+	}
+	for _, tt := range tests {
+		start, end, err := l.DateRange(tt.spec)
+		if err != nil {
+			t.Fatalf("DateRange(%q): %v", tt.spec, err)
+		}
+		if !start.Equal(tt.start) || !end.Equal(tt.end) {
+			t.Errorf("DateRange(%q) = %v..%v, want %v..%v", tt.spec, start, end, tt.start, tt.end)
+		}
+	}
+
+	if _, _, err := l.DateRange("someday"); err == nil {
+		t.Error("expected error for unknown date")
+	}
+}
+
+func TestDateRange_SundayIsEndOfWeek(t *testing.T) {
+	l, _ := New("UTC", "", "")
+	fixNow(t, time.Date(2026, 2, 15, 12, 0, 0, 0, time.UTC)) // Sunday
+	start, _, _ := l.DateRange("this-week")
+	if want := time.Date(2026, 2, 9, 0, 0, 0, 0, time.UTC); !start.Equal(want) {
+		t.Errorf("this-week starts %v, want %v", start, want)
+	}
+}
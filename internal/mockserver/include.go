@@ -0,0 +1,105 @@
+package mockserver
+
+import "strings"
+
+// relation is a resource that can be included with another: one record
+// named by a foreign key of the record (a task's project), or the records
+// whose foreign key names it (a project's tasks).
+type relation struct {
+	resource string
+	key      string
+	many     bool
+}
+
+// relations are the includes each resource answers, by include name.
+var relations = map[string]map[string]relation{
+	"clients": {
+		"projects": {"projects", "client_id", true},
+	},
+	"projects": {
+		"client":    {"clients", "client_id", false},
+		"tasklists": {"tasklists", "project_id", true},
+		"tasks":     {"tasks", "project_id", true},
+	},
+	"tasklists": {
+		"project": {"projects", "project_id", false},
+		"tasks":   {"tasks", "tasklist_id", true},
+	},
+	"tasks": {
+		"project":  {"projects", "project_id", false},
+		"tasklist": {"tasklists", "tasklist_id", false},
+		"entries":  {"entries", "task_id", true},
+	},
+	"entries": {
+		"task":    {"tasks", "task_id", false},
+		"project": {"projects", "project_id", false},
+		"user":    {"users", "user_id", false},
+	},
+	"users": {},
+}
+
+// parseIncludes splits an include parameter, such as
+// "tasklists.tasks,client", into its paths.
+func parseIncludes(include string) []string {
+	var paths []string
+	for _, path := range strings.Split(include, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// expand returns a copy of a record with the included relations added,
+// following dotted paths into the related records. The lock must be held.
+func (s *Server) expand(resource string, rec record, includes []string) (record, error) {
+	if len(includes) == 0 {
+		return rec, nil
+	}
+	var names []string
+	nested := make(map[string][]string)
+	for _, path := range includes {
+		name, rest, _ := strings.Cut(path, ".")
+		if _, seen := nested[name]; !seen {
+			names = append(names, name)
+			nested[name] = nil
+		}
+		if rest != "" {
+			nested[name] = append(nested[name], rest)
+		}
+	}
+
+	out := copyRecord(rec)
+	for _, name := range names {
+		rel, ok := relations[resource][name]
+		if !ok {
+			return nil, badRequest("unknown include %q for %s", name, resource)
+		}
+		if !rel.many {
+			related, ok := s.data[rel.resource][intField(rec, rel.key)]
+			if !ok {
+				out[name] = nil
+				continue
+			}
+			expanded, err := s.expand(rel.resource, related, nested[name])
+			if err != nil {
+				return nil, err
+			}
+			out[name] = expanded
+			continue
+		}
+		list := []record{}
+		for _, related := range s.sorted(rel.resource) {
+			if intField(related, rel.key) != intField(rec, "id") {
+				continue
+			}
+			expanded, err := s.expand(rel.resource, related, nested[name])
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, expanded)
+		}
+		out[name] = list
+	}
+	return out, nil
+}
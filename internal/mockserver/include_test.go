@@ -0,0 +1,61 @@
+package mockserver
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestParseIncludes(t *testing.T) {
+	got := parseIncludes(" tasklists.tasks, client,,")
+	if want := []string{"tasklists.tasks", "client"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parseIncludes = %q, want %q", got, want)
+	}
+}
+
+func TestIncludes(t *testing.T) {
+	s := New()
+	client := s.Add("clients", map[string]interface{}{"name": "Acme"})
+	project := s.Add("projects", map[string]interface{}{"name": "Website", "client_id": client})
+	list := s.Add("tasklists", map[string]interface{}{"name": "Design", "project_id": project})
+	task := s.Add("tasks", map[string]interface{}{"name": "Wireframes", "project_id": project, "tasklist_id": list})
+	s.Add("tasks", map[string]interface{}{"name": "Other", "project_id": 99, "tasklist_id": 99})
+	s.Add("entries", map[string]interface{}{"task_id": task, "project_id": project, "start_time": "2026-03-02T09:00:00Z"})
+	ts := start(t, s)
+
+	_, body := call(t, ts, http.MethodGet, "projects/1?include=tasklists.tasks,client", "")
+	p := items(body, "projects")[0]
+	if c, _ := p["client"].(map[string]interface{}); c["name"] != "Acme" {
+		t.Errorf("included client = %v", p["client"])
+	}
+	lists, _ := p["tasklists"].([]interface{})
+	if len(lists) != 1 {
+		t.Fatalf("included task lists = %v", p["tasklists"])
+	}
+	tasks, _ := lists[0].(map[string]interface{})["tasks"].([]interface{})
+	if len(tasks) != 1 || tasks[0].(map[string]interface{})["name"] != "Wireframes" {
+		t.Errorf("included tasks = %v", tasks)
+	}
+
+	_, body = call(t, ts, http.MethodGet, "entries?include=task.project", "")
+	e := items(body, "entries")[0]
+	taskRec, _ := e["task"].(map[string]interface{})
+	if proj, _ := taskRec["project"].(map[string]interface{}); proj["name"] != "Website" {
+		t.Errorf("included task.project = %v", e["task"])
+	}
+
+	_, body = call(t, ts, http.MethodGet, "tasks/2?include=project", "")
+	if got := items(body, "tasks")[0]; got["project"] != nil {
+		t.Errorf("include of a missing project = %v, want null", got["project"])
+	}
+
+	// Included relations are not stored
+	_, body = call(t, ts, http.MethodGet, "projects/1", "")
+	if _, ok := items(body, "projects")[0]["client"]; ok {
+		t.Error("expected no client without include")
+	}
+
+	if resp, _ := call(t, ts, http.MethodGet, "projects?include=invoices", ""); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("unknown include = %d, want 400", resp.StatusCode)
+	}
+}
@@ -0,0 +1,290 @@
+package mockserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// route handles an authenticated request for path, relative to BasePath.
+// It returns the status and the body to answer with, nil for none. The
+// lock must be held.
+func (s *Server) route(method, path string, query url.Values, body []byte) (int, interface{}, error) {
+	parts := strings.Split(path, "/")
+	if len(parts) > 2 {
+		return 0, nil, notFound("not found")
+	}
+	resource := parts[0]
+
+	if resource == "me" && len(parts) == 1 {
+		if method != http.MethodGet {
+			return 0, nil, &httpError{http.StatusMethodNotAllowed, "method not allowed"}
+		}
+		me, ok := s.data["users"][s.me]
+		if !ok {
+			return 0, nil, notFound("no user found")
+		}
+		return s.respond(http.StatusOK, "users", []record{me}, query)
+	}
+	if _, ok := s.data[resource]; !ok {
+		return 0, nil, notFound("not found")
+	}
+
+	if len(parts) == 1 {
+		switch method {
+		case http.MethodGet:
+			return s.list(resource, query)
+		case http.MethodPost:
+			if resource == "users" {
+				break
+			}
+			fields, err := decodeBody(body)
+			if err != nil {
+				return 0, nil, err
+			}
+			rec, err := s.create(resource, fields)
+			if err != nil {
+				return 0, nil, err
+			}
+			return s.respond(http.StatusCreated, resource, []record{rec}, query)
+		}
+		return 0, nil, &httpError{http.StatusMethodNotAllowed, "method not allowed"}
+	}
+
+	id, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, nil, notFound("not found")
+	}
+	rec, ok := s.data[resource][id]
+	if !ok {
+		return 0, nil, notFound("%s %d not found", singular(resource), id)
+	}
+	switch {
+	case method == http.MethodGet:
+		return s.respond(http.StatusOK, resource, []record{rec}, query)
+	case method == http.MethodPut && resource != "users":
+		fields, err := decodeBody(body)
+		if err != nil {
+			return 0, nil, err
+		}
+		rec, err := s.update(resource, rec, fields)
+		if err != nil {
+			return 0, nil, err
+		}
+		return s.respond(http.StatusOK, resource, []record{rec}, query)
+	case method == http.MethodDelete && resource != "users":
+		delete(s.data[resource], id)
+		return http.StatusOK, nil, nil
+	}
+	return 0, nil, &httpError{http.StatusMethodNotAllowed, "method not allowed"}
+}
+
+// list answers a list request, filtered by its where clause.
+func (s *Server) list(resource string, query url.Values) (int, interface{}, error) {
+	conds, err := parseWhere(query.Get("where"))
+	if err != nil {
+		return 0, nil, err
+	}
+	var recs []record
+	for _, rec := range s.sorted(resource) {
+		if matchAll(conds, rec) {
+			recs = append(recs, rec)
+		}
+	}
+	return s.respond(http.StatusOK, resource, recs, query)
+}
+
+// respond wraps records in Paymo's envelope, {"<resource>": [...]}, with
+// the relations asked for by the include parameter.
+func (s *Server) respond(status int, resource string, recs []record, query url.Values) (int, interface{}, error) {
+	includes := parseIncludes(query.Get("include"))
+	out := make([]record, 0, len(recs))
+	for _, rec := range recs {
+		expanded, err := s.expand(resource, rec, includes)
+		if err != nil {
+			return 0, nil, err
+		}
+		out = append(out, expanded)
+	}
+	return status, map[string]interface{}{resource: out}, nil
+}
+
+// decodeBody decodes a JSON request body.
+func decodeBody(body []byte) (record, error) {
+	var fields record
+	if err := json.Unmarshal(body, &fields); err != nil || fields == nil {
+		return nil, badRequest("invalid JSON body")
+	}
+	return fields, nil
+}
+
+// create validates and stores a new record, filling in what Paymo would.
+func (s *Server) create(resource string, fields record) (record, error) {
+	delete(fields, "id")
+	delete(fields, "created_on")
+	delete(fields, "updated_on")
+
+	if resource != "entries" {
+		if name, _ := fields["name"].(string); strings.TrimSpace(name) == "" {
+			return nil, badRequest("name is required")
+		}
+	}
+	switch resource {
+	case "clients", "projects":
+		if _, ok := fields["active"]; !ok {
+			fields["active"] = true
+		}
+		if resource == "projects" && intField(fields, "client_id") != 0 {
+			if err := s.requireRecord("clients", intField(fields, "client_id")); err != nil {
+				return nil, err
+			}
+		}
+	case "tasklists":
+		if err := s.requireRecord("projects", intField(fields, "project_id")); err != nil {
+			return nil, err
+		}
+	case "tasks":
+		projectID := intField(fields, "project_id")
+		if err := s.requireRecord("projects", projectID); err != nil {
+			return nil, err
+		}
+		if listID := intField(fields, "tasklist_id"); listID != 0 {
+			list, ok := s.data["tasklists"][listID]
+			if !ok || intField(list, "project_id") != projectID {
+				return nil, badRequest("tasklist %d is not in project %d", listID, projectID)
+			}
+		} else {
+			fields["tasklist_id"] = s.defaultTaskList(projectID)
+		}
+		if _, ok := fields["complete"]; !ok {
+			fields["complete"] = false
+		}
+	case "entries":
+		task, ok := s.data["tasks"][intField(fields, "task_id")]
+		if !ok {
+			return nil, badRequest("task_id must be an existing task")
+		}
+		fields["project_id"] = intField(task, "project_id")
+		if intField(fields, "user_id") == 0 {
+			fields["user_id"] = s.me
+		}
+		if _, ok := fields["billable"]; !ok {
+			fields["billable"] = task["billable"] == true
+		}
+		if _, ok := fields["billed"]; !ok {
+			fields["billed"] = false
+		}
+		if err := settleEntry(fields, fields); err != nil {
+			return nil, err
+		}
+	}
+	id := s.insert(resource, fields)
+	return s.data[resource][id], nil
+}
+
+// update merges fields into a stored record.
+func (s *Server) update(resource string, rec record, fields record) (record, error) {
+	delete(fields, "id")
+	delete(fields, "created_on")
+	updated := copyRecord(rec)
+	for k, v := range fields {
+		updated[k] = v
+	}
+	if resource == "entries" {
+		if task, ok := s.data["tasks"][intField(updated, "task_id")]; ok {
+			updated["project_id"] = intField(task, "project_id")
+		} else {
+			return nil, badRequest("task_id must be an existing task")
+		}
+		if err := settleEntry(updated, fields); err != nil {
+			return nil, err
+		}
+	}
+	updated["updated_on"] = s.timestamp()
+	s.data[resource][intField(rec, "id")] = normalize(updated)
+	return updated, nil
+}
+
+// requireRecord fails unless the record exists.
+func (s *Server) requireRecord(resource string, id int) error {
+	if _, ok := s.data[resource][id]; !ok {
+		return badRequest("%s_id must be an existing %s", singular(resource), singular(resource))
+	}
+	return nil
+}
+
+// defaultTaskList returns the first task list of a project, creating one
+// for a project without any, as Paymo does for tasks added without a list.
+func (s *Server) defaultTaskList(projectID int) int {
+	for _, list := range s.sorted("tasklists") {
+		if intField(list, "project_id") == projectID {
+			return intField(list, "id")
+		}
+	}
+	return s.insert("tasklists", record{"name": "Tasks", "project_id": projectID, "seq": 0})
+}
+
+// settleEntry works out the times of a time entry after the changed fields
+// were applied: the end from a changed duration, else the duration from the
+// start and end. An entry given a date and a duration starts at midnight
+// UTC that day; one with a start and no end is a running timer.
+func settleEntry(entry, changed record) error {
+	start, err := timeField(entry, "start_time")
+	if err != nil {
+		return err
+	}
+	if start.IsZero() {
+		date, _ := entry["date"].(string)
+		if start, err = time.Parse("2006-01-02", date); err != nil {
+			return badRequest("start_time or date is required")
+		}
+		entry["start_time"] = start.Format(time.RFC3339)
+	}
+	end, err := timeField(entry, "end_time")
+	if err != nil {
+		return err
+	}
+	_, durationChanged := changed["duration"]
+	_, endChanged := changed["end_time"]
+	if durationChanged && !endChanged {
+		end = start.Add(time.Duration(intField(entry, "duration")) * time.Second)
+		entry["end_time"] = end.Format(time.RFC3339)
+	}
+	if end.IsZero() {
+		entry["end_time"] = nil
+		entry["duration"] = 0
+		return nil
+	}
+	if end.Before(start) {
+		return badRequest("end_time must not be before start_time")
+	}
+	entry["duration"] = int(end.Sub(start).Seconds())
+	return nil
+}
+
+// timeField parses a timestamp field; a missing or empty one is zero.
+func timeField(rec record, field string) (time.Time, error) {
+	s, _ := rec[field].(string)
+	if s == "" || s == zeroTime {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, badRequest("invalid %s %q", field, s)
+	}
+	return t.UTC(), nil
+}
+
+// singular names one record of a resource in messages.
+func singular(resource string) string {
+	switch resource {
+	case "entries":
+		return "entry"
+	case "tasklists":
+		return "tasklist"
+	}
+	return strings.TrimSuffix(resource, "s")
+}
@@ -0,0 +1,120 @@
+package mockserver
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func TestCreate_Project(t *testing.T) {
+	s := New()
+	ts := start(t, s)
+
+	resp, body := call(t, ts, http.MethodPost, "projects", `{"name":"Website","billable":true}`)
+	projects := items(body, "projects")
+	if resp.StatusCode != http.StatusCreated || len(projects) != 1 {
+		t.Fatalf("POST projects = %d %v", resp.StatusCode, body)
+	}
+	if projects[0]["id"] != float64(1) || projects[0]["active"] != true || projects[0]["created_on"] == nil {
+		t.Errorf("created project = %v", projects[0])
+	}
+
+	for _, bad := range []string{`{"billable":true}`, `{"name":"X","client_id":9}`, `not json`} {
+		if resp, _ := call(t, ts, http.MethodPost, "projects", bad); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("POST projects %s = %d, want 400", bad, resp.StatusCode)
+		}
+	}
+}
+
+func TestCreate_TaskDefaultsTaskList(t *testing.T) {
+	s := New()
+	project := s.Add("projects", map[string]interface{}{"name": "Website"})
+	other := s.Add("projects", map[string]interface{}{"name": "Other"})
+	otherList := s.Add("tasklists", map[string]interface{}{"name": "Other list", "project_id": other})
+	ts := start(t, s)
+
+	_, body := call(t, ts, http.MethodPost, "tasks", `{"name":"Design","project_id":1}`)
+	tasks := items(body, "tasks")
+	if len(tasks) != 1 || tasks[0]["complete"] != false || tasks[0]["tasklist_id"] == nil {
+		t.Fatalf("created task = %v", body)
+	}
+	var list struct {
+		Name      string `json:"name"`
+		ProjectID int    `json:"project_id"`
+	}
+	if !s.Get("tasklists", int(tasks[0]["tasklist_id"].(float64)), &list) || list.ProjectID != project {
+		t.Errorf("default task list = %+v", list)
+	}
+
+	if resp, _ := call(t, ts, http.MethodPost, "tasks", `{"name":"X","project_id":1,"tasklist_id":`+strconv.Itoa(otherList)+`}`); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("task list of another project = %d, want 400", resp.StatusCode)
+	}
+	if resp, _ := call(t, ts, http.MethodPost, "tasks", `{"name":"X","project_id":99}`); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("unknown project = %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestEntries_Lifecycle(t *testing.T) {
+	s := New()
+	me := s.Add("users", map[string]interface{}{"name": "Dana"})
+	project := s.Add("projects", map[string]interface{}{"name": "Website"})
+	task := s.Add("tasks", map[string]interface{}{"name": "Design", "project_id": project, "billable": true})
+	ts := start(t, s)
+
+	// A start time alone is a running timer
+	_, body := call(t, ts, http.MethodPost, "entries", `{"task_id":`+strconv.Itoa(task)+`,"start_time":"2026-03-02T09:00:00Z"}`)
+	entry := items(body, "entries")[0]
+	if entry["end_time"] != nil || entry["duration"] != float64(0) || entry["user_id"] != float64(me) ||
+		entry["project_id"] != float64(project) || entry["billable"] != true {
+		t.Fatalf("running entry = %v", entry)
+	}
+	id := strconv.Itoa(int(entry["id"].(float64)))
+
+	_, body = call(t, ts, http.MethodGet, `entries?where=end_time%3D%22%22`, "")
+	if len(items(body, "entries")) != 1 {
+		t.Errorf("running entries = %v", body)
+	}
+
+	_, body = call(t, ts, http.MethodPut, "entries/"+id, `{"end_time":"2026-03-02T10:30:00Z"}`)
+	if entry := items(body, "entries")[0]; entry["duration"] != float64(5400) {
+		t.Errorf("stopped entry = %v", entry)
+	}
+	_, body = call(t, ts, http.MethodPut, "entries/"+id, `{"duration":3600}`)
+	if entry := items(body, "entries")[0]; entry["end_time"] != "2026-03-02T10:00:00Z" {
+		t.Errorf("entry with a new duration = %v", entry)
+	}
+	if resp, _ := call(t, ts, http.MethodPut, "entries/"+id, `{"end_time":"2026-03-02T08:00:00Z"}`); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("end before start = %d, want 400", resp.StatusCode)
+	}
+
+	_, body = call(t, ts, http.MethodPost, "entries", `{"task_id":`+strconv.Itoa(task)+`,"date":"2026-03-03","duration":1800}`)
+	if entry := items(body, "entries")[0]; entry["start_time"] != "2026-03-03T00:00:00Z" || entry["end_time"] != "2026-03-03T00:30:00Z" {
+		t.Errorf("entry by date = %v", entry)
+	}
+	if resp, _ := call(t, ts, http.MethodPost, "entries", `{"task_id":`+strconv.Itoa(task)+`}`); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("entry without a time = %d, want 400", resp.StatusCode)
+	}
+
+	if resp, _ := call(t, ts, http.MethodDelete, "entries/"+id, ""); resp.StatusCode != http.StatusOK {
+		t.Errorf("DELETE = %d", resp.StatusCode)
+	}
+	if resp, _ := call(t, ts, http.MethodGet, "entries/"+id, ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("GET deleted entry = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestUsers_ReadOnly(t *testing.T) {
+	s := New()
+	s.Add("users", map[string]interface{}{"name": "Dana"})
+	ts := start(t, s)
+
+	if _, body := call(t, ts, http.MethodGet, "me", ""); len(items(body, "users")) != 1 {
+		t.Errorf("GET me = %v", body)
+	}
+	if resp, _ := call(t, ts, http.MethodPost, "users", `{"name":"Eve"}`); resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("POST users = %d, want 405", resp.StatusCode)
+	}
+	if resp, _ := call(t, ts, http.MethodDelete, "users/1", ""); resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("DELETE users/1 = %d, want 405", resp.StatusCode)
+	}
+}
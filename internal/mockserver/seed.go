@@ -0,0 +1,79 @@
+package mockserver
+
+import "time"
+
+// Seed fills the server with a small sample account: a user, two clients,
+// three projects with task lists and tasks, and time entries over the last
+// few days. It is what `paymo dev mock-server` starts with.
+func (s *Server) Seed() {
+	me := s.Add("users", record{
+		"name": "Dana Demo", "email": "dana@example.com", "type": "Admin",
+		"active": true, "timezone": "UTC",
+	})
+	s.Add("users", record{
+		"name": "Sam Sample", "email": "sam@example.com", "type": "Employee",
+		"active": true, "timezone": "UTC",
+	})
+	s.SetMe(me)
+
+	acme := s.Add("clients", record{"name": "Acme Corp", "email": "billing@acme.example", "city": "Springfield", "active": true})
+	globex := s.Add("clients", record{"name": "Globex", "email": "ap@globex.example", "city": "Cypress Creek", "active": true})
+
+	projects := []struct {
+		name     string
+		clientID int
+		active   bool
+		lists    map[string][]string
+	}{
+		{"Website Redesign", acme, true, map[string][]string{
+			"Design":      {"Wireframes", "Visual Design"},
+			"Development": {"Development", "Bug Fixing"},
+		}},
+		{"Mobile App", globex, true, map[string][]string{
+			"Backlog": {"Login Screen", "Push Notifications"},
+		}},
+		{"Old Intranet", acme, false, map[string][]string{
+			"Maintenance": {"Server Updates"},
+		}},
+	}
+	var taskIDs []int
+	for _, p := range projects {
+		projectID := s.Add("projects", record{
+			"name": p.name, "client_id": p.clientID, "active": p.active,
+			"billable": true, "price_per_hour": 95, "users": []int{me},
+		})
+		seq := 0
+		for _, list := range []string{"Design", "Development", "Backlog", "Maintenance"} {
+			tasks, ok := p.lists[list]
+			if !ok {
+				continue
+			}
+			seq++
+			listID := s.Add("tasklists", record{"name": list, "project_id": projectID, "seq": seq})
+			for _, name := range tasks {
+				taskIDs = append(taskIDs, s.Add("tasks", record{
+					"name": name, "project_id": projectID, "tasklist_id": listID,
+					"complete": !p.active, "billable": true, "users": []int{me},
+				}))
+			}
+		}
+	}
+
+	today := s.now().UTC().Truncate(24 * time.Hour)
+	for day := 1; day <= 3; day++ {
+		start := today.AddDate(0, 0, -day).Add(9 * time.Hour)
+		for i, taskID := range taskIDs[day-1 : day+1] {
+			begin := start.Add(time.Duration(i) * 3 * time.Hour)
+			end := begin.Add(2*time.Hour + 30*time.Minute)
+			s.mu.Lock()
+			task := s.data["tasks"][taskID]
+			s.mu.Unlock()
+			s.Add("entries", record{
+				"task_id": taskID, "project_id": task["project_id"], "user_id": me,
+				"start_time": begin.Format(time.RFC3339), "end_time": end.Format(time.RFC3339),
+				"duration": int(end.Sub(begin).Seconds()), "description": "Sample work",
+				"billable": true, "billed": false,
+			})
+		}
+	}
+}
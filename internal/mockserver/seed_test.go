@@ -0,0 +1,35 @@
+package mockserver
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestSeed(t *testing.T) {
+	s := New()
+	s.Seed()
+	ts := start(t, s)
+
+	if _, body := call(t, ts, http.MethodGet, "me", ""); items(body, "users")[0]["name"] != "Dana Demo" {
+		t.Errorf("me = %v", body)
+	}
+	for resource, want := range map[string]int{
+		"users": 2, "clients": 2, "projects": 3, "tasklists": 4, "tasks": 7, "entries": 6,
+	} {
+		if _, body := call(t, ts, http.MethodGet, resource, ""); len(items(body, resource)) != want {
+			t.Errorf("%s: got %d, want %d", resource, len(items(body, resource)), want)
+		}
+	}
+	if _, body := call(t, ts, http.MethodGet, "projects?where=active%3Dtrue", ""); len(items(body, "projects")) != 2 {
+		t.Errorf("active projects = %v", body)
+	}
+
+	// Seeded entries belong to the tasks of their project
+	_, body := call(t, ts, http.MethodGet, "entries?include=task", "")
+	for _, e := range items(body, "entries") {
+		task, _ := e["task"].(map[string]interface{})
+		if task == nil || task["project_id"] != e["project_id"] || e["end_time"] == nil {
+			t.Errorf("seeded entry = %v", e)
+		}
+	}
+}
@@ -0,0 +1,489 @@
+// Package mockserver is an in-memory fake of the Paymo API, for tests and
+// local development. It serves clients, projects, task lists, tasks, time
+// entries and users under BasePath, answers where filters and includes the
+// way Paymo does, sends rate-limit headers and fails requests on demand.
+//
+// Records are kept as decoded JSON, so the package doesn't depend on the
+// API client and its tests can use it too: seed the server with the client's
+// own model structs through Add and read them back with Get and List.
+package mockserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BasePath is the path the API is served under; point the client at the
+// server URL followed by BasePath.
+const BasePath = "/api"
+
+// controlPath is where failures are injected over HTTP, for tests that run
+// the server in another process (see handleControl).
+const controlPath = "/_mock/"
+
+// resources are the collections the server keeps, by their API path.
+var resources = []string{"clients", "projects", "tasklists", "tasks", "entries", "users"}
+
+// record is one stored resource, as decoded JSON.
+type record map[string]interface{}
+
+// Request is a request the server received.
+type Request struct {
+	Method string
+	Path   string // relative to BasePath, e.g. "projects/5"
+	Query  url.Values
+}
+
+// Failure makes the server answer matching requests with an error instead
+// of handling them.
+type Failure struct {
+	Method     string `json:"method,omitempty"`      // "" matches any method
+	Path       string `json:"path,omitempty"`        // "projects" matches projects and projects/5; "" matches any path
+	Status     int    `json:"status,omitempty"`      // HTTP status; default 500
+	Message    string `json:"message,omitempty"`     // error message in the response body
+	RetryAfter int    `json:"retry_after,omitempty"` // Retry-After header, in seconds
+	Times      int    `json:"times,omitempty"`       // how many requests fail; 0 means all of them
+	Drop       bool   `json:"drop,omitempty"`        // close the connection without answering
+}
+
+// matches reports whether the failure applies to a request.
+func (f *Failure) matches(method, path string) bool {
+	if f.Method != "" && !strings.EqualFold(f.Method, method) {
+		return false
+	}
+	return f.Path == "" || path == f.Path || strings.HasPrefix(path, f.Path+"/")
+}
+
+// Server is the fake Paymo API. It is an http.Handler; serve it with
+// httptest.NewServer or an http.Server. It is safe for concurrent use.
+type Server struct {
+	mu       sync.Mutex
+	data     map[string]map[int]record
+	nextID   map[string]int
+	me       int
+	apiKey   string
+	failures []*Failure
+	requests []Request
+
+	rateLimit   int
+	ratePeriod  time.Duration
+	windowStart time.Time
+	windowCount int
+
+	// now is the server clock, replaced in tests.
+	now func() time.Time
+}
+
+// New returns an empty server. It accepts any credentials and doesn't rate
+// limit until told to.
+func New() *Server {
+	s := &Server{
+		data:   make(map[string]map[int]record),
+		nextID: make(map[string]int),
+		now:    time.Now,
+	}
+	for _, resource := range resources {
+		s.data[resource] = make(map[int]record)
+	}
+	return s
+}
+
+// SetAPIKey makes the server accept only this API key, or the email of one
+// of its users with any password. An empty key accepts any credentials.
+func (s *Server) SetAPIKey(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.apiKey = key
+}
+
+// SetRateLimit allows limit requests per period, reported in the
+// X-Ratelimit headers; further requests get HTTP 429 until the period is
+// over. A limit of 0 turns rate limiting and its headers off.
+func (s *Server) SetRateLimit(limit int, period time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rateLimit, s.ratePeriod = limit, period
+	s.windowStart, s.windowCount = time.Time{}, 0
+}
+
+// Fail adds a failure. Failures are checked in the order they were added.
+func (s *Server) Fail(f Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, &f)
+}
+
+// ClearFailures removes all failures.
+func (s *Server) ClearFailures() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = nil
+}
+
+// maxRequests is how many of the latest requests the server keeps for
+// Requests, so a long-running server doesn't grow without bound. Defined
+// as a var to allow test injection.
+var maxRequests = 1000
+
+// Requests returns the latest requests received (up to 1000), oldest
+// first.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	reqs := s.requests
+	if len(reqs) > maxRequests {
+		reqs = reqs[len(reqs)-maxRequests:]
+	}
+	return append([]Request(nil), reqs...)
+}
+
+// logRequest adds a request to the log, dropping the oldest once it holds
+// twice maxRequests.
+func (s *Server) logRequest(req Request) {
+	if len(s.requests) >= 2*maxRequests {
+		s.requests = append(s.requests[:0], s.requests[len(s.requests)-maxRequests:]...)
+	}
+	s.requests = append(s.requests, req)
+}
+
+// SetMe makes the user with this ID the authenticated user answering
+// /me. The first user added is the default.
+func (s *Server) SetMe(id int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.me = id
+}
+
+// Add stores a record in a resource collection and returns its ID. v is
+// anything that marshals to a JSON object, usually an API model struct; a
+// zero ID is assigned the next free one, and zero timestamps are dropped,
+// except created_on and updated_on, which default to now. Add panics on an
+// unknown resource or a value that isn't an object, like http.Handle does
+// on misuse.
+func (s *Server) Add(resource string, v interface{}) int {
+	rec, err := toRecord(v)
+	if err != nil {
+		panic(fmt.Sprintf("mockserver: adding to %s: %v", resource, err))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.data[resource]; !ok {
+		panic(fmt.Sprintf("mockserver: unknown resource %q", resource))
+	}
+	return s.insert(resource, rec)
+}
+
+// Get decodes the record with the given ID into dest. It reports false if
+// there is no such record.
+func (s *Server) Get(resource string, id int, dest interface{}) bool {
+	s.mu.Lock()
+	rec, ok := s.data[resource][id]
+	var raw []byte
+	if ok {
+		raw, _ = json.Marshal(rec)
+	}
+	s.mu.Unlock()
+	return ok && json.Unmarshal(raw, dest) == nil
+}
+
+// List decodes all records of a resource, by ID, into dest, a pointer to a
+// slice.
+func (s *Server) List(resource string, dest interface{}) error {
+	s.mu.Lock()
+	raw, err := json.Marshal(s.sorted(resource))
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, dest)
+}
+
+// insert stores a record, assigning an ID and timestamps. The lock must be
+// held.
+func (s *Server) insert(resource string, rec record) int {
+	rec = normalize(rec)
+	id := intField(rec, "id")
+	if id <= 0 {
+		s.nextID[resource]++
+		id = s.nextID[resource]
+	} else if id > s.nextID[resource] {
+		s.nextID[resource] = id
+	}
+	rec["id"] = id
+	stamp := s.timestamp()
+	for _, field := range []string{"created_on", "updated_on"} {
+		if rec[field] == nil {
+			rec[field] = stamp
+		}
+	}
+	if resource == "users" && s.me == 0 {
+		s.me = id
+	}
+	s.data[resource][id] = rec
+	return id
+}
+
+// sorted returns the records of a resource by ID. The lock must be held.
+func (s *Server) sorted(resource string) []record {
+	recs := make([]record, 0, len(s.data[resource]))
+	for _, rec := range s.data[resource] {
+		recs = append(recs, rec)
+	}
+	sort.Slice(recs, func(i, j int) bool { return intField(recs[i], "id") < intField(recs[j], "id") })
+	return recs
+}
+
+// timestamp returns the current time the way Paymo formats it.
+func (s *Server) timestamp() string {
+	return s.now().UTC().Format(time.RFC3339)
+}
+
+// ServeHTTP answers an API request.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if strings.HasPrefix(r.URL.Path, controlPath) {
+		s.handleControl(w, r)
+		return
+	}
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, BasePath), "/")
+	if !strings.HasPrefix(r.URL.Path, BasePath+"/") {
+		writeError(w, &httpError{http.StatusNotFound, "not found"})
+		return
+	}
+	s.logRequest(Request{Method: r.Method, Path: path, Query: r.URL.Query()})
+
+	if f := s.failure(r.Method, path); f != nil {
+		if f.Drop {
+			dropConnection(w)
+			return
+		}
+		if f.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(f.RetryAfter))
+		}
+		status := f.Status
+		if status == 0 {
+			status = http.StatusInternalServerError
+		}
+		message := f.Message
+		if message == "" {
+			message = http.StatusText(status)
+		}
+		writeError(w, &httpError{status, message})
+		return
+	}
+	if err := s.authenticate(r); err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := s.takeRequest(w.Header()); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, &httpError{http.StatusBadRequest, "reading request body"})
+		return
+	}
+	status, resp, err := s.route(r.Method, path, r.URL.Query(), body)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if resp == nil {
+		w.WriteHeader(status)
+		return
+	}
+	writeJSON(w, status, resp)
+}
+
+// failure returns the first failure matching a request, using one of its
+// times up. The lock must be held.
+func (s *Server) failure(method, path string) *Failure {
+	for i, f := range s.failures {
+		if !f.matches(method, path) {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.failures = append(s.failures[:i], s.failures[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+// dropConnection closes the connection without writing a response.
+func dropConnection(w http.ResponseWriter) {
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		panic(http.ErrAbortHandler)
+	}
+	conn, _, err := hj.Hijack()
+	if err != nil {
+		panic(http.ErrAbortHandler)
+	}
+	conn.Close()
+}
+
+// authenticate checks the request's basic auth credentials. The lock must
+// be held.
+func (s *Server) authenticate(r *http.Request) error {
+	user, _, ok := r.BasicAuth()
+	if !ok {
+		return &httpError{http.StatusUnauthorized, "authentication required"}
+	}
+	if s.apiKey == "" || user == s.apiKey {
+		return nil
+	}
+	for _, u := range s.data["users"] {
+		if email, _ := u["email"].(string); email != "" && strings.EqualFold(email, user) {
+			return nil
+		}
+	}
+	return &httpError{http.StatusUnauthorized, "invalid credentials"}
+}
+
+// takeRequest counts a request against the rate limit and sets the rate
+// limit headers. The lock must be held.
+func (s *Server) takeRequest(h http.Header) error {
+	if s.rateLimit <= 0 {
+		return nil
+	}
+	now := s.now()
+	if now.Sub(s.windowStart) >= s.ratePeriod {
+		s.windowStart, s.windowCount = now, 0
+	}
+	reset := strconv.Itoa(int(math.Ceil(s.windowStart.Add(s.ratePeriod).Sub(now).Seconds())))
+	h.Set("X-Ratelimit-Limit", strconv.Itoa(s.rateLimit))
+	h.Set("X-Ratelimit-Decay-Period", reset)
+	if s.windowCount >= s.rateLimit {
+		h.Set("X-Ratelimit-Remaining", "0")
+		h.Set("Retry-After", reset)
+		return &httpError{http.StatusTooManyRequests, "rate limit exceeded"}
+	}
+	s.windowCount++
+	h.Set("X-Ratelimit-Remaining", strconv.Itoa(s.rateLimit-s.windowCount))
+	return nil
+}
+
+// handleControl lets a test in another process inject failures: POST a
+// Failure as JSON to /_mock/failures to add it, DELETE to clear them all.
+// The lock must be held.
+func (s *Server) handleControl(w http.ResponseWriter, r *http.Request) {
+	if strings.TrimPrefix(r.URL.Path, controlPath) != "failures" {
+		writeError(w, &httpError{http.StatusNotFound, "not found"})
+		return
+	}
+	switch r.Method {
+	case http.MethodPost:
+		var f Failure
+		if err := json.NewDecoder(r.Body).Decode(&f); err != nil {
+			writeError(w, &httpError{http.StatusBadRequest, "invalid failure: " + err.Error()})
+			return
+		}
+		s.failures = append(s.failures, &f)
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodDelete:
+		s.failures = nil
+		w.WriteHeader(http.StatusOK)
+	default:
+		writeError(w, &httpError{http.StatusMethodNotAllowed, "method not allowed"})
+	}
+}
+
+// httpError is an error answered with its status and message.
+type httpError struct {
+	status  int
+	message string
+}
+
+func (e *httpError) Error() string { return e.message }
+
+func badRequest(format string, args ...interface{}) error {
+	return &httpError{http.StatusBadRequest, fmt.Sprintf(format, args...)}
+}
+
+func notFound(format string, args ...interface{}) error {
+	return &httpError{http.StatusNotFound, fmt.Sprintf(format, args...)}
+}
+
+// writeError answers with an error in Paymo's format.
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	if he, ok := err.(*httpError); ok {
+		status = he.status
+	}
+	writeJSON(w, status, map[string]string{"message": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// toRecord converts a value to a record through JSON.
+func toRecord(v interface{}) (record, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var rec record
+	if err := json.Unmarshal(raw, &rec); err != nil {
+		return nil, fmt.Errorf("not a JSON object: %w", err)
+	}
+	if rec == nil {
+		return nil, fmt.Errorf("not a JSON object")
+	}
+	return rec, nil
+}
+
+// zeroTime is how Go marshals an unset time.Time.
+const zeroTime = "0001-01-01T00:00:00Z"
+
+// normalize drops the zero timestamps of Go structs, which Paymo sends as
+// null, and stores IDs as ints.
+func normalize(rec record) record {
+	for field, v := range rec {
+		if v == zeroTime {
+			rec[field] = nil
+		}
+	}
+	rec["id"] = intField(rec, "id")
+	return rec
+}
+
+// intField returns a numeric field as an int, or 0.
+func intField(rec record, field string) int {
+	switch v := rec[field].(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	}
+	return 0
+}
+
+// copyRecord returns a shallow copy of a record, for responses that add
+// included relations.
+func copyRecord(rec record) record {
+	c := make(record, len(rec))
+	for k, v := range rec {
+		c[k] = v
+	}
+	return c
+}
@@ -0,0 +1,257 @@
+package mockserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// start serves s for the duration of the test.
+func start(t *testing.T, s *Server) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// call makes an authenticated request and decodes the JSON response.
+func call(t *testing.T, ts *httptest.Server, method, path, body string) (*http.Response, map[string]interface{}) {
+	t.Helper()
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req, err := http.NewRequest(method, ts.URL+BasePath+"/"+path, reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.SetBasicAuth("key", "x")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	var decoded map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&decoded)
+	return resp, decoded
+}
+
+// items returns the records of a response envelope.
+func items(resp map[string]interface{}, resource string) []map[string]interface{} {
+	var out []map[string]interface{}
+	list, _ := resp[resource].([]interface{})
+	for _, item := range list {
+		out = append(out, item.(map[string]interface{}))
+	}
+	return out
+}
+
+type project struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	ClientID  int       `json:"client_id,omitempty"`
+	Active    bool      `json:"active"`
+	CreatedOn time.Time `json:"created_on"`
+}
+
+func TestAddGetList(t *testing.T) {
+	s := New()
+	first := s.Add("projects", project{Name: "Alpha", Active: true})
+	second := s.Add("projects", project{ID: 10, Name: "Beta"})
+	third := s.Add("projects", project{Name: "Gamma"})
+	if first != 1 || second != 10 || third != 11 {
+		t.Errorf("IDs = %d, %d, %d; want 1, 10, 11", first, second, third)
+	}
+
+	var got project
+	if !s.Get("projects", first, &got) || got.Name != "Alpha" || got.CreatedOn.IsZero() {
+		t.Errorf("Get = %+v", got)
+	}
+	if s.Get("projects", 99, &got) {
+		t.Error("expected Get of a missing record to report false")
+	}
+	var all []project
+	if err := s.List("projects", &all); err != nil || len(all) != 3 || all[2].Name != "Gamma" {
+		t.Errorf("List = %+v, %v", all, err)
+	}
+}
+
+func TestAdd_UnknownResource(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected Add to panic on an unknown resource")
+		}
+	}()
+	New().Add("invoices", project{Name: "x"})
+}
+
+func TestServe_NotFound(t *testing.T) {
+	ts := start(t, New())
+	for _, path := range []string{"invoices", "projects/1", "projects/x", "projects/1/tasks"} {
+		if resp, body := call(t, ts, http.MethodGet, path, ""); resp.StatusCode != http.StatusNotFound || body["message"] == nil {
+			t.Errorf("GET %s = %d %v, want 404 with a message", path, resp.StatusCode, body)
+		}
+	}
+}
+
+func TestServe_Auth(t *testing.T) {
+	s := New()
+	s.Add("users", map[string]interface{}{"name": "Dana", "email": "dana@example.com"})
+	ts := start(t, s)
+
+	resp, err := http.Get(ts.URL + BasePath + "/me")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("unauthenticated request = %d, want 401", resp.StatusCode)
+	}
+
+	if resp, _ := call(t, ts, http.MethodGet, "me", ""); resp.StatusCode != http.StatusOK {
+		t.Errorf("any key = %d, want 200 without an API key set", resp.StatusCode)
+	}
+	s.SetAPIKey("secret")
+	if resp, _ := call(t, ts, http.MethodGet, "me", ""); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("wrong key = %d, want 401", resp.StatusCode)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+BasePath+"/me", nil)
+	req.SetBasicAuth("dana@example.com", "password")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("user email = %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestServe_RateLimit(t *testing.T) {
+	s := New()
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	s.SetRateLimit(2, time.Minute)
+	ts := start(t, s)
+
+	resp, _ := call(t, ts, http.MethodGet, "projects", "")
+	if resp.Header.Get("X-Ratelimit-Limit") != "2" || resp.Header.Get("X-Ratelimit-Remaining") != "1" ||
+		resp.Header.Get("X-Ratelimit-Decay-Period") != "60" {
+		t.Errorf("headers = %v", resp.Header)
+	}
+	now = now.Add(20 * time.Second)
+	call(t, ts, http.MethodGet, "projects", "")
+	resp, _ = call(t, ts, http.MethodGet, "projects", "")
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") != "40" {
+		t.Errorf("third request = %d, Retry-After %q; want 429 after 40s", resp.StatusCode, resp.Header.Get("Retry-After"))
+	}
+
+	now = now.Add(40 * time.Second)
+	if resp, _ := call(t, ts, http.MethodGet, "projects", ""); resp.StatusCode != http.StatusOK {
+		t.Errorf("after the period = %d, want 200", resp.StatusCode)
+	}
+
+	s.SetRateLimit(0, 0)
+	if resp, _ := call(t, ts, http.MethodGet, "projects", ""); resp.Header.Get("X-Ratelimit-Limit") != "" {
+		t.Error("expected no rate limit headers with rate limiting off")
+	}
+}
+
+func TestServe_Failures(t *testing.T) {
+	s := New()
+	ts := start(t, s)
+
+	s.Fail(Failure{Method: "GET", Path: "projects", Status: 503, Times: 1})
+	if resp, body := call(t, ts, http.MethodGet, "projects/1", ""); resp.StatusCode != 503 || body["message"] != "Service Unavailable" {
+		t.Errorf("injected failure = %d %v", resp.StatusCode, body)
+	}
+	if resp, _ := call(t, ts, http.MethodGet, "projects", ""); resp.StatusCode != http.StatusOK {
+		t.Errorf("after the failure was used up = %d, want 200", resp.StatusCode)
+	}
+
+	s.Fail(Failure{Path: "tasks", Status: 429, RetryAfter: 7, Message: "slow down"})
+	for i := 0; i < 2; i++ {
+		resp, body := call(t, ts, http.MethodGet, "tasks", "")
+		if resp.StatusCode != 429 || resp.Header.Get("Retry-After") != "7" || body["message"] != "slow down" {
+			t.Errorf("request %d = %d %v", i, resp.StatusCode, body)
+		}
+	}
+	if resp, _ := call(t, ts, http.MethodGet, "taskLists", ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected the failure not to match other paths, got %d", resp.StatusCode)
+	}
+	s.ClearFailures()
+	if resp, _ := call(t, ts, http.MethodGet, "tasks", ""); resp.StatusCode != http.StatusOK {
+		t.Errorf("after ClearFailures = %d, want 200", resp.StatusCode)
+	}
+
+	// net/http resends a GET once on a dropped keep-alive connection
+	s.Fail(Failure{Drop: true})
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+BasePath+"/projects", nil)
+	req.SetBasicAuth("key", "x")
+	if _, err := http.DefaultClient.Do(req); err == nil || !errors.Is(err, io.EOF) {
+		t.Errorf("dropped connection = %v, want EOF", err)
+	}
+}
+
+func TestServe_ControlFailures(t *testing.T) {
+	s := New()
+	ts := start(t, s)
+
+	resp, err := http.Post(ts.URL+"/_mock/failures", "application/json", strings.NewReader(`{"path":"me","status":502}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("POST /_mock/failures = %d", resp.StatusCode)
+	}
+	if resp, _ := call(t, ts, http.MethodGet, "me", ""); resp.StatusCode != 502 {
+		t.Errorf("GET me = %d, want the injected 502", resp.StatusCode)
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, ts.URL+"/_mock/failures", nil)
+	if resp, err := http.DefaultClient.Do(req); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("DELETE /_mock/failures = %v, %v", resp, err)
+	}
+	if resp, _ := call(t, ts, http.MethodGet, "me", ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("GET me = %d, want 404 with no users", resp.StatusCode)
+	}
+}
+
+func TestRequests(t *testing.T) {
+	s := New()
+	ts := start(t, s)
+	call(t, ts, http.MethodGet, "projects?where=active%3Dtrue", "")
+
+	reqs := s.Requests()
+	if len(reqs) != 1 || reqs[0].Method != "GET" || reqs[0].Path != "projects" || reqs[0].Query.Get("where") != "active=true" {
+		t.Errorf("Requests = %+v", reqs)
+	}
+}
+
+func TestRequests_Capped(t *testing.T) {
+	orig := maxRequests
+	defer func() { maxRequests = orig }()
+	maxRequests = 3
+
+	s := New()
+	ts := start(t, s)
+	for i := 1; i <= 10; i++ {
+		call(t, ts, http.MethodGet, fmt.Sprintf("projects/%d", i), "")
+		if n := len(s.requests); n > 2*maxRequests {
+			t.Fatalf("expected the log to stay bounded, got %d requests", n)
+		}
+	}
+
+	reqs := s.Requests()
+	if len(reqs) != 3 || reqs[0].Path != "projects/8" || reqs[2].Path != "projects/10" {
+		t.Errorf("expected the latest 3 requests, got %+v", reqs)
+	}
+}
@@ -0,0 +1,244 @@
+package mockserver
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// condition is one comparison of a where clause, such as project_id=5,
+// name like "%site%" or users in (1,2).
+type condition struct {
+	field   string
+	op      string // =, !=, <, <=, >, >=, like, not like, in, not in
+	values  []interface{}
+	pattern *regexp.Regexp // for like
+}
+
+var conditionPattern = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\s*(>=|<=|!=|=|>|<|(?i:\s+not\s+like\s+|\s+like\s+|\s+not\s+in\s+|\s+in\s+))\s*(.*)$`)
+
+// parseWhere parses a where clause: comparisons joined by "and". An empty
+// clause matches everything.
+func parseWhere(where string) ([]condition, error) {
+	var conds []condition
+	for _, part := range splitAnd(where) {
+		m := conditionPattern.FindStringSubmatch(part)
+		if m == nil {
+			return nil, badRequest("invalid where condition %q", part)
+		}
+		c := condition{field: m[1], op: strings.ToLower(strings.Join(strings.Fields(m[2]), " "))}
+		raw := strings.TrimSpace(m[3])
+		if c.op == "in" || c.op == "not in" {
+			if !strings.HasPrefix(raw, "(") || !strings.HasSuffix(raw, ")") {
+				return nil, badRequest("invalid list in where condition %q", part)
+			}
+			for _, item := range strings.Split(raw[1:len(raw)-1], ",") {
+				v, err := parseValue(strings.TrimSpace(item))
+				if err != nil {
+					return nil, badRequest("invalid value in where condition %q", part)
+				}
+				c.values = append(c.values, v)
+			}
+		} else {
+			v, err := parseValue(raw)
+			if err != nil {
+				return nil, badRequest("invalid value in where condition %q", part)
+			}
+			c.values = []interface{}{v}
+		}
+		if c.op == "like" || c.op == "not like" {
+			pattern, ok := c.values[0].(string)
+			if !ok {
+				return nil, badRequest("like needs a string in where condition %q", part)
+			}
+			c.pattern = likePattern(pattern)
+		}
+		conds = append(conds, c)
+	}
+	return conds, nil
+}
+
+// splitAnd splits a where clause on "and" outside quotes and parentheses.
+func splitAnd(where string) []string {
+	var parts []string
+	var quote byte
+	depth, start := 0, 0
+	lower := strings.ToLower(where)
+	for i := 0; i < len(where); i++ {
+		switch c := where[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case depth == 0 && strings.HasPrefix(lower[i:], " and "):
+			parts = append(parts, where[start:i])
+			start = i + len(" and ")
+			i = start - 1
+		}
+	}
+	parts = append(parts, where[start:])
+
+	var out []string
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// parseValue parses a quoted string, true, false or a number.
+func parseValue(raw string) (interface{}, error) {
+	if len(raw) >= 2 && (raw[0] == '"' || raw[0] == '\'') && raw[len(raw)-1] == raw[0] {
+		return raw[1 : len(raw)-1], nil
+	}
+	switch strings.ToLower(raw) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	return strconv.ParseFloat(raw, 64)
+}
+
+// likePattern turns a like pattern, with % as the wildcard, into a
+// case-insensitive regular expression.
+func likePattern(pattern string) *regexp.Regexp {
+	parts := strings.Split(pattern, "%")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.MustCompile("(?is)^" + strings.Join(parts, ".*") + "$")
+}
+
+// matchAll reports whether a record meets every condition.
+func matchAll(conds []condition, rec record) bool {
+	for _, c := range conds {
+		if !c.match(rec) {
+			return false
+		}
+	}
+	return true
+}
+
+// match reports whether a record meets the condition. A condition on a
+// list field, such as a project's users, holds if it holds for an element.
+func (c condition) match(rec record) bool {
+	v := rec[c.field]
+	switch c.op {
+	case "in", "not in":
+		found := false
+		for _, want := range c.values {
+			found = found || equal(v, want)
+		}
+		return found == (c.op == "in")
+	case "like", "not like":
+		s, _ := v.(string)
+		return c.pattern.MatchString(s) == (c.op == "like")
+	case "=":
+		return equal(v, c.values[0])
+	case "!=":
+		return !equal(v, c.values[0])
+	}
+	cmp, ok := compare(v, c.values[0])
+	if !ok {
+		return false
+	}
+	switch c.op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	default:
+		return cmp >= 0
+	}
+}
+
+// equal reports whether a field value equals want. null equals "".
+func equal(v, want interface{}) bool {
+	if list, ok := v.([]interface{}); ok {
+		for _, item := range list {
+			if equal(item, want) {
+				return true
+			}
+		}
+		return false
+	}
+	if v == nil {
+		return want == ""
+	}
+	cmp, ok := compare(v, want)
+	return ok && cmp == 0
+}
+
+// compare orders a field value against a value of a where clause: numbers
+// by value, timestamps by time, other strings by text. It reports false if
+// the two can't be compared.
+func compare(v, want interface{}) (int, bool) {
+	switch want := want.(type) {
+	case bool:
+		b, ok := v.(bool)
+		if !ok {
+			return 0, false
+		}
+		if b == want {
+			return 0, true
+		}
+		if want {
+			return -1, true
+		}
+		return 1, true
+	case float64:
+		n, ok := number(v)
+		if !ok {
+			return 0, false
+		}
+		return compareFloats(n, want), true
+	case string:
+		s, ok := v.(string)
+		if !ok {
+			if n, isNumber := number(v); isNumber {
+				if w, err := strconv.ParseFloat(want, 64); err == nil {
+					return compareFloats(n, w), true
+				}
+			}
+			return 0, false
+		}
+		if t, err := time.Parse(time.RFC3339, s); err == nil {
+			if w, err := time.Parse(time.RFC3339, want); err == nil {
+				return t.Compare(w), true
+			}
+		}
+		return strings.Compare(s, want), true
+	}
+	return 0, false
+}
+
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,79 @@
+package mockserver
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitAnd(t *testing.T) {
+	got := splitAnd(`project_id=1 AND name like "Rock and Roll" and users in (1, 2)`)
+	want := []string{"project_id=1", `name like "Rock and Roll"`, "users in (1, 2)"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("splitAnd = %q, want %q", got, want)
+	}
+	if got := splitAnd(""); got != nil {
+		t.Errorf("splitAnd(\"\") = %q, want none", got)
+	}
+}
+
+func TestParseWhere_Invalid(t *testing.T) {
+	for _, where := range []string{
+		"project_id",
+		"project_id=abc",
+		"users in 1,2",
+		"name like 5",
+		`name like "unterminated`,
+	} {
+		if _, err := parseWhere(where); err == nil {
+			t.Errorf("parseWhere(%q) succeeded, want an error", where)
+		}
+	}
+}
+
+func TestWhere_Match(t *testing.T) {
+	rec := record{
+		"id":         7,
+		"name":       "Website Redesign",
+		"client_id":  float64(3),
+		"active":     true,
+		"users":      []interface{}{float64(1), float64(4)},
+		"end_time":   nil,
+		"start_time": "2026-03-02T09:00:00Z",
+		"updated_on": "2026-03-02T10:00:00+01:00",
+	}
+	for where, want := range map[string]bool{
+		"":                                   true,
+		"id=7":                               true,
+		"client_id=3 and active=true":        true,
+		"client_id=3 and active=false":       false,
+		"client_id!=3":                       false,
+		`name like "%redesign%"`:             true,
+		`name like "web%"`:                   true,
+		`name like "%web"`:                   false,
+		`name not like "%site%"`:             false,
+		"users in (4)":                       true,
+		"users in (2,3)":                     false,
+		"users=1":                            true,
+		"id in (5, 6, 7)":                    true,
+		"id not in (7)":                      false,
+		`end_time=""`:                        true,
+		`start_time=""`:                      false,
+		`start_time>="2026-03-02T00:00:00Z"`: true,
+		`start_time<"2026-03-02T09:00:00Z"`:  false,
+		`start_time<="2026-03-02T09:00:00Z"`: true,
+		`updated_on>="2026-03-02T09:30:00Z"`: false,
+		`updated_on>="2026-03-02T09:00:00Z"`: true,
+		"id>5 and id<10":                     true,
+		"missing_field=1":                    false,
+		`client_id="3"`:                      true,
+	} {
+		conds, err := parseWhere(where)
+		if err != nil {
+			t.Errorf("parseWhere(%q): %v", where, err)
+			continue
+		}
+		if got := matchAll(conds, rec); got != want {
+			t.Errorf("%q matched = %v, want %v", where, got, want)
+		}
+	}
+}
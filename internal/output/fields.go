@@ -0,0 +1,228 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/ComputClaw/paymo-cli/internal/api"
+	"github.com/ComputClaw/paymo-cli/internal/locale"
+)
+
+// Resource definitions for the list and show commands. Field names double as
+// CSV/TSV headers, so they must stay stable.
+
+func entry(v interface{}) *api.TimeEntry { return v.(*api.TimeEntry) }
+func project(v interface{}) *api.Project { return v.(*api.Project) }
+func task(v interface{}) *api.Task       { return v.(*api.Task) }
+func client(v interface{}) *api.PaymoClient {
+	return v.(*api.PaymoClient)
+}
+
+// yesNo formats a boolean for tables.
+func yesNo(b bool) string {
+	if b {
+		return "Yes"
+	}
+	return "No"
+}
+
+// EntryResource renders time entries.
+var EntryResource = &Resource{
+	Name: "entries",
+	Fields: []Field{
+		{Name: "id", Header: "ID", Kind: KindInt, Width: 6, Text: func(v interface{}) string { return strconv.Itoa(entry(v).ID) }},
+		{Name: "project_id", Header: "Project ID", Kind: KindInt, Width: 10, Text: func(v interface{}) string { return strconv.Itoa(entryProjectID(entry(v))) }},
+		{Name: "project_name", Aliases: []string{"project"}, Header: "Project", Width: 20,
+			Text: func(v interface{}) string {
+				if e := entry(v); e.Project != nil {
+					return e.Project.Name
+				}
+				return "Unknown"
+			},
+			Raw: func(v interface{}) string {
+				if e := entry(v); e.Project != nil {
+					return e.Project.Name
+				}
+				return ""
+			},
+		},
+		{Name: "task_id", Header: "Task ID", Kind: KindInt, Width: 8, Text: func(v interface{}) string { return strconv.Itoa(entry(v).TaskID) }},
+		{Name: "task_name", Aliases: []string{"task"}, Header: "Task", Width: 20,
+			Text: func(v interface{}) string {
+				if e := entry(v); e.Task != nil {
+					return e.Task.Name
+				}
+				return "Unknown"
+			},
+			Raw: func(v interface{}) string {
+				if e := entry(v); e.Task != nil {
+					return e.Task.Name
+				}
+				return ""
+			},
+		},
+		{Name: "duration", Header: "Duration", Kind: KindDuration, Width: 10,
+			Text:  func(v interface{}) string { return formatDuration(entry(v).Duration) },
+			Raw:   func(v interface{}) string { return strconv.Itoa(entry(v).Duration) },
+			Total: func(items []interface{}) string { return formatDuration(totalDuration(items)) },
+		},
+		{Name: "date", Aliases: []string{"day"}, Header: "Date", Width: 10, Date: true, Time: func(v interface{}) time.Time { return entry(v).StartTime }},
+		{Name: "description", Header: "Description", Width: 30, Wrap: true, Text: func(v interface{}) string { return entry(v).Description }},
+		{Name: "start", Header: "Start", Width: 16, Time: func(v interface{}) time.Time { return entry(v).StartTime }},
+		{Name: "end", Header: "End", Width: 16, Time: func(v interface{}) time.Time { return entry(v).EndTime }},
+		{Name: "billable", Header: "Billable", Kind: KindBool, Width: 8,
+			Text: func(v interface{}) string { return yesNo(entry(v).Billable) },
+			Raw:  func(v interface{}) string { return strconv.FormatBool(entry(v).Billable) },
+		},
+		{Name: "billed", Header: "Billed", Kind: KindBool, Width: 8,
+			Text: func(v interface{}) string { return yesNo(entry(v).Billed) },
+			Raw:  func(v interface{}) string { return strconv.FormatBool(entry(v).Billed) },
+		},
+		{Name: "user_id", Header: "User ID", Kind: KindInt, Width: 8, Text: func(v interface{}) string { return strconv.Itoa(entry(v).UserID) }},
+	},
+	TableColumns: []string{"id", "project_name", "task_name", "duration", "date", "description"},
+	CSVColumns:   []string{"id", "project_id", "project_name", "task_id", "task_name", "duration", "date", "description"},
+	Empty:        "No time entries found.",
+	Footer: func(items []interface{}) string {
+		return fmt.Sprintf("Total: %s (%d entries)", formatDuration(totalDuration(items)), len(items))
+	},
+	Detail:   func(w io.Writer, v interface{}, l *locale.Locale) error { return formatEntryDetail(w, entry(v), l) },
+	Duration: func(v interface{}) int { return entry(v).Duration },
+}
+
+// totalDuration sums the durations of time entries.
+func totalDuration(items []interface{}) int {
+	total := 0
+	for _, item := range items {
+		total += entry(item).Duration
+	}
+	return total
+}
+
+// entryProjectID returns the project of an entry, falling back to its task's.
+func entryProjectID(e *api.TimeEntry) int {
+	if e.Project != nil {
+		return e.Project.ID
+	}
+	if e.Task != nil {
+		return e.Task.ProjectID
+	}
+	return 0
+}
+
+// ProjectResource renders projects.
+var ProjectResource = &Resource{
+	Name: "projects",
+	Fields: []Field{
+		{Name: "id", Header: "ID", Kind: KindInt, Width: 8, Text: func(v interface{}) string { return strconv.Itoa(project(v).ID) }},
+		{Name: "name", Header: "Name", Width: 30, Text: func(v interface{}) string { return project(v).Name }},
+		{Name: "code", Header: "Code", Width: 10, Text: func(v interface{}) string { return project(v).Code }},
+		{Name: "active", Header: "Status", Kind: KindBool, Width: 8,
+			Text: func(v interface{}) string {
+				if project(v).Active {
+					return "Active"
+				}
+				return "Inactive"
+			},
+			Raw: func(v interface{}) string { return strconv.FormatBool(project(v).Active) },
+		},
+		{Name: "billable", Header: "Billable", Kind: KindBool, Width: 8,
+			Text: func(v interface{}) string { return yesNo(project(v).Billable) },
+			Raw:  func(v interface{}) string { return strconv.FormatBool(project(v).Billable) },
+		},
+		{Name: "client_id", Aliases: []string{"client"}, Header: "Client ID", Kind: KindInt, Width: 10, Text: func(v interface{}) string { return strconv.Itoa(project(v).ClientID) }},
+		{Name: "description", Header: "Description", Width: 30, Wrap: true, Text: func(v interface{}) string { return project(v).Description }},
+		{Name: "budget_hours", Header: "Budget", Kind: KindFloat, Width: 8, Text: func(v interface{}) string { return strconv.FormatFloat(project(v).BudgetHours, 'f', -1, 64) }},
+		{Name: "price_per_hour", Header: "Rate", Kind: KindFloat, Width: 8, Text: func(v interface{}) string { return strconv.FormatFloat(project(v).PricePerHour, 'f', 2, 64) }},
+		{Name: "created", Header: "Created", Width: 10, Date: true, Time: func(v interface{}) time.Time { return project(v).CreatedOn }},
+	},
+	TableColumns: []string{"id", "name", "code", "active", "billable"},
+	CSVColumns:   []string{"id", "name", "code", "active", "billable", "client_id"},
+	Empty:        "No projects found.",
+	Footer: func(items []interface{}) string {
+		return fmt.Sprintf("Total: %d projects", len(items))
+	},
+	Detail: func(w io.Writer, v interface{}, l *locale.Locale) error { return formatProjectDetail(w, project(v), l) },
+}
+
+// TaskResource renders tasks.
+var TaskResource = &Resource{
+	Name: "tasks",
+	Fields: []Field{
+		{Name: "id", Header: "ID", Kind: KindInt, Width: 8, Text: func(v interface{}) string { return strconv.Itoa(task(v).ID) }},
+		{Name: "name", Header: "Name", Width: 35, Text: func(v interface{}) string { return task(v).Name }},
+		{Name: "code", Header: "Code", Width: 10, Text: func(v interface{}) string { return task(v).Code }},
+		{Name: "project_id", Aliases: []string{"project"}, Header: "Project", Kind: KindInt, Width: 20, Text: func(v interface{}) string { return strconv.Itoa(task(v).ProjectID) }},
+		{Name: "tasklist_id", Aliases: []string{"tasklist"}, Header: "Task List", Kind: KindInt, Width: 10, Text: func(v interface{}) string { return strconv.Itoa(task(v).TaskListID) }},
+		{Name: "complete", Header: "Status", Kind: KindBool, Width: 10,
+			Text: func(v interface{}) string {
+				if task(v).Complete {
+					return "Complete"
+				}
+				return "Open"
+			},
+			Raw: func(v interface{}) string { return strconv.FormatBool(task(v).Complete) },
+		},
+		{Name: "billable", Header: "Billable", Kind: KindBool, Width: 8,
+			Text: func(v interface{}) string { return yesNo(task(v).Billable) },
+			Raw:  func(v interface{}) string { return strconv.FormatBool(task(v).Billable) },
+		},
+		{Name: "due_date", Header: "Due Date", Width: 12,
+			Text: func(v interface{}) string {
+				if d := task(v).DueDate; d != "" {
+					return d
+				}
+				return "-"
+			},
+			Raw: func(v interface{}) string { return task(v).DueDate },
+		},
+		{Name: "priority", Header: "Priority", Kind: KindInt, Width: 8, Text: func(v interface{}) string { return strconv.Itoa(task(v).Priority) }},
+		{Name: "description", Header: "Description", Width: 30, Wrap: true, Text: func(v interface{}) string { return task(v).Description }},
+	},
+	TableColumns: []string{"id", "name", "project_id", "complete", "due_date"},
+	CSVColumns:   []string{"id", "name", "project_id", "complete", "billable", "due_date"},
+	Empty:        "No tasks found.",
+	Footer: func(items []interface{}) string {
+		return fmt.Sprintf("Total: %d tasks", len(items))
+	},
+	Detail: func(w io.Writer, v interface{}, l *locale.Locale) error { return formatTaskDetail(w, task(v), l) },
+}
+
+// ClientResource renders clients.
+var ClientResource = &Resource{
+	Name: "clients",
+	Fields: []Field{
+		{Name: "id", Header: "ID", Kind: KindInt, Width: 8, Text: func(v interface{}) string { return strconv.Itoa(client(v).ID) }},
+		{Name: "name", Header: "Name", Width: 30, Text: func(v interface{}) string { return client(v).Name }},
+		{Name: "email", Header: "Email", Width: 25, Text: func(v interface{}) string { return client(v).Email }},
+		{Name: "phone", Header: "Phone", Width: 15, Text: func(v interface{}) string { return client(v).Phone }},
+		{Name: "address", Header: "Address", Width: 25, Text: func(v interface{}) string { return client(v).Address }},
+		{Name: "city", Header: "City", Width: 15, Text: func(v interface{}) string { return client(v).City }},
+		{Name: "country", Header: "Country", Width: 15, Text: func(v interface{}) string { return client(v).Country }},
+		{Name: "active", Header: "Active", Kind: KindBool, Width: 8,
+			Text: func(v interface{}) string { return yesNo(client(v).Active) },
+			Raw:  func(v interface{}) string { return strconv.FormatBool(client(v).Active) },
+		},
+	},
+	TableColumns: []string{"id", "name", "email", "active"},
+	CSVColumns:   []string{"id", "name", "email", "phone", "city", "country", "active"},
+	Empty:        "No clients found.",
+	Footer: func(items []interface{}) string {
+		return fmt.Sprintf("Total: %d clients", len(items))
+	},
+}
+
+// resources lists every resource that list and show commands render.
+var resources = []*Resource{EntryResource, ProjectResource, TaskResource, ClientResource}
+
+// LookupResource returns the resource with the given name, or nil.
+func LookupResource(name string) *Resource {
+	for _, r := range resources {
+		if r.Name == name {
+			return r
+		}
+	}
+	return nil
+}
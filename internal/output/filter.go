@@ -0,0 +1,494 @@
+package output
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ComputClaw/paymo-cli/internal/locale"
+)
+
+// Kind is the value type of a field, used to type-check --filter expressions.
+type Kind int
+
+const (
+	KindString   Kind = iota
+	KindInt           // whole numbers (IDs, priority)
+	KindFloat         // decimal numbers (hours, rates)
+	KindBool          // yes/no flags
+	KindDuration      // seconds, written as 2h, 90m or 1h30m in filters
+	KindTime          // fields with a Time getter
+)
+
+// kind returns the value type of the field.
+func (fd Field) kind() Kind {
+	if fd.Time != nil {
+		return KindTime
+	}
+	return fd.Kind
+}
+
+// FilterError is a --filter syntax or type error at a position in the expression.
+type FilterError struct {
+	Expr string
+	Pos  int // byte offset of the offending token
+	Msg  string
+}
+
+func (e *FilterError) Error() string {
+	return fmt.Sprintf("invalid filter: %s at column %d\n  %s\n  %s^",
+		e.Msg, displayWidth(e.Expr[:e.Pos])+1, e.Expr, strings.Repeat(" ", displayWidth(e.Expr[:e.Pos])))
+}
+
+// Filter is a parsed --filter expression, evaluated locally against list items.
+//
+//	duration > 2h and billable and description ~ "review"
+//	not complete and priority >= 75
+//	(project = "Website" or project = "App") and date >= yesterday
+//
+// Comparisons are =, !=, <, <=, >, >=, ~ (contains, case-insensitive) and !~.
+// Terms combine with and, or, not and parentheses; a yes/no field on its own
+// is true when set.
+type Filter struct {
+	expr string
+	root filterNode
+}
+
+// String returns the expression the filter was parsed from.
+func (f *Filter) String() string {
+	return f.expr
+}
+
+// Match reports whether item satisfies the filter.
+func (f *Filter) Match(item interface{}) bool {
+	return f.root.eval(item)
+}
+
+// apply returns the items that satisfy the filter.
+func (f *Filter) apply(items []interface{}) []interface{} {
+	matched := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		if f.Match(item) {
+			matched = append(matched, item)
+		}
+	}
+	return matched
+}
+
+// ParseFilter parses a --filter expression over the resource's fields. Date
+// literals (today, 2026-02-01) are read in the locale's timezone.
+func (r *Resource) ParseFilter(expr string, l *locale.Locale) (*Filter, error) {
+	if l == nil {
+		l = locale.Default()
+	}
+	tokens, err := lexFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{expr: expr, tokens: tokens, res: r, loc: l}
+	if p.peek().kind == tokEOF {
+		return nil, p.errorf(p.peek(), "empty expression")
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.errorf(t, "unexpected %s", t.describe())
+	}
+	return &Filter{expr: expr, root: root}, nil
+}
+
+// --- Lexer ---
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokString
+	tokOp
+	tokLParen
+	tokRParen
+)
+
+type filterToken struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t filterToken) describe() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of expression"
+	case tokString:
+		return strconv.Quote(t.text)
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// keyword reports whether the token is the given (case-insensitive) keyword.
+func (t filterToken) keyword(kw string) bool {
+	return t.kind == tokWord && strings.EqualFold(t.text, kw)
+}
+
+// filterOps lists the operators, longest first so ">=" wins over ">".
+var filterOps = []string{"==", "!=", ">=", "<=", "!~", "&&", "||", "=", ">", "<", "~", "!"}
+
+// isWordRune reports whether r can appear in field names and bare literals
+// such as 1h30m, 2026-02-01 or 2026-02-01T09:00:00+01:00.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_.:+-/", r)
+}
+
+func lexFilter(expr string) ([]filterToken, error) {
+	var tokens []filterToken
+	i := 0
+	for i < len(expr) {
+		r := rune(expr[i])
+		switch {
+		case r == ' ' || r == '\t' || r == '\n':
+			i++
+		case r == '(':
+			tokens = append(tokens, filterToken{tokLParen, "(", i})
+			i++
+		case r == ')':
+			tokens = append(tokens, filterToken{tokRParen, ")", i})
+			i++
+		case r == '"' || r == '\'':
+			start := i
+			var b strings.Builder
+			i++
+			for {
+				if i >= len(expr) {
+					return nil, &FilterError{expr, start, "unterminated string"}
+				}
+				if expr[i] == '\\' && i+1 < len(expr) {
+					b.WriteByte(expr[i+1])
+					i += 2
+					continue
+				}
+				if rune(expr[i]) == r {
+					i++
+					break
+				}
+				b.WriteByte(expr[i])
+				i++
+			}
+			tokens = append(tokens, filterToken{tokString, b.String(), start})
+		default:
+			matched := false
+			for _, op := range filterOps {
+				if strings.HasPrefix(expr[i:], op) {
+					tokens = append(tokens, filterToken{tokOp, op, i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if matched {
+				continue
+			}
+			start := i
+			for i < len(expr) {
+				wr := []rune(expr[i:])[0]
+				if !isWordRune(wr) {
+					break
+				}
+				i += len(string(wr))
+			}
+			if i == start {
+				return nil, &FilterError{expr, start, fmt.Sprintf("unexpected character %q", []rune(expr[i:])[0])}
+			}
+			tokens = append(tokens, filterToken{tokWord, expr[start:i], start})
+		}
+	}
+	return append(tokens, filterToken{tokEOF, "", len(expr)}), nil
+}
+
+// --- Parser ---
+
+type filterParser struct {
+	expr   string
+	tokens []filterToken
+	pos    int
+	res    *Resource
+	loc    *locale.Locale
+}
+
+func (p *filterParser) peek() filterToken { return p.tokens[p.pos] }
+
+func (p *filterParser) next() filterToken {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *filterParser) errorf(t filterToken, format string, args ...interface{}) error {
+	return &FilterError{Expr: p.expr, Pos: t.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// parseOr: and-expr { ("or" | "||") and-expr }
+func (p *filterParser) parseOr() (filterNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for t := p.peek(); t.keyword("or") || (t.kind == tokOp && t.text == "||"); t = p.peek() {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+// parseAnd: unary { ("and" | "&&") unary }
+func (p *filterParser) parseAnd() (filterNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for t := p.peek(); t.keyword("and") || (t.kind == tokOp && t.text == "&&"); t = p.peek() {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+// parseUnary: ("not" | "!") unary | "(" or-expr ")" | term
+func (p *filterParser) parseUnary() (filterNode, error) {
+	t := p.peek()
+	switch {
+	case t.keyword("not") || (t.kind == tokOp && t.text == "!"):
+		p.next()
+		n, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{n}, nil
+	case t.kind == tokLParen:
+		p.next()
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if c := p.peek(); c.kind != tokRParen {
+			return nil, p.errorf(c, "expected \")\" to close the \"(\" at column %d, found %s", t.pos+1, c.describe())
+		}
+		p.next()
+		return n, nil
+	}
+	return p.parseTerm()
+}
+
+// parseTerm: field [op value]
+func (p *filterParser) parseTerm() (filterNode, error) {
+	t := p.next()
+	if t.kind != tokWord || t.keyword("and") || t.keyword("or") {
+		return nil, p.errorf(t, "expected a field name, found %s", t.describe())
+	}
+	fd, ok := p.res.field(strings.ToLower(t.text))
+	if !ok {
+		return nil, p.errorf(t, "unknown field %q for %s (available: %s)", t.text, p.res.Name, strings.Join(p.res.FieldNames(), ", "))
+	}
+
+	op := p.peek()
+	if op.kind != tokOp || op.text == "!" || op.text == "&&" || op.text == "||" {
+		// A bare field is a yes/no test
+		if fd.kind() != KindBool {
+			return nil, p.errorf(op, "expected a comparison after %q (only yes/no fields can stand alone)", t.text)
+		}
+		return boolNode{fd}, nil
+	}
+	p.next()
+
+	v := p.next()
+	if v.kind != tokWord && v.kind != tokString {
+		return nil, p.errorf(v, "expected a value after %q, found %s", op.text, v.describe())
+	}
+	return p.comparison(fd, op, v)
+}
+
+// comparison type-checks a comparison and parses its literal for the field's kind.
+func (p *filterParser) comparison(fd Field, op, v filterToken) (filterNode, error) {
+	c := cmpNode{fd: fd, op: op.text, loc: p.loc}
+	if c.op == "==" {
+		c.op = "="
+	}
+	kind := fd.kind()
+	if (c.op == "~" || c.op == "!~") && kind != KindString {
+		return nil, p.errorf(op, "operator %s needs a text field, %q is not one", c.op, fd.Name)
+	}
+
+	switch kind {
+	case KindString:
+		c.str = strings.ToLower(v.text)
+	case KindBool:
+		if c.op != "=" && c.op != "!=" {
+			return nil, p.errorf(op, "yes/no field %q only supports = and !=", fd.Name)
+		}
+		b, err := strconv.ParseBool(strings.ToLower(v.text))
+		if err != nil {
+			return nil, p.errorf(v, "%q expects true or false, got %s", fd.Name, v.describe())
+		}
+		c.num = boolNum(b)
+	case KindInt, KindFloat:
+		n, err := strconv.ParseFloat(v.text, 64)
+		if err != nil {
+			return nil, p.errorf(v, "%q expects a number, got %s", fd.Name, v.describe())
+		}
+		c.num = n
+	case KindDuration:
+		d, err := parseFilterDuration(v.text)
+		if err != nil {
+			return nil, p.errorf(v, "%q expects a duration like 2h, 90m or 1h30m, got %s", fd.Name, v.describe())
+		}
+		c.num = d.Seconds()
+	case KindTime:
+		t, byDay, err := parseFilterTime(v.text, p.loc)
+		if err != nil {
+			return nil, p.errorf(v, "%q expects a date like 2026-02-01, today or yesterday, got %s", fd.Name, v.describe())
+		}
+		c.time = t
+		c.byDay = byDay || fd.Date
+	}
+	return c, nil
+}
+
+// parseFilterDuration accepts Go durations (2h, 1h30m, 1.5h) and plain
+// numbers of seconds.
+func parseFilterDuration(s string) (time.Duration, error) {
+	if n, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Duration(n * float64(time.Second)), nil
+	}
+	return time.ParseDuration(s)
+}
+
+// parseFilterTime parses a date (compared by calendar day) or an RFC 3339
+// timestamp (compared exactly).
+func parseFilterTime(s string, l *locale.Locale) (time.Time, bool, error) {
+	switch strings.ToLower(s) {
+	case "today":
+		return l.Today(), true, nil
+	case "yesterday":
+		return l.Today().AddDate(0, 0, -1), true, nil
+	case "tomorrow":
+		return l.Today().AddDate(0, 0, 1), true, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, false, nil
+	}
+	t, err := l.ParseDate(s)
+	return t, true, err
+}
+
+func boolNum(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// --- Evaluation ---
+
+type filterNode interface {
+	eval(item interface{}) bool
+}
+
+type andNode struct{ left, right filterNode }
+type orNode struct{ left, right filterNode }
+type notNode struct{ inner filterNode }
+type boolNode struct{ fd Field }
+
+func (n andNode) eval(item interface{}) bool { return n.left.eval(item) && n.right.eval(item) }
+func (n orNode) eval(item interface{}) bool  { return n.left.eval(item) || n.right.eval(item) }
+func (n notNode) eval(item interface{}) bool { return !n.inner.eval(item) }
+
+func (n boolNode) eval(item interface{}) bool {
+	b, _ := strconv.ParseBool(n.fd.raw(item, locale.Default()))
+	return b
+}
+
+// cmpNode compares a field with a literal parsed for the field's kind.
+type cmpNode struct {
+	fd    Field
+	op    string
+	loc   *locale.Locale
+	str   string    // KindString, lowercased
+	num   float64   // numbers, durations (seconds) and booleans (0/1)
+	time  time.Time // KindTime
+	byDay bool      // compare times by calendar day
+}
+
+func (n cmpNode) eval(item interface{}) bool {
+	switch n.fd.kind() {
+	case KindString:
+		value := strings.ToLower(n.fd.raw(item, n.loc))
+		switch n.op {
+		case "~":
+			return strings.Contains(value, n.str)
+		case "!~":
+			return !strings.Contains(value, n.str)
+		}
+		return compareResult(strings.Compare(value, n.str), n.op)
+	case KindTime:
+		t := n.fd.Time(item)
+		if t.IsZero() {
+			return n.op == "!="
+		}
+		lit := n.time
+		if n.byDay {
+			t, lit = n.loc.StartOfDay(t), n.loc.StartOfDay(lit)
+		}
+		return compareResult(t.Compare(lit), n.op)
+	case KindBool:
+		b, _ := strconv.ParseBool(n.fd.raw(item, n.loc))
+		return compareResult(compareFloat(boolNum(b), n.num), n.op)
+	}
+	value, err := strconv.ParseFloat(n.fd.raw(item, n.loc), 64)
+	if err != nil {
+		return false
+	}
+	return compareResult(compareFloat(value, n.num), n.op)
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// compareResult applies a comparison operator to a three-way comparison.
+func compareResult(c int, op string) bool {
+	switch op {
+	case "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+package output
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ComputClaw/paymo-cli/internal/api"
+	"github.com/ComputClaw/paymo-cli/internal/locale"
+)
+
+func filterEntries() []interface{} {
+	entries := []api.TimeEntry{
+		{ID: 1, Duration: 9000, Billable: true, Description: "Code Review", StartTime: time.Date(2026, 2, 7, 9, 0, 0, 0, time.UTC), Project: &api.Project{Name: "Website"}},
+		{ID: 2, Duration: 3600, Billable: true, Description: "review notes", StartTime: time.Date(2026, 2, 8, 9, 0, 0, 0, time.UTC), Project: &api.Project{Name: "App"}},
+		{ID: 3, Duration: 10800, Billable: false, Description: "Planning", StartTime: time.Date(2026, 2, 8, 23, 30, 0, 0, time.UTC), Project: &api.Project{Name: "App"}},
+	}
+	items := make([]interface{}, len(entries))
+	for i := range entries {
+		items[i] = &entries[i]
+	}
+	return items
+}
+
+func TestParseFilter_Matches(t *testing.T) {
+	utc, _ := locale.New("UTC", "", "")
+	tests := []struct {
+		expr string
+		want []int
+	}{
+		{`duration > 2h and billable and description ~ "review"`, []int{1}},
+		{`duration >= 3600`, []int{1, 2, 3}},
+		{`duration <= 1h30m`, []int{2}},
+		{`not billable`, []int{3}},
+		{`billable = false`, []int{3}},
+		{`!billable || id == 1`, []int{1, 3}},
+		{`description !~ 'review'`, []int{3}},
+		{`project = app and (id = 2 or duration > 2h)`, []int{2, 3}},
+		{`date = 2026-02-08`, []int{2, 3}},
+		{`date > 2026-02-07`, []int{2, 3}},
+		{`start < 2026-02-08T12:00:00Z`, []int{1, 2}},
+		{`start = 2026-02-08`, []int{2, 3}},
+	}
+	for _, tt := range tests {
+		f, err := EntryResource.ParseFilter(tt.expr, utc)
+		if err != nil {
+			t.Errorf("ParseFilter(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := entryIDs(f.apply(filterEntries())); !equalInts(got, tt.want) {
+			t.Errorf("%q matched %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseFilter_DatesUseLocale(t *testing.T) {
+	// 23:30 UTC on the 8th is already the 9th in Copenhagen
+	cph, _ := locale.New("Europe/Copenhagen", "", "")
+	f, err := EntryResource.ParseFilter("date = 2026-02-09", cph)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := entryIDs(f.apply(filterEntries())); !equalInts(got, []int{3}) {
+		t.Errorf("matched %v, want [3]", got)
+	}
+}
+
+func TestParseFilter_OtherResources(t *testing.T) {
+	projects := []interface{}{
+		&api.Project{ID: 1, Active: true, ClientID: 5, BudgetHours: 40},
+		&api.Project{ID: 2, Active: false, ClientID: 6, BudgetHours: 12.5},
+	}
+	f, err := ProjectResource.ParseFilter("active and client = 5 and budget_hours > 20", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := f.apply(projects); len(got) != 1 || got[0].(*api.Project).ID != 1 {
+		t.Errorf("unexpected projects: %v", got)
+	}
+
+	tasks := []interface{}{
+		&api.Task{ID: 1, Complete: true, Priority: 100},
+		&api.Task{ID: 2, Complete: false, Priority: 50},
+	}
+	f, err = TaskResource.ParseFilter("not complete and priority >= 50", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := f.apply(tasks); len(got) != 1 || got[0].(*api.Task).ID != 2 {
+		t.Errorf("unexpected tasks: %v", got)
+	}
+}
+
+func TestParseFilter_Errors(t *testing.T) {
+	tests := []struct {
+		expr   string
+		msg    string
+		column int
+	}{
+		{"", "empty expression", 1},
+		{"nope > 1", `unknown field "nope"`, 1},
+		{"duration >", "expected a value", 11},
+		{"duration > soon", "expects a duration", 12},
+		{"id ~ 5", "needs a text field", 4},
+		{"billable > true", "only supports = and !=", 10},
+		{"description", "only yes/no fields can stand alone", 12},
+		{`description ~ "review`, "unterminated string", 15},
+		{"(billable or billed", `expected ")"`, 20},
+		{"billable billed", `unexpected "billed"`, 10},
+		{"date < someday", "expects a date", 8},
+		{"id = 1 and", "expected a field name", 11},
+	}
+	for _, tt := range tests {
+		_, err := EntryResource.ParseFilter(tt.expr, nil)
+		if err == nil {
+			t.Errorf("ParseFilter(%q): expected error", tt.expr)
+			continue
+		}
+		fe, ok := err.(*FilterError)
+		if !ok {
+			t.Errorf("ParseFilter(%q): expected *FilterError, got %T", tt.expr, err)
+			continue
+		}
+		if !strings.Contains(fe.Msg, tt.msg) || fe.Pos+1 != tt.column {
+			t.Errorf("ParseFilter(%q) = %q at column %d, want %q at column %d", tt.expr, fe.Msg, fe.Pos+1, tt.msg, tt.column)
+		}
+	}
+}
+
+func TestFilterError_ShowsCaret(t *testing.T) {
+	_, err := EntryResource.ParseFilter("billable and duration > soon", nil)
+	want := "invalid filter: \"duration\" expects a duration like 2h, 90m or 1h30m, got \"soon\" at column 25\n" +
+		"  billable and duration > soon\n" +
+		"                          ^"
+	if err == nil || err.Error() != want {
+		t.Errorf("error =\n%v\nwant\n%s", err, want)
+	}
+}
+
+func TestFormatter_Filter(t *testing.T) {
+	f, _ := EntryResource.ParseFilter("billable", nil)
+	out := render(t, "json", func(fm *Formatter) error {
+		fm.Filter = f
+		fm.Envelope = &Envelope{}
+		entries := filterEntries()
+		list := make([]api.TimeEntry, len(entries))
+		for i, e := range entries {
+			list[i] = *entry(e)
+		}
+		return fm.FormatTimeEntries(list)
+	})
+	var env struct {
+		Count         int                    `json:"count"`
+		TotalDuration int                    `json:"total_duration"`
+		Filters       map[string]interface{} `json:"filters"`
+	}
+	if err := json.Unmarshal([]byte(out), &env); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if env.Count != 2 || env.TotalDuration != 12600 || env.Filters["filter"] != "billable" {
+		t.Errorf("unexpected envelope: %+v", env)
+	}
+}
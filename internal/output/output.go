@@ -1,23 +1,33 @@
 package output
 
 import (
-	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/ComputClaw/paymo-cli/internal/api"
+	"github.com/ComputClaw/paymo-cli/internal/locale"
 )
 
 // Formatter handles output formatting
 type Formatter struct {
-	Format string
-	Quiet  bool
-	Writer io.Writer
+	Format   string
+	Quiet    bool
+	Writer   io.Writer
+	Columns  []string           // columns for tabular formats; nil for defaults
+	Template *template.Template // replaces the format when set
+	Style    string             // table style (box, ascii, plain, compact)
+	Width    int                // maximum table width; 0 for unlimited
+	Envelope *Envelope          // wraps structured list output when set (--envelope)
+	Locale   *locale.Locale     // timezone and date/time formats
+	Sort     []SortKey          // list order (--sort); nil keeps API order
+	GroupBy  string             // field to group lists by (--group-by)
+	Filter   *Filter            // drops list items that don't match (--filter)
 }
 
 // NewFormatter creates a new formatter with the specified format
@@ -25,6 +35,7 @@ func NewFormatter(format string) *Formatter {
 	return &Formatter{
 		Format: strings.ToLower(format),
 		Writer: os.Stdout,
+		Locale: locale.Default(),
 	}
 }
 
@@ -35,6 +46,24 @@ type SuccessResult struct {
 	ID      int    `json:"id,omitempty"`
 }
 
+// MockServerInfo is the JSON structure for a running mock Paymo server
+type MockServerInfo struct {
+	BaseURL string `json:"base_url"`
+	APIKey  string `json:"api_key,omitempty"` // empty if any key is accepted
+}
+
+// Envelope is the structure wrapped around list output with --envelope. The
+// command fills in the query and cache metadata; the formatter adds the data.
+type Envelope struct {
+	Data          interface{}            `json:"data"`
+	Count         int                    `json:"count"`
+	TotalDuration *int                   `json:"total_duration,omitempty"` // seconds, time entries only
+	Filters       map[string]interface{} `json:"filters"`
+	Cached        bool                   `json:"cached"`
+	Stale         bool                   `json:"stale"`
+	CachedAt      *time.Time             `json:"cached_at,omitempty"`
+}
+
 // ErrorResult is the JSON structure for error output
 type ErrorResult struct {
 	Error ErrorDetail `json:"error"`
@@ -53,12 +82,7 @@ func (f *Formatter) FormatProject(project *api.Project) error {
 		fmt.Fprintf(f.Writer, "%d\n", project.ID)
 		return nil
 	}
-	switch f.Format {
-	case "json":
-		return f.formatJSON(project)
-	default:
-		return f.formatProjectDetail(project)
-	}
+	return f.renderItem(ProjectResource, project)
 }
 
 // FormatTask outputs a single task (for show/create commands)
@@ -67,12 +91,7 @@ func (f *Formatter) FormatTask(task *api.Task) error {
 		fmt.Fprintf(f.Writer, "%d\n", task.ID)
 		return nil
 	}
-	switch f.Format {
-	case "json":
-		return f.formatJSON(task)
-	default:
-		return f.formatTaskDetail(task)
-	}
+	return f.renderItem(TaskResource, task)
 }
 
 // FormatTimeEntry outputs a single time entry (for start/stop commands)
@@ -81,21 +100,16 @@ func (f *Formatter) FormatTimeEntry(entry *api.TimeEntry) error {
 		fmt.Fprintf(f.Writer, "%d\n", entry.ID)
 		return nil
 	}
-	switch f.Format {
-	case "json":
-		return f.formatJSON(entry)
-	default:
-		return f.formatEntryDetail(entry)
-	}
+	return f.renderItem(EntryResource, entry)
 }
 
-// FormatTimerStatus outputs the current timer state
+// FormatTimerStatus outputs arbitrary data in structured formats
 func (f *Formatter) FormatTimerStatus(data interface{}) error {
 	if f.Quiet {
 		return nil
 	}
-	if f.Format == "json" {
-		return f.formatJSON(data)
+	if vr, ok := f.valueRenderer(); ok {
+		return vr.RenderValue(f.Writer, data)
 	}
 	return nil // caller handles table format
 }
@@ -108,18 +122,43 @@ func (f *Formatter) FormatSuccess(msg string, id int) error {
 		}
 		return nil
 	}
-	switch f.Format {
-	case "json":
-		return f.formatJSON(SuccessResult{Status: "ok", Message: msg, ID: id})
-	default:
-		fmt.Fprintln(f.Writer, msg)
+	if vr, ok := f.valueRenderer(); ok {
+		return vr.RenderValue(f.Writer, SuccessResult{Status: "ok", Message: msg, ID: id})
+	}
+	fmt.Fprintln(f.Writer, msg)
+	return nil
+}
+
+// FormatMockServer outputs where a mock Paymo server listens and how to
+// point paymo at it. Quiet mode prints only the base URL.
+func (f *Formatter) FormatMockServer(info MockServerInfo) error {
+	if f.Quiet {
+		fmt.Fprintln(f.Writer, info.BaseURL)
 		return nil
 	}
+	if vr, ok := f.valueRenderer(); ok {
+		return vr.RenderValue(f.Writer, info)
+	}
+	apiKey := info.APIKey
+	if apiKey == "" {
+		apiKey = "mock"
+	}
+	fmt.Fprintf(f.Writer, "Mock Paymo API listening on %s (Ctrl+C to stop)\n", info.BaseURL)
+	fmt.Fprintf(f.Writer, "  PAYMO_BASE_URL=%s PAYMO_API_KEY=%s paymo ...\n", info.BaseURL, apiKey)
+	return nil
+}
+
+// Structured reports whether the format is machine-readable (json, yaml,
+// ndjson) and can encode arbitrary values. Commands use it to decide between
+// FormatTimerStatus and their own human-readable output.
+func (f *Formatter) Structured() bool {
+	_, ok := f.valueRenderer()
+	return ok
 }
 
 // FormatError outputs a structured error to stderr
 func (f *Formatter) FormatError(err error) {
-	if f.Format == "json" {
+	if f.Structured() {
 		detail := ErrorDetail{Code: "GENERAL_ERROR", Message: err.Error()}
 		var apiErr *api.APIError
 		if errors.As(err, &apiErr) {
@@ -137,508 +176,203 @@ func (f *Formatter) FormatError(err error) {
 	}
 }
 
+// FormatTimeEntries outputs time entries in the specified format
+func (f *Formatter) FormatTimeEntries(entries []api.TimeEntry) error {
+	items := make([]interface{}, len(entries))
+	for i := range entries {
+		items[i] = &entries[i]
+	}
+	return f.renderList(EntryResource, entries, items)
+}
+
+// FormatClients outputs clients in the specified format
+func (f *Formatter) FormatClients(clients []api.PaymoClient) error {
+	items := make([]interface{}, len(clients))
+	for i := range clients {
+		items[i] = &clients[i]
+	}
+	return f.renderList(ClientResource, clients, items)
+}
+
+// FormatProjects outputs projects in the specified format
+func (f *Formatter) FormatProjects(projects []api.Project) error {
+	items := make([]interface{}, len(projects))
+	for i := range projects {
+		items[i] = &projects[i]
+	}
+	return f.renderList(ProjectResource, projects, items)
+}
+
+// FormatTasks outputs tasks in the specified format
+func (f *Formatter) FormatTasks(tasks []api.Task) error {
+	items := make([]interface{}, len(tasks))
+	for i := range tasks {
+		items[i] = &tasks[i]
+	}
+	return f.renderList(TaskResource, tasks, items)
+}
+
+// renderList renders a resource list with the selected renderer
+func (f *Formatter) renderList(res *Resource, value interface{}, items []interface{}) error {
+	if f.Filter != nil {
+		items = f.Filter.apply(items)
+		value = items
+	}
+	groupBy := ""
+	if fd, ok := res.field(f.GroupBy); ok {
+		groupBy = fd.Name
+	}
+	if len(f.Sort) > 0 || groupBy != "" {
+		items = sortItems(res, items, f.Sort, groupBy, f.Locale)
+		value = items
+	}
+	var groups []Group
+	if groupBy != "" {
+		groups = groupItems(res, items, groupBy, f.Locale)
+		value = groupValues(res, groups)
+	}
+
+	if f.Template != nil {
+		return executeTemplate(f.Writer, f.Template, items, f.Locale)
+	}
+	if f.Envelope != nil {
+		if vr, ok := f.valueRenderer(); ok {
+			return vr.RenderValue(f.Writer, f.envelope(res, value, items))
+		}
+	}
+	r, err := LookupRenderer(f.Format)
+	if err != nil {
+		return err
+	}
+	return r.Render(f.Writer, &Dataset{Resource: res, Value: value, Items: items, Columns: f.Columns, Style: f.Style, Width: f.Width, Locale: f.Locale, Groups: groups, GroupBy: groupBy})
+}
+
+// envelope returns a copy of f.Envelope wrapped around a list
+func (f *Formatter) envelope(res *Resource, value interface{}, items []interface{}) Envelope {
+	env := *f.Envelope
+	env.Data = value
+	env.Count = len(items)
+	filters := map[string]interface{}{}
+	for k, v := range env.Filters {
+		filters[k] = v
+	}
+	if f.Filter != nil {
+		filters["filter"] = f.Filter.String()
+	}
+	env.Filters = filters
+	if res.Duration != nil {
+		total := 0
+		for _, item := range items {
+			total += res.Duration(item)
+		}
+		env.TotalDuration = &total
+	}
+	return env
+}
+
+// renderItem renders a single resource with the selected renderer
+func (f *Formatter) renderItem(res *Resource, item interface{}) error {
+	if f.Template != nil {
+		return executeTemplate(f.Writer, f.Template, []interface{}{item}, f.Locale)
+	}
+	r, err := LookupRenderer(f.Format)
+	if err != nil {
+		return err
+	}
+	return r.Render(f.Writer, &Dataset{Resource: res, Value: item, Items: []interface{}{item}, Single: true, Columns: f.Columns, Locale: f.Locale})
+}
+
+// valueRenderer returns the selected renderer if it is a structured format
+func (f *Formatter) valueRenderer() (ValueRenderer, bool) {
+	r, err := LookupRenderer(f.Format)
+	if err != nil {
+		return nil, false
+	}
+	vr, ok := r.(ValueRenderer)
+	return vr, ok
+}
+
 // formatProjectDetail outputs a single project in human-readable detail format
-func (f *Formatter) formatProjectDetail(p *api.Project) error {
-	fmt.Fprintf(f.Writer, "Project: %s\n", p.Name)
-	fmt.Fprintf(f.Writer, "  ID:       %d\n", p.ID)
+func formatProjectDetail(w io.Writer, p *api.Project, l *locale.Locale) error {
+	fmt.Fprintf(w, "Project: %s\n", p.Name)
+	fmt.Fprintf(w, "  ID:       %d\n", p.ID)
 	if p.Code != "" {
-		fmt.Fprintf(f.Writer, "  Code:     %s\n", p.Code)
+		fmt.Fprintf(w, "  Code:     %s\n", p.Code)
 	}
 	if p.Description != "" {
-		fmt.Fprintf(f.Writer, "  Desc:     %s\n", p.Description)
+		fmt.Fprintf(w, "  Desc:     %s\n", p.Description)
 	}
 	status := "Inactive"
 	if p.Active {
 		status = "Active"
 	}
-	fmt.Fprintf(f.Writer, "  Status:   %s\n", status)
+	fmt.Fprintf(w, "  Status:   %s\n", status)
 	billable := "No"
 	if p.Billable {
 		billable = "Yes"
 	}
-	fmt.Fprintf(f.Writer, "  Billable: %s\n", billable)
+	fmt.Fprintf(w, "  Billable: %s\n", billable)
 	if p.BudgetHours > 0 {
-		fmt.Fprintf(f.Writer, "  Budget:   %.1f hours\n", p.BudgetHours)
+		fmt.Fprintf(w, "  Budget:   %.1f hours\n", p.BudgetHours)
 	}
 	if p.PricePerHour > 0 {
-		fmt.Fprintf(f.Writer, "  Rate:     $%.2f/hour\n", p.PricePerHour)
+		fmt.Fprintf(w, "  Rate:     $%.2f/hour\n", p.PricePerHour)
 	}
-	fmt.Fprintf(f.Writer, "  Created:  %s\n", p.CreatedOn.Format("2006-01-02"))
+	fmt.Fprintf(w, "  Created:  %s\n", l.Date(p.CreatedOn))
 	return nil
 }
 
 // formatTaskDetail outputs a single task in human-readable detail format
-func (f *Formatter) formatTaskDetail(t *api.Task) error {
-	fmt.Fprintf(f.Writer, "Task: %s\n", t.Name)
-	fmt.Fprintf(f.Writer, "  ID:         %d\n", t.ID)
+func formatTaskDetail(w io.Writer, t *api.Task, l *locale.Locale) error {
+	fmt.Fprintf(w, "Task: %s\n", t.Name)
+	fmt.Fprintf(w, "  ID:         %d\n", t.ID)
 	if t.Code != "" {
-		fmt.Fprintf(f.Writer, "  Code:       %s\n", t.Code)
+		fmt.Fprintf(w, "  Code:       %s\n", t.Code)
 	}
-	fmt.Fprintf(f.Writer, "  Project ID: %d\n", t.ProjectID)
+	fmt.Fprintf(w, "  Project ID: %d\n", t.ProjectID)
 	status := "Open"
 	if t.Complete {
 		status = "Complete"
 	}
-	fmt.Fprintf(f.Writer, "  Status:     %s\n", status)
+	fmt.Fprintf(w, "  Status:     %s\n", status)
 	billable := "No"
 	if t.Billable {
 		billable = "Yes"
 	}
-	fmt.Fprintf(f.Writer, "  Billable:   %s\n", billable)
+	fmt.Fprintf(w, "  Billable:   %s\n", billable)
 	if t.DueDate != "" {
-		fmt.Fprintf(f.Writer, "  Due Date:   %s\n", t.DueDate)
+		fmt.Fprintf(w, "  Due Date:   %s\n", t.DueDate)
 	}
 	if t.Description != "" {
-		fmt.Fprintf(f.Writer, "  Desc:       %s\n", t.Description)
+		fmt.Fprintf(w, "  Desc:       %s\n", t.Description)
 	}
-	fmt.Fprintf(f.Writer, "  Created:    %s\n", t.CreatedOn.Format("2006-01-02"))
+	fmt.Fprintf(w, "  Created:    %s\n", l.Date(t.CreatedOn))
 	return nil
 }
 
 // formatEntryDetail outputs a single time entry in human-readable detail format
-func (f *Formatter) formatEntryDetail(e *api.TimeEntry) error {
-	fmt.Fprintf(f.Writer, "Time Entry: #%d\n", e.ID)
+func formatEntryDetail(w io.Writer, e *api.TimeEntry, l *locale.Locale) error {
+	fmt.Fprintf(w, "Time Entry: #%d\n", e.ID)
 	if e.Project != nil {
-		fmt.Fprintf(f.Writer, "  Project:     %s\n", e.Project.Name)
+		fmt.Fprintf(w, "  Project:     %s\n", e.Project.Name)
 	}
 	if e.Task != nil {
-		fmt.Fprintf(f.Writer, "  Task:        %s\n", e.Task.Name)
+		fmt.Fprintf(w, "  Task:        %s\n", e.Task.Name)
 	}
 	if e.Description != "" {
-		fmt.Fprintf(f.Writer, "  Description: %s\n", e.Description)
+		fmt.Fprintf(w, "  Description: %s\n", e.Description)
 	}
-	fmt.Fprintf(f.Writer, "  Start:       %s\n", e.StartTime.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(w, "  Start:       %s\n", l.DateTime(e.StartTime))
 	if !e.EndTime.IsZero() {
-		fmt.Fprintf(f.Writer, "  End:         %s\n", e.EndTime.Format("2006-01-02 15:04:05"))
+		fmt.Fprintf(w, "  End:         %s\n", l.DateTime(e.EndTime))
 	}
 	if e.Duration > 0 {
-		fmt.Fprintf(f.Writer, "  Duration:    %s\n", formatDuration(e.Duration))
-	}
-	return nil
-}
-
-// FormatTimeEntries outputs time entries in the specified format
-func (f *Formatter) FormatTimeEntries(entries []api.TimeEntry) error {
-	switch f.Format {
-	case "json":
-		return f.formatJSON(entries)
-	case "csv":
-		return f.formatEntriesCSV(entries)
-	default:
-		return f.formatEntriesTable(entries)
-	}
-}
-
-// FormatClients outputs clients in the specified format
-func (f *Formatter) FormatClients(clients []api.PaymoClient) error {
-	switch f.Format {
-	case "json":
-		return f.formatJSON(clients)
-	case "csv":
-		return f.formatClientsCSV(clients)
-	default:
-		return f.formatClientsTable(clients)
-	}
-}
-
-// FormatProjects outputs projects in the specified format
-func (f *Formatter) FormatProjects(projects []api.Project) error {
-	switch f.Format {
-	case "json":
-		return f.formatJSON(projects)
-	case "csv":
-		return f.formatProjectsCSV(projects)
-	default:
-		return f.formatProjectsTable(projects)
-	}
-}
-
-// FormatTasks outputs tasks in the specified format
-func (f *Formatter) FormatTasks(tasks []api.Task) error {
-	switch f.Format {
-	case "json":
-		return f.formatJSON(tasks)
-	case "csv":
-		return f.formatTasksCSV(tasks)
-	default:
-		return f.formatTasksTable(tasks)
-	}
-}
-
-// formatJSON outputs data as JSON
-func (f *Formatter) formatJSON(data interface{}) error {
-	encoder := json.NewEncoder(f.Writer)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(data)
-}
-
-// formatEntriesTable outputs entries as a table
-func (f *Formatter) formatEntriesTable(entries []api.TimeEntry) error {
-	if len(entries) == 0 {
-		fmt.Fprintln(f.Writer, "No time entries found.")
-		return nil
-	}
-
-	// Calculate column widths
-	idWidth := 6
-	projectWidth := 20
-	taskWidth := 20
-	durationWidth := 10
-	dateWidth := 10
-	descWidth := 30
-
-	// Print header
-	fmt.Fprintf(f.Writer, "┌%s┬%s┬%s┬%s┬%s┬%s┐\n",
-		strings.Repeat("─", idWidth+2),
-		strings.Repeat("─", projectWidth+2),
-		strings.Repeat("─", taskWidth+2),
-		strings.Repeat("─", durationWidth+2),
-		strings.Repeat("─", dateWidth+2),
-		strings.Repeat("─", descWidth+2))
-
-	fmt.Fprintf(f.Writer, "│ %-*s │ %-*s │ %-*s │ %-*s │ %-*s │ %-*s │\n",
-		idWidth, "ID",
-		projectWidth, "Project",
-		taskWidth, "Task",
-		durationWidth, "Duration",
-		dateWidth, "Date",
-		descWidth, "Description")
-
-	fmt.Fprintf(f.Writer, "├%s┼%s┼%s┼%s┼%s┼%s┤\n",
-		strings.Repeat("─", idWidth+2),
-		strings.Repeat("─", projectWidth+2),
-		strings.Repeat("─", taskWidth+2),
-		strings.Repeat("─", durationWidth+2),
-		strings.Repeat("─", dateWidth+2),
-		strings.Repeat("─", descWidth+2))
-
-	// Print entries
-	var totalDuration int
-	for _, e := range entries {
-		projectName := "Unknown"
-		taskName := "Unknown"
-		if e.Project != nil {
-			projectName = truncate(e.Project.Name, projectWidth)
-		}
-		if e.Task != nil {
-			taskName = truncate(e.Task.Name, taskWidth)
-		}
-
-		duration := formatDuration(e.Duration)
-		date := e.StartTime.Format("2006-01-02")
-		desc := truncate(e.Description, descWidth)
-		totalDuration += e.Duration
-
-		fmt.Fprintf(f.Writer, "│ %-*d │ %-*s │ %-*s │ %-*s │ %-*s │ %-*s │\n",
-			idWidth, e.ID,
-			projectWidth, projectName,
-			taskWidth, taskName,
-			durationWidth, duration,
-			dateWidth, date,
-			descWidth, desc)
-	}
-
-	// Print footer with total
-	fmt.Fprintf(f.Writer, "└%s┴%s┴%s┴%s┴%s┴%s┘\n",
-		strings.Repeat("─", idWidth+2),
-		strings.Repeat("─", projectWidth+2),
-		strings.Repeat("─", taskWidth+2),
-		strings.Repeat("─", durationWidth+2),
-		strings.Repeat("─", dateWidth+2),
-		strings.Repeat("─", descWidth+2))
-
-	fmt.Fprintf(f.Writer, "\nTotal: %s (%d entries)\n", formatDuration(totalDuration), len(entries))
-
-	return nil
-}
-
-// formatProjectsTable outputs projects as a table
-func (f *Formatter) formatProjectsTable(projects []api.Project) error {
-	if len(projects) == 0 {
-		fmt.Fprintln(f.Writer, "No projects found.")
-		return nil
-	}
-
-	idWidth := 8
-	nameWidth := 30
-	codeWidth := 10
-	statusWidth := 8
-	billableWidth := 8
-
-	fmt.Fprintf(f.Writer, "┌%s┬%s┬%s┬%s┬%s┐\n",
-		strings.Repeat("─", idWidth+2),
-		strings.Repeat("─", nameWidth+2),
-		strings.Repeat("─", codeWidth+2),
-		strings.Repeat("─", statusWidth+2),
-		strings.Repeat("─", billableWidth+2))
-
-	fmt.Fprintf(f.Writer, "│ %-*s │ %-*s │ %-*s │ %-*s │ %-*s │\n",
-		idWidth, "ID",
-		nameWidth, "Name",
-		codeWidth, "Code",
-		statusWidth, "Status",
-		billableWidth, "Billable")
-
-	fmt.Fprintf(f.Writer, "├%s┼%s┼%s┼%s┼%s┤\n",
-		strings.Repeat("─", idWidth+2),
-		strings.Repeat("─", nameWidth+2),
-		strings.Repeat("─", codeWidth+2),
-		strings.Repeat("─", statusWidth+2),
-		strings.Repeat("─", billableWidth+2))
-
-	for _, p := range projects {
-		status := "Inactive"
-		if p.Active {
-			status = "Active"
-		}
-		billable := "No"
-		if p.Billable {
-			billable = "Yes"
-		}
-
-		fmt.Fprintf(f.Writer, "│ %-*d │ %-*s │ %-*s │ %-*s │ %-*s │\n",
-			idWidth, p.ID,
-			nameWidth, truncate(p.Name, nameWidth),
-			codeWidth, truncate(p.Code, codeWidth),
-			statusWidth, status,
-			billableWidth, billable)
-	}
-
-	fmt.Fprintf(f.Writer, "└%s┴%s┴%s┴%s┴%s┘\n",
-		strings.Repeat("─", idWidth+2),
-		strings.Repeat("─", nameWidth+2),
-		strings.Repeat("─", codeWidth+2),
-		strings.Repeat("─", statusWidth+2),
-		strings.Repeat("─", billableWidth+2))
-
-	fmt.Fprintf(f.Writer, "\nTotal: %d projects\n", len(projects))
-
-	return nil
-}
-
-// formatTasksTable outputs tasks as a table
-func (f *Formatter) formatTasksTable(tasks []api.Task) error {
-	if len(tasks) == 0 {
-		fmt.Fprintln(f.Writer, "No tasks found.")
-		return nil
+		fmt.Fprintf(w, "  Duration:    %s\n", formatDuration(e.Duration))
 	}
-
-	idWidth := 8
-	nameWidth := 35
-	projectWidth := 20
-	statusWidth := 10
-	dueDateWidth := 12
-
-	fmt.Fprintf(f.Writer, "┌%s┬%s┬%s┬%s┬%s┐\n",
-		strings.Repeat("─", idWidth+2),
-		strings.Repeat("─", nameWidth+2),
-		strings.Repeat("─", projectWidth+2),
-		strings.Repeat("─", statusWidth+2),
-		strings.Repeat("─", dueDateWidth+2))
-
-	fmt.Fprintf(f.Writer, "│ %-*s │ %-*s │ %-*s │ %-*s │ %-*s │\n",
-		idWidth, "ID",
-		nameWidth, "Name",
-		projectWidth, "Project",
-		statusWidth, "Status",
-		dueDateWidth, "Due Date")
-
-	fmt.Fprintf(f.Writer, "├%s┼%s┼%s┼%s┼%s┤\n",
-		strings.Repeat("─", idWidth+2),
-		strings.Repeat("─", nameWidth+2),
-		strings.Repeat("─", projectWidth+2),
-		strings.Repeat("─", statusWidth+2),
-		strings.Repeat("─", dueDateWidth+2))
-
-	for _, t := range tasks {
-		status := "Open"
-		if t.Complete {
-			status = "Complete"
-		}
-		dueDate := "-"
-		if t.DueDate != "" {
-			dueDate = t.DueDate
-		}
-
-		fmt.Fprintf(f.Writer, "│ %-*d │ %-*s │ %-*d │ %-*s │ %-*s │\n",
-			idWidth, t.ID,
-			nameWidth, truncate(t.Name, nameWidth),
-			projectWidth, t.ProjectID,
-			statusWidth, status,
-			dueDateWidth, dueDate)
-	}
-
-	fmt.Fprintf(f.Writer, "└%s┴%s┴%s┴%s┴%s┘\n",
-		strings.Repeat("─", idWidth+2),
-		strings.Repeat("─", nameWidth+2),
-		strings.Repeat("─", projectWidth+2),
-		strings.Repeat("─", statusWidth+2),
-		strings.Repeat("─", dueDateWidth+2))
-
-	fmt.Fprintf(f.Writer, "\nTotal: %d tasks\n", len(tasks))
-
-	return nil
-}
-
-// CSV formatters
-func (f *Formatter) formatEntriesCSV(entries []api.TimeEntry) error {
-	w := csv.NewWriter(f.Writer)
-	defer w.Flush()
-
-	w.Write([]string{"id", "project_id", "project_name", "task_id", "task_name", "duration", "date", "description"})
-
-	for _, e := range entries {
-		projectName := ""
-		taskName := ""
-		projectID := 0
-		if e.Project != nil {
-			projectName = e.Project.Name
-			projectID = e.Project.ID
-		}
-		if e.Task != nil {
-			taskName = e.Task.Name
-			if projectID == 0 {
-				projectID = e.Task.ProjectID
-			}
-		}
-
-		w.Write([]string{
-			fmt.Sprintf("%d", e.ID),
-			fmt.Sprintf("%d", projectID),
-			projectName,
-			fmt.Sprintf("%d", e.TaskID),
-			taskName,
-			fmt.Sprintf("%d", e.Duration),
-			e.StartTime.Format("2006-01-02"),
-			e.Description,
-		})
-	}
-
-	return nil
-}
-
-func (f *Formatter) formatProjectsCSV(projects []api.Project) error {
-	w := csv.NewWriter(f.Writer)
-	defer w.Flush()
-
-	w.Write([]string{"id", "name", "code", "active", "billable", "client_id"})
-
-	for _, p := range projects {
-		w.Write([]string{
-			fmt.Sprintf("%d", p.ID),
-			p.Name,
-			p.Code,
-			fmt.Sprintf("%t", p.Active),
-			fmt.Sprintf("%t", p.Billable),
-			fmt.Sprintf("%d", p.ClientID),
-		})
-	}
-
-	return nil
-}
-
-func (f *Formatter) formatTasksCSV(tasks []api.Task) error {
-	w := csv.NewWriter(f.Writer)
-	defer w.Flush()
-
-	w.Write([]string{"id", "name", "project_id", "complete", "billable", "due_date"})
-
-	for _, t := range tasks {
-		w.Write([]string{
-			fmt.Sprintf("%d", t.ID),
-			t.Name,
-			fmt.Sprintf("%d", t.ProjectID),
-			fmt.Sprintf("%t", t.Complete),
-			fmt.Sprintf("%t", t.Billable),
-			t.DueDate,
-		})
-	}
-
-	return nil
-}
-
-func (f *Formatter) formatClientsTable(clients []api.PaymoClient) error {
-	if len(clients) == 0 {
-		fmt.Fprintln(f.Writer, "No clients found.")
-		return nil
-	}
-
-	idWidth := 8
-	nameWidth := 30
-	emailWidth := 25
-	activeWidth := 8
-
-	fmt.Fprintf(f.Writer, "┌%s┬%s┬%s┬%s┐\n",
-		strings.Repeat("─", idWidth+2),
-		strings.Repeat("─", nameWidth+2),
-		strings.Repeat("─", emailWidth+2),
-		strings.Repeat("─", activeWidth+2))
-
-	fmt.Fprintf(f.Writer, "│ %-*s │ %-*s │ %-*s │ %-*s │\n",
-		idWidth, "ID",
-		nameWidth, "Name",
-		emailWidth, "Email",
-		activeWidth, "Active")
-
-	fmt.Fprintf(f.Writer, "├%s┼%s┼%s┼%s┤\n",
-		strings.Repeat("─", idWidth+2),
-		strings.Repeat("─", nameWidth+2),
-		strings.Repeat("─", emailWidth+2),
-		strings.Repeat("─", activeWidth+2))
-
-	for _, c := range clients {
-		name := truncate(c.Name, nameWidth)
-		email := truncate(c.Email, emailWidth)
-		active := "No"
-		if c.Active {
-			active = "Yes"
-		}
-		fmt.Fprintf(f.Writer, "│ %-*d │ %-*s │ %-*s │ %-*s │\n",
-			idWidth, c.ID,
-			nameWidth, name,
-			emailWidth, email,
-			activeWidth, active)
-	}
-
-	fmt.Fprintf(f.Writer, "└%s┴%s┴%s┴%s┘\n",
-		strings.Repeat("─", idWidth+2),
-		strings.Repeat("─", nameWidth+2),
-		strings.Repeat("─", emailWidth+2),
-		strings.Repeat("─", activeWidth+2))
-
-	fmt.Fprintf(f.Writer, "%d client(s)\n", len(clients))
-	return nil
-}
-
-func (f *Formatter) formatClientsCSV(clients []api.PaymoClient) error {
-	w := csv.NewWriter(f.Writer)
-	defer w.Flush()
-
-	w.Write([]string{"id", "name", "email", "phone", "city", "country", "active"})
-
-	for _, c := range clients {
-		w.Write([]string{
-			fmt.Sprintf("%d", c.ID),
-			c.Name,
-			c.Email,
-			c.Phone,
-			c.City,
-			c.Country,
-			fmt.Sprintf("%t", c.Active),
-		})
-	}
-
 	return nil
 }
 
 // Helper functions
-func truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
-	}
-	return s[:maxLen-3] + "..."
-}
-
 func formatDuration(seconds int) string {
 	d := time.Duration(seconds) * time.Second
 	hours := int(d.Hours())
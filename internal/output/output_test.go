@@ -293,4 +293,91 @@ func TestFormatDuration(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestFormatTimeEntries_Envelope(t *testing.T) {
+	entries := []api.TimeEntry{
+		{ID: 1, Duration: 3600},
+		{ID: 2, Duration: 1800},
+	}
+	cachedAt := time.Date(2026, 2, 7, 9, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	f := NewFormatter("json")
+	f.Writer = &buf
+	f.Envelope = &Envelope{
+		Filters:  map[string]interface{}{"date": "today"},
+		Cached:   true,
+		Stale:    true,
+		CachedAt: &cachedAt,
+	}
+	if err := f.FormatTimeEntries(entries); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var result struct {
+		Data          []api.TimeEntry        `json:"data"`
+		Count         int                    `json:"count"`
+		TotalDuration int                    `json:"total_duration"`
+		Filters       map[string]interface{} `json:"filters"`
+		Cached        bool                   `json:"cached"`
+		Stale         bool                   `json:"stale"`
+		CachedAt      time.Time              `json:"cached_at"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, buf.String())
+	}
+	if len(result.Data) != 2 || result.Count != 2 {
+		t.Errorf("expected 2 entries, got data=%d count=%d", len(result.Data), result.Count)
+	}
+	if result.TotalDuration != 5400 {
+		t.Errorf("expected total_duration 5400, got %d", result.TotalDuration)
+	}
+	if result.Filters["date"] != "today" || !result.Cached || !result.Stale || !result.CachedAt.Equal(cachedAt) {
+		t.Errorf("unexpected metadata: %+v", result)
+	}
+}
+
+func TestEnvelope_IgnoredByTables(t *testing.T) {
+	var buf bytes.Buffer
+	f := NewFormatter("table")
+	f.Writer = &buf
+	f.Envelope = &Envelope{}
+	if err := f.FormatProjects([]api.Project{{ID: 1, Name: "Alpha"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(buf.String(), `"data"`) || !strings.Contains(buf.String(), "Alpha") {
+		t.Errorf("expected a plain table, got:\n%s", buf.String())
+	}
+	if strings.Contains(buf.String(), "total_duration") {
+		t.Error("projects should not report a total duration")
+	}
+}
+
+func TestFormatMockServer(t *testing.T) {
+	info := MockServerInfo{BaseURL: "http://127.0.0.1:8080/api"}
+
+	var buf bytes.Buffer
+	f := NewFormatter("json")
+	f.Writer = &buf
+	if err := f.FormatMockServer(info); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, buf.String())
+	}
+	if result["base_url"] != info.BaseURL || len(result) != 1 {
+		t.Errorf("expected only the base URL, got %v", result)
+	}
+
+	buf.Reset()
+	f = NewFormatter("table")
+	f.Writer = &buf
+	if err := f.FormatMockServer(info); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "PAYMO_BASE_URL="+info.BaseURL+" PAYMO_API_KEY=mock") {
+		t.Errorf("expected the environment to point paymo at the server, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,421 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ComputClaw/paymo-cli/internal/locale"
+)
+
+// Renderer writes a dataset in one output format. Renderers are registered
+// by name and selected with --format.
+type Renderer interface {
+	Render(w io.Writer, d *Dataset) error
+}
+
+// ValueRenderer is implemented by structured formats (json, yaml, ndjson)
+// that can encode arbitrary values, not only resource lists.
+type ValueRenderer interface {
+	RenderValue(w io.Writer, v interface{}) error
+}
+
+// Field is one column of a resource.
+type Field struct {
+	Name    string                           // machine name, used for CSV/TSV headers and --columns
+	Aliases []string                         // alternative names accepted by --columns
+	Header  string                           // human-readable table header
+	Width   int                              // preferred table column width, used as a sizing weight
+	Wrap    bool                             // wrap long values in tables instead of eliding them
+	Text    func(v interface{}) string       // human-readable value
+	Raw     func(v interface{}) string       // machine-readable value (defaults to Text)
+	Time    func(v interface{}) time.Time    // time-valued fields: formatted by the locale instead of Text/Raw
+	Date    bool                             // with Time: show only the calendar date
+	Total   func(items []interface{}) string // optional aggregate shown in subtotal rows
+	Kind    Kind                             // value type for --filter; time fields are KindTime
+}
+
+// text returns the human-readable value of the field. Times are shown in the
+// locale's timezone and formats, "-" when unset.
+func (fd Field) text(v interface{}, l *locale.Locale) string {
+	if fd.Time == nil {
+		return fd.Text(v)
+	}
+	t := fd.Time(v)
+	switch {
+	case t.IsZero():
+		return "-"
+	case fd.Date:
+		return l.Date(t)
+	}
+	return l.DateTime(t)
+}
+
+// raw returns the machine-readable value of the field. Times are ISO dates
+// or RFC 3339 timestamps in the locale's timezone, empty when unset.
+func (fd Field) raw(v interface{}, l *locale.Locale) string {
+	if fd.Time == nil {
+		if fd.Raw != nil {
+			return fd.Raw(v)
+		}
+		return fd.Text(v)
+	}
+	t := fd.Time(v)
+	switch {
+	case t.IsZero():
+		return ""
+	case fd.Date:
+		return l.In(t).Format("2006-01-02")
+	}
+	return l.RFC3339(t)
+}
+
+// Resource describes how a resource type is rendered by tabular formats.
+type Resource struct {
+	Name         string
+	Fields       []Field
+	TableColumns []string                                                 // default columns for table/markdown
+	CSVColumns   []string                                                 // default columns for csv/tsv
+	Empty        string                                                   // message when a table has no rows
+	Footer       func(items []interface{}) string                         // optional summary line below tables
+	Detail       func(w io.Writer, v interface{}, l *locale.Locale) error // human-readable single-item view
+	Duration     func(v interface{}) int                                  // optional seconds tracked by an item, for totals
+}
+
+// field returns the field with the given name or alias.
+func (r *Resource) field(name string) (Field, bool) {
+	for _, fd := range r.Fields {
+		if fd.Name == name {
+			return fd, true
+		}
+		for _, alias := range fd.Aliases {
+			if alias == name {
+				return fd, true
+			}
+		}
+	}
+	return Field{}, false
+}
+
+// FieldNames returns the names of all fields, in definition order.
+func (r *Resource) FieldNames() []string {
+	names := make([]string, len(r.Fields))
+	for i, fd := range r.Fields {
+		names[i] = fd.Name
+	}
+	return names
+}
+
+// ParseColumns validates a comma-separated column list such as
+// "id,project,duration" and returns the canonical field names.
+func (r *Resource) ParseColumns(spec string) ([]string, error) {
+	var names []string
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		fd, ok := r.field(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q for %s (available: %s)", name, r.Name, strings.Join(r.FieldNames(), ", "))
+		}
+		names = append(names, fd.Name)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no columns given")
+	}
+	return names, nil
+}
+
+// columns resolves column names to fields, skipping unknown names.
+func (r *Resource) columns(names []string) []Field {
+	fields := make([]Field, 0, len(names))
+	for _, name := range names {
+		if fd, ok := r.field(name); ok {
+			fields = append(fields, fd)
+		}
+	}
+	return fields
+}
+
+// Dataset is a value prepared for rendering.
+type Dataset struct {
+	Resource *Resource
+	Value    interface{}    // the original value, for structured formats
+	Items    []interface{}  // one element per row
+	Single   bool           // Value is a single item rather than a list
+	Columns  []string       // selected columns; nil means the format's defaults
+	Style    string         // table style (box, ascii, plain, compact); empty for box
+	Width    int            // maximum table width in cells; 0 for unlimited
+	Locale   *locale.Locale // timezone and date/time formats; nil for the defaults
+	Groups   []Group        // Items split by GroupBy; nil when not grouped
+	GroupBy  string         // field the groups share
+}
+
+// rows returns the table rows of a list: the items, each group followed by
+// a subtotal row when grouped.
+func (d *Dataset) rows(fields []Field) []tableRow {
+	l := d.locale()
+	itemRow := func(item interface{}) tableRow {
+		cells := make([]string, len(fields))
+		for i, fd := range fields {
+			cells[i] = fd.text(item, l)
+		}
+		return tableRow{cells: cells}
+	}
+
+	var rows []tableRow
+	if d.Groups == nil {
+		for _, item := range d.Items {
+			rows = append(rows, itemRow(item))
+		}
+		return rows
+	}
+	for _, g := range d.Groups {
+		for _, item := range g.Items {
+			rows = append(rows, itemRow(item))
+		}
+		rows = append(rows, tableRow{cells: subtotalCells(fields, g, d.GroupBy), subtotal: true})
+	}
+	return rows
+}
+
+// tableRow is one row of a table or markdown list.
+type tableRow struct {
+	cells    []string
+	subtotal bool
+}
+
+// locale returns the dataset's locale, or the default one.
+func (d *Dataset) locale() *locale.Locale {
+	if d.Locale != nil {
+		return d.Locale
+	}
+	return locale.Default()
+}
+
+// fields returns the selected columns, or the given defaults.
+func (d *Dataset) fields(defaults []string) []Field {
+	if len(d.Columns) > 0 {
+		return d.Resource.columns(d.Columns)
+	}
+	return d.Resource.columns(defaults)
+}
+
+var renderers = map[string]Renderer{}
+
+// RegisterRenderer makes a renderer available under name. Registering the
+// same name twice replaces the previous renderer.
+func RegisterRenderer(name string, r Renderer) {
+	renderers[strings.ToLower(name)] = r
+}
+
+// LookupRenderer returns the renderer registered under name. An empty name
+// selects the table renderer.
+func LookupRenderer(name string) (Renderer, error) {
+	name = strings.ToLower(name)
+	if name == "" {
+		name = "table"
+	}
+	r, ok := renderers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown output format %q (available: %s)", name, strings.Join(Formats(), ", "))
+	}
+	return r, nil
+}
+
+// Formats returns the registered format names, sorted.
+func Formats() []string {
+	names := make([]string, 0, len(renderers))
+	for name := range renderers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	RegisterRenderer("table", tableRenderer{})
+	RegisterRenderer("json", jsonRenderer{})
+	RegisterRenderer("ndjson", ndjsonRenderer{})
+	RegisterRenderer("yaml", yamlRenderer{})
+	RegisterRenderer("csv", delimitedRenderer{comma: ','})
+	RegisterRenderer("tsv", delimitedRenderer{comma: '\t'})
+	RegisterRenderer("markdown", markdownRenderer{})
+}
+
+// jsonRenderer writes indented JSON.
+type jsonRenderer struct{}
+
+func (jsonRenderer) Render(w io.Writer, d *Dataset) error {
+	return jsonRenderer{}.RenderValue(w, d.Value)
+}
+
+func (jsonRenderer) RenderValue(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// ndjsonRenderer writes one compact JSON document per line.
+type ndjsonRenderer struct{}
+
+func (ndjsonRenderer) Render(w io.Writer, d *Dataset) error {
+	if d.Single {
+		return ndjsonRenderer{}.RenderValue(w, d.Value)
+	}
+	encoder := json.NewEncoder(w)
+	if d.Groups != nil {
+		for _, g := range groupValues(d.Resource, d.Groups) {
+			if err := encoder.Encode(g); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, item := range d.Items {
+		if err := encoder.Encode(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ndjsonRenderer) RenderValue(w io.Writer, v interface{}) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+// yamlRenderer writes YAML using the same keys as the JSON output.
+type yamlRenderer struct{}
+
+func (yamlRenderer) Render(w io.Writer, d *Dataset) error {
+	return yamlRenderer{}.RenderValue(w, d.Value)
+}
+
+func (yamlRenderer) RenderValue(w io.Writer, v interface{}) error {
+	// Round-trip through JSON so the json struct tags (and field order) apply
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	resetYAMLStyle(&node)
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// resetYAMLStyle switches a node decoded from JSON to block style.
+func resetYAMLStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		resetYAMLStyle(c)
+	}
+}
+
+// delimitedRenderer writes CSV or TSV with machine-readable values.
+type delimitedRenderer struct {
+	comma rune
+}
+
+func (r delimitedRenderer) Render(w io.Writer, d *Dataset) error {
+	if d.Resource == nil {
+		return fmt.Errorf("this output cannot be rendered as %s", r.name())
+	}
+	fields := d.fields(d.Resource.CSVColumns)
+
+	cw := csv.NewWriter(w)
+	cw.Comma = r.comma
+	header := make([]string, len(fields))
+	for i, fd := range fields {
+		header[i] = fd.Name
+	}
+	cw.Write(header)
+	for _, item := range d.Items {
+		row := make([]string, len(fields))
+		for i, fd := range fields {
+			row[i] = fd.raw(item, d.locale())
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (r delimitedRenderer) name() string {
+	if r.comma == '\t' {
+		return "tsv"
+	}
+	return "csv"
+}
+
+// markdownRenderer writes GitHub-flavored Markdown tables.
+type markdownRenderer struct{}
+
+func (markdownRenderer) Render(w io.Writer, d *Dataset) error {
+	if d.Resource == nil {
+		return fmt.Errorf("this output cannot be rendered as markdown")
+	}
+	if len(d.Items) == 0 {
+		fmt.Fprintln(w, d.Resource.Empty)
+		return nil
+	}
+	fields := d.fields(d.Resource.TableColumns)
+
+	headers := make([]string, len(fields))
+	rules := make([]string, len(fields))
+	for i, fd := range fields {
+		headers[i] = markdownEscape(fd.Header)
+		rules[i] = "---"
+	}
+	fmt.Fprintf(w, "| %s |\n", strings.Join(headers, " | "))
+	fmt.Fprintf(w, "| %s |\n", strings.Join(rules, " | "))
+	for _, row := range d.rows(fields) {
+		cells := make([]string, len(fields))
+		for i, cell := range row.cells {
+			cells[i] = markdownEscape(cell)
+			if row.subtotal && cell != "" {
+				cells[i] = "**" + cells[i] + "**"
+			}
+		}
+		fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | "))
+	}
+
+	if d.Resource.Footer != nil && !d.Single {
+		fmt.Fprintf(w, "\n%s\n", d.Resource.Footer(d.Items))
+	}
+	return nil
+}
+
+// markdownEscape makes a value safe inside a table cell.
+func markdownEscape(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
+
+// renderFieldList writes one "Header: value" line per field for a single item.
+func renderFieldList(w io.Writer, fields []Field, item interface{}, l *locale.Locale) error {
+	width := 0
+	for _, fd := range fields {
+		if n := len(fd.Header); n > width {
+			width = n
+		}
+	}
+	for _, fd := range fields {
+		fmt.Fprintf(w, "%-*s  %s\n", width+1, fd.Header+":", fd.text(item, l))
+	}
+	return nil
+}
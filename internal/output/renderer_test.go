@@ -0,0 +1,317 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ComputClaw/paymo-cli/internal/api"
+	"github.com/ComputClaw/paymo-cli/internal/locale"
+	"gopkg.in/yaml.v3"
+)
+
+func testEntries() []api.TimeEntry {
+	return []api.TimeEntry{
+		{
+			ID:          1,
+			TaskID:      100,
+			StartTime:   time.Date(2026, 2, 7, 9, 0, 0, 0, time.UTC),
+			Duration:    5400,
+			Description: "Fix | pipe",
+			Project:     &api.Project{ID: 7, Name: "Website"},
+			Task:        &api.Task{ID: 100, Name: "Development", ProjectID: 7},
+		},
+		{
+			ID:        2,
+			TaskID:    101,
+			StartTime: time.Date(2026, 2, 8, 9, 0, 0, 0, time.UTC),
+			Duration:  1800,
+		},
+	}
+}
+
+func render(t *testing.T, format string, fn func(f *Formatter) error) string {
+	t.Helper()
+	var buf bytes.Buffer
+	f := NewFormatter(format)
+	f.Writer = &buf
+	if err := fn(f); err != nil {
+		t.Fatalf("%s: unexpected error: %v", format, err)
+	}
+	return buf.String()
+}
+
+func TestLookupRenderer(t *testing.T) {
+	for _, name := range []string{"", "table", "JSON", "ndjson", "yaml", "csv", "tsv", "markdown"} {
+		if _, err := LookupRenderer(name); err != nil {
+			t.Errorf("LookupRenderer(%q): unexpected error: %v", name, err)
+		}
+	}
+
+	_, err := LookupRenderer("xml")
+	if err == nil {
+		t.Fatal("expected error for unknown format")
+	}
+	if !strings.Contains(err.Error(), "xml") || !strings.Contains(err.Error(), "markdown") {
+		t.Errorf("expected error naming the format and available ones, got: %v", err)
+	}
+}
+
+func TestFormatter_UnknownFormat(t *testing.T) {
+	var buf bytes.Buffer
+	f := NewFormatter("xml")
+	f.Writer = &buf
+	if err := f.FormatProjects(nil); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestStructured(t *testing.T) {
+	tests := map[string]bool{
+		"json": true, "ndjson": true, "yaml": true,
+		"table": false, "csv": false, "tsv": false, "markdown": false, "xml": false,
+	}
+	for format, want := range tests {
+		if got := NewFormatter(format).Structured(); got != want {
+			t.Errorf("Structured() for %s = %v, want %v", format, got, want)
+		}
+	}
+}
+
+func TestRegisterRenderer(t *testing.T) {
+	RegisterRenderer("count", renderFunc(func(w io.Writer, d *Dataset) error {
+		_, err := io.WriteString(w, strings.Repeat("x", len(d.Items)))
+		return err
+	}))
+	defer delete(renderers, "count")
+
+	out := render(t, "count", func(f *Formatter) error { return f.FormatTimeEntries(testEntries()) })
+	if out != "xx" {
+		t.Errorf("expected custom renderer output, got %q", out)
+	}
+}
+
+func TestNDJSON(t *testing.T) {
+	out := render(t, "ndjson", func(f *Formatter) error { return f.FormatTimeEntries(testEntries()) })
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d: %q", len(lines), out)
+	}
+	var e api.TimeEntry
+	if err := json.Unmarshal([]byte(lines[1]), &e); err != nil {
+		t.Fatalf("invalid JSON line: %v", err)
+	}
+	if e.ID != 2 {
+		t.Errorf("expected entry 2 on second line, got %d", e.ID)
+	}
+}
+
+func TestYAML(t *testing.T) {
+	out := render(t, "yaml", func(f *Formatter) error {
+		return f.FormatProject(&api.Project{ID: 1, Name: "Website", Code: "007"})
+	})
+	if !strings.Contains(out, "name: Website") {
+		t.Errorf("expected JSON field names as keys, got:\n%s", out)
+	}
+	var got map[string]interface{}
+	if err := yaml.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("invalid YAML: %v", err)
+	}
+	if got["code"] != "007" {
+		t.Errorf("expected numeric-looking code to stay a string, got %#v", got["code"])
+	}
+}
+
+func TestTSV(t *testing.T) {
+	out := render(t, "tsv", func(f *Formatter) error { return f.FormatTimeEntries(testEntries()) })
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if lines[0] != "id\tproject_id\tproject_name\ttask_id\ttask_name\tduration\tdate\tdescription" {
+		t.Errorf("unexpected header: %q", lines[0])
+	}
+	if lines[1] != "1\t7\tWebsite\t100\tDevelopment\t5400\t2026-02-07\tFix | pipe" {
+		t.Errorf("unexpected row: %q", lines[1])
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	out := render(t, "markdown", func(f *Formatter) error { return f.FormatTimeEntries(testEntries()) })
+	if !strings.HasPrefix(out, "| ID | Project | Task | Duration | Date | Description |\n| --- |") {
+		t.Errorf("unexpected markdown header:\n%s", out)
+	}
+	if !strings.Contains(out, `| Fix \| pipe |`) {
+		t.Errorf("expected escaped pipe in cell:\n%s", out)
+	}
+	if !strings.Contains(out, "Total: 2h 0m (2 entries)") {
+		t.Errorf("expected total footer:\n%s", out)
+	}
+
+	empty := render(t, "markdown", func(f *Formatter) error { return f.FormatTasks(nil) })
+	if strings.TrimSpace(empty) != "No tasks found." {
+		t.Errorf("expected empty message, got %q", empty)
+	}
+}
+
+func TestFormatSuccess_YAML(t *testing.T) {
+	out := render(t, "yaml", func(f *Formatter) error { return f.FormatSuccess("Task completed", 5) })
+	if !strings.Contains(out, "status: ok") || !strings.Contains(out, "id: 5") {
+		t.Errorf("expected structured success result, got:\n%s", out)
+	}
+}
+
+// renderFunc adapts a function to the Renderer interface.
+type renderFunc func(w io.Writer, d *Dataset) error
+
+func (fn renderFunc) Render(w io.Writer, d *Dataset) error { return fn(w, d) }
+
+func TestParseColumns(t *testing.T) {
+	cols, err := EntryResource.ParseColumns("id, project,TASK,billable,start,end")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"id", "project_name", "task_name", "billable", "start", "end"}
+	if strings.Join(cols, ",") != strings.Join(want, ",") {
+		t.Errorf("expected %v, got %v", want, cols)
+	}
+
+	_, err = ProjectResource.ParseColumns("id,bogus")
+	if err == nil || !strings.Contains(err.Error(), `unknown column "bogus" for projects`) {
+		t.Errorf("expected unknown column error, got: %v", err)
+	}
+	if _, err := ProjectResource.ParseColumns(" , "); err == nil {
+		t.Error("expected error for empty column list")
+	}
+}
+
+func TestColumns_TableAndCSV(t *testing.T) {
+	cols := []string{"id", "duration"}
+
+	var buf bytes.Buffer
+	f := NewFormatter("csv")
+	f.Writer = &buf
+	f.Columns = cols
+	if err := f.FormatTimeEntries(testEntries()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != "id,duration\n1,5400\n2,1800\n" {
+		t.Errorf("unexpected CSV:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	f.Format = "table"
+	if err := f.FormatTimeEntries(testEntries()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "│ ID │ Duration │") || strings.Contains(out, "Description") {
+		t.Errorf("expected only the selected columns:\n%s", out)
+	}
+}
+
+func TestColumns_SingleItem(t *testing.T) {
+	var buf bytes.Buffer
+	f := NewFormatter("table")
+	f.Writer = &buf
+	f.Columns = []string{"name", "billable"}
+	if err := f.FormatProject(&api.Project{ID: 1, Name: "Website", Billable: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != "Name:      Website\nBillable:  Yes\n" {
+		t.Errorf("unexpected field list:\n%q", buf.String())
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	tmpl, err := ParseTemplate(`{{.ID}}\t{{.Task.Name}}\t{{duration .Duration}}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	f := NewFormatter("json") // the template replaces the format
+	f.Writer = &buf
+	f.Template = tmpl
+	if err := f.FormatTimeEntries(testEntries()[:1]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != "1\tDevelopment\t1h 30m\n" {
+		t.Errorf("unexpected template output: %q", buf.String())
+	}
+
+	if _, err := ParseTemplate("{{.ID"); err == nil || !strings.Contains(err.Error(), "invalid template") {
+		t.Errorf("expected parse error, got: %v", err)
+	}
+
+	// Entry 2 has no task: .Task.Name renders empty
+	buf.Reset()
+	entries := testEntries()
+	if entries[1].Task != nil {
+		t.Fatal("expected test entry 2 without a task")
+	}
+	if err := f.FormatTimeEntries(entries); err != nil {
+		t.Fatalf("unexpected error for nil task: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != len(entries) || !strings.HasPrefix(lines[1], "2\t\t") {
+		t.Errorf("unexpected template output with nil task: %q", buf.String())
+	}
+	if entries[1].Task != nil {
+		t.Error("rendering must not fill in the entry itself")
+	}
+
+	// Unknown fields still fail
+	tmpl, _ = ParseTemplate(`{{.Task.Nope}}`)
+	f.Template = tmpl
+	if err := f.FormatTimeEntries(entries); err == nil {
+		t.Error("expected execution error for an unknown field")
+	}
+}
+
+func TestLocale_TimeFields(t *testing.T) {
+	l, err := locale.New("America/Los_Angeles", "01/02/2006", "3:04PM")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	entries := testEntries()[:1] // 09:00 UTC is 01:00 in Los Angeles
+
+	var buf bytes.Buffer
+	f := NewFormatter("csv")
+	f.Writer = &buf
+	f.Locale = l
+	f.Columns = []string{"date", "start", "end"}
+	if err := f.FormatTimeEntries(entries); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != "date,start,end\n2026-02-07,2026-02-07T01:00:00-08:00,\n" {
+		t.Errorf("unexpected CSV:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	f.Format = "table"
+	if err := f.FormatTimeEntries(entries); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out := buf.String(); !strings.Contains(out, "02/07/2026") || !strings.Contains(out, "02/07/2026 1:00AM") || !strings.Contains(out, " - ") {
+		t.Errorf("expected localized table:\n%s", out)
+	}
+
+	buf.Reset()
+	f.Columns = nil
+	if err := f.FormatTimeEntry(&entries[0]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Start:       02/07/2026 1:00AM") {
+		t.Errorf("expected localized detail view:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	tmpl, _ := ParseTemplate("{{date .StartTime}} {{time .StartTime}}")
+	f.Template = tmpl
+	if err := f.FormatTimeEntries(entries); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != "02/07/2026 1:00AM\n" {
+		t.Errorf("unexpected template output: %q", buf.String())
+	}
+}
@@ -0,0 +1,196 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ComputClaw/paymo-cli/internal/locale"
+)
+
+// SortKey orders list output by one field.
+type SortKey struct {
+	Field string // canonical field name
+	Desc  bool
+}
+
+// ParseSort validates a --sort value such as "duration,-date" and returns the
+// keys in order of precedence. A leading "-" sorts that field descending.
+func (r *Resource) ParseSort(spec string) ([]SortKey, error) {
+	var keys []SortKey
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		desc := strings.HasPrefix(name, "-")
+		name = strings.TrimLeft(name, "+-")
+		if name == "" {
+			continue
+		}
+		fd, ok := r.field(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown sort field %q for %s (available: %s)", name, r.Name, strings.Join(r.FieldNames(), ", "))
+		}
+		keys = append(keys, SortKey{Field: fd.Name, Desc: desc})
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no sort fields given")
+	}
+	return keys, nil
+}
+
+// ParseGroupBy validates a --group-by field name and returns its canonical name.
+func (r *Resource) ParseGroupBy(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	fd, ok := r.field(name)
+	if !ok {
+		return "", fmt.Errorf("unknown group-by field %q for %s (available: %s)", name, r.Name, strings.Join(r.FieldNames(), ", "))
+	}
+	return fd.Name, nil
+}
+
+// Group is a run of list items sharing the same --group-by value.
+type Group struct {
+	Key   string // machine-readable value, used in structured output
+	Label string // human-readable value, used in subtotal rows
+	Items []interface{}
+}
+
+// groupValue is the structured (json, yaml, ndjson) form of a group.
+type groupValue struct {
+	Group         string        `json:"group"`
+	Count         int           `json:"count"`
+	TotalDuration *int          `json:"total_duration,omitempty"` // seconds, time entries only
+	Items         []interface{} `json:"items"`
+}
+
+// sortItems returns a copy of items ordered by the group-by field (when set)
+// and then by the sort keys. The sort is stable, so ties keep API order.
+func sortItems(res *Resource, items []interface{}, keys []SortKey, groupBy string, l *locale.Locale) []interface{} {
+	if l == nil {
+		l = locale.Default()
+	}
+	if groupBy != "" {
+		keys = append([]SortKey{{Field: groupBy}}, keys...)
+	}
+	var fields []Field
+	var desc []bool
+	for _, k := range keys {
+		if fd, ok := res.field(k.Field); ok {
+			fields = append(fields, fd)
+			desc = append(desc, k.Desc)
+		}
+	}
+
+	sorted := append([]interface{}(nil), items...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		for n, fd := range fields {
+			c := fd.compare(sorted[i], sorted[j], l)
+			if c == 0 {
+				continue
+			}
+			if desc[n] {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+	return sorted
+}
+
+// groupItems splits items, already sorted by the group-by field, into runs
+// that share its value.
+func groupItems(res *Resource, items []interface{}, groupBy string, l *locale.Locale) []Group {
+	fd, ok := res.field(groupBy)
+	if !ok {
+		return nil
+	}
+	if l == nil {
+		l = locale.Default()
+	}
+	var groups []Group
+	for _, item := range items {
+		label := fd.text(item, l)
+		key := fd.raw(item, l)
+		if key == "" {
+			key = label
+		}
+		if n := len(groups); n > 0 && groups[n-1].Key == key {
+			groups[n-1].Items = append(groups[n-1].Items, item)
+			continue
+		}
+		groups = append(groups, Group{Key: key, Label: label, Items: []interface{}{item}})
+	}
+	return groups
+}
+
+// groupValues converts groups to their structured form.
+func groupValues(res *Resource, groups []Group) []groupValue {
+	values := make([]groupValue, len(groups))
+	for i, g := range groups {
+		values[i] = groupValue{Group: g.Key, Count: len(g.Items), Items: g.Items}
+		if res.Duration != nil {
+			total := 0
+			for _, item := range g.Items {
+				total += res.Duration(item)
+			}
+			values[i].TotalDuration = &total
+		}
+	}
+	return values
+}
+
+// subtotalCells returns the table cells of a group's subtotal row: the group
+// label and item count, and the Total of every field that has one.
+func subtotalCells(fields []Field, g Group, groupBy string) []string {
+	cells := make([]string, len(fields))
+	label := fmt.Sprintf("%s (%d)", g.Label, len(g.Items))
+	placed := false
+	for i, fd := range fields {
+		switch {
+		case i == 0:
+		case fd.Name == groupBy:
+			cells[i] = label
+			placed = true
+		case fd.Total != nil:
+			cells[i] = fd.Total(g.Items)
+		}
+	}
+	cells[0] = "Subtotal"
+	if !placed {
+		cells[0] = "Subtotal: " + label
+	}
+	return cells
+}
+
+// compare orders two items by this field: times chronologically, numbers
+// numerically and everything else case-insensitively.
+func (fd Field) compare(a, b interface{}, l *locale.Locale) int {
+	if fd.Time != nil && !fd.Date {
+		ta, tb := fd.Time(a), fd.Time(b)
+		switch {
+		case ta.Before(tb):
+			return -1
+		case ta.After(tb):
+			return 1
+		}
+		return 0
+	}
+	return compareText(fd.raw(a, l), fd.raw(b, l))
+}
+
+// compareText compares two values numerically when both are numbers.
+func compareText(a, b string) int {
+	na, errA := strconv.ParseFloat(a, 64)
+	nb, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		switch {
+		case na < nb:
+			return -1
+		case na > nb:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
@@ -0,0 +1,172 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ComputClaw/paymo-cli/internal/api"
+)
+
+func groupEntries() []api.TimeEntry {
+	website := &api.Project{ID: 7, Name: "Website"}
+	app := &api.Project{ID: 8, Name: "App"}
+	day := func(d, h int) time.Time { return time.Date(2026, 2, d, h, 0, 0, 0, time.UTC) }
+	return []api.TimeEntry{
+		{ID: 1, StartTime: day(7, 9), Duration: 3600, Project: website},
+		{ID: 2, StartTime: day(8, 9), Duration: 1800, Project: app},
+		{ID: 3, StartTime: day(8, 13), Duration: 3600, Project: website},
+		{ID: 4, StartTime: day(9, 9), Duration: 900, Project: app},
+	}
+}
+
+func entryIDs(items []interface{}) []int {
+	ids := make([]int, len(items))
+	for i, item := range items {
+		ids[i] = entry(item).ID
+	}
+	return ids
+}
+
+func TestParseSort(t *testing.T) {
+	keys, err := EntryResource.ParseSort("duration, -day")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []SortKey{{Field: "duration"}, {Field: "date", Desc: true}}
+	if len(keys) != 2 || keys[0] != want[0] || keys[1] != want[1] {
+		t.Errorf("ParseSort = %+v, want %+v", keys, want)
+	}
+
+	if _, err := EntryResource.ParseSort("nope"); err == nil || !strings.Contains(err.Error(), "unknown sort field") {
+		t.Errorf("expected unknown field error, got %v", err)
+	}
+	if _, err := ProjectResource.ParseGroupBy("client"); err != nil {
+		t.Errorf("expected client alias to be accepted, got %v", err)
+	}
+}
+
+func TestSortItems(t *testing.T) {
+	entries := groupEntries()
+	items := make([]interface{}, len(entries))
+	for i := range entries {
+		items[i] = &entries[i]
+	}
+
+	keys, _ := EntryResource.ParseSort("duration,-start")
+	got := entryIDs(sortItems(EntryResource, items, keys, "", nil))
+	if want := []int{4, 2, 3, 1}; !equalInts(got, want) {
+		t.Errorf("sorted IDs = %v, want %v", got, want)
+	}
+
+	// Numeric fields sort as numbers, not text
+	keys, _ = EntryResource.ParseSort("-id")
+	got = entryIDs(sortItems(EntryResource, items, keys, "", nil))
+	if want := []int{4, 3, 2, 1}; !equalInts(got, want) {
+		t.Errorf("sorted IDs = %v, want %v", got, want)
+	}
+
+	// Grouping sorts by the group first; ties keep API order
+	got = entryIDs(sortItems(EntryResource, items, nil, "project_name", nil))
+	if want := []int{2, 4, 1, 3}; !equalInts(got, want) {
+		t.Errorf("grouped IDs = %v, want %v", got, want)
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestGroupBy_JSON(t *testing.T) {
+	out := render(t, "json", func(f *Formatter) error {
+		f.GroupBy = "date"
+		return f.FormatTimeEntries(groupEntries())
+	})
+
+	var groups []struct {
+		Group         string          `json:"group"`
+		Count         int             `json:"count"`
+		TotalDuration int             `json:"total_duration"`
+		Items         []api.TimeEntry `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(out), &groups); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(groups) != 3 {
+		t.Fatalf("expected 3 day groups, got %d", len(groups))
+	}
+	if groups[1].Group != "2026-02-08" || groups[1].Count != 2 || groups[1].TotalDuration != 5400 || len(groups[1].Items) != 2 {
+		t.Errorf("unexpected group: %+v", groups[1])
+	}
+}
+
+func TestGroupBy_NDJSON(t *testing.T) {
+	out := render(t, "ndjson", func(f *Formatter) error {
+		f.GroupBy = "project"
+		return f.FormatTimeEntries(groupEntries())
+	})
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"group":"App"`) {
+		t.Errorf("expected one line per group:\n%s", out)
+	}
+}
+
+func TestGroupBy_TableSubtotals(t *testing.T) {
+	var buf bytes.Buffer
+	f := NewFormatter("table")
+	f.Writer = &buf
+	f.Columns = []string{"id", "project", "duration"}
+	f.GroupBy = "project_name"
+	if err := f.FormatTimeEntries(groupEntries()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"│ Subtotal │ App (2)     │ 45m      │",
+		"│ Subtotal │ Website (2) │ 2h 0m    │",
+		"Total: 2h 45m (4 entries)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "App (2)") > strings.Index(out, "Website (2)") {
+		t.Errorf("expected groups in order:\n%s", out)
+	}
+}
+
+func TestGroupBy_SubtotalLabelWithoutGroupColumn(t *testing.T) {
+	out := render(t, "markdown", func(f *Formatter) error {
+		f.Columns = []string{"id", "duration"}
+		f.GroupBy = "project"
+		return f.FormatTimeEntries(groupEntries())
+	})
+	if !strings.Contains(out, "| **Subtotal: App (2)** | **45m** |") {
+		t.Errorf("expected markdown subtotal row:\n%s", out)
+	}
+}
+
+func TestGroupBy_PlainStyleSeparatesGroups(t *testing.T) {
+	var buf bytes.Buffer
+	f := NewFormatter("table")
+	f.Writer = &buf
+	f.Style = StylePlain
+	f.Columns = []string{"id", "duration"}
+	f.GroupBy = "project"
+	if err := f.FormatTimeEntries(groupEntries()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Subtotal: App (2)      45m\n\n1") {
+		t.Errorf("expected a blank line between groups:\n%s", buf.String())
+	}
+}
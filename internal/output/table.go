@@ -0,0 +1,340 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/width"
+)
+
+// Table styles, selected with output.table_style in config.yaml.
+const (
+	StyleBox     = "box"     // Unicode box drawing (default)
+	StyleASCII   = "ascii"   // +---+ borders for terminals without Unicode
+	StylePlain   = "plain"   // no borders, two-space gaps (used when piped)
+	StyleCompact = "compact" // no borders, single-space gaps, underlined header
+)
+
+// minColumnWidth is the narrowest a column is squeezed to when the table
+// does not fit the terminal.
+const minColumnWidth = 6
+
+// rule is a horizontal line of a table.
+type rule struct {
+	left, mid, right, fill string
+	pad                    int // fill added on each side of a column
+}
+
+// tableStyle describes the borders of a table style.
+type tableStyle struct {
+	left, sep, right    string // row borders
+	top, header, bottom *rule  // optional horizontal rules
+}
+
+var tableStyles = map[string]tableStyle{
+	StyleBox: {
+		left: "│ ", sep: " │ ", right: " │",
+		top:    &rule{"┌", "┬", "┐", "─", 1},
+		header: &rule{"├", "┼", "┤", "─", 1},
+		bottom: &rule{"└", "┴", "┘", "─", 1},
+	},
+	StyleASCII: {
+		left: "| ", sep: " | ", right: " |",
+		top:    &rule{"+", "+", "+", "-", 1},
+		header: &rule{"+", "+", "+", "-", 1},
+		bottom: &rule{"+", "+", "+", "-", 1},
+	},
+	StylePlain: {sep: "  "},
+	StyleCompact: {
+		sep:    " ",
+		header: &rule{"", " ", "", "─", 0},
+	},
+}
+
+// lookupTableStyle returns the named style; empty selects box.
+func lookupTableStyle(name string) (tableStyle, error) {
+	name = strings.ToLower(name)
+	if name == "" {
+		name = StyleBox
+	}
+	style, ok := tableStyles[name]
+	if !ok {
+		return tableStyle{}, fmt.Errorf("unknown table style %q (available: %s, %s, %s, %s)",
+			name, StyleBox, StyleASCII, StylePlain, StyleCompact)
+	}
+	return style, nil
+}
+
+// tableRenderer writes tables sized to the available width, and detail views
+// for single items.
+type tableRenderer struct{}
+
+func (tableRenderer) Render(w io.Writer, d *Dataset) error {
+	if d.Resource == nil {
+		return fmt.Errorf("this output cannot be rendered as a table")
+	}
+	if d.Single {
+		if len(d.Columns) == 0 && d.Resource.Detail != nil {
+			return d.Resource.Detail(w, d.Value, d.locale())
+		}
+		return renderFieldList(w, d.fields(d.Resource.TableColumns), d.Value, d.locale())
+	}
+	style, err := lookupTableStyle(d.Style)
+	if err != nil {
+		return err
+	}
+	if len(d.Items) == 0 {
+		fmt.Fprintln(w, d.Resource.Empty)
+		return nil
+	}
+	fields := d.fields(d.Resource.TableColumns)
+
+	// Collect cell text and the natural width of every column
+	rows := d.rows(fields)
+	natural := make([]int, len(fields))
+	for i, fd := range fields {
+		natural[i] = displayWidth(fd.Header)
+	}
+	for _, row := range rows {
+		for i, cell := range row.cells {
+			for _, line := range strings.Split(cell, "\n") {
+				if n := displayWidth(line); n > natural[i] {
+					natural[i] = n
+				}
+			}
+		}
+	}
+
+	avail := 0
+	if d.Width > 0 {
+		avail = d.Width - displayWidth(style.left) - displayWidth(style.right) -
+			displayWidth(style.sep)*(len(fields)-1)
+	}
+	widths := fitWidths(fields, natural, avail)
+
+	printRule := func(r *rule) {
+		if r == nil {
+			return
+		}
+		parts := make([]string, len(widths))
+		for i, cw := range widths {
+			parts[i] = strings.Repeat(r.fill, cw+2*r.pad)
+		}
+		fmt.Fprintln(w, r.left+strings.Join(parts, r.mid)+r.right)
+	}
+	printRow := func(values []string) {
+		// Each cell becomes one or more lines; the row is as tall as its tallest cell
+		lines := make([][]string, len(fields))
+		height := 1
+		for i, fd := range fields {
+			if fd.Wrap {
+				lines[i] = wrapText(values[i], widths[i])
+			} else {
+				lines[i] = []string{truncate(strings.ReplaceAll(values[i], "\n", " "), widths[i])}
+			}
+			if len(lines[i]) > height {
+				height = len(lines[i])
+			}
+		}
+		for l := 0; l < height; l++ {
+			parts := make([]string, len(fields))
+			for i := range fields {
+				text := ""
+				if l < len(lines[i]) {
+					text = lines[i][l]
+				}
+				parts[i] = padRight(text, widths[i])
+			}
+			line := style.left + strings.Join(parts, style.sep) + style.right
+			if style.right == "" {
+				line = strings.TrimRight(line, " ")
+			}
+			fmt.Fprintln(w, line)
+		}
+	}
+
+	headers := make([]string, len(fields))
+	for i, fd := range fields {
+		headers[i] = fd.Header
+	}
+	printRule(style.top)
+	printRow(headers)
+	printRule(style.header)
+	for r, row := range rows {
+		if row.subtotal {
+			printRule(style.header)
+		}
+		printRow(row.cells)
+		if row.subtotal && r < len(rows)-1 {
+			// Separate groups: a rule, or a blank line in borderless styles
+			if style.header != nil {
+				printRule(style.header)
+			} else {
+				fmt.Fprintln(w)
+			}
+		}
+	}
+	printRule(style.bottom)
+
+	if d.Resource.Footer != nil {
+		fmt.Fprintf(w, "\n%s\n", d.Resource.Footer(d.Items))
+	}
+	return nil
+}
+
+// fitWidths sizes columns to fit avail cells (0 for unlimited). Columns
+// narrower than an equal share of the space keep their natural width; the
+// others split what is left in proportion to their preferred widths.
+func fitWidths(fields []Field, natural []int, avail int) []int {
+	widths := append([]int(nil), natural...)
+	total := 0
+	for _, n := range natural {
+		total += n
+	}
+	if avail <= 0 || total <= avail {
+		return widths
+	}
+
+	flexible := make([]bool, len(fields))
+	for i := range flexible {
+		flexible[i] = true
+	}
+	remaining, count := avail, len(fields)
+	for changed := true; changed && count > 0; {
+		changed = false
+		for i := range fields {
+			if flexible[i] && natural[i] <= remaining/count {
+				flexible[i] = false
+				remaining -= natural[i]
+				count--
+				changed = true
+			}
+		}
+	}
+
+	weight := func(i int) int {
+		if fields[i].Width > 0 {
+			return fields[i].Width
+		}
+		return 10
+	}
+	totalWeight := 0
+	for i := range fields {
+		if flexible[i] {
+			totalWeight += weight(i)
+		}
+	}
+	used := 0
+	for i := range fields {
+		if flexible[i] {
+			widths[i] = max(remaining*weight(i)/totalWeight, min(natural[i], minColumnWidth))
+		}
+		used += widths[i]
+	}
+
+	// Hand out the cells lost to rounding
+	for grew := true; grew && used < avail; {
+		grew = false
+		for i := range fields {
+			if flexible[i] && widths[i] < natural[i] && used < avail {
+				widths[i]++
+				used++
+				grew = true
+			}
+		}
+	}
+	return widths
+}
+
+// wrapText breaks s into lines of at most w display cells, at spaces where
+// possible.
+func wrapText(s string, w int) []string {
+	var lines []string
+	for _, para := range strings.Split(s, "\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			// Break words that are wider than the column on their own
+			for displayWidth(word) > w {
+				if line != "" {
+					lines = append(lines, line)
+					line = ""
+				}
+				head := cutWidth(word, w)
+				lines = append(lines, head)
+				word = word[len(head):]
+			}
+			switch {
+			case word == "":
+			case line == "":
+				line = word
+			case displayWidth(line)+1+displayWidth(word) <= w:
+				line += " " + word
+			default:
+				lines = append(lines, line)
+				line = word
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// truncate elides s to at most maxLen display cells.
+func truncate(s string, maxLen int) string {
+	if displayWidth(s) <= maxLen {
+		return s
+	}
+	if maxLen <= 3 {
+		return "..."[:max(maxLen, 0)]
+	}
+	return cutWidth(s, maxLen-3) + "..."
+}
+
+// cutWidth returns the longest prefix of s that fits in w display cells.
+func cutWidth(s string, w int) string {
+	used := 0
+	for i, r := range s {
+		rw := runeWidth(r)
+		if used+rw > w {
+			if i == 0 {
+				// Always make progress, even if a wide rune overflows a tiny column
+				return string(r)
+			}
+			return s[:i]
+		}
+		used += rw
+	}
+	return s
+}
+
+// padRight pads s with spaces to w display cells.
+func padRight(s string, w int) string {
+	if n := displayWidth(s); n < w {
+		return s + strings.Repeat(" ", w-n)
+	}
+	return s
+}
+
+// displayWidth returns the number of terminal cells s occupies.
+func displayWidth(s string) int {
+	n := 0
+	for _, r := range s {
+		n += runeWidth(r)
+	}
+	return n
+}
+
+// runeWidth returns the terminal cells of r: 2 for East Asian wide and
+// fullwidth characters, 0 for combining marks and control characters.
+func runeWidth(r rune) int {
+	if r == 0 || unicode.IsControl(r) || unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return 0
+	}
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	}
+	return 1
+}
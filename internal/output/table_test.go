@@ -0,0 +1,134 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ComputClaw/paymo-cli/internal/api"
+)
+
+func renderTable(t *testing.T, style string, width int, projects []api.Project) string {
+	t.Helper()
+	var buf bytes.Buffer
+	f := NewFormatter("table")
+	f.Writer = &buf
+	f.Style = style
+	f.Width = width
+	if err := f.FormatProjects(projects); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return buf.String()
+}
+
+func TestTableStyles(t *testing.T) {
+	projects := []api.Project{{ID: 1, Name: "Website", Code: "WEB", Active: true}}
+
+	tests := []struct {
+		style string
+		want  string
+	}{
+		{StyleBox, "┌────┬─────────┬──────┬────────┬──────────┐\n│ ID │ Name    │ Code │ Status │ Billable │\n"},
+		{StyleASCII, "+----+---------+------+--------+----------+\n| ID | Name    | Code | Status | Billable |\n"},
+		{StylePlain, "ID  Name     Code  Status  Billable\n1   Website  WEB   Active  No\n"},
+		{StyleCompact, "ID Name    Code Status Billable\n── ─────── ──── ────── ────────\n1  Website WEB  Active No\n"},
+	}
+	for _, tt := range tests {
+		out := renderTable(t, tt.style, 0, projects)
+		if !strings.HasPrefix(out, tt.want) {
+			t.Errorf("%s style:\n%s\nwant prefix:\n%s", tt.style, out, tt.want)
+		}
+	}
+}
+
+func TestTableStyle_Unknown(t *testing.T) {
+	var buf bytes.Buffer
+	f := NewFormatter("table")
+	f.Writer = &buf
+	f.Style = "fancy"
+	err := f.FormatProjects([]api.Project{{ID: 1}})
+	if err == nil || !strings.Contains(err.Error(), "unknown table style") {
+		t.Errorf("expected unknown style error, got: %v", err)
+	}
+}
+
+func TestTable_FitsWidth(t *testing.T) {
+	projects := []api.Project{
+		{ID: 1, Name: "A very long project name that will not fit in a narrow terminal", Code: "LONG"},
+	}
+	out := renderTable(t, StyleBox, 50, projects)
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if strings.HasPrefix(line, "Total") || line == "" {
+			continue
+		}
+		if n := displayWidth(line); n > 50 {
+			t.Errorf("line is %d cells wide, want <= 50: %q", n, line)
+		}
+	}
+	if !strings.Contains(out, "│ A very lo... │") {
+		t.Errorf("expected elided name:\n%s", out)
+	}
+}
+
+func TestTable_WrapsDescription(t *testing.T) {
+	var buf bytes.Buffer
+	f := NewFormatter("table")
+	f.Writer = &buf
+	f.Width = 40
+	f.Columns = []string{"id", "description"}
+	entries := []api.TimeEntry{{ID: 1, Description: "Reviewed the pull request and fixed the failing tests"}}
+	if err := f.FormatTimeEntries(entries); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "│ 1  │ Reviewed the pull request and   │") ||
+		!strings.Contains(out, "│    │ fixed the failing tests         │") {
+		t.Errorf("expected wrapped description:\n%s", out)
+	}
+}
+
+func TestDisplayWidth(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"abc", 3},
+		{"日本語", 6},
+		{"ｈｉ", 4}, // fullwidth
+		{"é", 1}, // combining accent
+		{"Ünïcödé", 7},
+	}
+	for _, tt := range tests {
+		if got := displayWidth(tt.s); got != tt.want {
+			t.Errorf("displayWidth(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestTable_EastAsianAlignment(t *testing.T) {
+	out := renderTable(t, StyleBox, 0, []api.Project{
+		{ID: 1, Name: "ウェブサイト"},
+		{ID: 2, Name: "Website"},
+	})
+	lines := strings.Split(out, "\n")
+	want := displayWidth(lines[0])
+	for _, line := range lines[1:5] {
+		if n := displayWidth(line); n != want {
+			t.Errorf("misaligned row (%d cells, want %d): %q", n, want, line)
+		}
+	}
+}
+
+func TestTruncate_Wide(t *testing.T) {
+	if got := truncate("日本語テキスト", 7); got != "日本..." {
+		t.Errorf("truncate = %q, want %q", got, "日本...")
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	got := wrapText("one two three\nsupercalifragilistic", 8)
+	want := []string{"one two", "three", "supercal", "ifragili", "stic"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("wrapText = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,100 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/ComputClaw/paymo-cli/internal/locale"
+)
+
+// templateFuncs are available to --template in addition to the builtins.
+var templateFuncs = template.FuncMap{
+	"duration": formatDuration,
+	"hours": func(seconds int) string {
+		return fmt.Sprintf("%.2f", float64(seconds)/3600)
+	},
+	"date":     locale.Default().Date,
+	"time":     locale.Default().Time,
+	"datetime": locale.Default().DateTime,
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// ParseTemplate parses a --template value. The escapes \t and \n are
+// expanded so templates can be written in single-quoted shell strings.
+func ParseTemplate(text string) (*template.Template, error) {
+	text = strings.NewReplacer(`\t`, "\t", `\n`, "\n").Replace(text)
+	tmpl, err := template.New("output").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// executeTemplate writes the template once per item, each on its own line.
+// The date functions use the given locale.
+func executeTemplate(w io.Writer, tmpl *template.Template, items []interface{}, l *locale.Locale) error {
+	if l != nil {
+		tmpl = tmpl.Funcs(template.FuncMap{"date": l.Date, "time": l.Time, "datetime": l.DateTime})
+	}
+	for _, item := range items {
+		var b strings.Builder
+		if err := tmpl.Execute(&b, templateData(item)); err != nil {
+			return fmt.Errorf("executing template: %w", err)
+		}
+		out := b.String()
+		if !strings.HasSuffix(out, "\n") {
+			out += "\n"
+		}
+		if _, err := io.WriteString(w, out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// templateData returns item with its nil struct pointers (such as an entry's
+// Task or Project when they weren't included) replaced by zero values, so
+// '{{.Task.Name}}' renders empty instead of failing.
+func templateData(item interface{}) interface{} {
+	v := reflect.ValueOf(item)
+	isPtr := v.Kind() == reflect.Ptr
+	if isPtr {
+		if v.IsNil() {
+			return item
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return item
+	}
+
+	var filled reflect.Value
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if !v.Type().Field(i).IsExported() || field.Kind() != reflect.Ptr ||
+			!field.IsNil() || field.Type().Elem().Kind() != reflect.Struct {
+			continue
+		}
+		if !filled.IsValid() {
+			filled = reflect.New(v.Type()).Elem()
+			filled.Set(v)
+		}
+		filled.Field(i).Set(reflect.New(field.Type().Elem()))
+	}
+	if !filled.IsValid() {
+		return item
+	}
+	if isPtr {
+		return filled.Addr().Interface()
+	}
+	return filled.Interface()
+}